@@ -0,0 +1,19 @@
+// Package api embeds the OpenAPI description of the HTTP API and the page
+// that renders it.
+//
+// openapi.json is written by hand and kept honest by the tests in
+// internal/http, which check that it covers every route and that real
+// responses match its schemas.
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3.1 document, served at /openapi.json.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// DocsPage is an HTML page rendering OpenAPI, served at /docs.
+//
+//go:embed docs.html
+var DocsPage []byte
@@ -2,19 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 
+	"fintrack-go/internal/blob"
 	"fintrack-go/internal/config"
 	"fintrack-go/internal/db"
+	appgrpc "fintrack-go/internal/grpc"
 	apphttp "fintrack-go/internal/http"
+	"fintrack-go/internal/metrics"
+	"fintrack-go/internal/migrate"
+	"fintrack-go/internal/privacy"
+	"fintrack-go/internal/tracing"
+	"fintrack-go/internal/trash"
+	"fintrack-go/internal/webhook"
+	"fintrack-go/sql/migrations"
 )
 
 func main() {
@@ -31,36 +44,150 @@ func main() {
 	zerolog.SetGlobalLevel(level)
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, logger, os.Args[2:]))
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	database, err := db.NewDB(ctx, cfg.DatabaseURL, logger)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter, cfg.TracingSampleRatio)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+
+	appMetrics := metrics.New()
+
+	database, closeDatabase, err := openDatabase(ctx, cfg, logger, appMetrics)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to connect to database")
 	}
-	defer database.Close()
+	defer closeDatabase()
+	// Everything the server does goes through the instrumented database, so
+	// the domain counters see all of its changes.
+	database = appMetrics.InstrumentDatabase(database)
+
+	// Event streams only end when their clients disconnect, so the server
+	// ends them itself when it shuts down.
+	streamsCtx, endStreams := context.WithCancel(context.Background())
+	defer endStreams()
+
+	blobStore, err := openBlobStore(cfg)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to open blob store")
+	}
+
+	exporter := privacy.NewExporter(logger, database, blobStore, privacy.ExportConfig{TTL: cfg.ExportTTL})
+	eraser := privacy.NewEraser(logger, database, exporter, privacy.ErasureConfig{ConfirmationTTL: cfg.ErasureConfirmationTTL})
 
-	r := apphttp.SetupRoutes(logger, database)
+	routes := apphttp.SetupRoutes(logger, database,
+		apphttp.WithIdempotencyStore(apphttp.NewMemoryIdempotencyStore(), cfg.IdempotencyTTL),
+		apphttp.WithRateLimits(apphttp.NewMemoryRateLimitStore(), apphttp.RateLimitPolicies{
+			Users:        apphttp.RateLimitPolicy(cfg.RateLimitUsers),
+			Categories:   apphttp.RateLimitPolicy(cfg.RateLimitCategories),
+			Transactions: apphttp.RateLimitPolicy(cfg.RateLimitTransactions),
+			Summary:      apphttp.RateLimitPolicy(cfg.RateLimitSummary),
+			GraphQL:      apphttp.RateLimitPolicy(cfg.RateLimitGraphQL),
+			Webhooks:     apphttp.RateLimitPolicy(cfg.RateLimitWebhooks),
+			Events:       apphttp.RateLimitPolicy(cfg.RateLimitEvents),
+			Audit:        apphttp.RateLimitPolicy(cfg.RateLimitAudit),
+			Trash:        apphttp.RateLimitPolicy(cfg.RateLimitTrash),
+			Privacy:      apphttp.RateLimitPolicy(cfg.RateLimitPrivacy),
+			Attachments:  apphttp.RateLimitPolicy(cfg.RateLimitAttachments),
+			Goals:        apphttp.RateLimitPolicy(cfg.RateLimitGoals),
+			Accounts:     apphttp.RateLimitPolicy(cfg.RateLimitAccounts),
+			Debts:        apphttp.RateLimitPolicy(cfg.RateLimitDebts),
+			Securities:   apphttp.RateLimitPolicy(cfg.RateLimitSecurities),
+		}),
+		apphttp.WithMetrics(appMetrics),
+		apphttp.WithShutdown(streamsCtx),
+		apphttp.WithPrivacy(exporter, eraser),
+		apphttp.WithAttachments(blobStore, apphttp.AttachmentLimits{
+			MaxSize:      cfg.AttachmentMaxSize,
+			ContentTypes: cfg.AttachmentTypes,
+		}),
+	)
 
+	// chi requires middleware to be registered before any route, so the
+	// server-wide chain wraps the application router instead of being added
+	// to it.
+	r := chi.NewRouter()
 	r.Use(apphttp.RequestID)
+	r.Use(apphttp.Audit)
+	r.Use(apphttp.Tracing)
 	r.Use(apphttp.Logger(logger))
 	r.Use(apphttp.AccessLog)
 	r.Use(middleware.Recoverer)
 	r.Use(apphttp.ContentType)
 	r.Use(apphttp.CORSMiddleware(cfg.CORSEnabled))
+	r.Mount("/", routes)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.ServerPort),
 		Handler: r,
 	}
+	srv.RegisterOnShutdown(endStreams)
 
 	go func() {
 		logger.Info().Msgf("Starting server on port %d", cfg.ServerPort)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatal().Err(err).Msg("Server failed to start")
 		}
 	}()
 
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to listen for gRPC")
+	}
+	grpcServer := appgrpc.NewServer(logger, database)
+
+	go func() {
+		logger.Info().Msgf("Starting gRPC server on port %d", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.Fatal().Err(err).Msg("gRPC server failed")
+		}
+	}()
+
+	dispatcher := webhook.NewDispatcher(logger, database, webhook.Config{
+		PollInterval:   cfg.WebhookPollInterval,
+		BatchSize:      webhook.DefaultBatchSize,
+		Timeout:        cfg.WebhookTimeout,
+		MaxAttempts:    cfg.WebhookMaxAttempts,
+		InitialBackoff: cfg.WebhookRetryBackoff,
+		MaxBackoff:     cfg.WebhookMaxBackoff,
+	})
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	dispatcherDone := make(chan struct{})
+
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(dispatcherCtx)
+	}()
+
+	purger := trash.NewPurger(logger, database, trash.Config{
+		Interval:  cfg.TrashPurgeInterval,
+		Retention: cfg.TrashRetention,
+	})
+	purgerCtx, stopPurger := context.WithCancel(context.Background())
+	purgerDone := make(chan struct{})
+
+	go func() {
+		defer close(purgerDone)
+		purger.Run(purgerCtx)
+	}()
+
+	cleaner := blob.NewCleaner(logger, database, blobStore, blob.CleanerConfig{
+		Interval:  cfg.BlobCleanupInterval,
+		BatchSize: blob.DefaultBatchSize,
+	})
+	cleanerCtx, stopCleaner := context.WithCancel(context.Background())
+	cleanerDone := make(chan struct{})
+
+	go func() {
+		defer close(cleanerDone)
+		cleaner.Run(cleanerCtx)
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
@@ -75,5 +202,89 @@ func main() {
 		os.Exit(1)
 	}
 
+	stopGRPC(shutdownCtx, grpcServer)
+
+	// Deliveries cut short are retried by the next server to start.
+	stopDispatcher()
+	<-dispatcherDone
+
+	stopPurger()
+	<-purgerDone
+
+	// Blobs left queued are removed by the next server to start.
+	stopCleaner()
+	<-cleanerDone
+
+	exporter.Close()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	logger.Info().Msg("Server stopped gracefully")
 }
+
+// openDatabase connects to the backend DATABASE_URL selects. Pool metrics
+// and MIGRATE_ON_START only apply to Postgres; a SQLite database is always
+// migrated when it is opened.
+func openDatabase(ctx context.Context, cfg *config.Config, logger zerolog.Logger, appMetrics *metrics.Metrics) (db.Database, func(), error) {
+	if cfg.DatabaseBackend() == config.BackendSQLite {
+		database, err := db.NewSQLiteDB(ctx, cfg.SQLitePath(), logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return database, database.Close, nil
+	}
+
+	database, err := db.NewDB(ctx, cfg.DatabaseURL, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.MigrateOnStart {
+		migrator, err := migrate.New(database.GetPool(), migrations.FS, logger)
+		if err == nil {
+			err = migrator.Up(ctx)
+		}
+		if err != nil {
+			database.Close()
+			return nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	appMetrics.RegisterPool(database.GetPool())
+
+	return database, database.Close, nil
+}
+
+// openBlobStore opens the store BLOB_STORE selects for attached files.
+func openBlobStore(cfg *config.Config) (blob.Store, error) {
+	if cfg.BlobStore == config.BlobStoreS3 {
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+			Timeout:         time.Minute,
+		})
+	}
+	return blob.NewLocalStore(cfg.BlobDir)
+}
+
+// stopGRPC lets in-flight gRPC calls finish, cutting off any still running
+// when ctx is done.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/config"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/migrate"
+	"fintrack-go/sql/migrations"
+)
+
+const migrateUsage = `Usage: fintrack migrate <command>
+
+Commands:
+  up                  Apply all pending migrations (default)
+  down [N]            Revert the last N applied migrations (default 1)
+  to VERSION          Apply or revert migrations until VERSION is the latest applied; 0 reverts all
+  status              List migrations and whether they are applied
+  baseline VERSION    Record migrations up to VERSION as applied without running them,
+                      for databases created with the old migrate.sh
+`
+
+// runMigrate implements the migrate subcommand and returns the exit code.
+func runMigrate(cfg *config.Config, logger zerolog.Logger, args []string) int {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Fprint(os.Stdout, migrateUsage)
+		return 0
+	}
+
+	run, err := parseMigrateCommand(command, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, migrateUsage)
+		return 2
+	}
+
+	if cfg.DatabaseBackend() == config.BackendSQLite {
+		fmt.Fprintln(os.Stderr, "SQLite databases are migrated automatically when the server opens them")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	database, err := db.NewDB(ctx, cfg.DatabaseURL, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to connect to database")
+		return 1
+	}
+	defer database.Close()
+
+	migrator, err := migrate.New(database.GetPool(), migrations.FS, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to load migrations")
+		return 1
+	}
+
+	if err := run(ctx, migrator); err != nil {
+		logger.Error().Err(err).Str("command", command).Msg("Migration failed")
+		return 1
+	}
+	return 0
+}
+
+func parseMigrateCommand(command string, args []string) (func(context.Context, *migrate.Migrator) error, error) {
+	switch command {
+	case "up":
+		return func(ctx context.Context, m *migrate.Migrator) error {
+			return m.Up(ctx)
+		}, nil
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid number of migrations to revert %q", args[0])
+			}
+			steps = n
+		}
+		return func(ctx context.Context, m *migrate.Migrator) error {
+			return m.Down(ctx, steps)
+		}, nil
+	case "to", "baseline":
+		if len(args) == 0 {
+			return nil, errors.New(command + " requires a version")
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < 0 {
+			return nil, fmt.Errorf("invalid version %q", args[0])
+		}
+		if command == "to" {
+			return func(ctx context.Context, m *migrate.Migrator) error {
+				return m.To(ctx, version)
+			}, nil
+		}
+		return func(ctx context.Context, m *migrate.Migrator) error {
+			return m.Baseline(ctx, version)
+		}, nil
+	case "status":
+		return printMigrationStatus, nil
+	default:
+		return nil, fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,56 @@
+// Package blob stores the files attached to transactions, and data exports,
+// on the local filesystem or in an S3-compatible object store, and removes
+// attachments once the database no longer refers to them.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned by Get for a key with no blob.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by key. Keys are slash-separated paths such as
+// "attachments/<user>/<id>"; Store implementations may reject keys with
+// empty, "." or ".." elements.
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any blob there.
+	// It fails if r has fewer or more bytes than size.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns a reader for the blob stored under key, which the caller
+	// closes, or ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a key with no blob
+	// is not an error, so deletions can be retried.
+	Delete(ctx context.Context, key string) error
+	// List returns the keys of the blobs stored under prefix, in no
+	// particular order. The prefix is empty or a key ending in a slash, such
+	// as "exports/".
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// validKey reports whether key is a relative slash-separated path without
+// empty, "." or ".." elements.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, element := range strings.Split(key, "/") {
+		if element == "" || element == "." || element == ".." || strings.ContainsRune(element, '\\') {
+			return false
+		}
+	}
+	return true
+}
+
+// validPrefix reports whether prefix can be listed: it is empty or a valid
+// key followed by a slash.
+func validPrefix(prefix string) bool {
+	return prefix == "" || (strings.HasSuffix(prefix, "/") && validKey(strings.TrimSuffix(prefix, "/")))
+}
+
+// errInvalidKey is returned for keys validKey rejects.
+var errInvalidKey = errors.New("invalid blob key")
@@ -0,0 +1,103 @@
+package blob
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+)
+
+// DefaultBatchSize is a BatchSize suited to most deployments.
+const DefaultBatchSize = 100
+
+// CleanerConfig controls how orphaned blobs are removed.
+type CleanerConfig struct {
+	// Interval is how often Run removes orphaned blobs.
+	Interval time.Duration
+	// BatchSize is how many blobs are removed at a time.
+	BatchSize int
+}
+
+// Cleaner removes the blobs of deleted attachments. The database queues the
+// storage key of every attachment it deletes, whether on its own, with its
+// transaction when the trash is purged, or with its user when they are
+// erased, and the Cleaner deletes them from the store. Several cleaners may
+// share a database and store.
+type Cleaner struct {
+	logger zerolog.Logger
+	db     db.Database
+	store  Store
+	config CleanerConfig
+}
+
+func NewCleaner(logger zerolog.Logger, database db.Database, store Store, config CleanerConfig) *Cleaner {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	return &Cleaner{
+		logger: logger,
+		db:     database,
+		store:  store,
+		config: config,
+	}
+}
+
+// Run removes orphaned blobs every Interval until ctx is done.
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := c.Clean(ctx)
+		if err != nil && ctx.Err() == nil {
+			c.logger.Error().Err(err).Msg("Failed to remove orphaned blobs")
+		}
+		if n > 0 {
+			c.logger.Info().Int("removed", n).Msg("Removed orphaned blobs")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Clean removes the orphaned blobs queued so far and returns how many there
+// were. A blob that can't be deleted stays queued and is retried next time.
+func (c *Cleaner) Clean(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		keys, err := c.db.ListOrphanedBlobs(ctx, c.config.BatchSize)
+		if err != nil {
+			return removed, err
+		}
+
+		var deleted []string
+		for _, key := range keys {
+			if err := c.store.Delete(ctx, key); err != nil {
+				if ctx.Err() != nil {
+					return removed, ctx.Err()
+				}
+				c.logger.Warn().Err(err).Str("key", key).Msg("Failed to delete orphaned blob")
+				continue
+			}
+			deleted = append(deleted, key)
+		}
+		if len(deleted) > 0 {
+			if err := c.db.ForgetOrphanedBlobs(ctx, deleted); err != nil {
+				return removed, err
+			}
+			removed += len(deleted)
+		}
+
+		// Stop at a short batch, or when nothing in a full one could be
+		// deleted, rather than retrying the same keys until the next tick.
+		if len(keys) < c.config.BatchSize || len(deleted) == 0 {
+			return removed, nil
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// failingStore fails to delete the keys in fail.
+type failingStore struct {
+	Store
+	mu   sync.Mutex
+	fail map[string]bool
+}
+
+func (s *failingStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail[key] {
+		return errors.New("unavailable")
+	}
+	return s.Store.Delete(ctx, key)
+}
+
+// attach stores a blob and records it as an attachment of a new transaction.
+func attach(t *testing.T, database db.Database, store Store, userID string) *models.Attachment {
+	t.Helper()
+	ctx := context.Background()
+	transaction, err := database.CreateTransaction(ctx, userID, nil, 10, nil, time.Now())
+	require.NoError(t, err)
+	key := "attachments/" + userID + "/" + transaction.ID
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("%PDF"), 4, "application/pdf"))
+	attachment, err := database.CreateAttachment(ctx, userID, transaction.ID, models.Attachment{
+		Filename:    "receipt.pdf",
+		ContentType: "application/pdf",
+		Size:        4,
+		StorageKey:  key,
+	})
+	require.NoError(t, err)
+	return attachment
+}
+
+func exists(t *testing.T, store Store, key string) bool {
+	t.Helper()
+	r, err := store.Get(context.Background(), key)
+	if errors.Is(err, ErrNotFound) {
+		return false
+	}
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, r)
+	r.Close()
+	return true
+}
+
+func TestCleaner_Clean(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "blobs@example.com")
+	require.NoError(t, err)
+	local, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	store := &failingStore{Store: local, fail: make(map[string]bool)}
+
+	kept := attach(t, database, store, user.ID)
+	deleted := attach(t, database, store, user.ID)
+	failing := attach(t, database, store, user.ID)
+	require.NoError(t, database.DeleteAttachment(ctx, user.ID, deleted.ID))
+	require.NoError(t, database.DeleteAttachment(ctx, user.ID, failing.ID))
+	store.fail[failing.StorageKey] = true
+
+	cleaner := NewCleaner(zerolog.Nop(), database, store, CleanerConfig{Interval: time.Hour, BatchSize: 1})
+	n, err := cleaner.Clean(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.True(t, exists(t, store, kept.StorageKey))
+	assert.False(t, exists(t, store, deleted.StorageKey))
+	assert.True(t, exists(t, store, failing.StorageKey))
+
+	keys, err := database.ListOrphanedBlobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{failing.StorageKey}, keys, "retried next time")
+
+	store.fail[failing.StorageKey] = false
+	n, err = cleaner.Clean(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.False(t, exists(t, store, failing.StorageKey))
+}
+
+func TestCleaner_CleansErasedUsers(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "erased@example.com")
+	require.NoError(t, err)
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	attachment := attach(t, database, store, user.ID)
+
+	require.NoError(t, database.DeleteUser(ctx, user.ID))
+	n, err := NewCleaner(zerolog.Nop(), database, store, CleanerConfig{Interval: time.Hour}).Clean(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.False(t, exists(t, store, attachment.StorageKey))
+}
+
+func TestCleaner_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "run@example.com")
+	require.NoError(t, err)
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	cleaner := NewCleaner(zerolog.Nop(), database, store, CleanerConfig{Interval: 10 * time.Millisecond})
+	done := make(chan struct{})
+	go func() {
+		cleaner.Run(ctx)
+		close(done)
+	}()
+
+	attachment := attach(t, database, store, user.ID)
+	require.NoError(t, database.DeleteAttachment(ctx, user.ID, attachment.ID))
+	assert.Eventually(t, func() bool { return !exists(t, store, attachment.StorageKey) }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after ctx was cancelled")
+	}
+}
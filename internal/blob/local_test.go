@@ -0,0 +1,96 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore runs the behaviour every Store must have against store.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	key := "attachments/user/receipt"
+
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+	r, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "%PDF-1.4", string(data))
+
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("replaced"), 8, "text/plain"))
+	r, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	data, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "replaced", string(data))
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, key), "deleting a missing blob")
+
+	assert.Error(t, store.Put(ctx, "attachments/short", strings.NewReader("abc"), 4, "text/plain"), "fewer bytes than size")
+	assert.Error(t, store.Put(ctx, "attachments/long", strings.NewReader("abcde"), 4, "text/plain"), "more bytes than size")
+	_, err = store.Get(ctx, "attachments/short")
+	assert.ErrorIs(t, err, ErrNotFound, "failed uploads leave nothing behind")
+
+	listed := []string{"exports/a/archive.zip", "exports/a/export.json", "exports/b/export.json"}
+	for _, key := range append(listed, "attachments/user/other") {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"))
+	}
+	keys, err := store.List(ctx, "exports/")
+	require.NoError(t, err)
+	sort.Strings(keys)
+	assert.Equal(t, listed, keys)
+	keys, err = store.List(ctx, "exports/a/")
+	require.NoError(t, err)
+	sort.Strings(keys)
+	assert.Equal(t, listed[:2], keys)
+	keys, err = store.List(ctx, "missing/")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	for _, key := range append(listed, "attachments/user/other") {
+		require.NoError(t, store.Delete(ctx, key))
+	}
+	keys, err = store.List(ctx, "exports/")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	for _, prefix := range []string{"exports", "/exports/", "../", "exports//"} {
+		_, err := store.List(ctx, prefix)
+		assert.ErrorIs(t, err, errInvalidKey, prefix)
+	}
+
+	for _, key := range []string{"", "../escape", "attachments/../../escape", "/absolute", "attachments//double", `attachments\..\escape`} {
+		assert.ErrorIs(t, store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"), errInvalidKey, key)
+		_, err := store.Get(ctx, key)
+		assert.ErrorIs(t, err, errInvalidKey, key)
+		assert.ErrorIs(t, store.Delete(ctx, key), errInvalidKey, key)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(dir, "blobs"))
+	require.NoError(t, err)
+
+	testStore(t, store)
+
+	entries, err := os.ReadDir(filepath.Join(dir, "blobs", "attachments"))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.True(t, entry.IsDir(), "no temporary files are left: %s", entry.Name())
+	}
+	_, err = os.Stat(filepath.Join(dir, "escape"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,7 @@
+package blob
+
+import "testing"
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
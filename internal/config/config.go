@@ -1,14 +1,143 @@
 package config
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/caarlos0/env/v11"
 )
 
+// Database backends, chosen by the scheme of DATABASE_URL.
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+)
+
+// Blob stores, chosen by BLOB_STORE.
+const (
+	BlobStoreLocal = "local"
+	BlobStoreS3    = "s3"
+)
+
 type Config struct {
-	DatabaseURL string `env:"DATABASE_URL,required"`
-	ServerPort  int    `env:"SERVER_PORT" envDefault:"8080"`
-	LogLevel    string `env:"LOG_LEVEL" envDefault:"INFO"`
-	CORSEnabled bool   `env:"CORS_ENABLED" envDefault:"false"`
+	DatabaseURL    string        `env:"DATABASE_URL,required"`
+	ServerPort     int           `env:"SERVER_PORT" envDefault:"8080"`
+	GRPCPort       int           `env:"GRPC_PORT" envDefault:"9090"`
+	LogLevel       string        `env:"LOG_LEVEL" envDefault:"INFO"`
+	CORSEnabled    bool          `env:"CORS_ENABLED" envDefault:"false"`
+	MigrateOnStart bool          `env:"MIGRATE_ON_START" envDefault:"false"`
+	IdempotencyTTL time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+
+	TracingExporter    string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+
+	RateLimitUsers        RateLimit `env:"RATE_LIMIT_USERS" envDefault:"10/1m"`
+	RateLimitCategories   RateLimit `env:"RATE_LIMIT_CATEGORIES" envDefault:"60/1m"`
+	RateLimitTransactions RateLimit `env:"RATE_LIMIT_TRANSACTIONS" envDefault:"120/1m"`
+	RateLimitSummary      RateLimit `env:"RATE_LIMIT_SUMMARY" envDefault:"30/1m"`
+	RateLimitGraphQL      RateLimit `env:"RATE_LIMIT_GRAPHQL" envDefault:"60/1m"`
+	RateLimitWebhooks     RateLimit `env:"RATE_LIMIT_WEBHOOKS" envDefault:"30/1m"`
+	RateLimitEvents       RateLimit `env:"RATE_LIMIT_EVENTS" envDefault:"30/1m"`
+	RateLimitAudit        RateLimit `env:"RATE_LIMIT_AUDIT" envDefault:"30/1m"`
+	RateLimitTrash        RateLimit `env:"RATE_LIMIT_TRASH" envDefault:"30/1m"`
+	RateLimitPrivacy      RateLimit `env:"RATE_LIMIT_PRIVACY" envDefault:"10/1m"`
+	RateLimitAttachments  RateLimit `env:"RATE_LIMIT_ATTACHMENTS" envDefault:"30/1m"`
+	RateLimitGoals        RateLimit `env:"RATE_LIMIT_GOALS" envDefault:"60/1m"`
+	RateLimitAccounts     RateLimit `env:"RATE_LIMIT_ACCOUNTS" envDefault:"60/1m"`
+	RateLimitDebts        RateLimit `env:"RATE_LIMIT_DEBTS" envDefault:"60/1m"`
+	RateLimitSecurities   RateLimit `env:"RATE_LIMIT_SECURITIES" envDefault:"60/1m"`
+
+	// A webhook delivery is tried up to WebhookMaxAttempts times, waiting
+	// WebhookRetryBackoff after the first failure and twice as long after
+	// each one since, up to WebhookMaxBackoff.
+	WebhookPollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"2s"`
+	WebhookTimeout      time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookMaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookRetryBackoff time.Duration `env:"WEBHOOK_RETRY_BACKOFF" envDefault:"30s"`
+	WebhookMaxBackoff   time.Duration `env:"WEBHOOK_MAX_BACKOFF" envDefault:"1h"`
+
+	// Deleted categories and transactions are purged every TrashPurgeInterval
+	// once they have been in the trash for TrashRetention. A zero retention
+	// keeps them until they are restored.
+	TrashRetention     time.Duration `env:"TRASH_RETENTION" envDefault:"720h"`
+	TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
+
+	// Data exports can be downloaded for ExportTTL, and an erasure must be
+	// confirmed within ErasureConfirmationTTL of being requested.
+	ExportTTL              time.Duration `env:"EXPORT_TTL" envDefault:"24h"`
+	ErasureConfirmationTTL time.Duration `env:"ERASURE_CONFIRMATION_TTL" envDefault:"15m"`
+
+	// Files attached to transactions are kept under BlobDir with the local
+	// store, or in an S3-compatible bucket with the s3 one. Uploads may have
+	// up to AttachmentMaxSize bytes and one of AttachmentTypes, as sniffed
+	// from their content. The files of deleted attachments are removed every
+	// BlobCleanupInterval.
+	BlobStore           string        `env:"BLOB_STORE" envDefault:"local"`
+	BlobDir             string        `env:"BLOB_DIR" envDefault:"data/blobs"`
+	S3Endpoint          string        `env:"S3_ENDPOINT"`
+	S3Region            string        `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket            string        `env:"S3_BUCKET"`
+	S3AccessKeyID       string        `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey   string        `env:"S3_SECRET_ACCESS_KEY"`
+	S3PathStyle         bool          `env:"S3_PATH_STYLE" envDefault:"false"`
+	AttachmentMaxSize   int64         `env:"ATTACHMENT_MAX_SIZE" envDefault:"10485760"`
+	AttachmentTypes     []string      `env:"ATTACHMENT_TYPES" envDefault:"image/jpeg,image/png,image/webp,application/pdf"`
+	BlobCleanupInterval time.Duration `env:"BLOB_CLEANUP_INTERVAL" envDefault:"5m"`
+}
+
+// RateLimit is a request budget written as "<requests>/<window>", for example
+// "120/1m". "off" or "0" disables the limit.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "off" || s == "0" {
+		*l = RateLimit{}
+		return nil
+	}
+
+	requests, window, ok := strings.Cut(s, "/")
+	if !ok {
+		return fmt.Errorf("invalid rate limit %q: expected <requests>/<window>", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid rate limit %q: requests must be a non-negative integer", s)
+	}
+
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid rate limit %q: window must be a positive duration", s)
+	}
+
+	*l = RateLimit{Requests: n, Window: d}
+	return nil
+}
+
+// DatabaseBackend returns the backend DATABASE_URL selects: sqlite for
+// sqlite: URLs and postgres for postgres:// and postgresql:// ones.
+func (c *Config) DatabaseBackend() string {
+	scheme, _, _ := strings.Cut(c.DatabaseURL, ":")
+	switch strings.ToLower(scheme) {
+	case "sqlite":
+		return BackendSQLite
+	case "postgres", "postgresql":
+		return BackendPostgres
+	}
+	return ""
+}
+
+// SQLitePath returns the database file of a sqlite: URL, written either as
+// sqlite:relative/path.db or sqlite:///absolute/path.db.
+func (c *Config) SQLitePath() string {
+	_, path, _ := strings.Cut(c.DatabaseURL, ":")
+	return strings.TrimPrefix(path, "//")
 }
 
 func Load() (*Config, error) {
@@ -16,5 +145,29 @@ func Load() (*Config, error) {
 	if err := env.Parse(&cfg); err != nil {
 		return nil, err
 	}
+
+	switch cfg.DatabaseBackend() {
+	case BackendSQLite:
+		if cfg.SQLitePath() == "" {
+			return nil, fmt.Errorf("invalid DATABASE_URL %q: sqlite URL has no file path", cfg.DatabaseURL)
+		}
+	case BackendPostgres:
+	default:
+		return nil, fmt.Errorf("invalid DATABASE_URL: scheme must be postgres, postgresql or sqlite")
+	}
+
+	switch cfg.BlobStore {
+	case BlobStoreLocal:
+	case BlobStoreS3:
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			return nil, fmt.Errorf("BLOB_STORE=s3 requires S3_ENDPOINT and S3_BUCKET")
+		}
+	default:
+		return nil, fmt.Errorf("invalid BLOB_STORE %q: must be local or s3", cfg.BlobStore)
+	}
+	if cfg.AttachmentMaxSize <= 0 {
+		return nil, fmt.Errorf("invalid ATTACHMENT_MAX_SIZE %d: must be positive", cfg.AttachmentMaxSize)
+	}
+
 	return &cfg, nil
 }
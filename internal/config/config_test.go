@@ -0,0 +1,153 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    RateLimit
+		wantErr bool
+	}{
+		{name: "per minute", input: "120/1m", want: RateLimit{Requests: 120, Window: time.Minute}},
+		{name: "per second", input: "5/1s", want: RateLimit{Requests: 5, Window: time.Second}},
+		{name: "off", input: "off", want: RateLimit{}},
+		{name: "zero", input: "0", want: RateLimit{}},
+		{name: "missing window", input: "120", wantErr: true},
+		{name: "bad requests", input: "many/1m", wantErr: true},
+		{name: "negative requests", input: "-1/1m", wantErr: true},
+		{name: "bad window", input: "10/soon", wantErr: true},
+		{name: "zero window", input: "10/0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RateLimit
+			err := got.UnmarshalText([]byte(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoad_RateLimits(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/fintrack")
+	t.Setenv("RATE_LIMIT_SUMMARY", "5/10s")
+	t.Setenv("RATE_LIMIT_USERS", "off")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{Requests: 5, Window: 10 * time.Second}, cfg.RateLimitSummary)
+	assert.Equal(t, RateLimit{}, cfg.RateLimitUsers)
+	assert.Equal(t, RateLimit{Requests: 120, Window: time.Minute}, cfg.RateLimitTransactions)
+	assert.Equal(t, RateLimit{Requests: 60, Window: time.Minute}, cfg.RateLimitGraphQL)
+	assert.Equal(t, RateLimit{Requests: 30, Window: time.Minute}, cfg.RateLimitWebhooks)
+	assert.Equal(t, RateLimit{Requests: 30, Window: time.Minute}, cfg.RateLimitEvents)
+	assert.Equal(t, RateLimit{Requests: 30, Window: time.Minute}, cfg.RateLimitAudit)
+	assert.Equal(t, RateLimit{Requests: 30, Window: time.Minute}, cfg.RateLimitTrash)
+	assert.Equal(t, RateLimit{Requests: 10, Window: time.Minute}, cfg.RateLimitPrivacy)
+	assert.Equal(t, RateLimit{Requests: 30, Window: time.Minute}, cfg.RateLimitAttachments)
+	assert.Equal(t, RateLimit{Requests: 60, Window: time.Minute}, cfg.RateLimitGoals)
+	assert.Equal(t, RateLimit{Requests: 60, Window: time.Minute}, cfg.RateLimitAccounts)
+	assert.Equal(t, RateLimit{Requests: 60, Window: time.Minute}, cfg.RateLimitDebts)
+	assert.Equal(t, RateLimit{Requests: 60, Window: time.Minute}, cfg.RateLimitSecurities)
+}
+
+func TestLoad_DatabaseBackend(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		wantBackend string
+		wantPath    string
+		wantErr     bool
+	}{
+		{name: "postgres", url: "postgres://fintrack@localhost/fintrack", wantBackend: BackendPostgres},
+		{name: "postgresql", url: "postgresql://fintrack@localhost/fintrack", wantBackend: BackendPostgres},
+		{name: "sqlite relative", url: "sqlite:data/fintrack.db", wantBackend: BackendSQLite, wantPath: "data/fintrack.db"},
+		{name: "sqlite absolute", url: "sqlite:///var/lib/fintrack/fintrack.db", wantBackend: BackendSQLite, wantPath: "/var/lib/fintrack/fintrack.db"},
+		{name: "sqlite without path", url: "sqlite://", wantErr: true},
+		{name: "unknown scheme", url: "mysql://localhost/fintrack", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", tt.url)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBackend, cfg.DatabaseBackend())
+			if tt.wantBackend == BackendSQLite {
+				assert.Equal(t, tt.wantPath, cfg.SQLitePath())
+			}
+		})
+	}
+}
+
+func TestLoad_Trash(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/fintrack")
+	t.Setenv("TRASH_RETENTION", "0")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.TrashRetention)
+	assert.Equal(t, time.Hour, cfg.TrashPurgeInterval)
+}
+
+func TestLoad_Privacy(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/fintrack")
+	t.Setenv("EXPORT_TTL", "1h")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, cfg.ExportTTL)
+	assert.Equal(t, 15*time.Minute, cfg.ErasureConfirmationTTL)
+}
+
+func TestLoad_Attachments(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/fintrack")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, BlobStoreLocal, cfg.BlobStore)
+	assert.Equal(t, "data/blobs", cfg.BlobDir)
+	assert.Equal(t, int64(10<<20), cfg.AttachmentMaxSize)
+	assert.Equal(t, []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}, cfg.AttachmentTypes)
+	assert.Equal(t, 5*time.Minute, cfg.BlobCleanupInterval)
+
+	t.Setenv("BLOB_STORE", "s3")
+	_, err = Load()
+	assert.ErrorContains(t, err, "S3_ENDPOINT")
+
+	t.Setenv("S3_ENDPOINT", "http://localhost:9000")
+	t.Setenv("S3_BUCKET", "receipts")
+	t.Setenv("S3_PATH_STYLE", "true")
+	t.Setenv("ATTACHMENT_TYPES", "application/pdf")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", cfg.S3Region)
+	assert.True(t, cfg.S3PathStyle)
+	assert.Equal(t, []string{"application/pdf"}, cfg.AttachmentTypes)
+
+	t.Setenv("BLOB_STORE", "ftp")
+	_, err = Load()
+	assert.Error(t, err)
+
+	t.Setenv("BLOB_STORE", "local")
+	t.Setenv("ATTACHMENT_MAX_SIZE", "0")
+	_, err = Load()
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"fintrack-go/internal/models"
+)
+
+const accountColumns = `a.id, a.user_id, a.name, a.type, a.created_at`
+
+func scanAccount(a *models.Account) []any {
+	return []any{&a.ID, &a.UserID, &a.Name, &a.Type, &a.CreatedAt}
+}
+
+const valuationColumns = `v.id, v.account_id, v.user_id, v.value, v.valued_at, v.created_at`
+
+func scanValuation(v *models.Valuation) []any {
+	return []any{&v.ID, &v.AccountID, &v.UserID, &v.Value, &v.ValuedAt, &v.CreatedAt}
+}
+
+func (db *DB) CreateAccount(ctx context.Context, userID string, account models.Account) (*models.Account, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the account.
+		var created *models.Account
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateAccount(ctx, userID, account)
+			return err
+		})
+		return created, err
+	}
+
+	query := `
+		INSERT INTO accounts AS a (user_id, name, type)
+		VALUES ($1, $2, $3)
+		RETURNING ` + accountColumns
+
+	var created models.Account
+	err := db.conn().QueryRow(ctx, query, userID, account.Name, account.Type).Scan(scanAccount(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntityAccount, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListAccounts(ctx context.Context, userID string) ([]models.Account, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM accounts a
+		WHERE a.user_id = $1
+		ORDER BY a.created_at, a.id
+	`
+	rows, err := db.conn().Query(ctx, query, userID)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var accounts []models.Account
+	for rows.Next() {
+		var account models.Account
+		if err := rows.Scan(scanAccount(&account)...); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (db *DB) GetAccount(ctx context.Context, userID, id string) (*models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts a WHERE a.id = $1 AND a.user_id = $2`
+
+	var account models.Account
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanAccount(&account)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	return &account, nil
+}
+
+func (db *DB) DeleteAccount(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteAccount(ctx, userID, id)
+		})
+	}
+
+	query := `DELETE FROM accounts a WHERE a.id = $1 AND a.user_id = $2 RETURNING ` + accountColumns
+
+	var account models.Account
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanAccount(&account)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAccountNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityAccount, account.ID, models.AuditDelete, account, nil)
+}
+
+func (db *DB) CreateValuation(ctx context.Context, userID, accountID string, valuation models.Valuation) (*models.Valuation, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the valuation.
+		var created *models.Valuation
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateValuation(ctx, userID, accountID, valuation)
+			return err
+		})
+		return created, err
+	}
+
+	if _, err := db.GetAccount(ctx, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO valuations AS v (account_id, user_id, value, valued_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + valuationColumns
+
+	var created models.Valuation
+	err := db.conn().QueryRow(ctx, query, accountID, userID, valuation.Value, valuation.ValuedAt).Scan(scanValuation(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntityValuation, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListValuations(ctx context.Context, userID string, filter models.ValuationFilter) ([]models.Valuation, error) {
+	var qb queryBuilder
+	qb.where("v.user_id = ?", userID)
+	if filter.AccountID != nil {
+		if _, err := db.GetAccount(ctx, userID, *filter.AccountID); err != nil {
+			return nil, err
+		}
+		qb.where("v.account_id = ?", *filter.AccountID)
+	}
+	if filter.To != nil {
+		qb.where("v.valued_at <= ?", *filter.To)
+	}
+
+	query := `SELECT ` + valuationColumns + ` FROM valuations v` + qb.clause() + ` ORDER BY v.valued_at, v.created_at, v.id`
+	rows, err := db.conn().Query(ctx, query, qb.args...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var valuations []models.Valuation
+	for rows.Next() {
+		var valuation models.Valuation
+		if err := rows.Scan(scanValuation(&valuation)...); err != nil {
+			return nil, err
+		}
+		valuations = append(valuations, valuation)
+	}
+
+	return valuations, rows.Err()
+}
+
+func (db *DB) DeleteValuation(ctx context.Context, userID, accountID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteValuation(ctx, userID, accountID, id)
+		})
+	}
+
+	if _, err := db.GetAccount(ctx, userID, accountID); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM valuations v WHERE v.id = $1 AND v.account_id = $2 RETURNING ` + valuationColumns
+
+	var valuation models.Valuation
+	err := db.conn().QueryRow(ctx, query, id, accountID).Scan(scanValuation(&valuation)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrValuationNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityValuation, valuation.ID, models.AuditDelete, valuation, nil)
+}
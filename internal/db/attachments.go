@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"fintrack-go/internal/models"
+)
+
+const attachmentColumns = `
+	a.id, a.user_id, a.transaction_id, a.filename, a.content_type, a.size, a.sha256,
+	a.storage_key, a.created_at
+`
+
+func scanAttachment(a *models.Attachment) []any {
+	return []any{
+		&a.ID,
+		&a.UserID,
+		&a.TransactionID,
+		&a.Filename,
+		&a.ContentType,
+		&a.Size,
+		&a.SHA256,
+		&a.StorageKey,
+		&a.CreatedAt,
+	}
+}
+
+func (db *DB) CreateAttachment(ctx context.Context, userID, transactionID string, attachment models.Attachment) (*models.Attachment, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the attachment.
+		var created *models.Attachment
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateAttachment(ctx, userID, transactionID, attachment)
+			return err
+		})
+		return created, err
+	}
+
+	// Locking the transaction keeps it from being moved to the trash until
+	// the attachment is recorded.
+	if _, err := db.lockUserTransaction(ctx, userID, transactionID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO attachments AS a (user_id, transaction_id, filename, content_type, size, sha256, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + attachmentColumns
+
+	var created models.Attachment
+	err := db.conn().QueryRow(ctx, query,
+		userID, transactionID, attachment.Filename, attachment.ContentType, attachment.Size, attachment.SHA256, attachment.StorageKey,
+	).Scan(scanAttachment(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntityAttachment, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListAttachments(ctx context.Context, userID, transactionID string) ([]models.Attachment, error) {
+	var exists bool
+	err := db.conn().QueryRow(ctx,
+		`SELECT true FROM transactions WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		transactionID, userID,
+	).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		WHERE a.transaction_id = $1
+		ORDER BY a.created_at, a.id
+	`
+	rows, err := db.conn().Query(ctx, query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.Attachment
+	for rows.Next() {
+		var attachment models.Attachment
+		if err := rows.Scan(scanAttachment(&attachment)...); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (db *DB) GetAttachment(ctx context.Context, userID, id string) (*models.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		JOIN transactions t ON t.id = a.transaction_id
+		WHERE a.id = $1 AND a.user_id = $2 AND t.deleted_at IS NULL
+	`
+
+	var attachment models.Attachment
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanAttachment(&attachment)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	return &attachment, nil
+}
+
+func (db *DB) DeleteAttachment(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteAttachment(ctx, userID, id)
+		})
+	}
+
+	// The attachments_orphan_blob trigger queues the blob for removal.
+	query := `
+		DELETE FROM attachments a
+		USING transactions t
+		WHERE t.id = a.transaction_id AND a.id = $1 AND a.user_id = $2 AND t.deleted_at IS NULL
+		RETURNING ` + attachmentColumns
+
+	var attachment models.Attachment
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanAttachment(&attachment)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAttachmentNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityAttachment, attachment.ID, models.AuditDelete, attachment, nil)
+}
+
+func (db *DB) ListOrphanedBlobs(ctx context.Context, limit int) ([]string, error) {
+	rows, err := db.conn().Query(ctx, `SELECT storage_key FROM orphaned_blobs ORDER BY created_at, storage_key LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (db *DB) ForgetOrphanedBlobs(ctx context.Context, keys []string) error {
+	_, err := db.conn().Exec(ctx, `DELETE FROM orphaned_blobs WHERE storage_key = ANY($1)`, keys)
+	return wrapPgError(err)
+}
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"fintrack-go/internal/models"
+)
+
+// SystemActor is the actor of changes made without an AuditInfo, such as
+// those of background jobs.
+const SystemActor = "system"
+
+// RedactedActor replaces the actor of the audit entries of an erased user,
+// which may identify them.
+const RedactedActor = "redacted"
+
+// AuditInfo says who is behind the changes made with a context, for the
+// audit log.
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+}
+
+type auditInfoKey struct{}
+
+// WithAuditInfo returns a copy of ctx whose changes are recorded as made by
+// info.Actor, in the request info.RequestID.
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+// APIKeyActor is the actor of requests made with an API key. It is a digest
+// of the key, so the log identifies clients without storing their keys.
+func APIKeyActor(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "api_key:" + hex.EncodeToString(sum[:8])
+}
+
+func auditInfo(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditInfoKey{}).(AuditInfo)
+	if info.Actor == "" {
+		info.Actor = SystemActor
+	}
+	return info
+}
+
+// newAuditEntry describes a change to one of userID's entities made with
+// ctx, with before and after snapshots of it, either of which may be nil.
+func newAuditEntry(ctx context.Context, userID, entity, entityID, action string, before, after any) (models.AuditEntry, error) {
+	info := auditInfo(ctx)
+	entry := models.AuditEntry{
+		UserID:   userID,
+		Actor:    info.Actor,
+		Entity:   entity,
+		EntityID: entityID,
+		Action:   action,
+	}
+	if info.RequestID != "" {
+		entry.RequestID = &info.RequestID
+	}
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return entry, err
+	}
+	entry.After, err = auditSnapshot(after)
+	return entry, err
+}
+
+// auditSnapshot returns v as JSON, as the API would return it, or nil for
+// no snapshot.
+func auditSnapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// dismissedDuplicate is the snapshot of a dismissed pair, identified by its
+// transactions' ids, lower first.
+type dismissedDuplicate struct {
+	TransactionID string `json:"transaction_id"`
+	DuplicateID   string `json:"duplicate_id"`
+}
+
+func (d dismissedDuplicate) id() string {
+	return d.TransactionID + ":" + d.DuplicateID
+}
+
+// priceImport is the snapshot of prices saved together, which would be too
+// many to record one by one. Imports have no id of their own, so each is
+// audited under a new one.
+type priceImport struct {
+	SecurityIDs []string  `json:"security_ids"`
+	Count       int       `json:"count"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+}
+
+// newPriceImport summarises prices, which must not be empty, as saved.
+func newPriceImport(prices []models.Price) priceImport {
+	imported := priceImport{Count: len(prices), From: prices[0].PricedAt, To: prices[0].PricedAt}
+	for _, price := range prices {
+		if !slices.Contains(imported.SecurityIDs, price.SecurityID) {
+			imported.SecurityIDs = append(imported.SecurityIDs, price.SecurityID)
+		}
+		if price.PricedAt.Before(imported.From) {
+			imported.From = price.PricedAt
+		}
+		if price.PricedAt.After(imported.To) {
+			imported.To = price.PricedAt
+		}
+	}
+	slices.Sort(imported.SecurityIDs)
+	return imported
+}
+
+// applyAuditFilter adds the conditions for a user's audit entries narrowed
+// by filter.
+func applyAuditFilter(b *queryBuilder, userID string, filter models.AuditFilter) {
+	b.where("user_id = ?", userID)
+
+	if filter.Entity != "" {
+		b.where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		b.where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		b.where("action = ?", filter.Action)
+	}
+	if filter.Actor != "" {
+		b.where("actor = ?", filter.Actor)
+	}
+	if filter.RequestID != "" {
+		b.where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		b.where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		b.where("created_at <= ?", *filter.To)
+	}
+	if filter.BeforeID > 0 {
+		b.where("id < ?", filter.BeforeID)
+	}
+}
+
+// matchesAuditFilter is applyAuditFilter for backends that don't speak SQL.
+func matchesAuditFilter(e models.AuditEntry, userID string, filter models.AuditFilter) bool {
+	switch {
+	case e.UserID != userID:
+		return false
+	case filter.Entity != "" && e.Entity != filter.Entity:
+		return false
+	case filter.EntityID != "" && e.EntityID != filter.EntityID:
+		return false
+	case filter.Action != "" && e.Action != filter.Action:
+		return false
+	case filter.Actor != "" && e.Actor != filter.Actor:
+		return false
+	case filter.RequestID != "" && (e.RequestID == nil || *e.RequestID != filter.RequestID):
+		return false
+	case filter.From != nil && e.CreatedAt.Before(*filter.From):
+		return false
+	case filter.To != nil && e.CreatedAt.After(*filter.To):
+		return false
+	case filter.BeforeID > 0 && e.ID >= filter.BeforeID:
+		return false
+	}
+	return true
+}
+
+func (db *DB) ListAuditEntries(ctx context.Context, userID string, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	var qb queryBuilder
+	applyAuditFilter(&qb, userID, filter)
+	qb.args = append(qb.args, filter.Limit)
+
+	query := `
+		SELECT id, user_id, actor, request_id, entity, entity_id, action, before, after, created_at
+		FROM audit_log
+	` + qb.clause() + ` ORDER BY id DESC LIMIT $` + strconv.Itoa(len(qb.args))
+
+	rows, err := db.conn().Query(ctx, query, qb.args...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Actor, &e.RequestID, &e.Entity, &e.EntityID, &e.Action, &e.Before, &e.After, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// audit records a change in the audit log. Callers run it in the
+// transaction making the change.
+func (db *DB) audit(ctx context.Context, userID, entity, entityID, action string, before, after any) error {
+	entry, err := newAuditEntry(ctx, userID, entity, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn().Exec(ctx, `
+		INSERT INTO audit_log (user_id, actor, request_id, entity, entity_id, action, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, entry.UserID, entry.Actor, entry.RequestID, entry.Entity, entry.EntityID, entry.Action, entry.Before, entry.After)
+	return wrapPgError(err)
+}
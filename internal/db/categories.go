@@ -2,38 +2,67 @@ package db
 
 import (
 	"context"
+	"errors"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"fintrack-go/internal/models"
 )
 
 func (db *DB) CreateCategory(ctx context.Context, userID, name string) (*models.Category, error) {
+	if db.tx == nil {
+		// The event and audit entry must be recorded with the category.
+		var category *models.Category
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			category, err = tx.CreateCategory(ctx, userID, name)
+			return err
+		})
+		return category, err
+	}
+
 	query := `INSERT INTO categories (user_id, name) VALUES ($1, $2) RETURNING id, user_id, name, created_at`
 	
 	var category models.Category
-	err := db.pool.QueryRow(ctx, query, userID, name).Scan(&category.ID, &category.UserID, &category.Name, &category.CreatedAt)
+	err := db.conn().QueryRow(ctx, query, userID, name).Scan(&category.ID, &category.UserID, &category.Name, &category.CreatedAt)
 	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok {
-			if pgErr.Code == "23505" {
-				if pgErr.ConstraintName == "categories_user_id_name_key" {
-					return nil, ErrDuplicateCategory
-				}
-			}
-			if pgErr.Code == "23503" {
-				return nil, ErrUserNotFound
-			}
-		}
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.publishEvent(ctx, userID, models.EventCategoryCreated, category); err != nil {
+		return nil, err
+	}
+	if err := db.audit(ctx, userID, models.EntityCategory, category.ID, models.AuditCreate, nil, category); err != nil {
 		return nil, err
 	}
 	
 	return &category, nil
 }
 
+func (db *DB) GetCategoriesByIDs(ctx context.Context, ids []string) ([]models.Category, error) {
+	query := `SELECT id, user_id, name, created_at, deleted_at FROM categories WHERE id = ANY($1)`
+
+	rows, err := db.conn().Query(ctx, query, ids)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.UserID, &category.Name, &category.CreatedAt, &category.DeletedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, wrapPgError(rows.Err())
+}
+
 func (db *DB) ListCategories(ctx context.Context, userID string) ([]models.Category, error) {
-	query := `SELECT id, user_id, name, created_at FROM categories WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, user_id, name, created_at FROM categories WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
 	
-	rows, err := db.pool.Query(ctx, query, userID)
+	rows, err := db.conn().Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -52,16 +81,88 @@ func (db *DB) ListCategories(ctx context.Context, userID string) ([]models.Categ
 }
 
 func (db *DB) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
-	query := `SELECT id, user_id, name, created_at FROM categories WHERE id = $1`
+	query := `SELECT id, user_id, name, created_at FROM categories WHERE id = $1 AND deleted_at IS NULL`
 	
 	var category models.Category
-	err := db.pool.QueryRow(ctx, query, id).Scan(&category.ID, &category.UserID, &category.Name, &category.CreatedAt)
-	if err == pgx.ErrNoRows {
+	err := db.conn().QueryRow(ctx, query, id).Scan(&category.ID, &category.UserID, &category.Name, &category.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrCategoryNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, wrapPgError(err)
 	}
 	
 	return &category, nil
 }
+
+func (db *DB) DeleteCategory(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The event and audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteCategory(ctx, userID, id)
+		})
+	}
+
+	query := `
+		UPDATE categories SET deleted_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		RETURNING id, user_id, name, created_at, deleted_at
+	`
+
+	var category models.Category
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(&category.ID, &category.UserID, &category.Name, &category.CreatedAt, &category.DeletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrCategoryNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	before := category
+	before.DeletedAt = nil
+	if err := db.publishEvent(ctx, userID, models.EventCategoryDeleted, models.DeletedResource{ID: category.ID}); err != nil {
+		return err
+	}
+	return db.audit(ctx, userID, models.EntityCategory, category.ID, models.AuditDelete, before, category)
+}
+
+func (db *DB) RestoreCategory(ctx context.Context, userID, id string) (*models.Category, error) {
+	if db.tx == nil {
+		// The event and audit entry must be recorded with the restore.
+		var category *models.Category
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			category, err = tx.RestoreCategory(ctx, userID, id)
+			return err
+		})
+		return category, err
+	}
+
+	// old is the row as it was before the update.
+	query := `
+		UPDATE categories c SET deleted_at = NULL
+		FROM categories old
+		WHERE c.id = old.id AND c.id = $1 AND c.user_id = $2 AND c.deleted_at IS NOT NULL
+		RETURNING c.id, c.user_id, c.name, c.created_at, old.deleted_at
+	`
+
+	var before models.Category
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(&before.ID, &before.UserID, &before.Name, &before.CreatedAt, &before.DeletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	category := before
+	category.DeletedAt = nil
+	if err := db.publishEvent(ctx, userID, models.EventCategoryRestored, category); err != nil {
+		return nil, err
+	}
+	if err := db.audit(ctx, userID, models.EntityCategory, category.ID, models.AuditUpdate, before, category); err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
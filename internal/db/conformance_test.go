@@ -0,0 +1,44 @@
+package db_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/db/dbtest"
+	"fintrack-go/tests/dbtestutil"
+)
+
+func TestMemoryDBConformance(t *testing.T) {
+	t.Parallel()
+
+	dbtest.RunConformance(t, func(t *testing.T) db.Database {
+		return db.NewMemoryDB()
+	})
+}
+
+func TestPostgresConformance(t *testing.T) {
+	t.Parallel()
+
+	dbtest.RunConformance(t, func(t *testing.T) db.Database {
+		database, err := db.NewDB(dbtestutil.CreateTestContext(t), dbtestutil.GetTestDatabaseURL(), zerolog.Nop())
+		require.NoError(t, err)
+		t.Cleanup(database.Close)
+		return database
+	})
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	t.Parallel()
+
+	dbtest.RunConformance(t, func(t *testing.T) db.Database {
+		path := filepath.Join(t.TempDir(), "fintrack.db")
+		database, err := db.NewSQLiteDB(dbtestutil.CreateTestContext(t), path, zerolog.Nop())
+		require.NoError(t, err)
+		t.Cleanup(database.Close)
+		return database
+	})
+}
@@ -12,14 +12,165 @@ type Database interface {
 	CreateUser(ctx context.Context, email string) (*models.User, error)
 	GetUserByID(ctx context.Context, id string) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	// DeleteUser erases a user: everything they own is deleted, their audit
+	// entries are redacted, and the erasure itself is audited with no
+	// snapshots.
+	DeleteUser(ctx context.Context, id string) error
 	CreateCategory(ctx context.Context, userID, name string) (*models.Category, error)
 	ListCategories(ctx context.Context, userID string) ([]models.Category, error)
 	GetCategoryByID(ctx context.Context, id string) (*models.Category, error)
+	// GetCategoriesByIDs returns the categories with the given ids in no
+	// particular order. Ids without a category are left out. Categories in
+	// the trash are included, as transactions still refer to them.
+	GetCategoriesByIDs(ctx context.Context, ids []string) ([]models.Category, error)
+	// DeleteCategory moves one of the user's categories to the trash. Its
+	// transactions keep it until it is purged, but are listed without its
+	// name and summarised as uncategorised until it is restored.
+	DeleteCategory(ctx context.Context, userID, id string) error
+	// RestoreCategory takes one of the user's categories out of the trash.
+	RestoreCategory(ctx context.Context, userID, id string) (*models.Category, error)
 	CreateTransaction(ctx context.Context, userID string, categoryID *string, amount float64, description *string, occurredAt time.Time) (*models.Transaction, error)
-	ListTransactions(ctx context.Context, userID string, from, to *time.Time) ([]models.Transaction, error)
+	ListTransactions(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error)
 	GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error)
+	// DeleteTransaction moves one of the user's transactions to the trash.
+	DeleteTransaction(ctx context.Context, userID, id string) error
+	// RestoreTransaction takes one of the user's transactions out of the
+	// trash.
+	RestoreTransaction(ctx context.Context, userID, id string) (*models.Transaction, error)
+	// ListTrash returns the user's deleted categories and transactions, most
+	// recently deleted first.
+	ListTrash(ctx context.Context, userID string) (*models.Trash, error)
+	// PurgeDeleted permanently deletes every category and transaction moved
+	// to the trash before deletedBefore, and returns how many there were.
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error)
+	// CreateAttachment records that the file stored under
+	// attachment.StorageKey is attached to one of the user's transactions.
+	CreateAttachment(ctx context.Context, userID, transactionID string, attachment models.Attachment) (*models.Attachment, error)
+	// ListAttachments returns the attachments of one of the user's
+	// transactions, oldest first.
+	ListAttachments(ctx context.Context, userID, transactionID string) ([]models.Attachment, error)
+	// GetAttachment returns one of the user's attachments. Those of
+	// transactions in the trash are not found until they are restored.
+	GetAttachment(ctx context.Context, userID, id string) (*models.Attachment, error)
+	// DeleteAttachment deletes one of the user's attachments.
+	DeleteAttachment(ctx context.Context, userID, id string) error
+	// ListOrphanedBlobs returns up to limit storage keys of deleted
+	// attachments, however they were deleted, whose blobs are still to be
+	// removed, oldest first.
+	ListOrphanedBlobs(ctx context.Context, limit int) ([]string, error)
+	// ForgetOrphanedBlobs records that the blobs stored under keys were
+	// removed.
+	ForgetOrphanedBlobs(ctx context.Context, keys []string) error
+	CreateGoal(ctx context.Context, userID string, goal models.Goal) (*models.Goal, error)
+	// ListGoals returns the user's goals, oldest first.
+	ListGoals(ctx context.Context, userID string) ([]models.Goal, error)
+	GetGoal(ctx context.Context, userID, id string) (*models.Goal, error)
+	// DeleteGoal deletes one of the user's goals with its contributions.
+	DeleteGoal(ctx context.Context, userID, id string) error
+	// CreateGoalContribution records a contribution to one of the user's
+	// goals. When contribution.TransactionID is set, its amount and date are
+	// those of the user's transaction, which may contribute to one goal at
+	// most.
+	CreateGoalContribution(ctx context.Context, userID, goalID string, contribution models.GoalContribution) (*models.GoalContribution, error)
+	// ListGoalContributions returns the contributions to one of the user's
+	// goals, earliest first. Those of transactions in the trash are left
+	// out until they are restored.
+	ListGoalContributions(ctx context.Context, userID, goalID string) ([]models.GoalContribution, error)
+	// DeleteGoalContribution deletes a contribution to one of the user's
+	// goals.
+	DeleteGoalContribution(ctx context.Context, userID, goalID, id string) error
+	CreateAccount(ctx context.Context, userID string, account models.Account) (*models.Account, error)
+	// ListAccounts returns the user's accounts, oldest first.
+	ListAccounts(ctx context.Context, userID string) ([]models.Account, error)
+	GetAccount(ctx context.Context, userID, id string) (*models.Account, error)
+	// DeleteAccount deletes one of the user's accounts with its valuations.
+	DeleteAccount(ctx context.Context, userID, id string) error
+	// CreateValuation records the value of one of the user's accounts as of
+	// valuation.ValuedAt.
+	CreateValuation(ctx context.Context, userID, accountID string, valuation models.Valuation) (*models.Valuation, error)
+	// ListValuations returns the user's valuations matching filter, earliest
+	// first. Filtering by an account that isn't the user's fails with
+	// ErrAccountNotFound.
+	ListValuations(ctx context.Context, userID string, filter models.ValuationFilter) ([]models.Valuation, error)
+	// DeleteValuation deletes a valuation of one of the user's accounts.
+	DeleteValuation(ctx context.Context, userID, accountID, id string) error
+	CreateDebt(ctx context.Context, userID string, debt models.Debt) (*models.Debt, error)
+	// ListDebts returns the user's debts, oldest first.
+	ListDebts(ctx context.Context, userID string) ([]models.Debt, error)
+	GetDebt(ctx context.Context, userID, id string) (*models.Debt, error)
+	// DeleteDebt deletes one of the user's debts with its payments.
+	DeleteDebt(ctx context.Context, userID, id string) error
+	// CreateDebtPayment records a payment toward one of the user's debts.
+	// When payment.TransactionID is set, its amount and date are those of
+	// the user's transaction, which may pay one debt at most.
+	CreateDebtPayment(ctx context.Context, userID, debtID string, payment models.DebtPayment) (*models.DebtPayment, error)
+	// ListDebtPayments returns the payments toward one of the user's debts,
+	// earliest first. Those of transactions in the trash are left out until
+	// they are restored.
+	ListDebtPayments(ctx context.Context, userID, debtID string) ([]models.DebtPayment, error)
+	// DeleteDebtPayment deletes a payment toward one of the user's debts.
+	DeleteDebtPayment(ctx context.Context, userID, debtID, id string) error
+	CreateSecurity(ctx context.Context, userID string, security models.Security) (*models.Security, error)
+	// ListSecurities returns the user's securities, oldest first.
+	ListSecurities(ctx context.Context, userID string) ([]models.Security, error)
+	GetSecurity(ctx context.Context, userID, id string) (*models.Security, error)
+	// DeleteSecurity deletes one of the user's securities with its lots and
+	// prices.
+	DeleteSecurity(ctx context.Context, userID, id string) error
+	// CreateLot records a buy or sell of one of the user's securities. It
+	// fails with ErrInsufficientQuantity if the security's sells would then
+	// exceed the units held at any time.
+	CreateLot(ctx context.Context, userID, securityID string, lot models.Lot) (*models.Lot, error)
+	// ListLots returns the user's lots matching filter, earliest first and
+	// buys before sells traded at the same time. Filtering by a security
+	// that isn't the user's fails with ErrSecurityNotFound.
+	ListLots(ctx context.Context, userID string, filter models.LotFilter) ([]models.Lot, error)
+	// DeleteLot deletes a lot of one of the user's securities, failing with
+	// ErrInsufficientQuantity if its sells would then exceed the units held.
+	DeleteLot(ctx context.Context, userID, securityID, id string) error
+	// SavePrices records the prices of the user's securities, replacing any
+	// a security already has at the same time. Either all of them are saved
+	// or, if one is for a security that isn't the user's, none is. Each call
+	// is audited as one price import.
+	SavePrices(ctx context.Context, userID string, prices []models.Price) error
+	// ListPrices returns the prices of the user's securities matching
+	// filter, earliest first. Filtering by a security that isn't the user's
+	// fails with ErrSecurityNotFound.
+	ListPrices(ctx context.Context, userID string, filter models.PriceFilter) ([]models.Price, error)
 	ValidateCategoryOwnership(ctx context.Context, categoryID, userID string) error
 	GetSummary(ctx context.Context, userID string, from, to *time.Time) (*models.Summary, error)
+	FindDuplicateTransactions(ctx context.Context, userID string, windowDays int) ([]models.DuplicatePair, error)
+	MergeDuplicateTransactions(ctx context.Context, userID, keepID, discardID string) (*models.Transaction, error)
+	DismissDuplicate(ctx context.Context, userID, transactionID, duplicateID string) error
+	CreateWebhookSubscription(ctx context.Context, userID, url, secret string, eventTypes []string) (*models.WebhookSubscription, error)
+	// ListWebhookSubscriptions returns the user's subscriptions, newest first.
+	ListWebhookSubscriptions(ctx context.Context, userID string) ([]models.WebhookSubscription, error)
+	// DeleteWebhookSubscription deletes one of the user's subscriptions, with
+	// its deliveries.
+	DeleteWebhookSubscription(ctx context.Context, userID, id string) error
+	// ListWebhookDeliveries returns the deliveries to one of the user's
+	// subscriptions, newest first.
+	ListWebhookDeliveries(ctx context.Context, userID, subscriptionID string) ([]models.WebhookDelivery, error)
+	// ClaimWebhookDeliveries returns up to limit pending deliveries due at
+	// now, longest waiting first, and puts their next attempt lease later.
+	// Until then no other claim returns them, and a dispatcher that stops
+	// before recording an attempt only delays them.
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutgoingWebhook, error)
+	RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error
+	// ListEvents returns up to limit of the user's events after afterID,
+	// oldest first.
+	ListEvents(ctx context.Context, userID string, afterID int64, limit int) ([]models.Event, error)
+	// LastEventID returns the ID of the user's latest event, or 0 if there
+	// are none.
+	LastEventID(ctx context.Context, userID string) (int64, error)
+	// WatchEvents returns a channel that receives a value when the user may
+	// have new events, until ctx is done and it is closed. Callers look for
+	// them with ListEvents.
+	WatchEvents(ctx context.Context, userID string) (<-chan struct{}, error)
+	// ListAuditEntries returns the user's audit entries matching filter,
+	// newest first.
+	ListAuditEntries(ctx context.Context, userID string, filter models.AuditFilter) ([]models.AuditEntry, error)
+	WithTx(ctx context.Context, fn func(tx Database) error) error
 }
 
 var _ Database = (*DB)(nil)
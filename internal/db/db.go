@@ -5,13 +5,40 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 )
 
 type DB struct {
 	pool   *pgxpool.Pool
 	logger zerolog.Logger
+
+	// tx is set on the DB handed to a WithTx callback; its methods then run
+	// in that transaction instead of on the pool.
+	tx pgx.Tx
+
+	listener *eventListener
+}
+
+// querier is implemented by both the pool and a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// conn returns what statements should run on: the current transaction, if
+// any, otherwise the pool.
+func (db *DB) conn() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.pool
 }
 
 func NewDB(ctx context.Context, databaseURL string, logger zerolog.Logger) (*DB, error) {
@@ -25,6 +52,7 @@ func NewDB(ctx context.Context, databaseURL string, logger zerolog.Logger) (*DB,
 	config.MaxConnLifetime = time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = 1 * time.Minute
+	config.ConnConfig.Tracer = newQueryTracer(otel.GetTracerProvider())
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -38,10 +66,13 @@ func NewDB(ctx context.Context, databaseURL string, logger zerolog.Logger) (*DB,
 
 	logger.Info().Msg("Successfully connected to database")
 
-	return &DB{pool: pool, logger: logger}, nil
+	return &DB{pool: pool, logger: logger, listener: newEventListener(config.ConnConfig.Copy(), logger)}, nil
 }
 
 func (db *DB) Close() {
+	if db.listener != nil {
+		db.listener.close()
+	}
 	if db.pool != nil {
 		db.pool.Close()
 		db.logger.Info().Msg("Database connection closed")
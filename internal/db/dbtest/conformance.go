@@ -0,0 +1,2244 @@
+// Package dbtest holds a behavioural test suite that every db.Database
+// implementation must pass, so the backends stay interchangeable.
+package dbtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// RunConformance runs the suite against databases returned by newDB. It may
+// be called for a fresh database per subtest or share one: every subtest
+// works with its own users.
+func RunConformance(t *testing.T, newDB func(t *testing.T) db.Database) {
+	t.Run("users", func(t *testing.T) { testUsers(t, newDB(t)) })
+	t.Run("categories", func(t *testing.T) { testCategories(t, newDB(t)) })
+	t.Run("transactions", func(t *testing.T) { testTransactions(t, newDB(t)) })
+	t.Run("transaction filters", func(t *testing.T) { testTransactionFilters(t, newDB(t)) })
+	t.Run("summary", func(t *testing.T) { testSummary(t, newDB(t)) })
+	t.Run("duplicates", func(t *testing.T) { testDuplicates(t, newDB(t)) })
+	t.Run("concurrent writes", func(t *testing.T) { testConcurrentWrites(t, newDB(t)) })
+	t.Run("WithTx", func(t *testing.T) { testWithTx(t, newDB(t)) })
+	t.Run("webhooks", func(t *testing.T) { testWebhooks(t, newDB(t)) })
+	t.Run("events", func(t *testing.T) { testEvents(t, newDB(t)) })
+	t.Run("audit", func(t *testing.T) { testAudit(t, newDB(t)) })
+	t.Run("trash", func(t *testing.T) { testTrash(t, newDB(t)) })
+	t.Run("erasure", func(t *testing.T) { testErasure(t, newDB(t)) })
+	t.Run("attachments", func(t *testing.T) { testAttachments(t, newDB(t)) })
+	t.Run("goals", func(t *testing.T) { testGoals(t, newDB(t)) })
+	t.Run("accounts", func(t *testing.T) { testAccounts(t, newDB(t)) })
+	t.Run("debts", func(t *testing.T) { testDebts(t, newDB(t)) })
+	t.Run("securities", func(t *testing.T) { testSecurities(t, newDB(t)) })
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func uniqueEmail() string {
+	return "conformance-" + uuid.NewString() + "@example.com"
+}
+
+func createUser(t *testing.T, database db.Database) *models.User {
+	user, err := database.CreateUser(testContext(t), uniqueEmail())
+	require.NoError(t, err)
+	return user
+}
+
+func createCategory(t *testing.T, database db.Database, userID, name string) *models.Category {
+	category, err := database.CreateCategory(testContext(t), userID, name)
+	require.NoError(t, err)
+	return category
+}
+
+func createTransaction(t *testing.T, database db.Database, userID string, categoryID *string, amount float64, description string, occurredAt time.Time) *models.Transaction {
+	var desc *string
+	if description != "" {
+		desc = &description
+	}
+	transaction, err := database.CreateTransaction(testContext(t), userID, categoryID, amount, desc, occurredAt)
+	require.NoError(t, err)
+	return transaction
+}
+
+func transactionIDs(transactions []models.Transaction) []string {
+	ids := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+	}
+	return ids
+}
+
+func categoryIDs(categories []models.Category) []string {
+	ids := make([]string, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+	return ids
+}
+
+// baseTime is a fixed instant with whole seconds, so it survives storage at
+// any timestamp precision.
+var baseTime = time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func testUsers(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	email := uniqueEmail()
+
+	user, err := database.CreateUser(ctx, email)
+	require.NoError(t, err)
+	assert.NotEmpty(t, user.ID)
+	assert.Equal(t, email, user.Email)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	byID, err := database.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, byID.Email)
+
+	byEmail, err := database.GetUserByEmail(ctx, email)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, byEmail.ID)
+
+	_, err = database.CreateUser(ctx, email)
+	assert.ErrorIs(t, err, db.ErrDuplicateEmail)
+
+	_, err = database.CreateUser(ctx, strings.ToUpper(email))
+	assert.NoError(t, err, "emails are compared case-sensitively")
+
+	upper, err := database.GetUserByID(ctx, strings.ToUpper(user.ID))
+	require.NoError(t, err, "ids are UUIDs and match in any case")
+	assert.Equal(t, user.ID, upper.ID)
+
+	_, err = database.GetUserByID(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+
+	_, err = database.GetUserByEmail(ctx, uniqueEmail())
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+
+	_, err = database.GetUserByID(ctx, "not-a-uuid")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, db.ErrUserNotFound, "a malformed id is an error, not a missing row")
+}
+
+func testCategories(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	user := createUser(t, database)
+	other := createUser(t, database)
+
+	food := createCategory(t, database, user.ID, "Food")
+	assert.NotEmpty(t, food.ID)
+	assert.Equal(t, user.ID, food.UserID)
+	assert.Equal(t, "Food", food.Name)
+	assert.False(t, food.CreatedAt.IsZero())
+
+	_, err := database.CreateCategory(ctx, user.ID, "Food")
+	assert.ErrorIs(t, err, db.ErrDuplicateCategory)
+
+	createCategory(t, database, user.ID, "food")
+	createCategory(t, database, other.ID, "Food")
+
+	_, err = database.CreateCategory(ctx, uuid.NewString(), "Food")
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+
+	transport := createCategory(t, database, user.ID, "Transport")
+
+	categories, err := database.ListCategories(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, categories, 3)
+	assert.Equal(t, transport.ID, categories[0].ID, "newest first")
+	assert.Equal(t, food.ID, categories[2].ID)
+
+	empty, err := database.ListCategories(ctx, createUser(t, database).ID)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	got, err := database.GetCategoryByID(ctx, food.ID)
+	require.NoError(t, err)
+	assert.Equal(t, food.Name, got.Name)
+	assert.Equal(t, user.ID, got.UserID)
+
+	_, err = database.GetCategoryByID(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, db.ErrCategoryNotFound)
+
+	travel := createCategory(t, database, other.ID, "Travel")
+	batch, err := database.GetCategoriesByIDs(ctx, []string{food.ID, uuid.NewString(), travel.ID, food.ID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{food.ID, travel.ID}, categoryIDs(batch), "missing ids are left out and repeated ones returned once")
+
+	batch, err = database.GetCategoriesByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+
+	_, err = database.GetCategoriesByIDs(ctx, []string{food.ID, "not-a-uuid"})
+	assert.ErrorIs(t, err, db.ErrInvalidID)
+
+	assert.NoError(t, database.ValidateCategoryOwnership(ctx, food.ID, user.ID))
+	assert.Error(t, database.ValidateCategoryOwnership(ctx, food.ID, other.ID))
+	assert.Error(t, database.ValidateCategoryOwnership(ctx, uuid.NewString(), user.ID))
+}
+
+func testTransactions(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	user := createUser(t, database)
+	other := createUser(t, database)
+	food := createCategory(t, database, user.ID, "Food")
+	otherFood := createCategory(t, database, other.ID, "Food")
+
+	lunch := "Lunch"
+	created, err := database.CreateTransaction(ctx, user.ID, &food.ID, 12.5, &lunch, baseTime)
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, user.ID, created.UserID)
+	assert.Equal(t, food.ID, *created.CategoryID)
+	assert.Equal(t, 12.5, created.Amount)
+	assert.Equal(t, lunch, *created.Description)
+	assert.True(t, baseTime.Equal(created.OccurredAt))
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Nil(t, created.CategoryName, "the created row carries no joined category name")
+
+	got, err := database.GetTransactionByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.CategoryName)
+	assert.Equal(t, "Food", *got.CategoryName)
+	assert.True(t, baseTime.Equal(got.OccurredAt))
+
+	plain, err := database.CreateTransaction(ctx, user.ID, nil, 3, nil, baseTime)
+	require.NoError(t, err)
+	assert.Nil(t, plain.CategoryID)
+	assert.Nil(t, plain.Description)
+
+	got, err = database.GetTransactionByID(ctx, plain.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.CategoryName)
+
+	_, err = database.GetTransactionByID(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+
+	t.Run("amounts are stored to the cent", func(t *testing.T) {
+		for amount, want := range map[float64]float64{10.005: 10.01, 0.125: 0.13, 7.994: 7.99} {
+			transaction, err := database.CreateTransaction(ctx, user.ID, nil, amount, nil, baseTime)
+			require.NoError(t, err)
+			stored, err := database.GetTransactionByID(ctx, transaction.ID)
+			require.NoError(t, err)
+			assert.Equal(t, want, stored.Amount, "amount %v", amount)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		_, err := database.CreateTransaction(ctx, user.ID, nil, 0, nil, baseTime)
+		assert.ErrorIs(t, err, db.ErrInvalidAmount, "zero amount")
+
+		_, err = database.CreateTransaction(ctx, user.ID, nil, -5, nil, baseTime)
+		assert.ErrorIs(t, err, db.ErrInvalidAmount, "negative amount")
+
+		_, err = database.CreateTransaction(ctx, user.ID, nil, 0.004, nil, baseTime)
+		assert.ErrorIs(t, err, db.ErrInvalidAmount, "amount rounding to zero")
+
+		_, err = database.CreateTransaction(ctx, user.ID, nil, 100000000, nil, baseTime)
+		assert.ErrorIs(t, err, db.ErrInvalidAmount, "amount too large for DECIMAL(10, 2)")
+
+		_, err = database.CreateTransaction(ctx, user.ID, &otherFood.ID, 5, nil, baseTime)
+		assert.ErrorIs(t, err, db.ErrCategoryNotOwned, "another user's category")
+		assert.ErrorIs(t, err, db.ErrValidation)
+
+		_, err = database.CreateTransaction(ctx, uuid.NewString(), nil, 5, nil, baseTime)
+		assert.ErrorIs(t, err, db.ErrUserNotFound, "unknown user")
+		assert.ErrorIs(t, err, db.ErrNotFound)
+
+		_, err = database.GetTransactionByID(ctx, "not-a-uuid")
+		assert.ErrorIs(t, err, db.ErrInvalidID, "malformed id")
+	})
+}
+
+func testTransactionFilters(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	user := createUser(t, database)
+	other := createUser(t, database)
+	food := createCategory(t, database, user.ID, "Food")
+	transport := createCategory(t, database, user.ID, "Transport")
+
+	older := createTransaction(t, database, user.ID, &food.ID, 10, "Groceries", baseTime.AddDate(0, 0, -10))
+	middle := createTransaction(t, database, user.ID, &transport.ID, 50, "", baseTime.AddDate(0, 0, -5))
+	newer := createTransaction(t, database, user.ID, nil, 100, "Rent", baseTime)
+	empty := ""
+	blank, err := database.CreateTransaction(ctx, user.ID, nil, 20, &empty, baseTime.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	createTransaction(t, database, other.ID, nil, 10, "Not mine", baseTime)
+
+	from := baseTime.AddDate(0, 0, -5)
+	to := baseTime.AddDate(0, 0, -1)
+	minAmount, maxAmount := 20.0, 50.0
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter models.TransactionFilter
+		want   []string
+	}{
+		{"all, newest first", models.TransactionFilter{}, []string{newer.ID, blank.ID, middle.ID, older.ID}},
+		{"date range is inclusive", models.TransactionFilter{From: &from, To: &to}, []string{blank.ID, middle.ID}},
+		{"amount range is inclusive", models.TransactionFilter{MinAmount: &minAmount, MaxAmount: &maxAmount}, []string{blank.ID, middle.ID}},
+		{"categories", models.TransactionFilter{CategoryIDs: []string{food.ID, transport.ID}}, []string{middle.ID, older.ID}},
+		{"uncategorized", models.TransactionFilter{Uncategorized: true, CategoryIDs: []string{food.ID}}, []string{newer.ID, blank.ID}},
+		{"with description", models.TransactionFilter{HasDescription: &yes}, []string{newer.ID, older.ID}},
+		{"without description", models.TransactionFilter{HasDescription: &no}, []string{blank.ID, middle.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, err := database.ListTransactions(ctx, user.ID, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, transactionIDs(transactions))
+		})
+	}
+
+	transactions, err := database.ListTransactions(ctx, user.ID, models.TransactionFilter{CategoryIDs: []string{food.ID}})
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.NotNil(t, transactions[0].CategoryName)
+	assert.Equal(t, "Food", *transactions[0].CategoryName)
+
+	none, err := database.ListTransactions(ctx, createUser(t, database).ID, models.TransactionFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func testSummary(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	user := createUser(t, database)
+	other := createUser(t, database)
+	food := createCategory(t, database, user.ID, "Food")
+	transport := createCategory(t, database, user.ID, "Transport")
+	createCategory(t, database, user.ID, "Unused")
+
+	createTransaction(t, database, user.ID, &food.ID, 0.1, "", baseTime)
+	createTransaction(t, database, user.ID, &food.ID, 0.2, "", baseTime.AddDate(0, 0, -1))
+	createTransaction(t, database, user.ID, &transport.ID, 3, "", baseTime)
+	createTransaction(t, database, user.ID, nil, 7.5, "", baseTime)
+	createTransaction(t, database, user.ID, nil, 1000, "", baseTime.AddDate(0, -2, 0))
+	createTransaction(t, database, other.ID, nil, 99, "", baseTime)
+
+	from := baseTime.AddDate(0, 0, -7)
+	to := baseTime
+	summary, err := database.GetSummary(ctx, user.ID, &from, &to)
+	require.NoError(t, err)
+
+	assert.Equal(t, user.ID, summary.UserID)
+	assert.True(t, from.Equal(summary.From))
+	assert.True(t, to.Equal(summary.To))
+	require.Len(t, summary.Categories, 3, "categories without transactions are left out")
+
+	assert.Equal(t, "Food", summary.Categories[0].CategoryName)
+	assert.Equal(t, food.ID, *summary.Categories[0].CategoryID)
+	assert.Equal(t, 0.3, summary.Categories[0].Total, "totals are exact to the cent")
+
+	assert.Equal(t, "Transport", summary.Categories[1].CategoryName)
+	assert.Equal(t, 3.0, summary.Categories[1].Total)
+
+	assert.Equal(t, "Uncategorized", summary.Categories[2].CategoryName)
+	assert.Nil(t, summary.Categories[2].CategoryID)
+	assert.Equal(t, 7.5, summary.Categories[2].Total)
+
+	all, err := database.GetSummary(ctx, user.ID, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, all.Categories, 3)
+	assert.Equal(t, 1007.5, all.Categories[2].Total, "no dates means every transaction")
+	assert.WithinDuration(t, time.Now(), all.To, time.Minute)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -30), all.From, time.Minute)
+
+	empty, err := database.GetSummary(ctx, createUser(t, database).ID, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty.Categories)
+}
+
+func testDuplicates(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	user := createUser(t, database)
+	other := createUser(t, database)
+	food := createCategory(t, database, user.ID, "Food")
+
+	original := createTransaction(t, database, user.ID, &food.ID, 42, "", baseTime)
+	copied := createTransaction(t, database, user.ID, nil, 42, "Coffee beans", baseTime.AddDate(0, 0, 2))
+	createTransaction(t, database, user.ID, nil, 42, "Coffee beans", baseTime.AddDate(0, 0, 10))
+	createTransaction(t, database, user.ID, nil, 43, "Coffee beans", baseTime)
+	train := createTransaction(t, database, user.ID, nil, 42, "Train ticket", baseTime.AddDate(0, 0, 1))
+	otherTxn := createTransaction(t, database, other.ID, nil, 42, "", baseTime)
+
+	pairs, err := database.FindDuplicateTransactions(ctx, user.ID, 3)
+	require.NoError(t, err)
+
+	found := false
+	for _, pair := range pairs {
+		ids := []string{pair.Transaction.ID, pair.Duplicate.ID}
+		assert.NotContains(t, ids, otherTxn.ID)
+		if pair.Transaction.ID == original.ID && pair.Duplicate.ID == copied.ID {
+			found = true
+			assert.Equal(t, 1.0, pair.Similarity)
+			require.NotNil(t, pair.Transaction.CategoryName)
+			assert.Equal(t, "Food", *pair.Transaction.CategoryName)
+		}
+		assert.False(t, pair.Transaction.ID == copied.ID && pair.Duplicate.ID == train.ID, "different descriptions are not duplicates")
+	}
+	assert.True(t, found, "same amount two days apart, earliest created first")
+
+	_, err = database.MergeDuplicateTransactions(ctx, user.ID, original.ID, original.ID)
+	assert.ErrorIs(t, err, db.ErrSameTransaction)
+	assert.ErrorIs(t, database.DismissDuplicate(ctx, user.ID, original.ID, original.ID), db.ErrSameTransaction)
+
+	_, err = database.MergeDuplicateTransactions(ctx, other.ID, original.ID, copied.ID)
+	assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+	assert.ErrorIs(t, database.DismissDuplicate(ctx, user.ID, original.ID, otherTxn.ID), db.ErrTransactionNotFound)
+
+	require.NoError(t, database.DismissDuplicate(ctx, user.ID, copied.ID, original.ID))
+	require.NoError(t, database.DismissDuplicate(ctx, user.ID, original.ID, copied.ID), "dismissing twice is fine")
+	pairs, err = database.FindDuplicateTransactions(ctx, user.ID, 3)
+	require.NoError(t, err)
+	for _, pair := range pairs {
+		assert.False(t, pair.Transaction.ID == original.ID && pair.Duplicate.ID == copied.ID, "dismissed pair is hidden")
+	}
+
+	merged, err := database.MergeDuplicateTransactions(ctx, user.ID, original.ID, copied.ID)
+	require.NoError(t, err)
+	assert.Equal(t, original.ID, merged.ID)
+	assert.Equal(t, food.ID, *merged.CategoryID, "kept category")
+	assert.Equal(t, "Coffee beans", *merged.Description, "missing description taken from the discarded one")
+	require.NotNil(t, merged.CategoryName)
+	assert.Equal(t, "Food", *merged.CategoryName)
+
+	_, err = database.GetTransactionByID(ctx, copied.ID)
+	assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+}
+
+func testConcurrentWrites(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	email := uniqueEmail()
+
+	const writers = 10
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = database.CreateUser(ctx, email)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, db.ErrDuplicateEmail)
+	}
+	assert.Equal(t, 1, succeeded, "exactly one writer wins a unique email")
+
+	user, err := database.GetUserByEmail(ctx, email)
+	require.NoError(t, err)
+
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = database.CreateTransaction(ctx, user.ID, nil, 1, nil, baseTime)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	transactions, err := database.ListTransactions(ctx, user.ID, models.TransactionFilter{})
+	require.NoError(t, err)
+	assert.Len(t, transactions, writers)
+}
+
+func testWithTx(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	errRollback := errors.New("roll back")
+
+	categoryNames := func(t *testing.T, userID string) []string {
+		categories, err := database.ListCategories(ctx, userID)
+		require.NoError(t, err)
+		names := make([]string, len(categories))
+		for i, category := range categories {
+			names[i] = category.Name
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("commits", func(t *testing.T) {
+		user := createUser(t, database)
+
+		var created *models.Transaction
+		err := database.WithTx(ctx, func(tx db.Database) error {
+			category, err := tx.CreateCategory(ctx, user.ID, "Rent")
+			if err != nil {
+				return err
+			}
+			// Writes made in the transaction are visible to it.
+			if _, err := tx.GetCategoryByID(ctx, category.ID); err != nil {
+				return err
+			}
+			created, err = tx.CreateTransaction(ctx, user.ID, &category.ID, 950, nil, baseTime)
+			return err
+		})
+		require.NoError(t, err)
+
+		got, err := database.GetTransactionByID(ctx, created.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.CategoryName)
+		assert.Equal(t, "Rent", *got.CategoryName)
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		user := createUser(t, database)
+
+		err := database.WithTx(ctx, func(tx db.Database) error {
+			if _, err := tx.CreateCategory(ctx, user.ID, "Travel"); err != nil {
+				return err
+			}
+			if _, err := tx.CreateTransaction(ctx, user.ID, nil, 20, nil, baseTime); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		assert.Empty(t, categoryNames(t, user.ID))
+		transactions, err := database.ListTransactions(ctx, user.ID, models.TransactionFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, transactions)
+	})
+
+	t.Run("nested transaction rolls back alone", func(t *testing.T) {
+		user := createUser(t, database)
+
+		err := database.WithTx(ctx, func(tx db.Database) error {
+			if _, err := tx.CreateCategory(ctx, user.ID, "Outer"); err != nil {
+				return err
+			}
+			err := tx.WithTx(ctx, func(inner db.Database) error {
+				if _, err := inner.CreateCategory(ctx, user.ID, "Inner"); err != nil {
+					return err
+				}
+				return errRollback
+			})
+			if !errors.Is(err, errRollback) {
+				return err
+			}
+			_, err = tx.CreateCategory(ctx, user.ID, "After")
+			return err
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"After", "Outer"}, categoryNames(t, user.ID))
+	})
+
+	t.Run("concurrent transactions are serializable", func(t *testing.T) {
+		user := createUser(t, database)
+
+		// Each transaction records how many transactions it saw. Run one
+		// after another, they see 0, 1, 2 and 3; any interleaving that lets
+		// two see the same count must be retried.
+		const writers = 4
+		var wg sync.WaitGroup
+		errs := make([]error, writers)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = database.WithTx(ctx, func(tx db.Database) error {
+					existing, err := tx.ListTransactions(ctx, user.ID, models.TransactionFilter{})
+					if err != nil {
+						return err
+					}
+					_, err = tx.CreateTransaction(ctx, user.ID, nil, float64(len(existing)+1), nil, baseTime)
+					return err
+				})
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		transactions, err := database.ListTransactions(ctx, user.ID, models.TransactionFilter{})
+		require.NoError(t, err)
+		amounts := make([]float64, len(transactions))
+		for i, transaction := range transactions {
+			amounts[i] = transaction.Amount
+		}
+		assert.ElementsMatch(t, []float64{1, 2, 3, 4}, amounts)
+	})
+}
+
+func testWebhooks(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	const secret = "conformance-secret"
+
+	createSubscription := func(t *testing.T, userID string, eventTypes ...string) *models.WebhookSubscription {
+		subscription, err := database.CreateWebhookSubscription(ctx, userID, "https://example.com/hook", secret, eventTypes)
+		require.NoError(t, err)
+		return subscription
+	}
+	deliveries := func(t *testing.T, userID, subscriptionID string) []models.WebhookDelivery {
+		deliveries, err := database.ListWebhookDeliveries(ctx, userID, subscriptionID)
+		require.NoError(t, err)
+		return deliveries
+	}
+	// claim claims every due delivery, which may include other tests', and
+	// returns the one with id.
+	claim := func(t *testing.T, now time.Time, id string) *models.OutgoingWebhook {
+		webhooks, err := database.ClaimWebhookDeliveries(ctx, now, time.Minute, 1000)
+		require.NoError(t, err)
+		for _, webhook := range webhooks {
+			if webhook.Delivery.ID == id {
+				return &webhook
+			}
+		}
+		return nil
+	}
+
+	t.Run("subscriptions", func(t *testing.T) {
+		user := createUser(t, database)
+		first := createSubscription(t, user.ID, models.EventTransactionCreated)
+		second := createSubscription(t, user.ID, models.EventTransactionCreated, models.EventCategoryCreated)
+		assert.Equal(t, user.ID, second.UserID)
+		assert.Equal(t, secret, second.Secret)
+		assert.Equal(t, []string{models.EventTransactionCreated, models.EventCategoryCreated}, second.EventTypes)
+		assert.False(t, second.CreatedAt.IsZero())
+
+		subscriptions, err := database.ListWebhookSubscriptions(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 2)
+		assert.Equal(t, second.ID, subscriptions[0].ID, "newest first")
+		assert.Equal(t, first.ID, subscriptions[1].ID)
+		assert.Equal(t, second.EventTypes, subscriptions[0].EventTypes)
+		assert.Equal(t, secret, subscriptions[0].Secret)
+
+		other := createUser(t, database)
+		subscriptions, err = database.ListWebhookSubscriptions(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, subscriptions)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := database.CreateWebhookSubscription(ctx, uuid.NewString(), "https://example.com/hook", secret, []string{models.EventTransactionCreated})
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+
+		_, err = database.CreateWebhookSubscription(ctx, "not-a-uuid", "https://example.com/hook", secret, []string{models.EventTransactionCreated})
+		assert.ErrorIs(t, err, db.ErrInvalidID)
+	})
+
+	t.Run("events are queued for matching subscriptions", func(t *testing.T) {
+		user := createUser(t, database)
+		transactions := createSubscription(t, user.ID, models.EventTransactionCreated)
+		categories := createSubscription(t, user.ID, models.EventCategoryCreated)
+		other := createUser(t, database)
+		otherSubscription := createSubscription(t, other.ID, models.EventTransactionCreated)
+
+		category := createCategory(t, database, user.ID, "Food")
+		transaction := createTransaction(t, database, user.ID, &category.ID, 42.5, "Dinner", baseTime)
+
+		queued := deliveries(t, user.ID, transactions.ID)
+		require.Len(t, queued, 1)
+		delivery := queued[0]
+		assert.Equal(t, transactions.ID, delivery.SubscriptionID)
+		assert.Equal(t, models.EventTransactionCreated, delivery.EventType)
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Zero(t, delivery.Attempts)
+		assert.NotNil(t, delivery.NextAttemptAt)
+		assert.Nil(t, delivery.LastAttemptAt)
+
+		var event struct {
+			ID   string             `json:"id"`
+			Type string             `json:"type"`
+			Data models.Transaction `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(delivery.Payload, &event))
+		assert.Equal(t, delivery.EventID, event.ID)
+		assert.Equal(t, models.EventTransactionCreated, event.Type)
+		assert.Equal(t, transaction.ID, event.Data.ID)
+		assert.Equal(t, 42.5, event.Data.Amount)
+
+		queued = deliveries(t, user.ID, categories.ID)
+		require.Len(t, queued, 1)
+		assert.Equal(t, models.EventCategoryCreated, queued[0].EventType)
+
+		assert.Empty(t, deliveries(t, other.ID, otherSubscription.ID))
+	})
+
+	t.Run("events roll back with the change", func(t *testing.T) {
+		user := createUser(t, database)
+		subscription := createSubscription(t, user.ID, models.EventTransactionCreated)
+
+		errRollback := errors.New("roll back")
+		err := database.WithTx(ctx, func(tx db.Database) error {
+			if _, err := tx.CreateTransaction(ctx, user.ID, nil, 10, nil, baseTime); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+		assert.Empty(t, deliveries(t, user.ID, subscription.ID))
+
+		// A failed change queues nothing either.
+		_, err = database.CreateTransaction(ctx, user.ID, nil, -1, nil, baseTime)
+		assert.Error(t, err)
+		assert.Empty(t, deliveries(t, user.ID, subscription.ID))
+	})
+
+	t.Run("claim and record attempts", func(t *testing.T) {
+		user := createUser(t, database)
+		subscription := createSubscription(t, user.ID, models.EventTransactionCreated)
+		createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		id := deliveries(t, user.ID, subscription.ID)[0].ID
+
+		now := time.Now().Add(time.Second)
+		claimed := claim(t, now, id)
+		require.NotNil(t, claimed, "a due delivery is claimed")
+		assert.Equal(t, subscription.URL, claimed.URL)
+		assert.Equal(t, secret, claimed.Secret)
+		assert.Equal(t, models.EventTransactionCreated, claimed.Delivery.EventType)
+		assert.NotEmpty(t, claimed.Delivery.Payload)
+
+		assert.Nil(t, claim(t, now, id), "a claimed delivery is leased")
+		assert.NotNil(t, claim(t, now.Add(2*time.Minute), id), "an expired lease can be claimed again")
+
+		status := 503
+		message := "service unavailable"
+		retryAt := now.Add(time.Hour)
+		require.NoError(t, database.RecordWebhookAttempt(ctx, id, models.WebhookAttempt{
+			At:             now,
+			ResponseStatus: &status,
+			Error:          &message,
+			NextAttemptAt:  &retryAt,
+		}))
+
+		delivery := deliveries(t, user.ID, subscription.ID)[0]
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, &status, delivery.ResponseStatus)
+		assert.Equal(t, &message, delivery.LastError)
+		require.NotNil(t, delivery.LastAttemptAt)
+		assert.WithinDuration(t, now, *delivery.LastAttemptAt, time.Millisecond)
+		require.NotNil(t, delivery.NextAttemptAt)
+		assert.WithinDuration(t, retryAt, *delivery.NextAttemptAt, time.Millisecond)
+
+		assert.Nil(t, claim(t, now.Add(time.Minute), id), "not due before its next attempt")
+		require.NotNil(t, claim(t, retryAt.Add(time.Second), id))
+
+		status = 204
+		require.NoError(t, database.RecordWebhookAttempt(ctx, id, models.WebhookAttempt{
+			At:             retryAt,
+			Delivered:      true,
+			ResponseStatus: &status,
+		}))
+
+		delivery = deliveries(t, user.ID, subscription.ID)[0]
+		assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Nil(t, delivery.LastError)
+		assert.Nil(t, delivery.NextAttemptAt)
+		assert.Nil(t, claim(t, retryAt.Add(time.Hour), id), "a delivered delivery is not claimed")
+	})
+
+	t.Run("out of attempts", func(t *testing.T) {
+		user := createUser(t, database)
+		subscription := createSubscription(t, user.ID, models.EventTransactionCreated)
+		createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		id := deliveries(t, user.ID, subscription.ID)[0].ID
+
+		message := "connection refused"
+		require.NoError(t, database.RecordWebhookAttempt(ctx, id, models.WebhookAttempt{At: time.Now(), Error: &message}))
+
+		delivery := deliveries(t, user.ID, subscription.ID)[0]
+		assert.Equal(t, models.DeliveryFailed, delivery.Status)
+		assert.Nil(t, delivery.ResponseStatus)
+		assert.Nil(t, delivery.NextAttemptAt)
+		assert.Nil(t, claim(t, time.Now().Add(time.Hour), id))
+
+		err := database.RecordWebhookAttempt(ctx, uuid.NewString(), models.WebhookAttempt{At: time.Now()})
+		assert.ErrorIs(t, err, db.ErrWebhookDeliveryNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		user := createUser(t, database)
+		subscription := createSubscription(t, user.ID, models.EventTransactionCreated)
+		createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		id := deliveries(t, user.ID, subscription.ID)[0].ID
+
+		other := createUser(t, database)
+		assert.ErrorIs(t, database.DeleteWebhookSubscription(ctx, other.ID, subscription.ID), db.ErrWebhookNotFound)
+		_, err := database.ListWebhookDeliveries(ctx, other.ID, subscription.ID)
+		assert.ErrorIs(t, err, db.ErrWebhookNotFound)
+
+		require.NoError(t, database.DeleteWebhookSubscription(ctx, user.ID, subscription.ID))
+		assert.ErrorIs(t, database.DeleteWebhookSubscription(ctx, user.ID, subscription.ID), db.ErrWebhookNotFound)
+		_, err = database.ListWebhookDeliveries(ctx, user.ID, subscription.ID)
+		assert.ErrorIs(t, err, db.ErrWebhookNotFound)
+		assert.Nil(t, claim(t, time.Now().Add(time.Hour), id), "deliveries are deleted with their subscription")
+	})
+}
+
+func testEvents(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+
+	events := func(t *testing.T, userID string, afterID int64) []models.Event {
+		events, err := database.ListEvents(ctx, userID, afterID, 100)
+		require.NoError(t, err)
+		return events
+	}
+	types := func(events []models.Event) []string {
+		var types []string
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return types
+	}
+
+	t.Run("changes are recorded in order", func(t *testing.T) {
+		user := createUser(t, database)
+		other := createUser(t, database)
+		createCategory(t, database, other.ID, "Other")
+
+		last, err := database.LastEventID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Zero(t, last)
+
+		category := createCategory(t, database, user.ID, "Food")
+		keep := createTransaction(t, database, user.ID, nil, 10, "Lunch", baseTime)
+		discard := createTransaction(t, database, user.ID, &category.ID, 10, "", baseTime)
+		_, err = database.MergeDuplicateTransactions(ctx, user.ID, keep.ID, discard.ID)
+		require.NoError(t, err)
+
+		all := events(t, user.ID, 0)
+		assert.Equal(t, []string{
+			models.EventCategoryCreated,
+			models.EventTransactionCreated,
+			models.EventTransactionCreated,
+			models.EventTransactionUpdated,
+			models.EventTransactionDeleted,
+		}, types(all))
+		for i, event := range all {
+			assert.Equal(t, user.ID, event.UserID)
+			assert.False(t, event.CreatedAt.IsZero())
+			if i > 0 {
+				assert.Greater(t, event.ID, all[i-1].ID)
+			}
+		}
+
+		var created models.Category
+		require.NoError(t, json.Unmarshal(all[0].Data, &created))
+		assert.Equal(t, category.ID, created.ID)
+		var updated models.Transaction
+		require.NoError(t, json.Unmarshal(all[3].Data, &updated))
+		assert.Equal(t, keep.ID, updated.ID)
+		assert.Equal(t, &category.ID, updated.CategoryID)
+		var deleted models.DeletedResource
+		require.NoError(t, json.Unmarshal(all[4].Data, &deleted))
+		assert.Equal(t, discard.ID, deleted.ID)
+
+		last, err = database.LastEventID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, all[4].ID, last)
+
+		page, err := database.ListEvents(ctx, user.ID, all[1].ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, all[2:4], page)
+		assert.Empty(t, events(t, user.ID, last))
+	})
+
+	t.Run("events roll back with the change", func(t *testing.T) {
+		user := createUser(t, database)
+
+		errRollback := errors.New("roll back")
+		err := database.WithTx(ctx, func(tx db.Database) error {
+			if _, err := tx.CreateCategory(ctx, user.ID, "Food"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+		assert.Empty(t, events(t, user.ID, 0))
+	})
+
+	t.Run("watchers are woken when changes commit", func(t *testing.T) {
+		user := createUser(t, database)
+		watchCtx, stop := context.WithCancel(ctx)
+		wakes, err := database.WatchEvents(watchCtx, user.ID)
+		require.NoError(t, err)
+
+		// Drain any wake from the watch starting up.
+		drain := time.After(200 * time.Millisecond)
+	drained:
+		for {
+			select {
+			case <-wakes:
+			case <-drain:
+				break drained
+			}
+		}
+
+		err = database.WithTx(ctx, func(tx db.Database) error {
+			if _, err := tx.CreateCategory(ctx, user.ID, "Food"); err != nil {
+				return err
+			}
+			select {
+			case <-wakes:
+				t.Error("woken before the change committed")
+			case <-time.After(50 * time.Millisecond):
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		select {
+		case <-wakes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("not woken after the change committed")
+		}
+		assert.Len(t, events(t, user.ID, 0), 1)
+
+		stop()
+		require.Eventually(t, func() bool {
+			select {
+			case _, ok := <-wakes:
+				return !ok
+			default:
+				return false
+			}
+		}, 5*time.Second, 10*time.Millisecond, "closed when the context is done")
+	})
+}
+
+func testAudit(t *testing.T, database db.Database) {
+	ctx := db.WithAuditInfo(testContext(t), db.AuditInfo{Actor: "api_key:test", RequestID: "req-1"})
+
+	entries := func(t *testing.T, userID string, filter models.AuditFilter) []models.AuditEntry {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = 100
+		}
+		entries, err := database.ListAuditEntries(ctx, userID, filter)
+		require.NoError(t, err)
+		return entries
+	}
+	summarize := func(entries []models.AuditEntry) []string {
+		var changes []string
+		for _, e := range entries {
+			changes = append(changes, e.Action+" "+e.Entity)
+		}
+		return changes
+	}
+
+	t.Run("every change is recorded with its actor and snapshots", func(t *testing.T) {
+		user, err := database.CreateUser(ctx, uniqueEmail())
+		require.NoError(t, err)
+		category, err := database.CreateCategory(ctx, user.ID, "Food")
+		require.NoError(t, err)
+		keep, err := database.CreateTransaction(ctx, user.ID, nil, 10, nil, baseTime)
+		require.NoError(t, err)
+		discard, err := database.CreateTransaction(ctx, user.ID, &category.ID, 10, nil, baseTime)
+		require.NoError(t, err)
+		other, err := database.CreateTransaction(ctx, user.ID, nil, 10, nil, baseTime)
+		require.NoError(t, err)
+		_, err = database.MergeDuplicateTransactions(ctx, user.ID, keep.ID, discard.ID)
+		require.NoError(t, err)
+		require.NoError(t, database.DismissDuplicate(ctx, user.ID, other.ID, keep.ID))
+		require.NoError(t, database.DismissDuplicate(ctx, user.ID, keep.ID, other.ID), "already dismissed")
+		subscription, err := database.CreateWebhookSubscription(ctx, user.ID, "https://example.com/hooks", "0123456789abcdef", []string{models.EventCategoryCreated})
+		require.NoError(t, err)
+		require.NoError(t, database.DeleteWebhookSubscription(ctx, user.ID, subscription.ID))
+
+		all := entries(t, user.ID, models.AuditFilter{})
+		assert.Equal(t, []string{
+			"delete webhook_subscription",
+			"create webhook_subscription",
+			"create dismissed_duplicate",
+			"delete transaction",
+			"update transaction",
+			"create transaction",
+			"create transaction",
+			"create transaction",
+			"create category",
+			"create user",
+		}, summarize(all))
+		for i, e := range all {
+			assert.Equal(t, user.ID, e.UserID)
+			assert.Equal(t, "api_key:test", e.Actor)
+			require.NotNil(t, e.RequestID)
+			assert.Equal(t, "req-1", *e.RequestID)
+			assert.False(t, e.CreatedAt.IsZero())
+			if i > 0 {
+				assert.Less(t, e.ID, all[i-1].ID)
+			}
+		}
+
+		created := all[9]
+		assert.Equal(t, user.ID, created.EntityID)
+		assert.Nil(t, created.Before)
+		assert.JSONEq(t, `"`+user.Email+`"`, jsonField(t, created.After, "email"))
+
+		updated, deleted := all[4], all[3]
+		assert.Equal(t, keep.ID, updated.EntityID)
+		assert.Equal(t, "null", jsonField(t, updated.Before, "category_id"))
+		assert.JSONEq(t, `"`+category.ID+`"`, jsonField(t, updated.After, "category_id"))
+		assert.Equal(t, discard.ID, deleted.EntityID)
+		assert.JSONEq(t, `"`+category.ID+`"`, jsonField(t, deleted.Before, "category_id"))
+		assert.Equal(t, "null", jsonField(t, deleted.Before, "deleted_at"))
+		assert.NotEqual(t, "null", jsonField(t, deleted.After, "deleted_at"), "discarded transactions go to the trash")
+
+		dismissed := all[2]
+		assert.Contains(t, dismissed.EntityID, keep.ID)
+		assert.Contains(t, dismissed.EntityID, other.ID)
+
+		removed := all[0]
+		assert.Equal(t, subscription.ID, removed.EntityID)
+		assert.JSONEq(t, `"https://example.com/hooks"`, jsonField(t, removed.Before, "url"))
+		assert.NotContains(t, string(removed.Before), "0123456789abcdef", "secrets are not logged")
+		assert.NotContains(t, string(all[1].After), "0123456789abcdef", "secrets are not logged")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		user := createUser(t, database)
+		category := createCategory(t, database, user.ID, "Food")
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		createUser(t, database)
+
+		assert.Equal(t, []string{"create category"}, summarize(entries(t, user.ID, models.AuditFilter{Entity: models.EntityCategory})))
+		assert.Equal(t, []string{"create transaction"}, summarize(entries(t, user.ID, models.AuditFilter{EntityID: transaction.ID})))
+		assert.Len(t, entries(t, user.ID, models.AuditFilter{Action: models.AuditCreate}), 3)
+		assert.Empty(t, entries(t, user.ID, models.AuditFilter{Action: models.AuditDelete}))
+		assert.Len(t, entries(t, user.ID, models.AuditFilter{Actor: db.SystemActor}), 3, "changes without audit info are the system's")
+		assert.Empty(t, entries(t, user.ID, models.AuditFilter{Actor: "api_key:test"}))
+		assert.Empty(t, entries(t, user.ID, models.AuditFilter{RequestID: "req-1"}))
+
+		all := entries(t, user.ID, models.AuditFilter{})
+		require.Len(t, all, 3)
+		from := all[0].CreatedAt
+		assert.Equal(t, all[:1], entries(t, user.ID, models.AuditFilter{From: &from}))
+		to := all[2].CreatedAt
+		assert.Equal(t, all[2:], entries(t, user.ID, models.AuditFilter{To: &to}))
+
+		page := entries(t, user.ID, models.AuditFilter{Limit: 2})
+		assert.Equal(t, all[:2], page)
+		assert.Equal(t, all[2:], entries(t, user.ID, models.AuditFilter{BeforeID: page[1].ID, Limit: 2}))
+		assert.Equal(t, category.ID, all[1].EntityID)
+	})
+
+	t.Run("entries roll back with the change", func(t *testing.T) {
+		user := createUser(t, database)
+
+		errRollback := errors.New("roll back")
+		err := database.WithTx(ctx, func(tx db.Database) error {
+			if _, err := tx.CreateCategory(ctx, user.ID, "Food"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+		assert.Equal(t, []string{"create user"}, summarize(entries(t, user.ID, models.AuditFilter{})))
+	})
+}
+
+func testTrash(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+
+	t.Run("deleted rows are hidden until restored", func(t *testing.T) {
+		user := createUser(t, database)
+		food := createCategory(t, database, user.ID, "Food")
+		rent := createCategory(t, database, user.ID, "Rent")
+		lunch := createTransaction(t, database, user.ID, &food.ID, 12.5, "Lunch", baseTime)
+		dinner := createTransaction(t, database, user.ID, &food.ID, 30, "Dinner", baseTime.Add(time.Hour))
+
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, lunch.ID))
+		require.NoError(t, database.DeleteCategory(ctx, user.ID, rent.ID))
+		assert.ErrorIs(t, database.DeleteTransaction(ctx, user.ID, lunch.ID), db.ErrTransactionNotFound, "already deleted")
+		assert.ErrorIs(t, database.DeleteCategory(ctx, user.ID, rent.ID), db.ErrCategoryNotFound, "already deleted")
+
+		_, err := database.GetTransactionByID(ctx, lunch.ID)
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+		_, err = database.GetCategoryByID(ctx, rent.ID)
+		assert.ErrorIs(t, err, db.ErrCategoryNotFound)
+		assert.ErrorIs(t, database.ValidateCategoryOwnership(ctx, rent.ID, user.ID), db.ErrCategoryNotOwned)
+		_, err = database.CreateCategory(ctx, user.ID, "Rent")
+		assert.ErrorIs(t, err, db.ErrDuplicateCategory, "the name is taken until the category is purged")
+
+		transactions, err := database.ListTransactions(ctx, user.ID, models.TransactionFilter{})
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+		assert.Equal(t, dinner.ID, transactions[0].ID)
+		categories, err := database.ListCategories(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, categories, 1)
+		assert.Equal(t, food.ID, categories[0].ID)
+		summary, err := database.GetSummary(ctx, user.ID, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, summary.Categories, 1)
+		assert.Equal(t, 30.0, summary.Categories[0].Total)
+
+		trash, err := database.ListTrash(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, trash.Categories, 1)
+		assert.Equal(t, rent.ID, trash.Categories[0].ID)
+		assert.NotNil(t, trash.Categories[0].DeletedAt)
+		require.Len(t, trash.Transactions, 1)
+		assert.Equal(t, lunch.ID, trash.Transactions[0].ID)
+		assert.NotNil(t, trash.Transactions[0].DeletedAt)
+		require.NotNil(t, trash.Transactions[0].CategoryName)
+		assert.Equal(t, "Food", *trash.Transactions[0].CategoryName)
+
+		restored, err := database.RestoreTransaction(ctx, user.ID, lunch.ID)
+		require.NoError(t, err)
+		assert.Equal(t, lunch.ID, restored.ID)
+		assert.Nil(t, restored.DeletedAt)
+		require.NotNil(t, restored.CategoryName)
+		assert.Equal(t, "Food", *restored.CategoryName)
+		category, err := database.RestoreCategory(ctx, user.ID, rent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Rent", category.Name)
+		assert.Nil(t, category.DeletedAt)
+
+		_, err = database.RestoreTransaction(ctx, user.ID, lunch.ID)
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound, "not in the trash")
+		_, err = database.RestoreCategory(ctx, user.ID, rent.ID)
+		assert.ErrorIs(t, err, db.ErrCategoryNotFound, "not in the trash")
+
+		trash, err = database.ListTrash(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, trash.Categories)
+		assert.Empty(t, trash.Transactions)
+		_, err = database.GetTransactionByID(ctx, lunch.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("transactions in a trashed category are shown without it", func(t *testing.T) {
+		user := createUser(t, database)
+		food := createCategory(t, database, user.ID, "Food")
+		travel := createCategory(t, database, user.ID, "Travel")
+		createTransaction(t, database, user.ID, &food.ID, 12.5, "Lunch", baseTime)
+		train := createTransaction(t, database, user.ID, &travel.ID, 40.1, "Train", baseTime)
+		createTransaction(t, database, user.ID, &travel.ID, 40.1, "Train", baseTime.AddDate(0, 0, 1))
+		createTransaction(t, database, user.ID, nil, 5.2, "Coffee", baseTime)
+
+		require.NoError(t, database.DeleteCategory(ctx, user.ID, travel.ID))
+
+		transaction, err := database.GetTransactionByID(ctx, train.ID)
+		require.NoError(t, err)
+		require.NotNil(t, transaction.CategoryID, "the transaction keeps its category to be restored with")
+		assert.Equal(t, travel.ID, *transaction.CategoryID)
+		assert.Nil(t, transaction.CategoryName)
+		transactions, err := database.ListTransactions(ctx, user.ID, models.TransactionFilter{CategoryIDs: []string{travel.ID}})
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		assert.Nil(t, transactions[0].CategoryName)
+		assert.Nil(t, transactions[1].CategoryName)
+		pairs, err := database.FindDuplicateTransactions(ctx, user.ID, 3)
+		require.NoError(t, err)
+		require.Len(t, pairs, 1)
+		assert.Nil(t, pairs[0].Transaction.CategoryName)
+		assert.Nil(t, pairs[0].Duplicate.CategoryName)
+
+		summary, err := database.GetSummary(ctx, user.ID, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, summary.Categories, 2)
+		assert.Equal(t, "Food", summary.Categories[0].CategoryName)
+		assert.Equal(t, 12.5, summary.Categories[0].Total)
+		assert.Equal(t, "Uncategorized", summary.Categories[1].CategoryName)
+		assert.Nil(t, summary.Categories[1].CategoryID)
+		assert.Equal(t, 85.4, summary.Categories[1].Total)
+
+		_, err = database.RestoreCategory(ctx, user.ID, travel.ID)
+		require.NoError(t, err)
+		transaction, err = database.GetTransactionByID(ctx, train.ID)
+		require.NoError(t, err)
+		require.NotNil(t, transaction.CategoryName)
+		assert.Equal(t, "Travel", *transaction.CategoryName)
+		summary, err = database.GetSummary(ctx, user.ID, nil, nil)
+		require.NoError(t, err)
+		assert.Len(t, summary.Categories, 3)
+	})
+
+	t.Run("other users' rows", func(t *testing.T) {
+		user := createUser(t, database)
+		other := createUser(t, database)
+		category := createCategory(t, database, user.ID, "Food")
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+
+		assert.ErrorIs(t, database.DeleteTransaction(ctx, other.ID, transaction.ID), db.ErrTransactionNotFound)
+		assert.ErrorIs(t, database.DeleteCategory(ctx, other.ID, category.ID), db.ErrCategoryNotFound)
+
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+		require.NoError(t, database.DeleteCategory(ctx, user.ID, category.ID))
+		_, err := database.RestoreTransaction(ctx, other.ID, transaction.ID)
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+		_, err = database.RestoreCategory(ctx, other.ID, category.ID)
+		assert.ErrorIs(t, err, db.ErrCategoryNotFound)
+		trash, err := database.ListTrash(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, trash.Categories)
+		assert.Empty(t, trash.Transactions)
+	})
+
+	t.Run("purge removes what was deleted before the cutoff", func(t *testing.T) {
+		user := createUser(t, database)
+		food := createCategory(t, database, user.ID, "Food")
+		kept := createTransaction(t, database, user.ID, &food.ID, 10, "", baseTime)
+		purged := createTransaction(t, database, user.ID, nil, 20, "", baseTime)
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, purged.ID))
+		require.NoError(t, database.DeleteCategory(ctx, user.ID, food.ID))
+
+		_, err := database.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		trash, err := database.ListTrash(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Len(t, trash.Categories, 1, "deleted after the cutoff")
+		assert.Len(t, trash.Transactions, 1, "deleted after the cutoff")
+
+		n, err := database.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, n, 2)
+		trash, err = database.ListTrash(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, trash.Categories)
+		assert.Empty(t, trash.Transactions)
+
+		_, err = database.RestoreTransaction(ctx, user.ID, purged.ID)
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+		transaction, err := database.GetTransactionByID(ctx, kept.ID)
+		require.NoError(t, err)
+		assert.Nil(t, transaction.CategoryID, "purged categories leave their transactions uncategorized")
+		_, err = database.CreateCategory(ctx, user.ID, "Food")
+		assert.NoError(t, err, "the name is free once purged")
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Action: models.AuditDelete, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 4, "moved to the trash, then purged")
+		for _, e := range entries[:2] {
+			assert.Nil(t, e.After, "purging is a permanent deletion")
+		}
+		for _, e := range entries[2:] {
+			assert.NotEqual(t, "null", jsonField(t, e.After, "deleted_at"))
+		}
+	})
+}
+
+func testErasure(t *testing.T, database db.Database) {
+	ctx := db.WithAuditInfo(testContext(t), db.AuditInfo{Actor: "api_key:test", RequestID: "req-1"})
+
+	user, err := database.CreateUser(ctx, uniqueEmail())
+	require.NoError(t, err)
+	food := createCategory(t, database, user.ID, "Food")
+	createTransaction(t, database, user.ID, &food.ID, 10, "Lunch", baseTime)
+	trashed := createTransaction(t, database, user.ID, nil, 20, "", baseTime)
+	require.NoError(t, database.DeleteTransaction(ctx, user.ID, trashed.ID))
+	_, err = database.CreateWebhookSubscription(ctx, user.ID, "https://example.com/hooks", "0123456789abcdef", []string{models.EventCategoryCreated})
+	require.NoError(t, err)
+	other := createUser(t, database)
+	otherCategory := createCategory(t, database, other.ID, "Food")
+
+	require.NoError(t, database.DeleteUser(ctx, user.ID))
+
+	_, err = database.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+	_, err = database.GetUserByEmail(ctx, user.Email)
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+	_, err = database.GetCategoryByID(ctx, food.ID)
+	assert.ErrorIs(t, err, db.ErrCategoryNotFound)
+	_, err = database.GetTransactionByID(ctx, trashed.ID)
+	assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+	subscriptions, err := database.ListWebhookSubscriptions(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+	events, err := database.ListEvents(ctx, user.ID, 0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.ErrorIs(t, database.DeleteUser(ctx, user.ID), db.ErrUserNotFound)
+
+	entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, entries, 7)
+	erased := entries[0]
+	assert.Equal(t, models.EntityUser, erased.Entity)
+	assert.Equal(t, models.AuditDelete, erased.Action)
+	assert.Equal(t, "api_key:test", erased.Actor, "the erasure itself is attributed")
+	assert.Nil(t, erased.Before)
+	assert.Nil(t, erased.After)
+	for _, e := range entries[1:] {
+		assert.Equal(t, db.RedactedActor, e.Actor)
+		assert.Nil(t, e.RequestID)
+		assert.Nil(t, e.Before)
+		assert.Nil(t, e.After)
+		assert.NotEmpty(t, e.EntityID, "the history of changes is kept")
+	}
+
+	category, err := database.GetCategoryByID(ctx, otherCategory.ID)
+	require.NoError(t, err, "other users are untouched")
+	assert.Equal(t, other.ID, category.UserID)
+	entries, err = database.ListAuditEntries(ctx, other.ID, models.AuditFilter{Limit: 100})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	assert.NotNil(t, entries[0].After)
+
+	_, err = database.CreateUser(ctx, user.Email)
+	assert.NoError(t, err, "the email is free once erased")
+}
+
+func testAttachments(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+
+	attach := func(t *testing.T, userID, transactionID, filename string) *models.Attachment {
+		t.Helper()
+		attachment, err := database.CreateAttachment(ctx, userID, transactionID, models.Attachment{
+			Filename:    filename,
+			ContentType: "application/pdf",
+			Size:        1024,
+			SHA256:      strings.Repeat("ab", 32),
+			StorageKey:  "attachments/" + uuid.NewString(),
+		})
+		require.NoError(t, err)
+		return attachment
+	}
+	// orphaned reports which of keys are queued for removal. Other subtests
+	// may share the database, so the queue is not assumed to hold only these.
+	orphaned := func(t *testing.T, keys ...string) []string {
+		t.Helper()
+		queued, err := database.ListOrphanedBlobs(ctx, 10000)
+		require.NoError(t, err)
+		var found []string
+		for _, key := range keys {
+			if slices.Contains(queued, key) {
+				found = append(found, key)
+			}
+		}
+		return found
+	}
+
+	t.Run("create, list and get", func(t *testing.T) {
+		user := createUser(t, database)
+		transaction := createTransaction(t, database, user.ID, nil, 10, "Printer", baseTime)
+
+		first := attach(t, user.ID, transaction.ID, "receipt.pdf")
+		assert.NotEmpty(t, first.ID)
+		assert.Equal(t, user.ID, first.UserID)
+		assert.Equal(t, transaction.ID, first.TransactionID)
+		assert.Equal(t, "receipt.pdf", first.Filename)
+		assert.Equal(t, int64(1024), first.Size)
+		assert.False(t, first.CreatedAt.IsZero())
+		second := attach(t, user.ID, transaction.ID, "invoice.pdf")
+
+		attachments, err := database.ListAttachments(ctx, user.ID, transaction.ID)
+		require.NoError(t, err)
+		require.Len(t, attachments, 2)
+		assert.Equal(t, first.ID, attachments[0].ID, "oldest first")
+		assert.Equal(t, second.ID, attachments[1].ID)
+
+		got, err := database.GetAttachment(ctx, user.ID, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first.StorageKey, got.StorageKey)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityAttachment, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("other users' transactions and attachments", func(t *testing.T) {
+		user := createUser(t, database)
+		other := createUser(t, database)
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		attachment := attach(t, user.ID, transaction.ID, "receipt.jpg")
+
+		_, err := database.CreateAttachment(ctx, other.ID, transaction.ID, models.Attachment{StorageKey: "attachments/" + uuid.NewString()})
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+		_, err = database.ListAttachments(ctx, other.ID, transaction.ID)
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+		_, err = database.GetAttachment(ctx, other.ID, attachment.ID)
+		assert.ErrorIs(t, err, db.ErrAttachmentNotFound)
+		assert.ErrorIs(t, database.DeleteAttachment(ctx, other.ID, attachment.ID), db.ErrAttachmentNotFound)
+		_, err = database.GetAttachment(ctx, user.ID, attachment.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("attachments of trashed transactions are hidden until restored", func(t *testing.T) {
+		user := createUser(t, database)
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		attachment := attach(t, user.ID, transaction.ID, "receipt.png")
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+
+		_, err := database.GetAttachment(ctx, user.ID, attachment.ID)
+		assert.ErrorIs(t, err, db.ErrAttachmentNotFound)
+		_, err = database.ListAttachments(ctx, user.ID, transaction.ID)
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+		_, err = database.CreateAttachment(ctx, user.ID, transaction.ID, models.Attachment{StorageKey: "attachments/" + uuid.NewString()})
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+		assert.Empty(t, orphaned(t, attachment.StorageKey), "kept while the transaction can be restored")
+
+		_, err = database.RestoreTransaction(ctx, user.ID, transaction.ID)
+		require.NoError(t, err)
+		_, err = database.GetAttachment(ctx, user.ID, attachment.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deleted attachments orphan their blobs", func(t *testing.T) {
+		user := createUser(t, database)
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		attachment := attach(t, user.ID, transaction.ID, "receipt.pdf")
+
+		require.NoError(t, database.DeleteAttachment(ctx, user.ID, attachment.ID))
+		_, err := database.GetAttachment(ctx, user.ID, attachment.ID)
+		assert.ErrorIs(t, err, db.ErrAttachmentNotFound)
+		assert.ErrorIs(t, database.DeleteAttachment(ctx, user.ID, attachment.ID), db.ErrAttachmentNotFound)
+		assert.Equal(t, []string{attachment.StorageKey}, orphaned(t, attachment.StorageKey))
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityAttachment, Action: models.AuditDelete, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, attachment.ID, entries[0].EntityID)
+
+		require.NoError(t, database.ForgetOrphanedBlobs(ctx, []string{attachment.StorageKey}))
+		assert.Empty(t, orphaned(t, attachment.StorageKey))
+		assert.NoError(t, database.ForgetOrphanedBlobs(ctx, []string{attachment.StorageKey}), "forgetting twice is harmless")
+	})
+
+	t.Run("purged transactions orphan their attachments' blobs", func(t *testing.T) {
+		user := createUser(t, database)
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		first := attach(t, user.ID, transaction.ID, "receipt.pdf")
+		second := attach(t, user.ID, transaction.ID, "invoice.pdf")
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+
+		_, err := database.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{first.StorageKey, second.StorageKey}, orphaned(t, first.StorageKey, second.StorageKey))
+		require.NoError(t, database.ForgetOrphanedBlobs(ctx, []string{first.StorageKey, second.StorageKey}))
+	})
+
+	t.Run("erased users orphan their attachments' blobs", func(t *testing.T) {
+		user := createUser(t, database)
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		attachment := attach(t, user.ID, transaction.ID, "receipt.pdf")
+
+		require.NoError(t, database.DeleteUser(ctx, user.ID))
+		assert.Equal(t, []string{attachment.StorageKey}, orphaned(t, attachment.StorageKey))
+		require.NoError(t, database.ForgetOrphanedBlobs(ctx, []string{attachment.StorageKey}))
+	})
+
+	t.Run("orphans are listed up to the limit", func(t *testing.T) {
+		user := createUser(t, database)
+		transaction := createTransaction(t, database, user.ID, nil, 10, "", baseTime)
+		attachment := attach(t, user.ID, transaction.ID, "receipt.pdf")
+		require.NoError(t, database.DeleteAttachment(ctx, user.ID, attachment.ID))
+		t.Cleanup(func() { _ = database.ForgetOrphanedBlobs(context.Background(), []string{attachment.StorageKey}) })
+
+		keys, err := database.ListOrphanedBlobs(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+	})
+}
+
+func testGoals(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	targetDate := baseTime.AddDate(1, 0, 0)
+
+	createGoal := func(t *testing.T, userID, name string, target float64) *models.Goal {
+		t.Helper()
+		goal, err := database.CreateGoal(ctx, userID, models.Goal{Name: name, TargetAmount: target, TargetDate: &targetDate})
+		require.NoError(t, err)
+		return goal
+	}
+	contribute := func(t *testing.T, userID, goalID string, amount float64, at time.Time) *models.GoalContribution {
+		t.Helper()
+		contribution, err := database.CreateGoalContribution(ctx, userID, goalID, models.GoalContribution{Amount: amount, ContributedAt: at})
+		require.NoError(t, err)
+		return contribution
+	}
+
+	t.Run("create, list, get and delete", func(t *testing.T) {
+		user := createUser(t, database)
+		fund := createGoal(t, user.ID, "Emergency fund", 10000.005)
+		assert.NotEmpty(t, fund.ID)
+		assert.Equal(t, user.ID, fund.UserID)
+		assert.Equal(t, "Emergency fund", fund.Name)
+		assert.Equal(t, 10000.01, fund.TargetAmount, "stored to the cent")
+		require.NotNil(t, fund.TargetDate)
+		assert.True(t, targetDate.Equal(*fund.TargetDate))
+		laptop, err := database.CreateGoal(ctx, user.ID, models.Goal{Name: "New laptop", TargetAmount: 1500})
+		require.NoError(t, err)
+		assert.Nil(t, laptop.TargetDate)
+
+		goals, err := database.ListGoals(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, goals, 2)
+		assert.Equal(t, fund.ID, goals[0].ID, "oldest first")
+		assert.Equal(t, laptop.ID, goals[1].ID)
+
+		got, err := database.GetGoal(ctx, user.ID, fund.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fund.Name, got.Name)
+		require.NotNil(t, got.TargetDate)
+		assert.True(t, targetDate.Equal(*got.TargetDate))
+
+		contribute(t, user.ID, fund.ID, 100, baseTime)
+		require.NoError(t, database.DeleteGoal(ctx, user.ID, fund.ID))
+		_, err = database.GetGoal(ctx, user.ID, fund.ID)
+		assert.ErrorIs(t, err, db.ErrGoalNotFound)
+		_, err = database.ListGoalContributions(ctx, user.ID, fund.ID)
+		assert.ErrorIs(t, err, db.ErrGoalNotFound)
+		assert.ErrorIs(t, database.DeleteGoal(ctx, user.ID, fund.ID), db.ErrGoalNotFound)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityGoal, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 3, "two created, one deleted")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		user := createUser(t, database)
+		_, err := database.CreateGoal(ctx, user.ID, models.Goal{Name: "Nothing", TargetAmount: 0.001})
+		assert.ErrorIs(t, err, db.ErrInvalidAmount)
+		_, err = database.CreateGoal(ctx, uuid.NewString(), models.Goal{Name: "Orphan", TargetAmount: 100})
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+		_, err = database.GetGoal(ctx, user.ID, "not-a-uuid")
+		assert.ErrorIs(t, err, db.ErrInvalidID)
+	})
+
+	t.Run("manual and transaction contributions", func(t *testing.T) {
+		user := createUser(t, database)
+		goal := createGoal(t, user.ID, "Holiday", 2000)
+		note := "Birthday money"
+		manual, err := database.CreateGoalContribution(ctx, user.ID, goal.ID, models.GoalContribution{Amount: 150.255, Note: &note, ContributedAt: baseTime.Add(time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, goal.ID, manual.GoalID)
+		assert.Equal(t, user.ID, manual.UserID)
+		assert.Nil(t, manual.TransactionID)
+		assert.Equal(t, 150.26, manual.Amount)
+		require.NotNil(t, manual.Note)
+		assert.Equal(t, note, *manual.Note)
+
+		transfer := createTransaction(t, database, user.ID, nil, 300, "Savings transfer", baseTime)
+		linked, err := database.CreateGoalContribution(ctx, user.ID, goal.ID, models.GoalContribution{TransactionID: &transfer.ID, Amount: 1})
+		require.NoError(t, err)
+		require.NotNil(t, linked.TransactionID)
+		assert.Equal(t, transfer.ID, *linked.TransactionID)
+		assert.Equal(t, 300.0, linked.Amount, "the transaction's amount")
+		assert.True(t, baseTime.Equal(linked.ContributedAt), "the transaction's date")
+
+		contributions, err := database.ListGoalContributions(ctx, user.ID, goal.ID)
+		require.NoError(t, err)
+		require.Len(t, contributions, 2)
+		assert.Equal(t, linked.ID, contributions[0].ID, "earliest first")
+		assert.Equal(t, manual.ID, contributions[1].ID)
+
+		other := createGoal(t, user.ID, "Car", 5000)
+		_, err = database.CreateGoalContribution(ctx, user.ID, other.ID, models.GoalContribution{TransactionID: &transfer.ID})
+		assert.ErrorIs(t, err, db.ErrTransactionContributed, "a transaction contributes to one goal")
+
+		require.NoError(t, database.DeleteGoalContribution(ctx, user.ID, goal.ID, manual.ID))
+		assert.ErrorIs(t, database.DeleteGoalContribution(ctx, user.ID, goal.ID, manual.ID), db.ErrGoalContributionNotFound)
+		assert.ErrorIs(t, database.DeleteGoalContribution(ctx, user.ID, other.ID, linked.ID), db.ErrGoalContributionNotFound)
+		contributions, err = database.ListGoalContributions(ctx, user.ID, goal.ID)
+		require.NoError(t, err)
+		assert.Len(t, contributions, 1)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityGoalContribution, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 3, "two created, one deleted")
+	})
+
+	t.Run("other users' goals and transactions", func(t *testing.T) {
+		user := createUser(t, database)
+		other := createUser(t, database)
+		goal := createGoal(t, user.ID, "Laptop", 1500)
+		contribution := contribute(t, user.ID, goal.ID, 100, baseTime)
+		transaction := createTransaction(t, database, other.ID, nil, 50, "", baseTime)
+
+		_, err := database.GetGoal(ctx, other.ID, goal.ID)
+		assert.ErrorIs(t, err, db.ErrGoalNotFound)
+		goals, err := database.ListGoals(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, goals)
+		assert.ErrorIs(t, database.DeleteGoal(ctx, other.ID, goal.ID), db.ErrGoalNotFound)
+		_, err = database.ListGoalContributions(ctx, other.ID, goal.ID)
+		assert.ErrorIs(t, err, db.ErrGoalNotFound)
+		_, err = database.CreateGoalContribution(ctx, other.ID, goal.ID, models.GoalContribution{Amount: 10, ContributedAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrGoalNotFound)
+		assert.ErrorIs(t, database.DeleteGoalContribution(ctx, other.ID, goal.ID, contribution.ID), db.ErrGoalNotFound)
+		_, err = database.CreateGoalContribution(ctx, user.ID, goal.ID, models.GoalContribution{TransactionID: &transaction.ID})
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+	})
+
+	t.Run("contributions of trashed transactions are left out until restored", func(t *testing.T) {
+		user := createUser(t, database)
+		goal := createGoal(t, user.ID, "Emergency fund", 10000)
+		transaction := createTransaction(t, database, user.ID, nil, 250, "", baseTime)
+		contribution, err := database.CreateGoalContribution(ctx, user.ID, goal.ID, models.GoalContribution{TransactionID: &transaction.ID})
+		require.NoError(t, err)
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+
+		contributions, err := database.ListGoalContributions(ctx, user.ID, goal.ID)
+		require.NoError(t, err)
+		assert.Empty(t, contributions)
+		assert.ErrorIs(t, database.DeleteGoalContribution(ctx, user.ID, goal.ID, contribution.ID), db.ErrGoalContributionNotFound)
+		_, err = database.CreateGoalContribution(ctx, user.ID, goal.ID, models.GoalContribution{TransactionID: &transaction.ID})
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+
+		_, err = database.RestoreTransaction(ctx, user.ID, transaction.ID)
+		require.NoError(t, err)
+		contributions, err = database.ListGoalContributions(ctx, user.ID, goal.ID)
+		require.NoError(t, err)
+		assert.Len(t, contributions, 1)
+
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+		_, err = database.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		_, err = database.RestoreTransaction(ctx, user.ID, transaction.ID)
+		require.ErrorIs(t, err, db.ErrTransactionNotFound)
+		contributions, err = database.ListGoalContributions(ctx, user.ID, goal.ID)
+		require.NoError(t, err)
+		assert.Empty(t, contributions, "purged with the transaction")
+	})
+
+	t.Run("erased users' goals are deleted", func(t *testing.T) {
+		user := createUser(t, database)
+		goal := createGoal(t, user.ID, "Holiday", 2000)
+		contribute(t, user.ID, goal.ID, 100, baseTime)
+
+		require.NoError(t, database.DeleteUser(ctx, user.ID))
+		goals, err := database.ListGoals(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, goals)
+		_, err = database.GetGoal(ctx, user.ID, goal.ID)
+		assert.ErrorIs(t, err, db.ErrGoalNotFound)
+	})
+}
+
+func testAccounts(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+
+	createAccount := func(t *testing.T, userID, name, accountType string) *models.Account {
+		t.Helper()
+		account, err := database.CreateAccount(ctx, userID, models.Account{Name: name, Type: accountType})
+		require.NoError(t, err)
+		return account
+	}
+	value := func(t *testing.T, userID, accountID string, amount float64, at time.Time) *models.Valuation {
+		t.Helper()
+		valuation, err := database.CreateValuation(ctx, userID, accountID, models.Valuation{Value: amount, ValuedAt: at})
+		require.NoError(t, err)
+		return valuation
+	}
+
+	t.Run("create, list, get and delete", func(t *testing.T) {
+		user := createUser(t, database)
+		house := createAccount(t, user.ID, "House", models.AccountProperty)
+		assert.NotEmpty(t, house.ID)
+		assert.Equal(t, user.ID, house.UserID)
+		assert.Equal(t, "House", house.Name)
+		assert.Equal(t, models.AccountProperty, house.Type)
+		assert.False(t, house.Liability())
+		mortgage := createAccount(t, user.ID, "Mortgage", models.AccountMortgage)
+		assert.True(t, mortgage.Liability())
+
+		accounts, err := database.ListAccounts(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, accounts, 2)
+		assert.Equal(t, house.ID, accounts[0].ID, "oldest first")
+		assert.Equal(t, mortgage.ID, accounts[1].ID)
+
+		got, err := database.GetAccount(ctx, user.ID, house.ID)
+		require.NoError(t, err)
+		assert.Equal(t, house.Name, got.Name)
+		assert.Equal(t, house.Type, got.Type)
+
+		value(t, user.ID, house.ID, 250000, baseTime)
+		require.NoError(t, database.DeleteAccount(ctx, user.ID, house.ID))
+		_, err = database.GetAccount(ctx, user.ID, house.ID)
+		assert.ErrorIs(t, err, db.ErrAccountNotFound)
+		valuations, err := database.ListValuations(ctx, user.ID, models.ValuationFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, valuations, "deleted with the account")
+		assert.ErrorIs(t, database.DeleteAccount(ctx, user.ID, house.ID), db.ErrAccountNotFound)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityAccount, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 3, "two created, one deleted")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		user := createUser(t, database)
+		_, err := database.CreateAccount(ctx, user.ID, models.Account{Name: "Boat", Type: "boat"})
+		assert.ErrorIs(t, err, db.ErrInvalidAccountType)
+		_, err = database.CreateAccount(ctx, uuid.NewString(), models.Account{Name: "Orphan", Type: models.AccountCash})
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+		_, err = database.GetAccount(ctx, user.ID, "not-a-uuid")
+		assert.ErrorIs(t, err, db.ErrInvalidID)
+
+		account := createAccount(t, user.ID, "Car", models.AccountVehicle)
+		_, err = database.CreateValuation(ctx, user.ID, account.ID, models.Valuation{Value: -1, ValuedAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrInvalidValue)
+		_, err = database.CreateValuation(ctx, user.ID, account.ID, models.Valuation{Value: 100000000, ValuedAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrInvalidValue)
+		_, err = database.CreateValuation(ctx, user.ID, uuid.NewString(), models.Valuation{Value: 1, ValuedAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrAccountNotFound)
+	})
+
+	t.Run("valuations", func(t *testing.T) {
+		user := createUser(t, database)
+		checking := createAccount(t, user.ID, "Checking", models.AccountCash)
+		card := createAccount(t, user.ID, "Credit card", models.AccountCreditCard)
+		later := value(t, user.ID, checking.ID, 1200.005, baseTime.AddDate(0, 1, 0))
+		assert.Equal(t, checking.ID, later.AccountID)
+		assert.Equal(t, user.ID, later.UserID)
+		assert.Equal(t, 1200.01, later.Value, "stored to the cent")
+		assert.True(t, baseTime.AddDate(0, 1, 0).Equal(later.ValuedAt))
+		earlier := value(t, user.ID, checking.ID, 900, baseTime)
+		owed := value(t, user.ID, card.ID, 0, baseTime.AddDate(0, 0, 10))
+		assert.Zero(t, owed.Value, "a paid-off balance")
+
+		valuations, err := database.ListValuations(ctx, user.ID, models.ValuationFilter{})
+		require.NoError(t, err)
+		require.Len(t, valuations, 3)
+		assert.Equal(t, earlier.ID, valuations[0].ID, "earliest first")
+		assert.Equal(t, owed.ID, valuations[1].ID)
+		assert.Equal(t, later.ID, valuations[2].ID)
+
+		valuations, err = database.ListValuations(ctx, user.ID, models.ValuationFilter{AccountID: &checking.ID})
+		require.NoError(t, err)
+		assert.Len(t, valuations, 2)
+		to := baseTime.AddDate(0, 0, 10)
+		valuations, err = database.ListValuations(ctx, user.ID, models.ValuationFilter{To: &to})
+		require.NoError(t, err)
+		assert.Len(t, valuations, 2, "valued up to and including to")
+
+		require.NoError(t, database.DeleteValuation(ctx, user.ID, checking.ID, earlier.ID))
+		assert.ErrorIs(t, database.DeleteValuation(ctx, user.ID, checking.ID, earlier.ID), db.ErrValuationNotFound)
+		assert.ErrorIs(t, database.DeleteValuation(ctx, user.ID, card.ID, later.ID), db.ErrValuationNotFound)
+		valuations, err = database.ListValuations(ctx, user.ID, models.ValuationFilter{AccountID: &checking.ID})
+		require.NoError(t, err)
+		assert.Len(t, valuations, 1)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityValuation, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 4, "three created, one deleted")
+	})
+
+	t.Run("other users' accounts", func(t *testing.T) {
+		user := createUser(t, database)
+		other := createUser(t, database)
+		account := createAccount(t, user.ID, "Savings", models.AccountCash)
+		valuation := value(t, user.ID, account.ID, 5000, baseTime)
+
+		_, err := database.GetAccount(ctx, other.ID, account.ID)
+		assert.ErrorIs(t, err, db.ErrAccountNotFound)
+		accounts, err := database.ListAccounts(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, accounts)
+		assert.ErrorIs(t, database.DeleteAccount(ctx, other.ID, account.ID), db.ErrAccountNotFound)
+		_, err = database.CreateValuation(ctx, other.ID, account.ID, models.Valuation{Value: 1, ValuedAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrAccountNotFound)
+		_, err = database.ListValuations(ctx, other.ID, models.ValuationFilter{AccountID: &account.ID})
+		assert.ErrorIs(t, err, db.ErrAccountNotFound)
+		valuations, err := database.ListValuations(ctx, other.ID, models.ValuationFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, valuations)
+		assert.ErrorIs(t, database.DeleteValuation(ctx, other.ID, account.ID, valuation.ID), db.ErrAccountNotFound)
+	})
+
+	t.Run("erased users' accounts are deleted", func(t *testing.T) {
+		user := createUser(t, database)
+		account := createAccount(t, user.ID, "House", models.AccountProperty)
+		value(t, user.ID, account.ID, 300000, baseTime)
+
+		require.NoError(t, database.DeleteUser(ctx, user.ID))
+		accounts, err := database.ListAccounts(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, accounts)
+		valuations, err := database.ListValuations(ctx, user.ID, models.ValuationFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, valuations)
+	})
+}
+
+func testDebts(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+	mortgage := models.Debt{
+		Name: "Mortgage", Principal: 250000, AnnualRate: 4.5, TermMonths: 300, Payment: 1389.58, FirstPaymentDate: baseTime,
+	}
+
+	createDebt := func(t *testing.T, userID string, debt models.Debt) *models.Debt {
+		t.Helper()
+		created, err := database.CreateDebt(ctx, userID, debt)
+		require.NoError(t, err)
+		return created
+	}
+	pay := func(t *testing.T, userID, debtID string, amount float64, at time.Time) *models.DebtPayment {
+		t.Helper()
+		payment, err := database.CreateDebtPayment(ctx, userID, debtID, models.DebtPayment{Amount: amount, PaidAt: at})
+		require.NoError(t, err)
+		return payment
+	}
+
+	t.Run("create, list, get and delete", func(t *testing.T) {
+		user := createUser(t, database)
+		house := createDebt(t, user.ID, models.Debt{
+			Name: "Mortgage", Principal: 250000.005, AnnualRate: 4.1255, TermMonths: 300, Payment: 1389.575, FirstPaymentDate: baseTime,
+		})
+		assert.NotEmpty(t, house.ID)
+		assert.Equal(t, user.ID, house.UserID)
+		assert.Equal(t, "Mortgage", house.Name)
+		assert.Equal(t, 250000.01, house.Principal, "stored to the cent")
+		assert.Equal(t, 4.126, house.AnnualRate, "stored to three decimals")
+		assert.Equal(t, 300, house.TermMonths)
+		assert.Equal(t, 1389.58, house.Payment)
+		assert.True(t, baseTime.Equal(house.FirstPaymentDate))
+		car := createDebt(t, user.ID, models.Debt{
+			Name: "Car loan", Principal: 12000, TermMonths: 36, Payment: 333.33, FirstPaymentDate: baseTime,
+		})
+		assert.Zero(t, car.AnnualRate)
+
+		debts, err := database.ListDebts(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, debts, 2)
+		assert.Equal(t, house.ID, debts[0].ID, "oldest first")
+		assert.Equal(t, car.ID, debts[1].ID)
+
+		got, err := database.GetDebt(ctx, user.ID, house.ID)
+		require.NoError(t, err)
+		assert.Equal(t, house.Principal, got.Principal)
+		assert.Equal(t, house.AnnualRate, got.AnnualRate)
+		assert.True(t, baseTime.Equal(got.FirstPaymentDate))
+
+		pay(t, user.ID, house.ID, 1389.58, baseTime)
+		require.NoError(t, database.DeleteDebt(ctx, user.ID, house.ID))
+		_, err = database.GetDebt(ctx, user.ID, house.ID)
+		assert.ErrorIs(t, err, db.ErrDebtNotFound)
+		_, err = database.ListDebtPayments(ctx, user.ID, house.ID)
+		assert.ErrorIs(t, err, db.ErrDebtNotFound)
+		assert.ErrorIs(t, database.DeleteDebt(ctx, user.ID, house.ID), db.ErrDebtNotFound)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityDebt, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 3, "two created, one deleted")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		user := createUser(t, database)
+		tests := []struct {
+			name string
+			debt models.Debt
+			err  error
+		}{
+			{name: "no principal", debt: models.Debt{Name: "Loan", Principal: 0.001, TermMonths: 12, Payment: 100}, err: db.ErrInvalidAmount},
+			{name: "no payment", debt: models.Debt{Name: "Loan", Principal: 1000, TermMonths: 12}, err: db.ErrInvalidAmount},
+			{name: "negative rate", debt: models.Debt{Name: "Loan", Principal: 1000, AnnualRate: -1, TermMonths: 12, Payment: 100}, err: db.ErrInvalidRate},
+			{name: "rate of 100%", debt: models.Debt{Name: "Loan", Principal: 1000, AnnualRate: 100, TermMonths: 12, Payment: 100}, err: db.ErrInvalidRate},
+			{name: "no term", debt: models.Debt{Name: "Loan", Principal: 1000, Payment: 100}, err: db.ErrInvalidTerm},
+			{name: "term over 50 years", debt: models.Debt{Name: "Loan", Principal: 1000, TermMonths: 601, Payment: 100}, err: db.ErrInvalidTerm},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.debt.FirstPaymentDate = baseTime
+				_, err := database.CreateDebt(ctx, user.ID, tt.debt)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+
+		_, err := database.CreateDebt(ctx, uuid.NewString(), mortgage)
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+		_, err = database.GetDebt(ctx, user.ID, "not-a-uuid")
+		assert.ErrorIs(t, err, db.ErrInvalidID)
+		debt := createDebt(t, user.ID, mortgage)
+		_, err = database.CreateDebtPayment(ctx, user.ID, debt.ID, models.DebtPayment{Amount: 0.001, PaidAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrInvalidAmount)
+	})
+
+	t.Run("manual and transaction payments", func(t *testing.T) {
+		user := createUser(t, database)
+		debt := createDebt(t, user.ID, mortgage)
+		note := "Bonus overpayment"
+		manual, err := database.CreateDebtPayment(ctx, user.ID, debt.ID, models.DebtPayment{Amount: 5000.255, Note: &note, PaidAt: baseTime.Add(time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, debt.ID, manual.DebtID)
+		assert.Equal(t, user.ID, manual.UserID)
+		assert.Nil(t, manual.TransactionID)
+		assert.Equal(t, 5000.26, manual.Amount)
+		require.NotNil(t, manual.Note)
+		assert.Equal(t, note, *manual.Note)
+
+		installment := createTransaction(t, database, user.ID, nil, 1389.58, "Mortgage payment", baseTime)
+		linked, err := database.CreateDebtPayment(ctx, user.ID, debt.ID, models.DebtPayment{TransactionID: &installment.ID, Amount: 1})
+		require.NoError(t, err)
+		require.NotNil(t, linked.TransactionID)
+		assert.Equal(t, installment.ID, *linked.TransactionID)
+		assert.Equal(t, 1389.58, linked.Amount, "the transaction's amount")
+		assert.True(t, baseTime.Equal(linked.PaidAt), "the transaction's date")
+
+		payments, err := database.ListDebtPayments(ctx, user.ID, debt.ID)
+		require.NoError(t, err)
+		require.Len(t, payments, 2)
+		assert.Equal(t, linked.ID, payments[0].ID, "earliest first")
+		assert.Equal(t, manual.ID, payments[1].ID)
+
+		other := createDebt(t, user.ID, models.Debt{Name: "Car loan", Principal: 12000, TermMonths: 36, Payment: 333.33, FirstPaymentDate: baseTime})
+		_, err = database.CreateDebtPayment(ctx, user.ID, other.ID, models.DebtPayment{TransactionID: &installment.ID})
+		assert.ErrorIs(t, err, db.ErrTransactionPaysDebt, "a transaction pays one debt")
+
+		require.NoError(t, database.DeleteDebtPayment(ctx, user.ID, debt.ID, manual.ID))
+		assert.ErrorIs(t, database.DeleteDebtPayment(ctx, user.ID, debt.ID, manual.ID), db.ErrDebtPaymentNotFound)
+		assert.ErrorIs(t, database.DeleteDebtPayment(ctx, user.ID, other.ID, linked.ID), db.ErrDebtPaymentNotFound)
+		payments, err = database.ListDebtPayments(ctx, user.ID, debt.ID)
+		require.NoError(t, err)
+		assert.Len(t, payments, 1)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityDebtPayment, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 3, "two created, one deleted")
+	})
+
+	t.Run("other users' debts and transactions", func(t *testing.T) {
+		user := createUser(t, database)
+		other := createUser(t, database)
+		debt := createDebt(t, user.ID, mortgage)
+		payment := pay(t, user.ID, debt.ID, 1389.58, baseTime)
+		transaction := createTransaction(t, database, other.ID, nil, 50, "", baseTime)
+
+		_, err := database.GetDebt(ctx, other.ID, debt.ID)
+		assert.ErrorIs(t, err, db.ErrDebtNotFound)
+		debts, err := database.ListDebts(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, debts)
+		assert.ErrorIs(t, database.DeleteDebt(ctx, other.ID, debt.ID), db.ErrDebtNotFound)
+		_, err = database.ListDebtPayments(ctx, other.ID, debt.ID)
+		assert.ErrorIs(t, err, db.ErrDebtNotFound)
+		_, err = database.CreateDebtPayment(ctx, other.ID, debt.ID, models.DebtPayment{Amount: 10, PaidAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrDebtNotFound)
+		assert.ErrorIs(t, database.DeleteDebtPayment(ctx, other.ID, debt.ID, payment.ID), db.ErrDebtNotFound)
+		_, err = database.CreateDebtPayment(ctx, user.ID, debt.ID, models.DebtPayment{TransactionID: &transaction.ID})
+		assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+	})
+
+	t.Run("payments of trashed transactions are left out until restored", func(t *testing.T) {
+		user := createUser(t, database)
+		debt := createDebt(t, user.ID, mortgage)
+		transaction := createTransaction(t, database, user.ID, nil, 1389.58, "", baseTime)
+		payment, err := database.CreateDebtPayment(ctx, user.ID, debt.ID, models.DebtPayment{TransactionID: &transaction.ID})
+		require.NoError(t, err)
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+
+		payments, err := database.ListDebtPayments(ctx, user.ID, debt.ID)
+		require.NoError(t, err)
+		assert.Empty(t, payments)
+		assert.ErrorIs(t, database.DeleteDebtPayment(ctx, user.ID, debt.ID, payment.ID), db.ErrDebtPaymentNotFound)
+
+		_, err = database.RestoreTransaction(ctx, user.ID, transaction.ID)
+		require.NoError(t, err)
+		payments, err = database.ListDebtPayments(ctx, user.ID, debt.ID)
+		require.NoError(t, err)
+		assert.Len(t, payments, 1)
+
+		require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+		_, err = database.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		payments, err = database.ListDebtPayments(ctx, user.ID, debt.ID)
+		require.NoError(t, err)
+		assert.Empty(t, payments, "purged with the transaction")
+	})
+
+	t.Run("erased users' debts are deleted", func(t *testing.T) {
+		user := createUser(t, database)
+		debt := createDebt(t, user.ID, mortgage)
+		pay(t, user.ID, debt.ID, 1389.58, baseTime)
+
+		require.NoError(t, database.DeleteUser(ctx, user.ID))
+		debts, err := database.ListDebts(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, debts)
+		_, err = database.GetDebt(ctx, user.ID, debt.ID)
+		assert.ErrorIs(t, err, db.ErrDebtNotFound)
+	})
+}
+
+func testSecurities(t *testing.T, database db.Database) {
+	ctx := testContext(t)
+
+	createSecurity := func(t *testing.T, userID, symbol string) *models.Security {
+		t.Helper()
+		security, err := database.CreateSecurity(ctx, userID, models.Security{Symbol: symbol, Name: symbol + " Inc."})
+		require.NoError(t, err)
+		return security
+	}
+	trade := func(t *testing.T, userID, securityID, side string, quantity, price float64, at time.Time) *models.Lot {
+		t.Helper()
+		lot, err := database.CreateLot(ctx, userID, securityID, models.Lot{
+			Side: side, Quantity: quantity, Price: price, Fees: 1.5, TradedAt: at,
+		})
+		require.NoError(t, err)
+		return lot
+	}
+
+	t.Run("create, list, get and delete", func(t *testing.T) {
+		user := createUser(t, database)
+		acme := createSecurity(t, user.ID, "ACME")
+		assert.NotEmpty(t, acme.ID)
+		assert.Equal(t, user.ID, acme.UserID)
+		assert.Equal(t, "ACME", acme.Symbol)
+		assert.Equal(t, "ACME Inc.", acme.Name)
+		globex := createSecurity(t, user.ID, "GLBX")
+
+		securities, err := database.ListSecurities(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, securities, 2)
+		assert.Equal(t, acme.ID, securities[0].ID, "oldest first")
+		assert.Equal(t, globex.ID, securities[1].ID)
+
+		got, err := database.GetSecurity(ctx, user.ID, acme.ID)
+		require.NoError(t, err)
+		assert.Equal(t, acme.Symbol, got.Symbol)
+
+		trade(t, user.ID, acme.ID, models.LotBuy, 10, 50, baseTime)
+		require.NoError(t, database.SavePrices(ctx, user.ID, []models.Price{{SecurityID: acme.ID, Price: 55, PricedAt: baseTime}}))
+		require.NoError(t, database.DeleteSecurity(ctx, user.ID, acme.ID))
+		_, err = database.GetSecurity(ctx, user.ID, acme.ID)
+		assert.ErrorIs(t, err, db.ErrSecurityNotFound)
+		lots, err := database.ListLots(ctx, user.ID, models.LotFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, lots, "deleted with the security")
+		prices, err := database.ListPrices(ctx, user.ID, models.PriceFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, prices, "deleted with the security")
+		assert.ErrorIs(t, database.DeleteSecurity(ctx, user.ID, acme.ID), db.ErrSecurityNotFound)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntitySecurity, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 3, "two created, one deleted")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		user := createUser(t, database)
+		security := createSecurity(t, user.ID, "ACME")
+		_, err := database.CreateSecurity(ctx, user.ID, models.Security{Symbol: "ACME", Name: "Acme again"})
+		assert.ErrorIs(t, err, db.ErrSymbolTaken)
+		createSecurity(t, createUser(t, database).ID, "ACME")
+		_, err = database.CreateSecurity(ctx, uuid.NewString(), models.Security{Symbol: "ORPH", Name: "Orphan"})
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+		_, err = database.GetSecurity(ctx, user.ID, "not-a-uuid")
+		assert.ErrorIs(t, err, db.ErrInvalidID)
+
+		for _, tc := range []struct {
+			lot  models.Lot
+			want error
+		}{
+			{models.Lot{Side: "short", Quantity: 1, Price: 1}, db.ErrInvalidSide},
+			{models.Lot{Side: models.LotBuy, Quantity: 0, Price: 1}, db.ErrInvalidQuantity},
+			{models.Lot{Side: models.LotBuy, Quantity: 1e12, Price: 1}, db.ErrInvalidQuantity},
+			{models.Lot{Side: models.LotBuy, Quantity: 1, Price: -1}, db.ErrInvalidPrice},
+			{models.Lot{Side: models.LotBuy, Quantity: 1, Price: 1, Fees: -1}, db.ErrInvalidFees},
+		} {
+			tc.lot.TradedAt = baseTime
+			_, err := database.CreateLot(ctx, user.ID, security.ID, tc.lot)
+			assert.ErrorIs(t, err, tc.want, "%+v", tc.lot)
+		}
+		_, err = database.CreateLot(ctx, user.ID, uuid.NewString(), models.Lot{Side: models.LotBuy, Quantity: 1, Price: 1, TradedAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrSecurityNotFound)
+		err = database.SavePrices(ctx, user.ID, []models.Price{{SecurityID: security.ID, Price: -1, PricedAt: baseTime}})
+		assert.ErrorIs(t, err, db.ErrInvalidPrice)
+	})
+
+	t.Run("lots", func(t *testing.T) {
+		user := createUser(t, database)
+		acme := createSecurity(t, user.ID, "ACME")
+		globex := createSecurity(t, user.ID, "GLBX")
+		bought := trade(t, user.ID, acme.ID, models.LotBuy, 10.1234567, 50.12345, baseTime)
+		assert.Equal(t, acme.ID, bought.SecurityID)
+		assert.Equal(t, user.ID, bought.UserID)
+		assert.Equal(t, models.LotBuy, bought.Side)
+		assert.Equal(t, 10.123457, bought.Quantity, "stored to the millionth")
+		assert.Equal(t, 50.1235, bought.Price, "stored to four decimals")
+		assert.Equal(t, 1.5, bought.Fees)
+		assert.True(t, baseTime.Equal(bought.TradedAt))
+		// Traded at the same time as a later buy, the sell comes after it.
+		sold := trade(t, user.ID, acme.ID, models.LotSell, 10, 60, baseTime.AddDate(0, 1, 0))
+		topUp := trade(t, user.ID, acme.ID, models.LotBuy, 5, 55, baseTime.AddDate(0, 1, 0))
+		other := trade(t, user.ID, globex.ID, models.LotBuy, 1, 10, baseTime.AddDate(0, 0, 10))
+
+		lots, err := database.ListLots(ctx, user.ID, models.LotFilter{})
+		require.NoError(t, err)
+		require.Len(t, lots, 4)
+		assert.Equal(t, bought.ID, lots[0].ID, "earliest first")
+		assert.Equal(t, other.ID, lots[1].ID)
+		assert.Equal(t, topUp.ID, lots[2].ID, "buys before sells at the same time")
+		assert.Equal(t, sold.ID, lots[3].ID)
+
+		lots, err = database.ListLots(ctx, user.ID, models.LotFilter{SecurityID: &acme.ID})
+		require.NoError(t, err)
+		assert.Len(t, lots, 3)
+		to := baseTime.AddDate(0, 0, 10)
+		lots, err = database.ListLots(ctx, user.ID, models.LotFilter{To: &to})
+		require.NoError(t, err)
+		assert.Len(t, lots, 2, "traded up to and including to")
+
+		_, err = database.CreateLot(ctx, user.ID, acme.ID, models.Lot{
+			Side: models.LotSell, Quantity: 5.2, Price: 60, TradedAt: baseTime.AddDate(0, 2, 0),
+		})
+		assert.ErrorIs(t, err, db.ErrInsufficientQuantity, "only 5.123457 left")
+		_, err = database.CreateLot(ctx, user.ID, acme.ID, models.Lot{
+			Side: models.LotSell, Quantity: 1, Price: 60, TradedAt: baseTime.AddDate(0, 0, -1),
+		})
+		assert.ErrorIs(t, err, db.ErrInsufficientQuantity, "nothing held yet")
+		assert.ErrorIs(t, database.DeleteLot(ctx, user.ID, acme.ID, bought.ID), db.ErrInsufficientQuantity)
+		trade(t, user.ID, acme.ID, models.LotSell, 5.123457, 60, baseTime.AddDate(0, 2, 0))
+
+		require.NoError(t, database.DeleteLot(ctx, user.ID, acme.ID, sold.ID), "deleting a sell only adds to the position")
+		assert.ErrorIs(t, database.DeleteLot(ctx, user.ID, acme.ID, sold.ID), db.ErrLotNotFound)
+		assert.ErrorIs(t, database.DeleteLot(ctx, user.ID, globex.ID, topUp.ID), db.ErrLotNotFound)
+		lots, err = database.ListLots(ctx, user.ID, models.LotFilter{SecurityID: &acme.ID})
+		require.NoError(t, err)
+		assert.Len(t, lots, 3)
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityLot, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, entries, 6, "five created, one deleted")
+	})
+
+	t.Run("prices", func(t *testing.T) {
+		user := createUser(t, database)
+		acme := createSecurity(t, user.ID, "ACME")
+		globex := createSecurity(t, user.ID, "GLBX")
+		require.NoError(t, database.SavePrices(ctx, user.ID, []models.Price{
+			{SecurityID: globex.ID, Price: 10, PricedAt: baseTime},
+			{SecurityID: acme.ID, Price: 50.12345, PricedAt: baseTime},
+			{SecurityID: acme.ID, Price: 51, PricedAt: baseTime.AddDate(0, 0, 1)},
+		}))
+		require.NoError(t, database.SavePrices(ctx, user.ID, []models.Price{
+			{SecurityID: acme.ID, Price: 52, PricedAt: baseTime.AddDate(0, 0, 1)},
+		}), "replaces the day's price")
+
+		prices, err := database.ListPrices(ctx, user.ID, models.PriceFilter{})
+		require.NoError(t, err)
+		require.Len(t, prices, 3)
+		assert.True(t, baseTime.Equal(prices[0].PricedAt), "earliest first")
+		assert.Equal(t, 50.1235, prices[slices.IndexFunc(prices, func(p models.Price) bool { return p.SecurityID == acme.ID })].Price)
+		assert.Equal(t, acme.ID, prices[2].SecurityID)
+		assert.Equal(t, 52.0, prices[2].Price)
+
+		prices, err = database.ListPrices(ctx, user.ID, models.PriceFilter{SecurityID: &globex.ID})
+		require.NoError(t, err)
+		assert.Len(t, prices, 1)
+		from, to := baseTime.AddDate(0, 0, 1), baseTime.AddDate(0, 0, 1)
+		prices, err = database.ListPrices(ctx, user.ID, models.PriceFilter{From: &from})
+		require.NoError(t, err)
+		assert.Len(t, prices, 1)
+		prices, err = database.ListPrices(ctx, user.ID, models.PriceFilter{To: &to})
+		require.NoError(t, err)
+		assert.Len(t, prices, 3, "priced up to and including to")
+
+		entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Entity: models.EntityPriceImport, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 2, "one per import")
+		assert.Equal(t, models.AuditCreate, entries[1].Action)
+		assert.NotEqual(t, entries[0].EntityID, entries[1].EntityID)
+		assert.Nil(t, entries[1].Before)
+		var imported struct {
+			SecurityIDs []string  `json:"security_ids"`
+			Count       int       `json:"count"`
+			From        time.Time `json:"from"`
+			To          time.Time `json:"to"`
+		}
+		require.NoError(t, json.Unmarshal(entries[1].After, &imported))
+		assert.ElementsMatch(t, []string{acme.ID, globex.ID}, imported.SecurityIDs)
+		assert.Equal(t, 3, imported.Count)
+		assert.True(t, baseTime.Equal(imported.From), "earliest priced")
+		assert.True(t, baseTime.AddDate(0, 0, 1).Equal(imported.To), "latest priced")
+	})
+
+	t.Run("other users' securities", func(t *testing.T) {
+		user := createUser(t, database)
+		other := createUser(t, database)
+		security := createSecurity(t, user.ID, "ACME")
+		lot := trade(t, user.ID, security.ID, models.LotBuy, 1, 10, baseTime)
+		require.NoError(t, database.SavePrices(ctx, user.ID, []models.Price{{SecurityID: security.ID, Price: 10, PricedAt: baseTime}}))
+
+		_, err := database.GetSecurity(ctx, other.ID, security.ID)
+		assert.ErrorIs(t, err, db.ErrSecurityNotFound)
+		securities, err := database.ListSecurities(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, securities)
+		assert.ErrorIs(t, database.DeleteSecurity(ctx, other.ID, security.ID), db.ErrSecurityNotFound)
+		_, err = database.CreateLot(ctx, other.ID, security.ID, models.Lot{Side: models.LotBuy, Quantity: 1, Price: 1, TradedAt: baseTime})
+		assert.ErrorIs(t, err, db.ErrSecurityNotFound)
+		_, err = database.ListLots(ctx, other.ID, models.LotFilter{SecurityID: &security.ID})
+		assert.ErrorIs(t, err, db.ErrSecurityNotFound)
+		lots, err := database.ListLots(ctx, other.ID, models.LotFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, lots)
+		assert.ErrorIs(t, database.DeleteLot(ctx, other.ID, security.ID, lot.ID), db.ErrSecurityNotFound)
+		_, err = database.ListPrices(ctx, other.ID, models.PriceFilter{SecurityID: &security.ID})
+		assert.ErrorIs(t, err, db.ErrSecurityNotFound)
+		prices, err := database.ListPrices(ctx, other.ID, models.PriceFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, prices)
+
+		own := createSecurity(t, other.ID, "GLBX")
+		err = database.SavePrices(ctx, other.ID, []models.Price{
+			{SecurityID: own.ID, Price: 20, PricedAt: baseTime},
+			{SecurityID: security.ID, Price: 20, PricedAt: baseTime},
+		})
+		assert.ErrorIs(t, err, db.ErrSecurityNotFound)
+		prices, err = database.ListPrices(ctx, other.ID, models.PriceFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, prices, "none saved when one can't be")
+		entries, err := database.ListAuditEntries(ctx, other.ID, models.AuditFilter{Entity: models.EntityPriceImport, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("erased users' securities are deleted", func(t *testing.T) {
+		user := createUser(t, database)
+		security := createSecurity(t, user.ID, "ACME")
+		trade(t, user.ID, security.ID, models.LotBuy, 1, 10, baseTime)
+		require.NoError(t, database.SavePrices(ctx, user.ID, []models.Price{{SecurityID: security.ID, Price: 10, PricedAt: baseTime}}))
+
+		require.NoError(t, database.DeleteUser(ctx, user.ID))
+		securities, err := database.ListSecurities(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, securities)
+		lots, err := database.ListLots(ctx, user.ID, models.LotFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, lots)
+		prices, err := database.ListPrices(ctx, user.ID, models.PriceFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, prices)
+	})
+}
+
+// jsonField returns the raw JSON of a field of the object in data.
+func jsonField(t *testing.T, data json.RawMessage, name string) string {
+	t.Helper()
+	var object map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &object))
+	field, ok := object[name]
+	if !ok {
+		return "null"
+	}
+	return string(field)
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"fintrack-go/internal/models"
+)
+
+const debtColumns = `
+	d.id, d.user_id, d.name, d.principal, d.annual_rate, d.term_months, d.payment, d.first_payment_date, d.created_at
+`
+
+func scanDebt(d *models.Debt) []any {
+	return []any{
+		&d.ID,
+		&d.UserID,
+		&d.Name,
+		&d.Principal,
+		&d.AnnualRate,
+		&d.TermMonths,
+		&d.Payment,
+		&d.FirstPaymentDate,
+		&d.CreatedAt,
+	}
+}
+
+const debtPaymentColumns = `
+	dp.id, dp.debt_id, dp.user_id, dp.transaction_id, dp.amount, dp.note, dp.paid_at, dp.created_at
+`
+
+func scanDebtPayment(p *models.DebtPayment) []any {
+	return []any{
+		&p.ID,
+		&p.DebtID,
+		&p.UserID,
+		&p.TransactionID,
+		&p.Amount,
+		&p.Note,
+		&p.PaidAt,
+		&p.CreatedAt,
+	}
+}
+
+func (db *DB) CreateDebt(ctx context.Context, userID string, debt models.Debt) (*models.Debt, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the debt.
+		var created *models.Debt
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateDebt(ctx, userID, debt)
+			return err
+		})
+		return created, err
+	}
+
+	query := `
+		INSERT INTO debts AS d (user_id, name, principal, annual_rate, term_months, payment, first_payment_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + debtColumns
+
+	var created models.Debt
+	err := db.conn().QueryRow(ctx, query,
+		userID, debt.Name, debt.Principal, debt.AnnualRate, debt.TermMonths, debt.Payment, debt.FirstPaymentDate,
+	).Scan(scanDebt(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntityDebt, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListDebts(ctx context.Context, userID string) ([]models.Debt, error) {
+	query := `
+		SELECT ` + debtColumns + `
+		FROM debts d
+		WHERE d.user_id = $1
+		ORDER BY d.created_at, d.id
+	`
+	rows, err := db.conn().Query(ctx, query, userID)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var debts []models.Debt
+	for rows.Next() {
+		var debt models.Debt
+		if err := rows.Scan(scanDebt(&debt)...); err != nil {
+			return nil, err
+		}
+		debts = append(debts, debt)
+	}
+
+	return debts, rows.Err()
+}
+
+func (db *DB) GetDebt(ctx context.Context, userID, id string) (*models.Debt, error) {
+	query := `SELECT ` + debtColumns + ` FROM debts d WHERE d.id = $1 AND d.user_id = $2`
+
+	var debt models.Debt
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanDebt(&debt)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDebtNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	return &debt, nil
+}
+
+func (db *DB) DeleteDebt(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteDebt(ctx, userID, id)
+		})
+	}
+
+	query := `DELETE FROM debts d WHERE d.id = $1 AND d.user_id = $2 RETURNING ` + debtColumns
+
+	var debt models.Debt
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanDebt(&debt)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDebtNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityDebt, debt.ID, models.AuditDelete, debt, nil)
+}
+
+func (db *DB) CreateDebtPayment(ctx context.Context, userID, debtID string, payment models.DebtPayment) (*models.DebtPayment, error) {
+	if db.tx == nil {
+		// The transaction must not move to the trash before the payment is
+		// recorded, and the audit entry must be recorded with it.
+		var created *models.DebtPayment
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateDebtPayment(ctx, userID, debtID, payment)
+			return err
+		})
+		return created, err
+	}
+
+	if _, err := db.GetDebt(ctx, userID, debtID); err != nil {
+		return nil, err
+	}
+	if payment.TransactionID != nil {
+		transaction, err := db.lockUserTransaction(ctx, userID, *payment.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		payment.Amount = transaction.Amount
+		payment.PaidAt = transaction.OccurredAt
+	}
+
+	query := `
+		INSERT INTO debt_payments AS dp (debt_id, user_id, transaction_id, amount, note, paid_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + debtPaymentColumns
+
+	var created models.DebtPayment
+	err := db.conn().QueryRow(ctx, query,
+		debtID, userID, payment.TransactionID, payment.Amount, payment.Note, payment.PaidAt,
+	).Scan(scanDebtPayment(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntityDebtPayment, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListDebtPayments(ctx context.Context, userID, debtID string) ([]models.DebtPayment, error) {
+	if _, err := db.GetDebt(ctx, userID, debtID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + debtPaymentColumns + `
+		FROM debt_payments dp
+		LEFT JOIN transactions t ON t.id = dp.transaction_id
+		WHERE dp.debt_id = $1 AND t.deleted_at IS NULL
+		ORDER BY dp.paid_at, dp.created_at, dp.id
+	`
+	rows, err := db.conn().Query(ctx, query, debtID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []models.DebtPayment
+	for rows.Next() {
+		var payment models.DebtPayment
+		if err := rows.Scan(scanDebtPayment(&payment)...); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func (db *DB) DeleteDebtPayment(ctx context.Context, userID, debtID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteDebtPayment(ctx, userID, debtID, id)
+		})
+	}
+
+	if _, err := db.GetDebt(ctx, userID, debtID); err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM debt_payments dp
+		WHERE dp.id = $1 AND dp.debt_id = $2
+			AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.id = dp.transaction_id AND t.deleted_at IS NOT NULL)
+		RETURNING ` + debtPaymentColumns
+
+	var payment models.DebtPayment
+	err := db.conn().QueryRow(ctx, query, id, debtID).Scan(scanDebtPayment(&payment)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDebtPaymentNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityDebtPayment, payment.ID, models.AuditDelete, payment, nil)
+}
@@ -0,0 +1,328 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"fintrack-go/internal/models"
+)
+
+const (
+	// DefaultDuplicateWindowDays is how far apart two transactions may occur
+	// and still be considered duplicates when the caller doesn't say.
+	DefaultDuplicateWindowDays = 3
+
+	// duplicateSimilarityThreshold is the minimum description similarity for
+	// a candidate pair to be reported.
+	duplicateSimilarityThreshold = 0.5
+)
+
+const transactionPairColumns = `
+	a.id, a.user_id, a.category_id, a.amount, a.description, a.occurred_at, a.created_at, ca.name,
+	b.id, b.user_id, b.category_id, b.amount, b.description, b.occurred_at, b.created_at, cb.name
+`
+
+// FindDuplicateTransactions returns pairs of the user's transactions with the
+// same amount, occurring within windowDays of each other and with similar
+// descriptions. Pairs previously dismissed are left out.
+func (db *DB) FindDuplicateTransactions(ctx context.Context, userID string, windowDays int) ([]models.DuplicatePair, error) {
+	query := `
+		SELECT ` + transactionPairColumns + `
+		FROM transactions a
+		JOIN transactions b
+			ON b.user_id = a.user_id
+			AND b.amount = a.amount
+			AND a.id < b.id
+			AND b.deleted_at IS NULL
+			AND b.occurred_at BETWEEN a.occurred_at - make_interval(days => $2) AND a.occurred_at + make_interval(days => $2)
+		LEFT JOIN categories ca ON a.category_id = ca.id AND ca.deleted_at IS NULL
+		LEFT JOIN categories cb ON b.category_id = cb.id AND cb.deleted_at IS NULL
+		WHERE a.user_id = $1
+			AND a.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM dismissed_duplicates d
+				WHERE d.transaction_id = a.id AND d.duplicate_id = b.id
+			)
+	`
+
+	rows, err := db.conn().Query(ctx, query, userID, windowDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []models.DuplicatePair
+	for rows.Next() {
+		var a, b models.Transaction
+		if err := rows.Scan(
+			&a.ID, &a.UserID, &a.CategoryID, &a.Amount, &a.Description, &a.OccurredAt, &a.CreatedAt, &a.CategoryName,
+			&b.ID, &b.UserID, &b.CategoryID, &b.Amount, &b.Description, &b.OccurredAt, &b.CreatedAt, &b.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		if pair, ok := newDuplicatePair(a, b); ok {
+			pairs = append(pairs, pair)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortDuplicatePairs(pairs)
+	return pairs, nil
+}
+
+// MergeDuplicateTransactions keeps keepID and moves discardID to the trash. A
+// category or description missing from the kept transaction is taken from the
+// discarded one.
+func (db *DB) MergeDuplicateTransactions(ctx context.Context, userID, keepID, discardID string) (*models.Transaction, error) {
+	if keepID == discardID {
+		return nil, ErrSameTransaction
+	}
+	if db.tx == nil {
+		// The update, the delete, their events and their audit entries
+		// succeed or fail together.
+		var transaction *models.Transaction
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			transaction, err = tx.MergeDuplicateTransactions(ctx, userID, keepID, discardID)
+			return err
+		})
+		return transaction, err
+	}
+
+	keep, err := db.lockUserTransaction(ctx, userID, keepID)
+	if err != nil {
+		return nil, err
+	}
+	discard, err := db.lockUserTransaction(ctx, userID, discardID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE transactions k
+		SET category_id = COALESCE(k.category_id, d.category_id),
+			description = COALESCE(NULLIF(k.description, ''), d.description)
+		FROM transactions d
+		WHERE k.id = $1 AND d.id = $2 AND k.user_id = $3 AND d.user_id = $3
+	`
+	tag, err := db.conn().Exec(ctx, query, keep.ID, discard.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrTransactionNotFound
+	}
+
+	query = `
+		SELECT
+			t.id, t.user_id, t.category_id, t.amount, t.description, t.occurred_at, t.created_at,
+			c.name as category_name
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+		WHERE t.id = $1
+	`
+	var transaction models.Transaction
+	err = db.conn().QueryRow(ctx, query, keepID).Scan(
+		&transaction.ID,
+		&transaction.UserID,
+		&transaction.CategoryID,
+		&transaction.Amount,
+		&transaction.Description,
+		&transaction.OccurredAt,
+		&transaction.CreatedAt,
+		&transaction.CategoryName,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.publishEvent(ctx, userID, models.EventTransactionUpdated, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.audit(ctx, userID, models.EntityTransaction, keep.ID, models.AuditUpdate, keep, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.trashTransaction(ctx, discard); err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// lockUserTransaction returns one of the user's transactions, unless it is in
+// the trash, locked until the end of the transaction db runs in, as it is
+// before a change.
+func (db *DB) lockUserTransaction(ctx context.Context, userID, id string) (*models.Transaction, error) {
+	query := `
+		SELECT
+			t.id, t.user_id, t.category_id, t.amount, t.description, t.occurred_at, t.created_at,
+			c.name as category_name
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+		WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
+		FOR UPDATE OF t
+	`
+	var transaction models.Transaction
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(
+		&transaction.ID,
+		&transaction.UserID,
+		&transaction.CategoryID,
+		&transaction.Amount,
+		&transaction.Description,
+		&transaction.OccurredAt,
+		&transaction.CreatedAt,
+		&transaction.CategoryName,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	return &transaction, nil
+}
+
+// DismissDuplicate records that two of the user's transactions are not
+// duplicates so FindDuplicateTransactions stops reporting them.
+func (db *DB) DismissDuplicate(ctx context.Context, userID, transactionID, duplicateID string) error {
+	if transactionID == duplicateID {
+		return ErrSameTransaction
+	}
+	if db.tx == nil {
+		// The audit entry must be recorded with the dismissal.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DismissDuplicate(ctx, userID, transactionID, duplicateID)
+		})
+	}
+
+	var owned int
+	err := db.conn().QueryRow(ctx, `SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND id IN ($2, $3) AND deleted_at IS NULL`, userID, transactionID, duplicateID).Scan(&owned)
+	if err != nil {
+		return err
+	}
+	if owned != 2 {
+		return ErrTransactionNotFound
+	}
+
+	query := `
+		INSERT INTO dismissed_duplicates (transaction_id, duplicate_id, user_id)
+		VALUES (LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid), $3)
+		ON CONFLICT DO NOTHING
+		RETURNING transaction_id, duplicate_id
+	`
+	var dismissed dismissedDuplicate
+	err = db.conn().QueryRow(ctx, query, transactionID, duplicateID, userID).Scan(&dismissed.TransactionID, &dismissed.DuplicateID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Already dismissed: nothing changed.
+		return nil
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityDismissedDuplicate, dismissed.id(), models.AuditCreate, nil, dismissed)
+}
+
+// pairDuplicates does in Go what the FindDuplicateTransactions query does in
+// SQL, for backends that can't: it pairs transactions with the same amount
+// occurring within windowDays of each other, skipping pairs isDismissed
+// reports, and keeps those with similar descriptions.
+func pairDuplicates(transactions []models.Transaction, windowDays int, isDismissed func(a, b string) bool) []models.DuplicatePair {
+	window := time.Duration(windowDays) * 24 * time.Hour
+
+	var pairs []models.DuplicatePair
+	for i, a := range transactions {
+		for _, b := range transactions[i+1:] {
+			if a.Amount != b.Amount {
+				continue
+			}
+			if gap := a.OccurredAt.Sub(b.OccurredAt); gap > window || gap < -window {
+				continue
+			}
+			if isDismissed(a.ID, b.ID) {
+				continue
+			}
+			if pair, ok := newDuplicatePair(a, b); ok {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+
+	sortDuplicatePairs(pairs)
+	return pairs
+}
+
+// pairKey orders two transaction ids the way dismissed_duplicates stores
+// them, lower first.
+func pairKey(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// newDuplicatePair orders a candidate pair by creation time and scores its
+// descriptions, reporting false when they are too different.
+func newDuplicatePair(a, b models.Transaction) (models.DuplicatePair, bool) {
+	similarity := descriptionSimilarity(a.Description, b.Description)
+	if similarity < duplicateSimilarityThreshold {
+		return models.DuplicatePair{}, false
+	}
+	if b.CreatedAt.Before(a.CreatedAt) {
+		a, b = b, a
+	}
+	return models.DuplicatePair{Transaction: a, Duplicate: b, Similarity: similarity}, true
+}
+
+func sortDuplicatePairs(pairs []models.DuplicatePair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Transaction.OccurredAt.After(pairs[j].Transaction.OccurredAt)
+	})
+}
+
+// descriptionSimilarity scores two descriptions between 0 and 1 as the share
+// of words in the shorter one that also appear in the longer one, ignoring
+// case and punctuation. A missing description on either side says nothing
+// against a match, so it scores as similar.
+func descriptionSimilarity(a, b *string) float64 {
+	wordsA := descriptionWords(a)
+	wordsB := descriptionWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 1
+	}
+
+	if len(wordsA) > len(wordsB) {
+		wordsA, wordsB = wordsB, wordsA
+	}
+	shared := 0
+	for word := range wordsA {
+		if _, ok := wordsB[word]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA))
+}
+
+func descriptionWords(desc *string) map[string]struct{} {
+	if desc == nil {
+		return nil
+	}
+	fields := strings.FieldsFunc(strings.ToLower(*desc), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	words := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		words[field] = struct{}{}
+	}
+	return words
+}
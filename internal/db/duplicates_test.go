@@ -0,0 +1,202 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/models"
+	"fintrack-go/tests/dbtestutil"
+)
+
+func TestDescriptionSimilarity(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		a, b *string
+		want float64
+	}{
+		{name: "both missing", a: nil, b: nil, want: 1},
+		{name: "one missing", a: str("Lunch"), b: nil, want: 1},
+		{name: "identical ignoring case and punctuation", a: str("Lunch, Cafe!"), b: str("lunch cafe"), want: 1},
+		{name: "shorter contained in longer", a: str("Amazon"), b: str("AMAZON MKTPLACE PMTS"), want: 1},
+		{name: "partial overlap", a: str("coffee shop"), b: str("coffee beans"), want: 0.5},
+		{name: "unrelated", a: str("Coffee"), b: str("Train ticket"), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, descriptionSimilarity(tt.a, tt.b), 0.0001)
+		})
+	}
+}
+
+func TestNewDuplicatePair(t *testing.T) {
+	coffee := "Coffee"
+	train := "Train ticket"
+	earlier := models.Transaction{ID: "b", Description: &coffee, CreatedAt: time.Now().Add(-time.Hour)}
+	later := models.Transaction{ID: "a", Description: &coffee, CreatedAt: time.Now()}
+
+	pair, ok := newDuplicatePair(later, earlier)
+	require.True(t, ok)
+	assert.Equal(t, "b", pair.Transaction.ID)
+	assert.Equal(t, "a", pair.Duplicate.ID)
+
+	different := models.Transaction{ID: "c", Description: &train, CreatedAt: time.Now()}
+	_, ok = newDuplicatePair(earlier, different)
+	assert.False(t, ok)
+}
+
+func TestFindDuplicateTransactions(t *testing.T) {
+	t.Run("finds same amount within window", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		user, err := db.CreateUser(ctx, "dupes@example.com")
+		require.NoError(t, err)
+
+		now := time.Now()
+		imported := "AMAZON MKTPLACE PMTS"
+		manual := "amazon"
+		other := "Groceries"
+
+		original, err := db.CreateTransaction(ctx, user.ID, nil, 42.0, &imported, now)
+		require.NoError(t, err)
+		duplicate, err := db.CreateTransaction(ctx, user.ID, nil, 42.0, &manual, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+
+		// Same amount but outside the window
+		_, err = db.CreateTransaction(ctx, user.ID, nil, 42.0, &imported, now.Add(-10*24*time.Hour))
+		require.NoError(t, err)
+		// Same day, different amount
+		_, err = db.CreateTransaction(ctx, user.ID, nil, 43.0, &imported, now)
+		require.NoError(t, err)
+		// Same amount and day, unrelated description
+		_, err = db.CreateTransaction(ctx, user.ID, nil, 42.0, &other, now)
+		require.NoError(t, err)
+
+		pairs, err := db.FindDuplicateTransactions(ctx, user.ID, DefaultDuplicateWindowDays)
+		require.NoError(t, err)
+		require.Len(t, pairs, 1)
+		assert.Equal(t, original.ID, pairs[0].Transaction.ID)
+		assert.Equal(t, duplicate.ID, pairs[0].Duplicate.ID)
+	})
+
+	t.Run("dismissed pairs are not reported", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		user, err := db.CreateUser(ctx, "dismiss@example.com")
+		require.NoError(t, err)
+
+		first, err := db.CreateTransaction(ctx, user.ID, nil, 9.99, nil, time.Now())
+		require.NoError(t, err)
+		second, err := db.CreateTransaction(ctx, user.ID, nil, 9.99, nil, time.Now())
+		require.NoError(t, err)
+
+		pairs, err := db.FindDuplicateTransactions(ctx, user.ID, DefaultDuplicateWindowDays)
+		require.NoError(t, err)
+		require.Len(t, pairs, 1)
+
+		require.NoError(t, db.DismissDuplicate(ctx, user.ID, second.ID, first.ID))
+		// Dismissing again is a no-op
+		require.NoError(t, db.DismissDuplicate(ctx, user.ID, first.ID, second.ID))
+
+		pairs, err = db.FindDuplicateTransactions(ctx, user.ID, DefaultDuplicateWindowDays)
+		require.NoError(t, err)
+		assert.Empty(t, pairs)
+	})
+
+	t.Run("dismiss requires ownership", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		owner, err := db.CreateUser(ctx, "owner@example.com")
+		require.NoError(t, err)
+		other, err := db.CreateUser(ctx, "other@example.com")
+		require.NoError(t, err)
+
+		first, err := db.CreateTransaction(ctx, owner.ID, nil, 5.0, nil, time.Now())
+		require.NoError(t, err)
+		second, err := db.CreateTransaction(ctx, owner.ID, nil, 5.0, nil, time.Now())
+		require.NoError(t, err)
+
+		err = db.DismissDuplicate(ctx, other.ID, first.ID, second.ID)
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+		err = db.DismissDuplicate(ctx, owner.ID, first.ID, first.ID)
+		assert.ErrorIs(t, err, ErrSameTransaction)
+	})
+}
+
+func TestMergeDuplicateTransactions(t *testing.T) {
+	t.Run("keeps one and fills missing fields", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		user, err := db.CreateUser(ctx, "merge@example.com")
+		require.NoError(t, err)
+
+		category, err := db.CreateCategory(ctx, user.ID, "Food")
+		require.NoError(t, err)
+
+		desc := "Lunch"
+		keep, err := db.CreateTransaction(ctx, user.ID, nil, 12.5, &desc, time.Now())
+		require.NoError(t, err)
+		discard, err := db.CreateTransaction(ctx, user.ID, &category.ID, 12.5, nil, time.Now())
+		require.NoError(t, err)
+
+		merged, err := db.MergeDuplicateTransactions(ctx, user.ID, keep.ID, discard.ID)
+		require.NoError(t, err)
+		assert.Equal(t, keep.ID, merged.ID)
+		assert.Equal(t, &category.ID, merged.CategoryID)
+		assert.Equal(t, &desc, merged.Description)
+
+		_, err = db.GetTransactionByID(ctx, discard.ID)
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+	})
+
+	t.Run("other user's transaction", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		owner, err := db.CreateUser(ctx, "merge-owner@example.com")
+		require.NoError(t, err)
+		other, err := db.CreateUser(ctx, "merge-other@example.com")
+		require.NoError(t, err)
+
+		keep, err := db.CreateTransaction(ctx, owner.ID, nil, 1.0, nil, time.Now())
+		require.NoError(t, err)
+		discard, err := db.CreateTransaction(ctx, other.ID, nil, 1.0, nil, time.Now())
+		require.NoError(t, err)
+
+		_, err = db.MergeDuplicateTransactions(ctx, owner.ID, keep.ID, discard.ID)
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+		_, err = db.GetTransactionByID(ctx, discard.ID)
+		assert.NoError(t, err)
+	})
+}
@@ -1,11 +1,162 @@
 package db
 
-import "errors"
+import (
+	"errors"
 
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// The kinds of domain error. Every *Error matches exactly one of them with
+// errors.Is, which is what callers such as the HTTP layer switch on.
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrDuplicateEmail    = errors.New("email already exists")
-	ErrCategoryNotFound  = errors.New("category not found")
-	ErrDuplicateCategory = errors.New("category name already exists for this user")
-	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrForbidden  = errors.New("forbidden")
+	ErrValidation = errors.New("validation failed")
 )
+
+// Error is a domain error: something the caller asked for that the data
+// doesn't allow, as opposed to the database failing.
+type Error struct {
+	// Kind is one of ErrNotFound, ErrConflict, ErrForbidden or ErrValidation.
+	Kind error
+	// Code identifies the error in API responses and never changes.
+	Code    string
+	Message string
+	// Field names the input the error is about, if any.
+	Field string
+	// Err is the underlying cause, such as the Postgres error it came from.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches e's kind, and any *Error with the same code, so errors.Is(err,
+// ErrUserNotFound) holds for a wrapped constraint violation too.
+func (e *Error) Is(target error) bool {
+	if t, ok := target.(*Error); ok {
+		return t.Code == e.Code
+	}
+	return target == e.Kind
+}
+
+// wrap returns a copy of e caused by err.
+func (e *Error) wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+var (
+	ErrUserNotFound        = &Error{Kind: ErrNotFound, Code: "user_not_found", Message: "user not found"}
+	ErrDuplicateEmail      = &Error{Kind: ErrConflict, Code: "email_taken", Message: "email already exists", Field: "email"}
+	ErrCategoryNotFound    = &Error{Kind: ErrNotFound, Code: "category_not_found", Message: "category not found"}
+	ErrDuplicateCategory   = &Error{Kind: ErrConflict, Code: "category_name_taken", Message: "category name already exists for this user", Field: "name"}
+	ErrCategoryNotOwned    = &Error{Kind: ErrValidation, Code: "category_not_owned", Message: "category does not belong to user", Field: "category_id"}
+	ErrTransactionNotFound = &Error{Kind: ErrNotFound, Code: "transaction_not_found", Message: "transaction not found"}
+	ErrSameTransaction     = &Error{Kind: ErrValidation, Code: "same_transaction", Message: "a transaction cannot be a duplicate of itself"}
+	ErrInvalidAmount       = &Error{Kind: ErrValidation, Code: "invalid_amount", Message: "amount must be greater than 0 and less than 100000000", Field: "amount"}
+	ErrInvalidID           = &Error{Kind: ErrValidation, Code: "invalid_id", Message: "invalid UUID format"}
+	ErrWebhookNotFound     = &Error{Kind: ErrNotFound, Code: "webhook_not_found", Message: "webhook subscription not found"}
+	ErrAttachmentNotFound  = &Error{Kind: ErrNotFound, Code: "attachment_not_found", Message: "attachment not found"}
+	ErrGoalNotFound        = &Error{Kind: ErrNotFound, Code: "goal_not_found", Message: "goal not found"}
+
+	ErrGoalContributionNotFound = &Error{Kind: ErrNotFound, Code: "goal_contribution_not_found", Message: "goal contribution not found"}
+	ErrTransactionContributed   = &Error{Kind: ErrConflict, Code: "transaction_already_contributed", Message: "transaction already contributes to a goal", Field: "transaction_id"}
+
+	ErrAccountNotFound    = &Error{Kind: ErrNotFound, Code: "account_not_found", Message: "account not found"}
+	ErrInvalidAccountType = &Error{Kind: ErrValidation, Code: "invalid_account_type", Message: "unknown account type", Field: "type"}
+	ErrValuationNotFound  = &Error{Kind: ErrNotFound, Code: "valuation_not_found", Message: "valuation not found"}
+	ErrInvalidValue       = &Error{Kind: ErrValidation, Code: "invalid_value", Message: "value must be at least 0 and less than 100000000", Field: "value"}
+
+	ErrDebtNotFound        = &Error{Kind: ErrNotFound, Code: "debt_not_found", Message: "debt not found"}
+	ErrDebtPaymentNotFound = &Error{Kind: ErrNotFound, Code: "debt_payment_not_found", Message: "debt payment not found"}
+	ErrTransactionPaysDebt = &Error{Kind: ErrConflict, Code: "transaction_already_paid", Message: "transaction already pays a debt", Field: "transaction_id"}
+	ErrInvalidRate         = &Error{Kind: ErrValidation, Code: "invalid_rate", Message: "annual_rate must be at least 0 and less than 100", Field: "annual_rate"}
+	ErrInvalidTerm         = &Error{Kind: ErrValidation, Code: "invalid_term", Message: "term_months must be between 1 and 600", Field: "term_months"}
+
+	ErrSecurityNotFound     = &Error{Kind: ErrNotFound, Code: "security_not_found", Message: "security not found"}
+	ErrSymbolTaken          = &Error{Kind: ErrConflict, Code: "symbol_taken", Message: "symbol already exists for this user", Field: "symbol"}
+	ErrLotNotFound          = &Error{Kind: ErrNotFound, Code: "lot_not_found", Message: "lot not found"}
+	ErrInvalidSide          = &Error{Kind: ErrValidation, Code: "invalid_side", Message: "unknown lot side", Field: "side"}
+	ErrInvalidQuantity      = &Error{Kind: ErrValidation, Code: "invalid_quantity", Message: "quantity must be greater than 0 and less than 1000000000000", Field: "quantity"}
+	ErrInvalidPrice         = &Error{Kind: ErrValidation, Code: "invalid_price", Message: "price must be at least 0 and less than 100000000", Field: "price"}
+	ErrInvalidFees          = &Error{Kind: ErrValidation, Code: "invalid_fees", Message: "fees must be at least 0 and less than 100000000", Field: "fees"}
+	ErrInsufficientQuantity = &Error{Kind: ErrConflict, Code: "insufficient_quantity", Message: "sells cannot exceed the quantity held", Field: "quantity"}
+
+	ErrWebhookDeliveryNotFound = &Error{Kind: ErrNotFound, Code: "webhook_delivery_not_found", Message: "webhook delivery not found"}
+
+	ErrSerializationFailure = &Error{Kind: ErrConflict, Code: "transaction_conflict", Message: "transaction kept conflicting with concurrent transactions"}
+)
+
+// constraintErrors maps the schema's constraints to the error violating
+// them means.
+var constraintErrors = map[string]*Error{
+	"users_email_key":               ErrDuplicateEmail,
+	"categories_user_id_name_key":   ErrDuplicateCategory,
+	"categories_user_id_fkey":       ErrUserNotFound,
+	"transactions_user_id_fkey":     ErrUserNotFound,
+	"transactions_category_id_fkey": ErrCategoryNotFound,
+	"transactions_amount_check":     ErrInvalidAmount,
+
+	"webhook_subscriptions_user_id_fkey": ErrUserNotFound,
+
+	"goals_user_id_fkey":                    ErrUserNotFound,
+	"goals_target_amount_check":             ErrInvalidAmount,
+	"goal_contributions_amount_check":       ErrInvalidAmount,
+	"goal_contributions_transaction_id_key": ErrTransactionContributed,
+
+	"accounts_user_id_fkey":  ErrUserNotFound,
+	"accounts_type_check":    ErrInvalidAccountType,
+	"valuations_value_check": ErrInvalidValue,
+
+	"debts_user_id_fkey":               ErrUserNotFound,
+	"debts_principal_check":            ErrInvalidAmount,
+	"debts_payment_check":              ErrInvalidAmount,
+	"debts_annual_rate_check":          ErrInvalidRate,
+	"debts_term_months_check":          ErrInvalidTerm,
+	"debt_payments_amount_check":       ErrInvalidAmount,
+	"debt_payments_transaction_id_key": ErrTransactionPaysDebt,
+
+	"securities_user_id_fkey":       ErrUserNotFound,
+	"securities_user_id_symbol_key": ErrSymbolTaken,
+	"lots_side_check":               ErrInvalidSide,
+	"lots_quantity_check":           ErrInvalidQuantity,
+	"lots_price_check":              ErrInvalidPrice,
+	"lots_fees_check":               ErrInvalidFees,
+	"security_prices_price_check":   ErrInvalidPrice,
+}
+
+// wrapPgError turns a Postgres integrity violation into a domain error and
+// returns any other error unchanged. Violations of constraints without a
+// specific error still get the right kind.
+func wrapPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	if domainErr, ok := constraintErrors[pgErr.ConstraintName]; ok {
+		return domainErr.wrap(err)
+	}
+
+	switch pgErr.Code {
+	case "23503": // foreign_key_violation
+		return &Error{Kind: ErrNotFound, Code: "reference_not_found", Message: "referenced record not found", Err: err}
+	case "23505": // unique_violation
+		return &Error{Kind: ErrConflict, Code: "already_exists", Message: "record already exists", Err: err}
+	case "23514": // check_violation
+		return &Error{Kind: ErrValidation, Code: "constraint_violation", Message: "value violates a constraint", Err: err}
+	case "22003": // numeric_value_out_of_range
+		return ErrInvalidAmount.wrap(err)
+	case "22P02": // invalid_text_representation
+		return ErrInvalidID.wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Is(t *testing.T) {
+	wrapped := ErrUserNotFound.wrap(errors.New("cause"))
+
+	assert.ErrorIs(t, wrapped, ErrUserNotFound, "matches the error with the same code")
+	assert.ErrorIs(t, wrapped, ErrNotFound, "matches its kind")
+	assert.NotErrorIs(t, wrapped, ErrCategoryNotFound)
+	assert.NotErrorIs(t, wrapped, ErrConflict)
+	assert.Equal(t, "user not found", wrapped.Error())
+}
+
+func TestWrapPgError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *pgconn.PgError
+		want     error
+		wantKind error
+	}{
+		{
+			name:     "duplicate email",
+			err:      &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"},
+			want:     ErrDuplicateEmail,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "duplicate category",
+			err:      &pgconn.PgError{Code: "23505", ConstraintName: "categories_user_id_name_key"},
+			want:     ErrDuplicateCategory,
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "missing user",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "transactions_user_id_fkey"},
+			want:     ErrUserNotFound,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "missing category",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "transactions_category_id_fkey"},
+			want:     ErrCategoryNotFound,
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "amount check",
+			err:      &pgconn.PgError{Code: "23514", ConstraintName: "transactions_amount_check"},
+			want:     ErrInvalidAmount,
+			wantKind: ErrValidation,
+		},
+		{
+			name:     "unknown unique constraint",
+			err:      &pgconn.PgError{Code: "23505", ConstraintName: "something_key"},
+			wantKind: ErrConflict,
+		},
+		{
+			name:     "unknown foreign key",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "something_fkey"},
+			wantKind: ErrNotFound,
+		},
+		{
+			name:     "unknown check",
+			err:      &pgconn.PgError{Code: "23514", ConstraintName: "something_check"},
+			wantKind: ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapPgError(tt.err)
+
+			assert.ErrorIs(t, got, tt.wantKind)
+			if tt.want != nil {
+				assert.ErrorIs(t, got, tt.want)
+			}
+			var pgErr *pgconn.PgError
+			assert.ErrorAs(t, got, &pgErr, "the Postgres error stays reachable")
+		})
+	}
+
+	t.Run("other errors are unchanged", func(t *testing.T) {
+		other := &pgconn.PgError{Code: "57014"}
+		assert.Same(t, other, wrapPgError(other))
+
+		plain := errors.New("boom")
+		assert.Same(t, plain, wrapPgError(plain))
+	})
+}
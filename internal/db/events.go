@@ -0,0 +1,250 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/models"
+)
+
+// eventsChannel is the channel new events are announced on with NOTIFY. The
+// payload is the user's ID.
+const eventsChannel = "ledger_events"
+
+// maxListenBackoff caps the wait between attempts to listen again after the
+// listening connection fails.
+const maxListenBackoff = 30 * time.Second
+
+func (db *DB) ListEvents(ctx context.Context, userID string, afterID int64, limit int) ([]models.Event, error) {
+	query := `
+		SELECT id, user_id, type, data, created_at
+		FROM events
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := db.conn().Query(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Type, &event.Data, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (db *DB) LastEventID(ctx context.Context, userID string) (int64, error) {
+	var id int64
+	err := db.conn().QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM events WHERE user_id = $1`, userID).Scan(&id)
+	return id, wrapPgError(err)
+}
+
+func (db *DB) WatchEvents(ctx context.Context, userID string) (<-chan struct{}, error) {
+	if db.listener == nil {
+		return nil, errors.New("db: not listening for events")
+	}
+	db.listener.start()
+	return db.listener.hub.watch(ctx, userID), nil
+}
+
+// publishEvent records an event in the user's ledger, announces it to every
+// server once the transaction commits, and queues its webhook deliveries.
+// Callers run it in the transaction making the change.
+func (db *DB) publishEvent(ctx context.Context, userID, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// Ids come from a sequence as rows are inserted, not as they commit, so
+	// a reader that has seen event 11 could miss event 10 committing after
+	// it. Holding a lock on the user until commit numbers each user's
+	// events in commit order.
+	if _, err := db.conn().Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, userID); err != nil {
+		return wrapPgError(err)
+	}
+	_, err = db.conn().Exec(ctx, `INSERT INTO events (user_id, type, data) VALUES ($1, $2, $3)`, userID, eventType, payload)
+	if err != nil {
+		return wrapPgError(err)
+	}
+	if _, err := db.conn().Exec(ctx, `SELECT pg_notify($1, $2)`, eventsChannel, userID); err != nil {
+		return wrapPgError(err)
+	}
+
+	if !slices.Contains(models.WebhookEventTypes, eventType) {
+		return nil
+	}
+	return db.enqueueWebhookEvent(ctx, userID, eventType, data)
+}
+
+// eventHub wakes the watchers of each user's events in this process.
+type eventHub struct {
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{watchers: make(map[string]map[chan struct{}]struct{})}
+}
+
+// watch returns a channel that receives a value after the user's events are
+// notified, until ctx is done, when it is closed. Wakes that arrive while
+// one is pending are merged into it.
+func (h *eventHub) watch(ctx context.Context, userID string) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.watchers[userID] == nil {
+		h.watchers[userID] = make(map[chan struct{}]struct{})
+	}
+	h.watchers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		h.mu.Lock()
+		delete(h.watchers[userID], ch)
+		if len(h.watchers[userID]) == 0 {
+			delete(h.watchers, userID)
+		}
+		h.mu.Unlock()
+		close(ch)
+	})
+	return ch
+}
+
+// notify wakes the watchers of each user.
+func (h *eventHub) notify(userIDs ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, userID := range userIDs {
+		for ch := range h.watchers[userID] {
+			wake(ch)
+		}
+	}
+}
+
+// notifyAll wakes every watcher, for when notifications may have been lost.
+func (h *eventHub) notifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, watchers := range h.watchers {
+		for ch := range watchers {
+			wake(ch)
+		}
+	}
+}
+
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// eventListener listens for eventsChannel on a connection of its own, so
+// that events published by any server wake this one's watchers. It starts
+// with the first watcher and reconnects until closed.
+type eventListener struct {
+	hub        *eventHub
+	connConfig *pgx.ConnConfig
+	logger     zerolog.Logger
+
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newEventListener(connConfig *pgx.ConnConfig, logger zerolog.Logger) *eventListener {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &eventListener{
+		hub:        newEventHub(),
+		connConfig: connConfig,
+		logger:     logger,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+}
+
+func (l *eventListener) start() {
+	l.once.Do(func() {
+		go l.run()
+	})
+}
+
+// close stops listening and waits for the connection to close.
+func (l *eventListener) close() {
+	l.cancel()
+	// Make sure run has started, or won't, before waiting for it.
+	l.once.Do(func() {
+		close(l.done)
+	})
+	<-l.done
+}
+
+func (l *eventListener) run() {
+	defer close(l.done)
+
+	backoff := time.Second
+	for {
+		listening, err := l.listen(l.ctx)
+		if l.ctx.Err() != nil {
+			return
+		}
+		if listening {
+			backoff = time.Second
+		}
+		l.logger.Warn().Err(err).Dur("retry_in", backoff).Msg("Lost connection listening for events")
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxListenBackoff)
+	}
+}
+
+// listen connects, listens and wakes watchers until the connection fails. It
+// reports whether it got as far as listening.
+func (l *eventListener) listen(ctx context.Context) (bool, error) {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+eventsChannel); err != nil {
+		return false, err
+	}
+	// Anything published while no connection was listening went unnoticed,
+	// so have every watcher look for itself.
+	l.hub.notifyAll()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		l.hub.notify(notification.Payload)
+	}
+}
@@ -0,0 +1,230 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"fintrack-go/internal/models"
+)
+
+const goalColumns = `g.id, g.user_id, g.name, g.target_amount, g.target_date, g.created_at`
+
+func scanGoal(g *models.Goal) []any {
+	return []any{&g.ID, &g.UserID, &g.Name, &g.TargetAmount, &g.TargetDate, &g.CreatedAt}
+}
+
+const goalContributionColumns = `
+	gc.id, gc.goal_id, gc.user_id, gc.transaction_id, gc.amount, gc.note, gc.contributed_at, gc.created_at
+`
+
+func scanGoalContribution(c *models.GoalContribution) []any {
+	return []any{
+		&c.ID,
+		&c.GoalID,
+		&c.UserID,
+		&c.TransactionID,
+		&c.Amount,
+		&c.Note,
+		&c.ContributedAt,
+		&c.CreatedAt,
+	}
+}
+
+func (db *DB) CreateGoal(ctx context.Context, userID string, goal models.Goal) (*models.Goal, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the goal.
+		var created *models.Goal
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateGoal(ctx, userID, goal)
+			return err
+		})
+		return created, err
+	}
+
+	query := `
+		INSERT INTO goals AS g (user_id, name, target_amount, target_date)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + goalColumns
+
+	var created models.Goal
+	err := db.conn().QueryRow(ctx, query, userID, goal.Name, goal.TargetAmount, goal.TargetDate).Scan(scanGoal(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntityGoal, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListGoals(ctx context.Context, userID string) ([]models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.user_id = $1
+		ORDER BY g.created_at, g.id
+	`
+	rows, err := db.conn().Query(ctx, query, userID)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var goals []models.Goal
+	for rows.Next() {
+		var goal models.Goal
+		if err := rows.Scan(scanGoal(&goal)...); err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+
+	return goals, rows.Err()
+}
+
+func (db *DB) GetGoal(ctx context.Context, userID, id string) (*models.Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM goals g WHERE g.id = $1 AND g.user_id = $2`
+
+	var goal models.Goal
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanGoal(&goal)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrGoalNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	return &goal, nil
+}
+
+func (db *DB) DeleteGoal(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteGoal(ctx, userID, id)
+		})
+	}
+
+	query := `DELETE FROM goals g WHERE g.id = $1 AND g.user_id = $2 RETURNING ` + goalColumns
+
+	var goal models.Goal
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanGoal(&goal)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrGoalNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityGoal, goal.ID, models.AuditDelete, goal, nil)
+}
+
+func (db *DB) CreateGoalContribution(ctx context.Context, userID, goalID string, contribution models.GoalContribution) (*models.GoalContribution, error) {
+	if db.tx == nil {
+		// The transaction must not move to the trash before the contribution
+		// is recorded, and the audit entry must be recorded with it.
+		var created *models.GoalContribution
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateGoalContribution(ctx, userID, goalID, contribution)
+			return err
+		})
+		return created, err
+	}
+
+	if _, err := db.GetGoal(ctx, userID, goalID); err != nil {
+		return nil, err
+	}
+	if contribution.TransactionID != nil {
+		transaction, err := db.lockUserTransaction(ctx, userID, *contribution.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		contribution.Amount = transaction.Amount
+		contribution.ContributedAt = transaction.OccurredAt
+	}
+
+	query := `
+		INSERT INTO goal_contributions AS gc (goal_id, user_id, transaction_id, amount, note, contributed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + goalContributionColumns
+
+	var created models.GoalContribution
+	err := db.conn().QueryRow(ctx, query,
+		goalID, userID, contribution.TransactionID, contribution.Amount, contribution.Note, contribution.ContributedAt,
+	).Scan(scanGoalContribution(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntityGoalContribution, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListGoalContributions(ctx context.Context, userID, goalID string) ([]models.GoalContribution, error) {
+	if _, err := db.GetGoal(ctx, userID, goalID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + goalContributionColumns + `
+		FROM goal_contributions gc
+		LEFT JOIN transactions t ON t.id = gc.transaction_id
+		WHERE gc.goal_id = $1 AND t.deleted_at IS NULL
+		ORDER BY gc.contributed_at, gc.created_at, gc.id
+	`
+	rows, err := db.conn().Query(ctx, query, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contributions []models.GoalContribution
+	for rows.Next() {
+		var contribution models.GoalContribution
+		if err := rows.Scan(scanGoalContribution(&contribution)...); err != nil {
+			return nil, err
+		}
+		contributions = append(contributions, contribution)
+	}
+
+	return contributions, rows.Err()
+}
+
+func (db *DB) DeleteGoalContribution(ctx context.Context, userID, goalID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteGoalContribution(ctx, userID, goalID, id)
+		})
+	}
+
+	if _, err := db.GetGoal(ctx, userID, goalID); err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM goal_contributions gc
+		WHERE gc.id = $1 AND gc.goal_id = $2
+			AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.id = gc.transaction_id AND t.deleted_at IS NOT NULL)
+		RETURNING ` + goalContributionColumns
+
+	var contribution models.GoalContribution
+	err := db.conn().QueryRow(ctx, query, id, goalID).Scan(scanGoalContribution(&contribution)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrGoalContributionNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityGoalContribution, contribution.ID, models.AuditDelete, contribution, nil)
+}
@@ -0,0 +1,2497 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/money"
+)
+
+// maxAmount is the first value that no longer fits DECIMAL(10, 2).
+const maxAmount = 1e8
+
+// maxQuantity bounds a lot's quantity, as its column's check does.
+const maxQuantity = 1e12
+
+// MemoryDB is a Database held in process memory. It follows the same rules as
+// the Postgres schema: unique emails, unique category names per user,
+// positive amounts stored to the cent, and the same cascades when rows are
+// deleted. It is safe for concurrent use. Data is lost when the process exits.
+type MemoryDB struct {
+	mu sync.RWMutex
+
+	// seq orders rows created at the same instant, most recent last.
+	seq int64
+	// writes counts changes, so WithTx can tell whether anything was
+	// committed while its transaction ran.
+	writes       int64
+	users        map[string]memoryRow[models.User]
+	emails       map[string]string
+	categories   map[string]memoryRow[models.Category]
+	transactions map[string]memoryRow[models.Transaction]
+	// dismissed is keyed by the pair's ids, lower first, and holds the user.
+	dismissed     map[[2]string]string
+	subscriptions map[string]memoryRow[models.WebhookSubscription]
+	deliveries    map[string]memoryRow[models.WebhookDelivery]
+	attachments   map[string]memoryRow[models.Attachment]
+	// orphaned holds the storage keys of deleted attachments' blobs, with
+	// when they were orphaned.
+	orphaned      map[string]memoryRow[time.Time]
+	goals         map[string]memoryRow[models.Goal]
+	contributions map[string]memoryRow[models.GoalContribution]
+	accounts      map[string]memoryRow[models.Account]
+	valuations    map[string]memoryRow[models.Valuation]
+	debts         map[string]memoryRow[models.Debt]
+	debtPayments  map[string]memoryRow[models.DebtPayment]
+	securities    map[string]memoryRow[models.Security]
+	lots          map[string]memoryRow[models.Lot]
+	// prices are keyed by security and time, which a later import of the
+	// same time replaces.
+	prices map[memoryPriceKey]models.Price
+	// events are in id order. A transaction's copy is clipped, so that
+	// appending to it never writes to the original.
+	events []models.Event
+	// audit is in id order and clipped in a transaction's copy, like events.
+	audit []models.AuditEntry
+
+	hub *eventHub
+	// published is set on a transaction's copy, and collects the users whose
+	// watchers to wake when it commits.
+	published *[]string
+
+	now func() time.Time
+}
+
+type memoryRow[T any] struct {
+	value T
+	seq   int64
+}
+
+type memoryPriceKey struct {
+	securityID string
+	pricedAt   int64
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		users:         make(map[string]memoryRow[models.User]),
+		emails:        make(map[string]string),
+		categories:    make(map[string]memoryRow[models.Category]),
+		transactions:  make(map[string]memoryRow[models.Transaction]),
+		dismissed:     make(map[[2]string]string),
+		subscriptions: make(map[string]memoryRow[models.WebhookSubscription]),
+		deliveries:    make(map[string]memoryRow[models.WebhookDelivery]),
+		attachments:   make(map[string]memoryRow[models.Attachment]),
+		orphaned:      make(map[string]memoryRow[time.Time]),
+		goals:         make(map[string]memoryRow[models.Goal]),
+		contributions: make(map[string]memoryRow[models.GoalContribution]),
+		accounts:      make(map[string]memoryRow[models.Account]),
+		valuations:    make(map[string]memoryRow[models.Valuation]),
+		debts:         make(map[string]memoryRow[models.Debt]),
+		debtPayments:  make(map[string]memoryRow[models.DebtPayment]),
+		securities:    make(map[string]memoryRow[models.Security]),
+		lots:          make(map[string]memoryRow[models.Lot]),
+		prices:        make(map[memoryPriceKey]models.Price),
+		hub:           newEventHub(),
+		now:           time.Now,
+	}
+}
+
+var _ Database = (*MemoryDB)(nil)
+
+func (db *MemoryDB) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (db *MemoryDB) Close() {}
+
+func (db *MemoryDB) CreateUser(ctx context.Context, email string) (*models.User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.emails[email]; ok {
+		return nil, ErrDuplicateEmail
+	}
+
+	user := models.User{ID: uuid.NewString(), Email: email, CreatedAt: db.timestamp()}
+	db.users[user.ID] = newRow(db, user)
+	db.emails[email] = user.ID
+	if err := db.recordAudit(ctx, user.ID, models.EntityUser, user.ID, models.AuditCreate, nil, user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (db *MemoryDB) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	if err := parseIDs(&id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user := row.value
+	return &user, nil
+}
+
+func (db *MemoryDB) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	id, ok := db.emails[email]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user := db.users[id].value
+	return &user, nil
+}
+
+func (db *MemoryDB) DeleteUser(ctx context.Context, id string) error {
+	if err := parseIDs(&id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	// Delete what ON DELETE CASCADE would.
+	db.writes++
+	delete(db.users, id)
+	delete(db.emails, row.value.Email)
+	for categoryID, row := range db.categories {
+		if row.value.UserID == id {
+			delete(db.categories, categoryID)
+		}
+	}
+	for transactionID, row := range db.transactions {
+		if row.value.UserID == id {
+			db.deleteTransaction(transactionID)
+		}
+	}
+	for subscriptionID, row := range db.subscriptions {
+		if row.value.UserID == id {
+			delete(db.subscriptions, subscriptionID)
+		}
+	}
+	for deliveryID, row := range db.deliveries {
+		if _, ok := db.subscriptions[row.value.SubscriptionID]; !ok {
+			delete(db.deliveries, deliveryID)
+		}
+	}
+	for goalID, row := range db.goals {
+		if row.value.UserID == id {
+			delete(db.goals, goalID)
+		}
+	}
+	for contributionID, row := range db.contributions {
+		if row.value.UserID == id {
+			delete(db.contributions, contributionID)
+		}
+	}
+	for accountID, row := range db.accounts {
+		if row.value.UserID == id {
+			delete(db.accounts, accountID)
+		}
+	}
+	for valuationID, row := range db.valuations {
+		if row.value.UserID == id {
+			delete(db.valuations, valuationID)
+		}
+	}
+	for debtID, row := range db.debts {
+		if row.value.UserID == id {
+			delete(db.debts, debtID)
+		}
+	}
+	for paymentID, row := range db.debtPayments {
+		if row.value.UserID == id {
+			delete(db.debtPayments, paymentID)
+		}
+	}
+	for securityID, row := range db.securities {
+		if row.value.UserID == id {
+			delete(db.securities, securityID)
+		}
+	}
+	for lotID, row := range db.lots {
+		if row.value.UserID == id {
+			delete(db.lots, lotID)
+		}
+	}
+	for key := range db.prices {
+		if _, ok := db.securities[key.securityID]; !ok {
+			delete(db.prices, key)
+		}
+	}
+	// The slices may be shared with a transaction's copy, so build new ones
+	// rather than changing them in place.
+	var events []models.Event
+	for _, event := range db.events {
+		if event.UserID != id {
+			events = append(events, event)
+		}
+	}
+	db.events = events
+	audit := make([]models.AuditEntry, len(db.audit))
+	for i, entry := range db.audit {
+		if entry.UserID == id {
+			entry.Before, entry.After, entry.RequestID = nil, nil, nil
+			entry.Actor = RedactedActor
+		}
+		audit[i] = entry
+	}
+	db.audit = audit
+
+	return db.recordAudit(ctx, id, models.EntityUser, id, models.AuditDelete, nil, nil)
+}
+
+func (db *MemoryDB) CreateCategory(ctx context.Context, userID, name string) (*models.Category, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+	for _, row := range db.categories {
+		if row.value.UserID == userID && row.value.Name == name {
+			return nil, ErrDuplicateCategory
+		}
+	}
+
+	category := models.Category{ID: uuid.NewString(), UserID: userID, Name: name, CreatedAt: db.timestamp()}
+	db.categories[category.ID] = newRow(db, category)
+	if err := db.publishEvent(userID, models.EventCategoryCreated, category); err != nil {
+		return nil, err
+	}
+	if err := db.recordAudit(ctx, userID, models.EntityCategory, category.ID, models.AuditCreate, nil, category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (db *MemoryDB) ListCategories(ctx context.Context, userID string) ([]models.Category, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var rows []memoryRow[models.Category]
+	for _, row := range db.categories {
+		if row.value.UserID == userID && row.value.DeletedAt == nil {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].value.CreatedAt.Equal(rows[j].value.CreatedAt) {
+			return rows[i].value.CreatedAt.After(rows[j].value.CreatedAt)
+		}
+		return rows[i].seq > rows[j].seq
+	})
+
+	var categories []models.Category
+	for _, row := range rows {
+		categories = append(categories, row.value)
+	}
+	return categories, nil
+}
+
+func (db *MemoryDB) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
+	if err := parseIDs(&id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.categories[id]
+	if !ok || row.value.DeletedAt != nil {
+		return nil, ErrCategoryNotFound
+	}
+	category := row.value
+	return &category, nil
+}
+
+func (db *MemoryDB) GetCategoriesByIDs(ctx context.Context, ids []string) ([]models.Category, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if err := parseIDs(&id); err != nil {
+			return nil, err
+		}
+		wanted[id] = true
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var categories []models.Category
+	for id := range wanted {
+		if row, ok := db.categories[id]; ok {
+			category := row.value
+			category.DeletedAt = copyTime(category.DeletedAt)
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+func (db *MemoryDB) DeleteCategory(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.categories[id]
+	if !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+		return ErrCategoryNotFound
+	}
+
+	before := row.value
+	deletedAt := db.timestamp()
+	row.value.DeletedAt = &deletedAt
+	db.categories[id] = row
+	db.writes++
+
+	if err := db.publishEvent(userID, models.EventCategoryDeleted, models.DeletedResource{ID: id}); err != nil {
+		return err
+	}
+	return db.recordAudit(ctx, userID, models.EntityCategory, id, models.AuditDelete, before, row.value)
+}
+
+func (db *MemoryDB) RestoreCategory(ctx context.Context, userID, id string) (*models.Category, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.categories[id]
+	if !ok || row.value.UserID != userID || row.value.DeletedAt == nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	before := row.value
+	row.value.DeletedAt = nil
+	db.categories[id] = row
+	db.writes++
+
+	category := row.value
+	if err := db.publishEvent(userID, models.EventCategoryRestored, category); err != nil {
+		return nil, err
+	}
+	if err := db.recordAudit(ctx, userID, models.EntityCategory, id, models.AuditUpdate, before, category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (db *MemoryDB) CreateTransaction(ctx context.Context, userID string, categoryID *string, amount float64, description *string, occurredAt time.Time) (*models.Transaction, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if categoryID != nil {
+		if err := db.validateCategoryOwnership(*categoryID, userID); err != nil {
+			return nil, err
+		}
+		id, _ := parseID(*categoryID)
+		categoryID = &id
+	}
+
+	// DECIMAL(10, 2) rounds to the cent before the CHECK (amount > 0) applies.
+	amount = money.Round(amount)
+	if amount <= 0 || math.Abs(amount) >= maxAmount {
+		return nil, ErrInvalidAmount
+	}
+	if _, ok := db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	transaction := models.Transaction{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		CategoryID:  copyString(categoryID),
+		Amount:      amount,
+		Description: copyString(description),
+		OccurredAt:  occurredAt.Round(time.Microsecond),
+		CreatedAt:   db.timestamp(),
+	}
+	db.transactions[transaction.ID] = newRow(db, transaction)
+
+	// Like INSERT ... RETURNING, the result has no category name. It gets its
+	// own copies of the pointer fields so callers can't modify the stored row.
+	result := transaction
+	result.CategoryID = copyString(categoryID)
+	result.Description = copyString(description)
+	if err := db.publishEvent(userID, models.EventTransactionCreated, result); err != nil {
+		return nil, err
+	}
+	if err := db.recordAudit(ctx, userID, models.EntityTransaction, result.ID, models.AuditCreate, nil, result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (db *MemoryDB) ListTransactions(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if len(filter.CategoryIDs) > 0 {
+		categoryIDs := make([]string, len(filter.CategoryIDs))
+		for i, id := range filter.CategoryIDs {
+			canonical, err := parseID(id)
+			if err != nil {
+				return nil, err
+			}
+			categoryIDs[i] = canonical
+		}
+		filter.CategoryIDs = categoryIDs
+	}
+
+	transactions := db.filterTransactions(userID, filter)
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].OccurredAt.After(transactions[j].OccurredAt)
+	})
+	return transactions, nil
+}
+
+func (db *MemoryDB) GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error) {
+	if err := parseIDs(&id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.transactions[id]
+	if !ok || row.value.DeletedAt != nil {
+		return nil, ErrTransactionNotFound
+	}
+	transaction := db.withCategoryName(row.value)
+	return &transaction, nil
+}
+
+func (db *MemoryDB) DeleteTransaction(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.transactions[id]
+	if !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+		return ErrTransactionNotFound
+	}
+	return db.trashTransaction(ctx, id)
+}
+
+// trashTransaction is DB.trashTransaction for MemoryDB. Callers hold db.mu
+// for writing.
+func (db *MemoryDB) trashTransaction(ctx context.Context, id string) error {
+	row := db.transactions[id]
+	before := db.withCategoryName(row.value)
+	deletedAt := db.timestamp()
+	row.value.DeletedAt = &deletedAt
+	db.transactions[id] = row
+	db.writes++
+
+	userID := row.value.UserID
+	if err := db.publishEvent(userID, models.EventTransactionDeleted, models.DeletedResource{ID: id}); err != nil {
+		return err
+	}
+	return db.recordAudit(ctx, userID, models.EntityTransaction, id, models.AuditDelete, before, db.withCategoryName(row.value))
+}
+
+func (db *MemoryDB) RestoreTransaction(ctx context.Context, userID, id string) (*models.Transaction, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.transactions[id]
+	if !ok || row.value.UserID != userID || row.value.DeletedAt == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	before := db.withCategoryName(row.value)
+	row.value.DeletedAt = nil
+	db.transactions[id] = row
+	db.writes++
+
+	transaction := db.withCategoryName(row.value)
+	if err := db.publishEvent(userID, models.EventTransactionRestored, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.recordAudit(ctx, userID, models.EntityTransaction, id, models.AuditUpdate, before, transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (db *MemoryDB) CreateSecurity(ctx context.Context, userID string, security models.Security) (*models.Security, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+	for _, row := range db.securities {
+		if row.value.UserID == userID && row.value.Symbol == security.Symbol {
+			return nil, ErrSymbolTaken
+		}
+	}
+
+	security.ID = uuid.NewString()
+	security.UserID = userID
+	security.CreatedAt = db.timestamp()
+	db.securities[security.ID] = newRow(db, security)
+
+	if err := db.recordAudit(ctx, userID, models.EntitySecurity, security.ID, models.AuditCreate, nil, security); err != nil {
+		return nil, err
+	}
+	return &security, nil
+}
+
+func (db *MemoryDB) ListSecurities(ctx context.Context, userID string) ([]models.Security, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var rows []memoryRow[models.Security]
+	for _, row := range db.securities {
+		if row.value.UserID == userID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].seq < rows[j].seq
+	})
+
+	var securities []models.Security
+	for _, row := range rows {
+		securities = append(securities, row.value)
+	}
+	return securities, nil
+}
+
+func (db *MemoryDB) GetSecurity(ctx context.Context, userID, id string) (*models.Security, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.securities[id]
+	if !ok || row.value.UserID != userID {
+		return nil, ErrSecurityNotFound
+	}
+	security := row.value
+	return &security, nil
+}
+
+func (db *MemoryDB) DeleteSecurity(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.securities[id]
+	if !ok || row.value.UserID != userID {
+		return ErrSecurityNotFound
+	}
+
+	db.writes++
+	delete(db.securities, id)
+	for lotID, row := range db.lots {
+		if row.value.SecurityID == id {
+			delete(db.lots, lotID)
+		}
+	}
+	for key := range db.prices {
+		if key.securityID == id {
+			delete(db.prices, key)
+		}
+	}
+	return db.recordAudit(ctx, userID, models.EntitySecurity, id, models.AuditDelete, row.value, nil)
+}
+
+func (db *MemoryDB) CreateLot(ctx context.Context, userID, securityID string, lot models.Lot) (*models.Lot, error) {
+	if err := parseIDs(&userID, &securityID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.securities[securityID]; !ok || row.value.UserID != userID {
+		return nil, ErrSecurityNotFound
+	}
+	if !slices.Contains(models.LotSides, lot.Side) {
+		return nil, ErrInvalidSide
+	}
+	lot.Quantity = roundQuantity(lot.Quantity)
+	if lot.Quantity <= 0 || lot.Quantity >= maxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+	lot.Price = roundPrice(lot.Price)
+	if lot.Price < 0 || lot.Price >= maxAmount {
+		return nil, ErrInvalidPrice
+	}
+	lot.Fees = money.Round(lot.Fees)
+	if lot.Fees < 0 || lot.Fees >= maxAmount {
+		return nil, ErrInvalidFees
+	}
+
+	lot.ID = uuid.NewString()
+	lot.SecurityID = securityID
+	lot.UserID = userID
+	lot.TradedAt = lot.TradedAt.Round(time.Microsecond)
+	lot.CreatedAt = db.timestamp()
+	row := newRow(db, lot)
+	if lot.Side == models.LotSell {
+		if err := checkPosition(db.sortedLots(securityID, row)); err != nil {
+			return nil, err
+		}
+	}
+	db.lots[lot.ID] = row
+
+	if err := db.recordAudit(ctx, userID, models.EntityLot, lot.ID, models.AuditCreate, nil, lot); err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+// sortedLots returns a security's lots with extra, in the order ListLots
+// returns them. Callers hold db.mu.
+func (db *MemoryDB) sortedLots(securityID string, extra ...memoryRow[models.Lot]) []models.Lot {
+	rows := extra
+	for _, row := range db.lots {
+		if row.value.SecurityID == securityID {
+			rows = append(rows, row)
+		}
+	}
+	sortLotRows(rows)
+
+	var lots []models.Lot
+	for _, row := range rows {
+		lots = append(lots, row.value)
+	}
+	return lots
+}
+
+// sortLotRows sorts lots by when they were traded, buys before sells traded
+// at the same time.
+func sortLotRows(rows []memoryRow[models.Lot]) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i].value, rows[j].value
+		if !a.TradedAt.Equal(b.TradedAt) {
+			return a.TradedAt.Before(b.TradedAt)
+		}
+		if a.Side != b.Side {
+			return a.Side == models.LotBuy
+		}
+		return rows[i].seq < rows[j].seq
+	})
+}
+
+func (db *MemoryDB) ListLots(ctx context.Context, userID string, filter models.LotFilter) ([]models.Lot, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+	if filter.SecurityID != nil {
+		if _, err := parseID(*filter.SecurityID); err != nil {
+			return nil, err
+		}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if filter.SecurityID != nil {
+		if row, ok := db.securities[*filter.SecurityID]; !ok || row.value.UserID != userID {
+			return nil, ErrSecurityNotFound
+		}
+	}
+
+	var rows []memoryRow[models.Lot]
+	for _, row := range db.lots {
+		l := row.value
+		if l.UserID != userID ||
+			(filter.SecurityID != nil && l.SecurityID != *filter.SecurityID) ||
+			(filter.To != nil && l.TradedAt.After(*filter.To)) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortLotRows(rows)
+
+	var lots []models.Lot
+	for _, row := range rows {
+		lots = append(lots, row.value)
+	}
+	return lots, nil
+}
+
+func (db *MemoryDB) DeleteLot(ctx context.Context, userID, securityID, id string) error {
+	if err := parseIDs(&userID, &securityID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.securities[securityID]; !ok || row.value.UserID != userID {
+		return ErrSecurityNotFound
+	}
+	row, ok := db.lots[id]
+	if !ok || row.value.SecurityID != securityID {
+		return ErrLotNotFound
+	}
+	if row.value.Side == models.LotBuy {
+		lots := slices.DeleteFunc(db.sortedLots(securityID), func(l models.Lot) bool {
+			return l.ID == id
+		})
+		if err := checkPosition(lots); err != nil {
+			return err
+		}
+	}
+
+	db.writes++
+	delete(db.lots, id)
+	return db.recordAudit(ctx, userID, models.EntityLot, id, models.AuditDelete, row.value, nil)
+}
+
+func (db *MemoryDB) SavePrices(ctx context.Context, userID string, prices []models.Price) error {
+	if err := parseIDs(&userID); err != nil {
+		return err
+	}
+	for i := range prices {
+		if _, err := parseID(prices[i].SecurityID); err != nil {
+			return err
+		}
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Check every price before saving any, so that none is saved if one
+	// can't be.
+	saved := make([]models.Price, len(prices))
+	for i, price := range prices {
+		if row, ok := db.securities[price.SecurityID]; !ok || row.value.UserID != userID {
+			return ErrSecurityNotFound
+		}
+		price.Price = roundPrice(price.Price)
+		if price.Price < 0 || price.Price >= maxAmount {
+			return ErrInvalidPrice
+		}
+		price.PricedAt = price.PricedAt.Round(time.Microsecond)
+		saved[i] = price
+	}
+
+	if len(saved) == 0 {
+		return nil
+	}
+
+	db.writes++
+	for _, price := range saved {
+		db.prices[memoryPriceKey{price.SecurityID, price.PricedAt.UnixMicro()}] = price
+	}
+	return db.recordAudit(ctx, userID, models.EntityPriceImport, uuid.NewString(), models.AuditCreate, nil, newPriceImport(saved))
+}
+
+func (db *MemoryDB) ListPrices(ctx context.Context, userID string, filter models.PriceFilter) ([]models.Price, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+	if filter.SecurityID != nil {
+		if _, err := parseID(*filter.SecurityID); err != nil {
+			return nil, err
+		}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if filter.SecurityID != nil {
+		if row, ok := db.securities[*filter.SecurityID]; !ok || row.value.UserID != userID {
+			return nil, ErrSecurityNotFound
+		}
+	}
+
+	var prices []models.Price
+	for _, p := range db.prices {
+		if row, ok := db.securities[p.SecurityID]; !ok || row.value.UserID != userID ||
+			(filter.SecurityID != nil && p.SecurityID != *filter.SecurityID) ||
+			(filter.From != nil && p.PricedAt.Before(*filter.From)) ||
+			(filter.To != nil && p.PricedAt.After(*filter.To)) {
+			continue
+		}
+		prices = append(prices, p)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		a, b := prices[i], prices[j]
+		if !a.PricedAt.Equal(b.PricedAt) {
+			return a.PricedAt.Before(b.PricedAt)
+		}
+		return a.SecurityID < b.SecurityID
+	})
+	return prices, nil
+}
+
+func (db *MemoryDB) ValidateCategoryOwnership(ctx context.Context, categoryID, userID string) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.validateCategoryOwnership(categoryID, userID)
+}
+
+func (db *MemoryDB) GetSummary(ctx context.Context, userID string, from, to *time.Time) (*models.Summary, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	totals := make(map[string]*models.CategorySummary)
+	cents := make(map[string]int64)
+	var categories []models.CategorySummary
+	for _, transaction := range db.filterTransactions(userID, models.TransactionFilter{From: from, To: to}) {
+		// Transactions in a trashed category have no name and are grouped
+		// with the uncategorised ones, as with the join in SQL.
+		key := ""
+		if transaction.CategoryName != nil {
+			key = *transaction.CategoryID
+		}
+		summary, ok := totals[key]
+		if !ok {
+			summary = &models.CategorySummary{CategoryName: "Uncategorized"}
+			if transaction.CategoryName != nil {
+				summary.CategoryID = copyString(transaction.CategoryID)
+				summary.CategoryName = *transaction.CategoryName
+			}
+			totals[key] = summary
+		}
+		// Amounts are exact decimals in Postgres, so sum whole cents.
+		cents[key] += money.ToCents(transaction.Amount)
+	}
+	for key, summary := range totals {
+		summary.Total = money.FromCents(cents[key])
+		categories = append(categories, *summary)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].CategoryName < categories[j].CategoryName
+	})
+
+	now := db.now()
+	summary := &models.Summary{
+		UserID:     userID,
+		From:       now.AddDate(0, 0, -30),
+		To:         now,
+		Categories: categories,
+	}
+	if from != nil {
+		summary.From = *from
+	}
+	if to != nil {
+		summary.To = *to
+	}
+	return summary, nil
+}
+
+func (db *MemoryDB) FindDuplicateTransactions(ctx context.Context, userID string, windowDays int) ([]models.DuplicatePair, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	transactions := db.filterTransactions(userID, models.TransactionFilter{})
+	return pairDuplicates(transactions, windowDays, func(a, b string) bool {
+		_, ok := db.dismissed[pairKey(a, b)]
+		return ok
+	}), nil
+}
+
+func (db *MemoryDB) MergeDuplicateTransactions(ctx context.Context, userID, keepID, discardID string) (*models.Transaction, error) {
+	if err := parseIDs(&userID, &keepID, &discardID); err != nil {
+		return nil, err
+	}
+	if keepID == discardID {
+		return nil, ErrSameTransaction
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	keep, ok := db.transactions[keepID]
+	if !ok || keep.value.UserID != userID || keep.value.DeletedAt != nil {
+		return nil, ErrTransactionNotFound
+	}
+	discard, ok := db.transactions[discardID]
+	if !ok || discard.value.UserID != userID || discard.value.DeletedAt != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	before := db.withCategoryName(keep.value)
+
+	if keep.value.CategoryID == nil {
+		keep.value.CategoryID = discard.value.CategoryID
+	}
+	if keep.value.Description == nil || *keep.value.Description == "" {
+		keep.value.Description = discard.value.Description
+	}
+	db.transactions[keepID] = keep
+	db.writes++
+
+	transaction := db.withCategoryName(keep.value)
+	if err := db.publishEvent(userID, models.EventTransactionUpdated, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.recordAudit(ctx, userID, models.EntityTransaction, keepID, models.AuditUpdate, before, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.trashTransaction(ctx, discardID); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (db *MemoryDB) DismissDuplicate(ctx context.Context, userID, transactionID, duplicateID string) error {
+	if err := parseIDs(&userID, &transactionID, &duplicateID); err != nil {
+		return err
+	}
+	if transactionID == duplicateID {
+		return ErrSameTransaction
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, id := range []string{transactionID, duplicateID} {
+		if row, ok := db.transactions[id]; !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+			return ErrTransactionNotFound
+		}
+	}
+
+	key := pairKey(transactionID, duplicateID)
+	if _, ok := db.dismissed[key]; ok {
+		return nil
+	}
+	db.dismissed[key] = userID
+	db.writes++
+
+	dismissed := dismissedDuplicate{TransactionID: key[0], DuplicateID: key[1]}
+	return db.recordAudit(ctx, userID, models.EntityDismissedDuplicate, dismissed.id(), models.AuditCreate, nil, dismissed)
+}
+
+func (db *MemoryDB) ListTrash(ctx context.Context, userID string) (*models.Trash, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var categories []memoryRow[models.Category]
+	for _, row := range db.categories {
+		if row.value.UserID == userID && row.value.DeletedAt != nil {
+			categories = append(categories, row)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return trashedBefore(*categories[j].value.DeletedAt, categories[j].seq, *categories[i].value.DeletedAt, categories[i].seq)
+	})
+	var transactions []memoryRow[models.Transaction]
+	for _, row := range db.transactions {
+		if row.value.UserID == userID && row.value.DeletedAt != nil {
+			transactions = append(transactions, row)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return trashedBefore(*transactions[j].value.DeletedAt, transactions[j].seq, *transactions[i].value.DeletedAt, transactions[i].seq)
+	})
+
+	trash := &models.Trash{}
+	for _, row := range categories {
+		category := row.value
+		category.DeletedAt = copyTime(category.DeletedAt)
+		trash.Categories = append(trash.Categories, category)
+	}
+	for _, row := range transactions {
+		trash.Transactions = append(trash.Transactions, db.withCategoryName(row.value))
+	}
+	return trash, nil
+}
+
+// trashedBefore orders rows in the trash by when they were deleted, then by
+// when they were created.
+func trashedBefore(a time.Time, aSeq int64, b time.Time, bSeq int64) bool {
+	if !a.Equal(b) {
+		return a.Before(b)
+	}
+	return aSeq < bSeq
+}
+
+func (db *MemoryDB) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var transactions []models.Transaction
+	for id, row := range db.transactions {
+		if row.value.DeletedAt != nil && row.value.DeletedAt.Before(deletedBefore) {
+			transactions = append(transactions, row.value)
+			db.deleteTransaction(id)
+		}
+	}
+	var categories []models.Category
+	for id, row := range db.categories {
+		if row.value.DeletedAt != nil && row.value.DeletedAt.Before(deletedBefore) {
+			categories = append(categories, row.value)
+			db.writes++
+			delete(db.categories, id)
+		}
+	}
+	// Like ON DELETE SET NULL, leave the transactions of purged categories
+	// uncategorized.
+	for _, category := range categories {
+		for id, row := range db.transactions {
+			if row.value.CategoryID != nil && *row.value.CategoryID == category.ID {
+				row.value.CategoryID = nil
+				db.transactions[id] = row
+			}
+		}
+	}
+
+	for _, t := range transactions {
+		if err := db.recordAudit(ctx, t.UserID, models.EntityTransaction, t.ID, models.AuditDelete, t, nil); err != nil {
+			return 0, err
+		}
+	}
+	for _, c := range categories {
+		if err := db.recordAudit(ctx, c.UserID, models.EntityCategory, c.ID, models.AuditDelete, c, nil); err != nil {
+			return 0, err
+		}
+	}
+	return len(transactions) + len(categories), nil
+}
+
+func (db *MemoryDB) CreateAttachment(ctx context.Context, userID, transactionID string, attachment models.Attachment) (*models.Attachment, error) {
+	if err := parseIDs(&userID, &transactionID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.transactions[transactionID]
+	if !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+		return nil, ErrTransactionNotFound
+	}
+	for _, row := range db.attachments {
+		if row.value.StorageKey == attachment.StorageKey {
+			return nil, &Error{Kind: ErrConflict, Code: "already_exists", Message: "record already exists"}
+		}
+	}
+
+	attachment.ID = uuid.NewString()
+	attachment.UserID = userID
+	attachment.TransactionID = transactionID
+	attachment.CreatedAt = db.timestamp()
+	db.attachments[attachment.ID] = newRow(db, attachment)
+
+	if err := db.recordAudit(ctx, userID, models.EntityAttachment, attachment.ID, models.AuditCreate, nil, attachment); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (db *MemoryDB) ListAttachments(ctx context.Context, userID, transactionID string) ([]models.Attachment, error) {
+	if err := parseIDs(&userID, &transactionID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if row, ok := db.transactions[transactionID]; !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	var rows []memoryRow[models.Attachment]
+	for _, row := range db.attachments {
+		if row.value.TransactionID == transactionID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].value.CreatedAt.Equal(rows[j].value.CreatedAt) {
+			return rows[i].value.CreatedAt.Before(rows[j].value.CreatedAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	var attachments []models.Attachment
+	for _, row := range rows {
+		attachments = append(attachments, row.value)
+	}
+	return attachments, nil
+}
+
+func (db *MemoryDB) GetAttachment(ctx context.Context, userID, id string) (*models.Attachment, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	attachment, ok := db.userAttachment(userID, id)
+	if !ok {
+		return nil, ErrAttachmentNotFound
+	}
+	return &attachment, nil
+}
+
+func (db *MemoryDB) DeleteAttachment(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	attachment, ok := db.userAttachment(userID, id)
+	if !ok {
+		return ErrAttachmentNotFound
+	}
+
+	db.deleteAttachment(id)
+	return db.recordAudit(ctx, userID, models.EntityAttachment, id, models.AuditDelete, attachment, nil)
+}
+
+// userAttachment returns the user's attachment if its transaction is not in
+// the trash. Callers hold db.mu.
+func (db *MemoryDB) userAttachment(userID, id string) (models.Attachment, bool) {
+	row, ok := db.attachments[id]
+	if !ok || row.value.UserID != userID {
+		return models.Attachment{}, false
+	}
+	if t := db.transactions[row.value.TransactionID]; t.value.DeletedAt != nil {
+		return models.Attachment{}, false
+	}
+	return row.value, true
+}
+
+func (db *MemoryDB) ListOrphanedBlobs(ctx context.Context, limit int) ([]string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	type orphan struct {
+		key string
+		row memoryRow[time.Time]
+	}
+	var orphans []orphan
+	for key, row := range db.orphaned {
+		orphans = append(orphans, orphan{key, row})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if !orphans[i].row.value.Equal(orphans[j].row.value) {
+			return orphans[i].row.value.Before(orphans[j].row.value)
+		}
+		return orphans[i].key < orphans[j].key
+	})
+
+	var keys []string
+	for _, o := range orphans[:min(limit, len(orphans))] {
+		keys = append(keys, o.key)
+	}
+	return keys, nil
+}
+
+func (db *MemoryDB) ForgetOrphanedBlobs(ctx context.Context, keys []string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := db.orphaned[key]; ok {
+			db.writes++
+			delete(db.orphaned, key)
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) CreateGoal(ctx context.Context, userID string, goal models.Goal) (*models.Goal, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	goal.TargetAmount = money.Round(goal.TargetAmount)
+	if goal.TargetAmount <= 0 || goal.TargetAmount >= maxAmount {
+		return nil, ErrInvalidAmount
+	}
+	if _, ok := db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	goal.ID = uuid.NewString()
+	goal.UserID = userID
+	goal.TargetDate = roundTime(goal.TargetDate)
+	goal.CreatedAt = db.timestamp()
+	db.goals[goal.ID] = newRow(db, goal)
+
+	goal.TargetDate = copyTime(goal.TargetDate)
+	if err := db.recordAudit(ctx, userID, models.EntityGoal, goal.ID, models.AuditCreate, nil, goal); err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (db *MemoryDB) ListGoals(ctx context.Context, userID string) ([]models.Goal, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var rows []memoryRow[models.Goal]
+	for _, row := range db.goals {
+		if row.value.UserID == userID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].seq < rows[j].seq
+	})
+
+	var goals []models.Goal
+	for _, row := range rows {
+		goal := row.value
+		goal.TargetDate = copyTime(goal.TargetDate)
+		goals = append(goals, goal)
+	}
+	return goals, nil
+}
+
+func (db *MemoryDB) GetGoal(ctx context.Context, userID, id string) (*models.Goal, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.goals[id]
+	if !ok || row.value.UserID != userID {
+		return nil, ErrGoalNotFound
+	}
+	goal := row.value
+	goal.TargetDate = copyTime(goal.TargetDate)
+	return &goal, nil
+}
+
+func (db *MemoryDB) DeleteGoal(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.goals[id]
+	if !ok || row.value.UserID != userID {
+		return ErrGoalNotFound
+	}
+
+	db.writes++
+	delete(db.goals, id)
+	for contributionID, row := range db.contributions {
+		if row.value.GoalID == id {
+			delete(db.contributions, contributionID)
+		}
+	}
+	return db.recordAudit(ctx, userID, models.EntityGoal, id, models.AuditDelete, row.value, nil)
+}
+
+func (db *MemoryDB) CreateGoalContribution(ctx context.Context, userID, goalID string, contribution models.GoalContribution) (*models.GoalContribution, error) {
+	if err := parseIDs(&userID, &goalID); err != nil {
+		return nil, err
+	}
+	if contribution.TransactionID != nil {
+		id, err := parseID(*contribution.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		contribution.TransactionID = &id
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.goals[goalID]; !ok || row.value.UserID != userID {
+		return nil, ErrGoalNotFound
+	}
+	if contribution.TransactionID != nil {
+		row, ok := db.transactions[*contribution.TransactionID]
+		if !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+			return nil, ErrTransactionNotFound
+		}
+		for _, c := range db.contributions {
+			if c.value.TransactionID != nil && *c.value.TransactionID == row.value.ID {
+				return nil, ErrTransactionContributed
+			}
+		}
+		contribution.Amount = row.value.Amount
+		contribution.ContributedAt = row.value.OccurredAt
+	} else {
+		contribution.Amount = money.Round(contribution.Amount)
+		if contribution.Amount <= 0 || contribution.Amount >= maxAmount {
+			return nil, ErrInvalidAmount
+		}
+		contribution.ContributedAt = contribution.ContributedAt.Round(time.Microsecond)
+	}
+
+	contribution.ID = uuid.NewString()
+	contribution.GoalID = goalID
+	contribution.UserID = userID
+	contribution.Note = copyString(contribution.Note)
+	contribution.CreatedAt = db.timestamp()
+	db.contributions[contribution.ID] = newRow(db, contribution)
+
+	result := copyGoalContribution(contribution)
+	if err := db.recordAudit(ctx, userID, models.EntityGoalContribution, result.ID, models.AuditCreate, nil, result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (db *MemoryDB) ListGoalContributions(ctx context.Context, userID, goalID string) ([]models.GoalContribution, error) {
+	if err := parseIDs(&userID, &goalID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if row, ok := db.goals[goalID]; !ok || row.value.UserID != userID {
+		return nil, ErrGoalNotFound
+	}
+
+	var rows []memoryRow[models.GoalContribution]
+	for _, row := range db.contributions {
+		if row.value.GoalID == goalID && !db.contributionTrashed(row.value) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i].value, rows[j].value
+		if !a.ContributedAt.Equal(b.ContributedAt) {
+			return a.ContributedAt.Before(b.ContributedAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	var contributions []models.GoalContribution
+	for _, row := range rows {
+		contributions = append(contributions, copyGoalContribution(row.value))
+	}
+	return contributions, nil
+}
+
+func (db *MemoryDB) DeleteGoalContribution(ctx context.Context, userID, goalID, id string) error {
+	if err := parseIDs(&userID, &goalID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.goals[goalID]; !ok || row.value.UserID != userID {
+		return ErrGoalNotFound
+	}
+	row, ok := db.contributions[id]
+	if !ok || row.value.GoalID != goalID || db.contributionTrashed(row.value) {
+		return ErrGoalContributionNotFound
+	}
+
+	db.writes++
+	delete(db.contributions, id)
+	return db.recordAudit(ctx, userID, models.EntityGoalContribution, id, models.AuditDelete, copyGoalContribution(row.value), nil)
+}
+
+// contributionTrashed reports whether c is taken from a transaction in the
+// trash. Callers hold db.mu.
+func (db *MemoryDB) contributionTrashed(c models.GoalContribution) bool {
+	if c.TransactionID == nil {
+		return false
+	}
+	return db.transactions[*c.TransactionID].value.DeletedAt != nil
+}
+
+func (db *MemoryDB) CreateAccount(ctx context.Context, userID string, account models.Account) (*models.Account, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !slices.Contains(models.AccountTypes, account.Type) {
+		return nil, ErrInvalidAccountType
+	}
+	if _, ok := db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	account.ID = uuid.NewString()
+	account.UserID = userID
+	account.CreatedAt = db.timestamp()
+	db.accounts[account.ID] = newRow(db, account)
+
+	if err := db.recordAudit(ctx, userID, models.EntityAccount, account.ID, models.AuditCreate, nil, account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (db *MemoryDB) ListAccounts(ctx context.Context, userID string) ([]models.Account, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var rows []memoryRow[models.Account]
+	for _, row := range db.accounts {
+		if row.value.UserID == userID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].seq < rows[j].seq
+	})
+
+	var accounts []models.Account
+	for _, row := range rows {
+		accounts = append(accounts, row.value)
+	}
+	return accounts, nil
+}
+
+func (db *MemoryDB) GetAccount(ctx context.Context, userID, id string) (*models.Account, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.accounts[id]
+	if !ok || row.value.UserID != userID {
+		return nil, ErrAccountNotFound
+	}
+	account := row.value
+	return &account, nil
+}
+
+func (db *MemoryDB) DeleteAccount(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.accounts[id]
+	if !ok || row.value.UserID != userID {
+		return ErrAccountNotFound
+	}
+
+	db.writes++
+	delete(db.accounts, id)
+	for valuationID, row := range db.valuations {
+		if row.value.AccountID == id {
+			delete(db.valuations, valuationID)
+		}
+	}
+	return db.recordAudit(ctx, userID, models.EntityAccount, id, models.AuditDelete, row.value, nil)
+}
+
+func (db *MemoryDB) CreateValuation(ctx context.Context, userID, accountID string, valuation models.Valuation) (*models.Valuation, error) {
+	if err := parseIDs(&userID, &accountID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.accounts[accountID]; !ok || row.value.UserID != userID {
+		return nil, ErrAccountNotFound
+	}
+	valuation.Value = money.Round(valuation.Value)
+	if valuation.Value < 0 || valuation.Value >= maxAmount {
+		return nil, ErrInvalidValue
+	}
+
+	valuation.ID = uuid.NewString()
+	valuation.AccountID = accountID
+	valuation.UserID = userID
+	valuation.ValuedAt = valuation.ValuedAt.Round(time.Microsecond)
+	valuation.CreatedAt = db.timestamp()
+	db.valuations[valuation.ID] = newRow(db, valuation)
+
+	if err := db.recordAudit(ctx, userID, models.EntityValuation, valuation.ID, models.AuditCreate, nil, valuation); err != nil {
+		return nil, err
+	}
+	return &valuation, nil
+}
+
+func (db *MemoryDB) ListValuations(ctx context.Context, userID string, filter models.ValuationFilter) ([]models.Valuation, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+	if filter.AccountID != nil {
+		if _, err := parseID(*filter.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if filter.AccountID != nil {
+		if row, ok := db.accounts[*filter.AccountID]; !ok || row.value.UserID != userID {
+			return nil, ErrAccountNotFound
+		}
+	}
+
+	var rows []memoryRow[models.Valuation]
+	for _, row := range db.valuations {
+		v := row.value
+		if v.UserID != userID ||
+			(filter.AccountID != nil && v.AccountID != *filter.AccountID) ||
+			(filter.To != nil && v.ValuedAt.After(*filter.To)) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i].value, rows[j].value
+		if !a.ValuedAt.Equal(b.ValuedAt) {
+			return a.ValuedAt.Before(b.ValuedAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	var valuations []models.Valuation
+	for _, row := range rows {
+		valuations = append(valuations, row.value)
+	}
+	return valuations, nil
+}
+
+func (db *MemoryDB) DeleteValuation(ctx context.Context, userID, accountID, id string) error {
+	if err := parseIDs(&userID, &accountID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.accounts[accountID]; !ok || row.value.UserID != userID {
+		return ErrAccountNotFound
+	}
+	row, ok := db.valuations[id]
+	if !ok || row.value.AccountID != accountID {
+		return ErrValuationNotFound
+	}
+
+	db.writes++
+	delete(db.valuations, id)
+	return db.recordAudit(ctx, userID, models.EntityValuation, id, models.AuditDelete, row.value, nil)
+}
+
+func (db *MemoryDB) CreateDebt(ctx context.Context, userID string, debt models.Debt) (*models.Debt, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	debt.Principal = money.Round(debt.Principal)
+	debt.Payment = money.Round(debt.Payment)
+	if debt.Principal <= 0 || debt.Principal >= maxAmount || debt.Payment <= 0 || debt.Payment >= maxAmount {
+		return nil, ErrInvalidAmount
+	}
+	debt.AnnualRate = roundRate(debt.AnnualRate)
+	if debt.AnnualRate < 0 || debt.AnnualRate >= 100 {
+		return nil, ErrInvalidRate
+	}
+	if debt.TermMonths < 1 || debt.TermMonths > 600 {
+		return nil, ErrInvalidTerm
+	}
+	if _, ok := db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	debt.ID = uuid.NewString()
+	debt.UserID = userID
+	debt.FirstPaymentDate = debt.FirstPaymentDate.Round(time.Microsecond)
+	debt.CreatedAt = db.timestamp()
+	db.debts[debt.ID] = newRow(db, debt)
+
+	if err := db.recordAudit(ctx, userID, models.EntityDebt, debt.ID, models.AuditCreate, nil, debt); err != nil {
+		return nil, err
+	}
+	return &debt, nil
+}
+
+func (db *MemoryDB) ListDebts(ctx context.Context, userID string) ([]models.Debt, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var rows []memoryRow[models.Debt]
+	for _, row := range db.debts {
+		if row.value.UserID == userID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].seq < rows[j].seq
+	})
+
+	var debts []models.Debt
+	for _, row := range rows {
+		debts = append(debts, row.value)
+	}
+	return debts, nil
+}
+
+func (db *MemoryDB) GetDebt(ctx context.Context, userID, id string) (*models.Debt, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.debts[id]
+	if !ok || row.value.UserID != userID {
+		return nil, ErrDebtNotFound
+	}
+	debt := row.value
+	return &debt, nil
+}
+
+func (db *MemoryDB) DeleteDebt(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.debts[id]
+	if !ok || row.value.UserID != userID {
+		return ErrDebtNotFound
+	}
+
+	db.writes++
+	delete(db.debts, id)
+	for paymentID, row := range db.debtPayments {
+		if row.value.DebtID == id {
+			delete(db.debtPayments, paymentID)
+		}
+	}
+	return db.recordAudit(ctx, userID, models.EntityDebt, id, models.AuditDelete, row.value, nil)
+}
+
+func (db *MemoryDB) CreateDebtPayment(ctx context.Context, userID, debtID string, payment models.DebtPayment) (*models.DebtPayment, error) {
+	if err := parseIDs(&userID, &debtID); err != nil {
+		return nil, err
+	}
+	if payment.TransactionID != nil {
+		id, err := parseID(*payment.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		payment.TransactionID = &id
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.debts[debtID]; !ok || row.value.UserID != userID {
+		return nil, ErrDebtNotFound
+	}
+	if payment.TransactionID != nil {
+		row, ok := db.transactions[*payment.TransactionID]
+		if !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+			return nil, ErrTransactionNotFound
+		}
+		for _, p := range db.debtPayments {
+			if p.value.TransactionID != nil && *p.value.TransactionID == row.value.ID {
+				return nil, ErrTransactionPaysDebt
+			}
+		}
+		payment.Amount = row.value.Amount
+		payment.PaidAt = row.value.OccurredAt
+	} else {
+		payment.Amount = money.Round(payment.Amount)
+		if payment.Amount <= 0 || payment.Amount >= maxAmount {
+			return nil, ErrInvalidAmount
+		}
+		payment.PaidAt = payment.PaidAt.Round(time.Microsecond)
+	}
+
+	payment.ID = uuid.NewString()
+	payment.DebtID = debtID
+	payment.UserID = userID
+	payment.Note = copyString(payment.Note)
+	payment.CreatedAt = db.timestamp()
+	db.debtPayments[payment.ID] = newRow(db, payment)
+
+	result := copyDebtPayment(payment)
+	if err := db.recordAudit(ctx, userID, models.EntityDebtPayment, result.ID, models.AuditCreate, nil, result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (db *MemoryDB) ListDebtPayments(ctx context.Context, userID, debtID string) ([]models.DebtPayment, error) {
+	if err := parseIDs(&userID, &debtID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if row, ok := db.debts[debtID]; !ok || row.value.UserID != userID {
+		return nil, ErrDebtNotFound
+	}
+
+	var rows []memoryRow[models.DebtPayment]
+	for _, row := range db.debtPayments {
+		if row.value.DebtID == debtID && !db.debtPaymentTrashed(row.value) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i].value, rows[j].value
+		if !a.PaidAt.Equal(b.PaidAt) {
+			return a.PaidAt.Before(b.PaidAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	var payments []models.DebtPayment
+	for _, row := range rows {
+		payments = append(payments, copyDebtPayment(row.value))
+	}
+	return payments, nil
+}
+
+func (db *MemoryDB) DeleteDebtPayment(ctx context.Context, userID, debtID, id string) error {
+	if err := parseIDs(&userID, &debtID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if row, ok := db.debts[debtID]; !ok || row.value.UserID != userID {
+		return ErrDebtNotFound
+	}
+	row, ok := db.debtPayments[id]
+	if !ok || row.value.DebtID != debtID || db.debtPaymentTrashed(row.value) {
+		return ErrDebtPaymentNotFound
+	}
+
+	db.writes++
+	delete(db.debtPayments, id)
+	return db.recordAudit(ctx, userID, models.EntityDebtPayment, id, models.AuditDelete, copyDebtPayment(row.value), nil)
+}
+
+// debtPaymentTrashed reports whether p is taken from a transaction in the
+// trash. Callers hold db.mu.
+func (db *MemoryDB) debtPaymentTrashed(p models.DebtPayment) bool {
+	if p.TransactionID == nil {
+		return false
+	}
+	return db.transactions[*p.TransactionID].value.DeletedAt != nil
+}
+
+func (db *MemoryDB) CreateWebhookSubscription(ctx context.Context, userID, url, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	subscription := models.WebhookSubscription{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: slices.Clone(eventTypes),
+		CreatedAt:  db.timestamp(),
+	}
+	db.subscriptions[subscription.ID] = newRow(db, subscription)
+
+	subscription.EventTypes = slices.Clone(eventTypes)
+	if err := db.recordAudit(ctx, userID, models.EntityWebhook, subscription.ID, models.AuditCreate, nil, subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (db *MemoryDB) ListWebhookSubscriptions(ctx context.Context, userID string) ([]models.WebhookSubscription, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var rows []memoryRow[models.WebhookSubscription]
+	for _, row := range db.subscriptions {
+		if row.value.UserID == userID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].value.CreatedAt.Equal(rows[j].value.CreatedAt) {
+			return rows[i].value.CreatedAt.After(rows[j].value.CreatedAt)
+		}
+		return rows[i].seq > rows[j].seq
+	})
+
+	var subscriptions []models.WebhookSubscription
+	for _, row := range rows {
+		subscription := row.value
+		subscription.EventTypes = slices.Clone(subscription.EventTypes)
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (db *MemoryDB) DeleteWebhookSubscription(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.subscriptions[id]
+	if !ok || row.value.UserID != userID {
+		return ErrWebhookNotFound
+	}
+
+	db.writes++
+	delete(db.subscriptions, id)
+	for deliveryID, row := range db.deliveries {
+		if row.value.SubscriptionID == id {
+			delete(db.deliveries, deliveryID)
+		}
+	}
+	return db.recordAudit(ctx, userID, models.EntityWebhook, id, models.AuditDelete, row.value, nil)
+}
+
+func (db *MemoryDB) ListWebhookDeliveries(ctx context.Context, userID, subscriptionID string) ([]models.WebhookDelivery, error) {
+	if err := parseIDs(&userID, &subscriptionID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if row, ok := db.subscriptions[subscriptionID]; !ok || row.value.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+
+	var rows []memoryRow[models.WebhookDelivery]
+	for _, row := range db.deliveries {
+		if row.value.SubscriptionID == subscriptionID {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].value.CreatedAt.Equal(rows[j].value.CreatedAt) {
+			return rows[i].value.CreatedAt.After(rows[j].value.CreatedAt)
+		}
+		return rows[i].seq > rows[j].seq
+	})
+
+	var deliveries []models.WebhookDelivery
+	for _, row := range rows {
+		deliveries = append(deliveries, row.value)
+	}
+	return deliveries, nil
+}
+
+func (db *MemoryDB) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutgoingWebhook, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var rows []memoryRow[models.WebhookDelivery]
+	for _, row := range db.deliveries {
+		if row.value.Status == models.DeliveryPending && !row.value.NextAttemptAt.After(now) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].value.NextAttemptAt.Equal(*rows[j].value.NextAttemptAt) {
+			return rows[i].value.NextAttemptAt.Before(*rows[j].value.NextAttemptAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	leased := now.Add(lease).Round(time.Microsecond)
+	var webhooks []models.OutgoingWebhook
+	for _, row := range rows {
+		delivery := row.value
+		delivery.NextAttemptAt = &leased
+		db.deliveries[delivery.ID] = memoryRow[models.WebhookDelivery]{value: delivery, seq: row.seq}
+
+		subscription := db.subscriptions[delivery.SubscriptionID].value
+		webhooks = append(webhooks, models.OutgoingWebhook{Delivery: delivery, URL: subscription.URL, Secret: subscription.Secret})
+	}
+	if len(webhooks) > 0 {
+		db.writes++
+	}
+	return webhooks, nil
+}
+
+func (db *MemoryDB) RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error {
+	if err := parseIDs(&id); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.deliveries[id]
+	if !ok {
+		return ErrWebhookDeliveryNotFound
+	}
+
+	delivery := row.value
+	at := attempt.At.Round(time.Microsecond)
+	delivery.Status, delivery.NextAttemptAt = attemptStatus(attempt)
+	if delivery.NextAttemptAt != nil {
+		next := delivery.NextAttemptAt.Round(time.Microsecond)
+		delivery.NextAttemptAt = &next
+	}
+	delivery.Attempts++
+	delivery.ResponseStatus = copyInt(attempt.ResponseStatus)
+	delivery.LastError = copyString(attempt.Error)
+	delivery.LastAttemptAt = &at
+
+	db.writes++
+	db.deliveries[id] = memoryRow[models.WebhookDelivery]{value: delivery, seq: row.seq}
+	return nil
+}
+
+func (db *MemoryDB) ListEvents(ctx context.Context, userID string, afterID int64, limit int) ([]models.Event, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	start, _ := slices.BinarySearchFunc(db.events, afterID+1, func(event models.Event, id int64) int {
+		return cmp.Compare(event.ID, id)
+	})
+	var events []models.Event
+	for _, event := range db.events[start:] {
+		if len(events) == limit {
+			break
+		}
+		if event.UserID == userID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (db *MemoryDB) LastEventID(ctx context.Context, userID string) (int64, error) {
+	if err := parseIDs(&userID); err != nil {
+		return 0, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for i := len(db.events) - 1; i >= 0; i-- {
+		if db.events[i].UserID == userID {
+			return db.events[i].ID, nil
+		}
+	}
+	return 0, nil
+}
+
+func (db *MemoryDB) WatchEvents(ctx context.Context, userID string) (<-chan struct{}, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+	return db.hub.watch(ctx, userID), nil
+}
+
+// publishEvent is DB.publishEvent for MemoryDB. Callers hold db.mu for
+// writing, so watchers woken straight away only see the event once it is
+// released.
+func (db *MemoryDB) publishEvent(userID, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	db.writes++
+	db.seq++
+	db.events = append(db.events, models.Event{
+		ID:        db.seq,
+		UserID:    userID,
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: db.timestamp(),
+	})
+	if db.published != nil {
+		*db.published = append(*db.published, userID)
+	} else {
+		db.hub.notify(userID)
+	}
+
+	if !slices.Contains(models.WebhookEventTypes, eventType) {
+		return nil
+	}
+	return db.enqueueWebhookEvent(userID, eventType, data)
+}
+
+// enqueueWebhookEvent is DB.enqueueWebhookEvent for MemoryDB. Callers hold
+// db.mu for writing.
+func (db *MemoryDB) enqueueWebhookEvent(userID, eventType string, data any) error {
+	eventID, payload, err := newWebhookEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+
+	var subscriptions []memoryRow[models.WebhookSubscription]
+	for _, row := range db.subscriptions {
+		if row.value.UserID == userID && slices.Contains(row.value.EventTypes, eventType) {
+			subscriptions = append(subscriptions, row)
+		}
+	}
+	// Deliveries are created in a stable order, as the other backends do.
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].seq < subscriptions[j].seq })
+
+	createdAt := db.timestamp()
+	for _, row := range subscriptions {
+		delivery := models.WebhookDelivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: row.value.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  &createdAt,
+			CreatedAt:      createdAt,
+		}
+		db.deliveries[delivery.ID] = newRow(db, delivery)
+	}
+	return nil
+}
+
+func (db *MemoryDB) ListAuditEntries(ctx context.Context, userID string, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var entries []models.AuditEntry
+	for i := len(db.audit) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		if matchesAuditFilter(db.audit[i], userID, filter) {
+			entries = append(entries, db.audit[i])
+		}
+	}
+	return entries, nil
+}
+
+// recordAudit is DB.audit for MemoryDB. Callers hold db.mu for writing.
+func (db *MemoryDB) recordAudit(ctx context.Context, userID, entity, entityID, action string, before, after any) error {
+	entry, err := newAuditEntry(ctx, userID, entity, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	db.writes++
+	db.seq++
+	entry.ID = db.seq
+	entry.CreatedAt = db.timestamp()
+	db.audit = append(db.audit, entry)
+	return nil
+}
+
+// WithTx runs fn against a copy of the database and, if fn succeeds, makes
+// the copy current. When another write was committed in the meantime the
+// copy is discarded and fn runs again on a fresh one, which makes
+// transactions serializable as they are with Postgres.
+func (db *MemoryDB) WithTx(ctx context.Context, fn func(tx Database) error) error {
+	for attempt := 1; ; attempt++ {
+		db.mu.RLock()
+		tx := db.clone()
+		db.mu.RUnlock()
+		base := tx.writes
+		var published []string
+		tx.published = &published
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		db.mu.Lock()
+		committed := db.writes == base
+		if committed {
+			db.seq, db.writes = tx.seq, tx.writes
+			db.users, db.emails = tx.users, tx.emails
+			db.categories, db.transactions, db.dismissed = tx.categories, tx.transactions, tx.dismissed
+			db.subscriptions, db.deliveries = tx.subscriptions, tx.deliveries
+			db.attachments, db.orphaned = tx.attachments, tx.orphaned
+			db.goals, db.contributions = tx.goals, tx.contributions
+			db.accounts, db.valuations = tx.accounts, tx.valuations
+			db.debts, db.debtPayments = tx.debts, tx.debtPayments
+			db.securities, db.lots, db.prices = tx.securities, tx.lots, tx.prices
+			db.events, db.audit = tx.events, tx.audit
+		}
+		db.mu.Unlock()
+
+		if committed {
+			if db.published != nil {
+				// A nested transaction is only committed with the outer one.
+				*db.published = append(*db.published, published...)
+			} else {
+				db.hub.notify(published...)
+			}
+			return nil
+		}
+		if attempt == maxTxAttempts {
+			return ErrSerializationFailure
+		}
+		if err := sleepBeforeRetry(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// clone returns a copy of db. Stored rows are never modified in place, so
+// the maps are copied but the rows are shared. Callers hold db.mu.
+func (db *MemoryDB) clone() *MemoryDB {
+	return &MemoryDB{
+		seq:           db.seq,
+		writes:        db.writes,
+		users:         maps.Clone(db.users),
+		emails:        maps.Clone(db.emails),
+		categories:    maps.Clone(db.categories),
+		transactions:  maps.Clone(db.transactions),
+		dismissed:     maps.Clone(db.dismissed),
+		subscriptions: maps.Clone(db.subscriptions),
+		deliveries:    maps.Clone(db.deliveries),
+		attachments:   maps.Clone(db.attachments),
+		orphaned:      maps.Clone(db.orphaned),
+		goals:         maps.Clone(db.goals),
+		contributions: maps.Clone(db.contributions),
+		accounts:      maps.Clone(db.accounts),
+		valuations:    maps.Clone(db.valuations),
+		debts:         maps.Clone(db.debts),
+		debtPayments:  maps.Clone(db.debtPayments),
+		securities:    maps.Clone(db.securities),
+		lots:          maps.Clone(db.lots),
+		prices:        maps.Clone(db.prices),
+		events:        slices.Clip(db.events),
+		audit:         slices.Clip(db.audit),
+		hub:           db.hub,
+		now:           db.now,
+	}
+}
+
+// deleteTransaction removes a transaction and, as ON DELETE CASCADE does, the
+// dismissals that refer to it. Callers hold db.mu.
+func (db *MemoryDB) deleteTransaction(id string) {
+	db.writes++
+	delete(db.transactions, id)
+	for key := range db.dismissed {
+		if key[0] == id || key[1] == id {
+			delete(db.dismissed, key)
+		}
+	}
+	for attachmentID, row := range db.attachments {
+		if row.value.TransactionID == id {
+			db.deleteAttachment(attachmentID)
+		}
+	}
+	for contributionID, row := range db.contributions {
+		if row.value.TransactionID != nil && *row.value.TransactionID == id {
+			delete(db.contributions, contributionID)
+		}
+	}
+	for paymentID, row := range db.debtPayments {
+		if row.value.TransactionID != nil && *row.value.TransactionID == id {
+			delete(db.debtPayments, paymentID)
+		}
+	}
+}
+
+// deleteAttachment deletes an attachment and, like the
+// attachments_orphan_blob trigger, queues its blob for removal. Callers hold
+// db.mu for writing.
+func (db *MemoryDB) deleteAttachment(id string) {
+	key := db.attachments[id].value.StorageKey
+	delete(db.attachments, id)
+	if _, ok := db.orphaned[key]; !ok {
+		db.orphaned[key] = newRow(db, db.timestamp())
+	}
+}
+
+// filterTransactions returns copies of the user's transactions matching
+// filter, with category names filled in, in creation order. Callers hold db.mu.
+func (db *MemoryDB) filterTransactions(userID string, filter models.TransactionFilter) []models.Transaction {
+	var rows []memoryRow[models.Transaction]
+	for _, row := range db.transactions {
+		if matchesTransactionFilter(row.value, userID, filter) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].seq < rows[j].seq
+	})
+
+	transactions := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, db.withCategoryName(row.value))
+	}
+	return transactions
+}
+
+// withCategoryName returns a copy of t with CategoryName set, as the LEFT
+// JOIN on categories that are not in the trash does. Callers hold db.mu.
+func (db *MemoryDB) withCategoryName(t models.Transaction) models.Transaction {
+	t.CategoryID = copyString(t.CategoryID)
+	t.Description = copyString(t.Description)
+	t.DeletedAt = copyTime(t.DeletedAt)
+	t.CategoryName = nil
+	if t.CategoryID != nil {
+		if row, ok := db.categories[*t.CategoryID]; ok && row.value.DeletedAt == nil {
+			name := row.value.Name
+			t.CategoryName = &name
+		}
+	}
+	return t
+}
+
+// validateCategoryOwnership is ValidateCategoryOwnership for callers holding
+// db.mu.
+func (db *MemoryDB) validateCategoryOwnership(categoryID, userID string) error {
+	if err := parseIDs(&categoryID, &userID); err != nil {
+		return err
+	}
+	if row, ok := db.categories[categoryID]; !ok || row.value.UserID != userID || row.value.DeletedAt != nil {
+		return ErrCategoryNotOwned
+	}
+	return nil
+}
+
+// timestamp returns the current time at the microsecond precision Postgres
+// stores. Callers hold db.mu for writing.
+func (db *MemoryDB) timestamp() time.Time {
+	return db.now().Round(time.Microsecond)
+}
+
+// newRow wraps v with the next sequence number. Callers hold db.mu for
+// writing.
+func newRow[T any](db *MemoryDB, v T) memoryRow[T] {
+	db.writes++
+	db.seq++
+	return memoryRow[T]{value: v, seq: db.seq}
+}
+
+// parseID returns id in the canonical lowercase form ids are stored in. Like
+// Postgres, it accepts any case and rejects ids that aren't UUIDs with an
+// error rather than treating them as missing rows.
+func parseID(id string) (string, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", ErrInvalidID.wrap(fmt.Errorf("invalid input syntax for type uuid: %q", id))
+	}
+	return parsed.String(), nil
+}
+
+// parseIDs is parseID for several ids, replacing each in place.
+func parseIDs(ids ...*string) error {
+	for _, id := range ids {
+		canonical, err := parseID(*id)
+		if err != nil {
+			return err
+		}
+		*id = canonical
+	}
+	return nil
+}
+
+// roundRate rounds an annual rate to the three decimals of its
+// DECIMAL(6, 3) column.
+func roundRate(rate float64) float64 {
+	return math.Round(rate*1000) / 1000
+}
+
+// roundQuantity and roundPrice round a lot's quantity and a price to the
+// decimals of their DECIMAL(19, 6) and DECIMAL(12, 4) columns. Values
+// money.ToScale rejects come back as 0 or far out of range, so the callers'
+// range checks still turn them away.
+func roundQuantity(quantity float64) float64 {
+	units, _ := money.ToScale(quantity, 6)
+	return float64(units) / 1e6
+}
+
+func roundPrice(price float64) float64 {
+	units, _ := money.ToScale(price, 4)
+	return float64(units) / 1e4
+}
+
+// checkPosition fails with ErrInsufficientQuantity if lots, in the order
+// ListLots returns them, ever sell more units than were bought before.
+// Quantities are compared in millionths so that rounding can't tip the
+// position below zero.
+func checkPosition(lots []models.Lot) error {
+	var held int64
+	for _, lot := range lots {
+		// Stored quantities are finite and within DECIMAL(19, 6).
+		quantity, _ := money.ToScale(lot.Quantity, 6)
+		if lot.Side == models.LotSell {
+			quantity = -quantity
+		}
+		held += quantity
+		if held < 0 {
+			return ErrInsufficientQuantity
+		}
+	}
+	return nil
+}
+
+func copyInt(n *int) *int {
+	if n == nil {
+		return nil
+	}
+	v := *n
+	return &v
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+// copyGoalContribution returns a copy of c that shares no pointers with it.
+func copyGoalContribution(c models.GoalContribution) models.GoalContribution {
+	c.TransactionID = copyString(c.TransactionID)
+	c.Note = copyString(c.Note)
+	return c
+}
+
+func copyDebtPayment(p models.DebtPayment) models.DebtPayment {
+	p.TransactionID = copyString(p.TransactionID)
+	p.Note = copyString(p.Note)
+	return p
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
+
+// roundTime returns a copy of t at the microsecond precision timestamps are
+// stored at.
+func roundTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.Round(time.Microsecond)
+	return &v
+}
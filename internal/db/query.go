@@ -0,0 +1,140 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fintrack-go/internal/models"
+)
+
+// queryBuilder collects WHERE conditions and their arguments, numbering the
+// positional placeholders so callers never have to track $n by hand.
+type queryBuilder struct {
+	conditions []string
+	args       []any
+
+	// sqlite builds conditions for the SQLite schema, which stores timestamps
+	// as text and has no array parameters.
+	sqlite bool
+}
+
+// where appends a condition. Every "?" in cond is replaced by the next $n
+// placeholder and consumes one of args, in order.
+func (b *queryBuilder) where(cond string, args ...any) {
+	if n := strings.Count(cond, "?"); n != len(args) {
+		panic(fmt.Sprintf("db: condition %q has %d placeholders but %d args", cond, n, len(args)))
+	}
+
+	var sb strings.Builder
+	for _, r := range cond {
+		if r == '?' {
+			arg := args[0]
+			if t, ok := arg.(time.Time); ok && b.sqlite {
+				arg = sqliteTime(t)
+			}
+			b.args = append(b.args, arg)
+			args = args[1:]
+			sb.WriteString("$" + strconv.Itoa(len(b.args)))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	b.conditions = append(b.conditions, sb.String())
+}
+
+// clause returns the accumulated conditions as a WHERE clause, or an empty
+// string when there are none.
+func (b *queryBuilder) clause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// applyTransactionFilter adds the conditions for a user's transactions,
+// aliased as t, narrowed by filter. ListTransactions and GetSummary both use
+// it so the two always agree on which rows are included. Transactions in the
+// trash never are.
+func applyTransactionFilter(b *queryBuilder, userID string, filter models.TransactionFilter) {
+	b.where("t.user_id = ?", userID)
+	b.where("t.deleted_at IS NULL")
+
+	if filter.From != nil {
+		b.where("t.occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		b.where("t.occurred_at <= ?", *filter.To)
+	}
+	if filter.MinAmount != nil {
+		b.where("t.amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		b.where("t.amount <= ?", *filter.MaxAmount)
+	}
+
+	if filter.Uncategorized {
+		b.where("t.category_id IS NULL")
+	} else if len(filter.CategoryIDs) > 0 {
+		if b.sqlite {
+			args := make([]any, len(filter.CategoryIDs))
+			for i, id := range filter.CategoryIDs {
+				args[i] = id
+			}
+			b.where("t.category_id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")+")", args...)
+		} else {
+			b.where("t.category_id = ANY(?)", filter.CategoryIDs)
+		}
+	}
+
+	if filter.HasDescription != nil {
+		if *filter.HasDescription {
+			b.where("t.description IS NOT NULL AND t.description <> ''")
+		} else {
+			b.where("(t.description IS NULL OR t.description = '')")
+		}
+	}
+}
+
+// matchesTransactionFilter reports whether t is included by the conditions
+// applyTransactionFilter would add for userID and filter. Backends that don't
+// speak SQL use it in place of the WHERE clause, so it must be kept in step.
+func matchesTransactionFilter(t models.Transaction, userID string, filter models.TransactionFilter) bool {
+	if t.UserID != userID || t.DeletedAt != nil {
+		return false
+	}
+
+	if filter.From != nil && t.OccurredAt.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && t.OccurredAt.After(*filter.To) {
+		return false
+	}
+	if filter.MinAmount != nil && t.Amount < *filter.MinAmount {
+		return false
+	}
+	if filter.MaxAmount != nil && t.Amount > *filter.MaxAmount {
+		return false
+	}
+
+	if filter.Uncategorized {
+		if t.CategoryID != nil {
+			return false
+		}
+	} else if len(filter.CategoryIDs) > 0 {
+		if t.CategoryID == nil || !slices.Contains(filter.CategoryIDs, *t.CategoryID) {
+			return false
+		}
+	}
+
+	if filter.HasDescription != nil {
+		hasDescription := t.Description != nil && *t.Description != ""
+		if hasDescription != *filter.HasDescription {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"fintrack-go/internal/models"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	t.Run("no conditions", func(t *testing.T) {
+		var qb queryBuilder
+		assert.Equal(t, "", qb.clause())
+		assert.Empty(t, qb.args)
+	})
+
+	t.Run("numbers placeholders in order", func(t *testing.T) {
+		var qb queryBuilder
+		qb.where("a = ?", 1)
+		qb.where("b IS NULL")
+		qb.where("c BETWEEN ? AND ?", 2, 3)
+
+		assert.Equal(t, " WHERE a = $1 AND b IS NULL AND c BETWEEN $2 AND $3", qb.clause())
+		assert.Equal(t, []any{1, 2, 3}, qb.args)
+	})
+
+	t.Run("panics on placeholder mismatch", func(t *testing.T) {
+		var qb queryBuilder
+		assert.Panics(t, func() { qb.where("a = ? AND b = ?", 1) })
+	})
+}
+
+func TestApplyTransactionFilter(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	minAmount := 10.0
+	maxAmount := 100.0
+	hasDescription := false
+	categoryIDs := []string{"660e8400-e29b-41d4-a716-446655440001"}
+
+	tests := []struct {
+		name       string
+		filter     models.TransactionFilter
+		wantClause string
+		wantArgs   []any
+	}{
+		{
+			name:       "user only",
+			filter:     models.TransactionFilter{},
+			wantClause: " WHERE t.user_id = $1 AND t.deleted_at IS NULL",
+			wantArgs:   []any{userID},
+		},
+		{
+			name:       "date range",
+			filter:     models.TransactionFilter{From: &from, To: &to},
+			wantClause: " WHERE t.user_id = $1 AND t.deleted_at IS NULL AND t.occurred_at >= $2 AND t.occurred_at <= $3",
+			wantArgs:   []any{userID, from, to},
+		},
+		{
+			name:       "amount range and categories",
+			filter:     models.TransactionFilter{MinAmount: &minAmount, MaxAmount: &maxAmount, CategoryIDs: categoryIDs},
+			wantClause: " WHERE t.user_id = $1 AND t.deleted_at IS NULL AND t.amount >= $2 AND t.amount <= $3 AND t.category_id = ANY($4)",
+			wantArgs:   []any{userID, minAmount, maxAmount, categoryIDs},
+		},
+		{
+			name:       "uncategorized overrides categories",
+			filter:     models.TransactionFilter{Uncategorized: true, CategoryIDs: categoryIDs},
+			wantClause: " WHERE t.user_id = $1 AND t.deleted_at IS NULL AND t.category_id IS NULL",
+			wantArgs:   []any{userID},
+		},
+		{
+			name:       "without description",
+			filter:     models.TransactionFilter{HasDescription: &hasDescription},
+			wantClause: " WHERE t.user_id = $1 AND t.deleted_at IS NULL AND (t.description IS NULL OR t.description = '')",
+			wantArgs:   []any{userID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var qb queryBuilder
+			applyTransactionFilter(&qb, userID, tt.filter)
+
+			assert.Equal(t, tt.wantClause, qb.clause())
+			assert.Equal(t, tt.wantArgs, qb.args)
+		})
+	}
+}
+
+func TestApplyTransactionFilter_SQLite(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	from := time.Date(2026, 1, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	categoryIDs := []string{"660e8400-e29b-41d4-a716-446655440001", "660e8400-e29b-41d4-a716-446655440002"}
+
+	qb := queryBuilder{sqlite: true}
+	applyTransactionFilter(&qb, userID, models.TransactionFilter{From: &from, CategoryIDs: categoryIDs})
+
+	assert.Equal(t, " WHERE t.user_id = $1 AND t.deleted_at IS NULL AND t.occurred_at >= $2 AND t.category_id IN ($3, $4)", qb.clause())
+	assert.Equal(t, []any{userID, "2026-01-01T08:30:00.000000Z", categoryIDs[0], categoryIDs[1]}, qb.args)
+}
+
+func TestMatchesTransactionFilter(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	categoryID := "660e8400-e29b-41d4-a716-446655440001"
+	otherCategoryID := "660e8400-e29b-41d4-a716-446655440002"
+	occurredAt := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	empty := ""
+	lunch := "Lunch"
+
+	base := models.Transaction{UserID: userID, CategoryID: &categoryID, Amount: 25, Description: &lunch, OccurredAt: occurredAt}
+	ptr := func(v float64) *float64 { return &v }
+	boolPtr := func(v bool) *bool { return &v }
+
+	tests := []struct {
+		name   string
+		txn    func(models.Transaction) models.Transaction
+		filter models.TransactionFilter
+		want   bool
+	}{
+		{name: "no filter", filter: models.TransactionFilter{}, want: true},
+		{name: "other user", txn: func(t models.Transaction) models.Transaction { t.UserID = "other"; return t }, want: false},
+		{name: "deleted", txn: func(t models.Transaction) models.Transaction { t.DeletedAt = &occurredAt; return t }, want: false},
+		{name: "from is inclusive", filter: models.TransactionFilter{From: &occurredAt}, want: true},
+		{name: "to is inclusive", filter: models.TransactionFilter{To: &occurredAt}, want: true},
+		{name: "before from", filter: models.TransactionFilter{From: ptrTime(occurredAt.Add(time.Second))}, want: false},
+		{name: "amount in range", filter: models.TransactionFilter{MinAmount: ptr(25), MaxAmount: ptr(25)}, want: true},
+		{name: "amount below min", filter: models.TransactionFilter{MinAmount: ptr(25.01)}, want: false},
+		{name: "matching category", filter: models.TransactionFilter{CategoryIDs: []string{otherCategoryID, categoryID}}, want: true},
+		{name: "other category", filter: models.TransactionFilter{CategoryIDs: []string{otherCategoryID}}, want: false},
+		{name: "uncategorized excludes categorised", filter: models.TransactionFilter{Uncategorized: true}, want: false},
+		{
+			name:   "uncategorized overrides categories",
+			txn:    func(t models.Transaction) models.Transaction { t.CategoryID = nil; return t },
+			filter: models.TransactionFilter{Uncategorized: true, CategoryIDs: []string{categoryID}},
+			want:   true,
+		},
+		{
+			name:   "empty description counts as none",
+			txn:    func(t models.Transaction) models.Transaction { t.Description = &empty; return t },
+			filter: models.TransactionFilter{HasDescription: boolPtr(false)},
+			want:   true,
+		},
+		{name: "has description", filter: models.TransactionFilter{HasDescription: boolPtr(true)}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := base
+			if tt.txn != nil {
+				txn = tt.txn(txn)
+			}
+			assert.Equal(t, tt.want, matchesTransactionFilter(txn, userID, tt.filter))
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
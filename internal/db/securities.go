@@ -0,0 +1,345 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"fintrack-go/internal/models"
+)
+
+const securityColumns = `s.id, s.user_id, s.symbol, s.name, s.created_at`
+
+func scanSecurity(s *models.Security) []any {
+	return []any{&s.ID, &s.UserID, &s.Symbol, &s.Name, &s.CreatedAt}
+}
+
+const lotColumns = `
+	l.id, l.security_id, l.user_id, l.side, l.quantity, l.price, l.fees, l.traded_at, l.created_at
+`
+
+func scanLot(l *models.Lot) []any {
+	return []any{
+		&l.ID,
+		&l.SecurityID,
+		&l.UserID,
+		&l.Side,
+		&l.Quantity,
+		&l.Price,
+		&l.Fees,
+		&l.TradedAt,
+		&l.CreatedAt,
+	}
+}
+
+const priceColumns = `p.security_id, p.price, p.priced_at`
+
+func scanPrice(p *models.Price) []any {
+	return []any{&p.SecurityID, &p.Price, &p.PricedAt}
+}
+
+func (db *DB) CreateSecurity(ctx context.Context, userID string, security models.Security) (*models.Security, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the security.
+		var created *models.Security
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateSecurity(ctx, userID, security)
+			return err
+		})
+		return created, err
+	}
+
+	query := `
+		INSERT INTO securities AS s (user_id, symbol, name)
+		VALUES ($1, $2, $3)
+		RETURNING ` + securityColumns
+
+	var created models.Security
+	err := db.conn().QueryRow(ctx, query, userID, security.Symbol, security.Name).Scan(scanSecurity(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, userID, models.EntitySecurity, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (db *DB) ListSecurities(ctx context.Context, userID string) ([]models.Security, error) {
+	query := `
+		SELECT ` + securityColumns + `
+		FROM securities s
+		WHERE s.user_id = $1
+		ORDER BY s.created_at, s.id
+	`
+	rows, err := db.conn().Query(ctx, query, userID)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var securities []models.Security
+	for rows.Next() {
+		var security models.Security
+		if err := rows.Scan(scanSecurity(&security)...); err != nil {
+			return nil, err
+		}
+		securities = append(securities, security)
+	}
+
+	return securities, rows.Err()
+}
+
+func (db *DB) GetSecurity(ctx context.Context, userID, id string) (*models.Security, error) {
+	query := `SELECT ` + securityColumns + ` FROM securities s WHERE s.id = $1 AND s.user_id = $2`
+
+	var security models.Security
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanSecurity(&security)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSecurityNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	return &security, nil
+}
+
+// lockSecurity locks one of the user's securities until the transaction
+// ends, so that its lots are checked against a position nothing else is
+// changing.
+func (db *DB) lockSecurity(ctx context.Context, userID, id string) error {
+	query := `SELECT s.id FROM securities s WHERE s.id = $1 AND s.user_id = $2 FOR UPDATE`
+
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSecurityNotFound
+	}
+	return wrapPgError(err)
+}
+
+func (db *DB) DeleteSecurity(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteSecurity(ctx, userID, id)
+		})
+	}
+
+	query := `DELETE FROM securities s WHERE s.id = $1 AND s.user_id = $2 RETURNING ` + securityColumns
+
+	var security models.Security
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(scanSecurity(&security)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSecurityNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntitySecurity, security.ID, models.AuditDelete, security, nil)
+}
+
+func (db *DB) CreateLot(ctx context.Context, userID, securityID string, lot models.Lot) (*models.Lot, error) {
+	if db.tx == nil {
+		// The position must be checked with the lot in place, and the audit
+		// entry recorded with it.
+		var created *models.Lot
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			created, err = tx.CreateLot(ctx, userID, securityID, lot)
+			return err
+		})
+		return created, err
+	}
+
+	if err := db.lockSecurity(ctx, userID, securityID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO lots AS l (security_id, user_id, side, quantity, price, fees, traded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + lotColumns
+
+	var created models.Lot
+	err := db.conn().QueryRow(ctx, query,
+		securityID, userID, lot.Side, lot.Quantity, lot.Price, lot.Fees, lot.TradedAt,
+	).Scan(scanLot(&created)...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	if created.Side == models.LotSell {
+		if err := db.checkPosition(ctx, userID, securityID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.audit(ctx, userID, models.EntityLot, created.ID, models.AuditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// checkPosition checks that the sells of one of the user's securities never
+// exceed the units held.
+func (db *DB) checkPosition(ctx context.Context, userID, securityID string) error {
+	lots, err := db.ListLots(ctx, userID, models.LotFilter{SecurityID: &securityID})
+	if err != nil {
+		return err
+	}
+	return checkPosition(lots)
+}
+
+func (db *DB) ListLots(ctx context.Context, userID string, filter models.LotFilter) ([]models.Lot, error) {
+	var qb queryBuilder
+	qb.where("l.user_id = ?", userID)
+	if filter.SecurityID != nil {
+		if _, err := db.GetSecurity(ctx, userID, *filter.SecurityID); err != nil {
+			return nil, err
+		}
+		qb.where("l.security_id = ?", *filter.SecurityID)
+	}
+	if filter.To != nil {
+		qb.where("l.traded_at <= ?", *filter.To)
+	}
+
+	// 'buy' sorts before 'sell'.
+	query := `SELECT ` + lotColumns + ` FROM lots l` + qb.clause() + ` ORDER BY l.traded_at, l.side, l.created_at, l.id`
+	rows, err := db.conn().Query(ctx, query, qb.args...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var lots []models.Lot
+	for rows.Next() {
+		var lot models.Lot
+		if err := rows.Scan(scanLot(&lot)...); err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, rows.Err()
+}
+
+func (db *DB) DeleteLot(ctx context.Context, userID, securityID, id string) error {
+	if db.tx == nil {
+		// The position must be checked without the lot, and the audit entry
+		// recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteLot(ctx, userID, securityID, id)
+		})
+	}
+
+	if err := db.lockSecurity(ctx, userID, securityID); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM lots l WHERE l.id = $1 AND l.security_id = $2 RETURNING ` + lotColumns
+
+	var lot models.Lot
+	err := db.conn().QueryRow(ctx, query, id, securityID).Scan(scanLot(&lot)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrLotNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	if lot.Side == models.LotBuy {
+		if err := db.checkPosition(ctx, userID, securityID); err != nil {
+			return err
+		}
+	}
+
+	return db.audit(ctx, userID, models.EntityLot, lot.ID, models.AuditDelete, lot, nil)
+}
+
+func (db *DB) SavePrices(ctx context.Context, userID string, prices []models.Price) error {
+	if db.tx == nil {
+		// A price file is imported whole or not at all.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.SavePrices(ctx, userID, prices)
+		})
+	}
+
+	owned := make(map[string]bool)
+	for _, price := range prices {
+		if owned[price.SecurityID] {
+			continue
+		}
+		if _, err := db.GetSecurity(ctx, userID, price.SecurityID); err != nil {
+			return err
+		}
+		owned[price.SecurityID] = true
+	}
+
+	if len(prices) == 0 {
+		return nil
+	}
+
+	// Sent in one round trip. A batch rather than a single statement, so
+	// that a later price for the same time replaces an earlier one.
+	query := `
+		INSERT INTO security_prices (security_id, price, priced_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (security_id, priced_at) DO UPDATE SET price = EXCLUDED.price
+	`
+	var batch pgx.Batch
+	for _, price := range prices {
+		batch.Queue(query, price.SecurityID, price.Price, price.PricedAt)
+	}
+	if err := db.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityPriceImport, uuid.NewString(), models.AuditCreate, nil, newPriceImport(prices))
+}
+
+func (db *DB) ListPrices(ctx context.Context, userID string, filter models.PriceFilter) ([]models.Price, error) {
+	var qb queryBuilder
+	qb.where("s.user_id = ?", userID)
+	if filter.SecurityID != nil {
+		if _, err := db.GetSecurity(ctx, userID, *filter.SecurityID); err != nil {
+			return nil, err
+		}
+		qb.where("p.security_id = ?", *filter.SecurityID)
+	}
+	if filter.From != nil {
+		qb.where("p.priced_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		qb.where("p.priced_at <= ?", *filter.To)
+	}
+
+	query := `
+		SELECT ` + priceColumns + `
+		FROM security_prices p
+		JOIN securities s ON s.id = p.security_id` + qb.clause() + `
+		ORDER BY p.priced_at, p.security_id`
+	rows, err := db.conn().Query(ctx, query, qb.args...)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var prices []models.Price
+	for rows.Next() {
+		var price models.Price
+		if err := rows.Scan(scanPrice(&price)...); err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, rows.Err()
+}
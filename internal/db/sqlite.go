@@ -0,0 +1,2776 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"fintrack-go/internal/migrate"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/money"
+	sqlitemigrations "fintrack-go/sql/sqlite"
+)
+
+// sqliteTimeLayout is fixed width so stored timestamps sort as text.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// SQLiteDB is a Database stored in a single SQLite file, for installs where
+// running Postgres isn't worth it. Its schema is migrated when it is opened.
+type SQLiteDB struct {
+	db     *sql.DB
+	logger zerolog.Logger
+
+	// tx is set on the SQLiteDB handed to a WithTx callback, and depth counts
+	// the savepoints of nested calls.
+	tx    *sql.Tx
+	depth int
+
+	hub *eventHub
+	// published collects the users whose watchers to wake when the
+	// transaction commits.
+	published *[]string
+}
+
+// sqliteConn is implemented by both *sql.DB and *sql.Tx.
+type sqliteConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// conn returns what statements should run on: the current transaction, if
+// any, otherwise the database.
+func (db *SQLiteDB) conn() sqliteConn {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.db
+}
+
+var _ Database = (*SQLiteDB)(nil)
+
+// NewSQLiteDB opens, creating if needed, the SQLite database at path and
+// applies any pending migrations.
+func NewSQLiteDB(ctx context.Context, path string, logger zerolog.Logger) (*SQLiteDB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	// Write transactions take the lock up front so two of them can't
+	// deadlock upgrading from a read lock.
+	params.Set("_txlock", "immediate")
+
+	sqlDB, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	db := &SQLiteDB{db: sqlDB, logger: logger, hub: newEventHub()}
+	if err := db.migrate(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	logger.Info().Str("path", path).Msg("Successfully opened SQLite database")
+
+	return db, nil
+}
+
+func (db *SQLiteDB) Close() {
+	if db.db != nil {
+		db.db.Close()
+		db.logger.Info().Msg("Database connection closed")
+	}
+}
+
+func (db *SQLiteDB) Ping(ctx context.Context) error {
+	return db.db.PingContext(ctx)
+}
+
+// migrate applies the pending embedded migrations in one transaction,
+// recording them in schema_migrations as the Postgres runner does.
+func (db *SQLiteDB) migrate(ctx context.Context) error {
+	migrations, err := migrate.Load(sqlitemigrations.FS)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied := make(map[int]string)
+	rows, err := tx.QueryContext(ctx, `SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version int
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = checksum
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	known := make(map[int]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+	for version := range applied {
+		if !known[version] {
+			return fmt.Errorf("%w: version %d", migrate.ErrUnknownVersion, version)
+		}
+	}
+
+	for _, m := range migrations {
+		if checksum, ok := applied[m.Version]; ok {
+			if checksum != m.Checksum {
+				return fmt.Errorf("%w: %d_%s", migrate.ErrChecksumMismatch, m.Version, m.Name)
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+			m.Version, m.Name, m.Checksum, sqliteTime(time.Now()),
+		)
+		if err != nil {
+			return err
+		}
+		db.logger.Info().Int("version", m.Version).Str("name", m.Name).Msg("Applied migration")
+	}
+
+	return tx.Commit()
+}
+
+func (db *SQLiteDB) CreateUser(ctx context.Context, email string) (*models.User, error) {
+	user := models.User{ID: uuid.NewString(), Email: email, CreatedAt: now()}
+
+	// The audit entry must be written with the user.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		_, err := tx.conn().ExecContext(ctx,
+			`INSERT INTO users (id, email, created_at) VALUES ($1, $2, $3)`,
+			user.ID, user.Email, sqliteTime(user.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, user.ID, models.EntityUser, user.ID, models.AuditCreate, nil, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (db *SQLiteDB) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	if err := parseIDs(&id); err != nil {
+		return nil, err
+	}
+	return db.getUser(ctx, `SELECT id, email, created_at FROM users WHERE id = $1`, id)
+}
+
+func (db *SQLiteDB) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return db.getUser(ctx, `SELECT id, email, created_at FROM users WHERE email = $1`, email)
+}
+
+func (db *SQLiteDB) DeleteUser(ctx context.Context, id string) error {
+	if err := parseIDs(&id); err != nil {
+		return err
+	}
+
+	// The redaction and audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		// Everything the user owns goes with them, by ON DELETE CASCADE.
+		result, err := tx.conn().ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrUserNotFound
+		}
+
+		query := `
+			UPDATE audit_log SET before = NULL, after = NULL, request_id = NULL, actor = $2
+			WHERE user_id = $1
+		`
+		if _, err := tx.conn().ExecContext(ctx, query, id, RedactedActor); err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, id, models.EntityUser, id, models.AuditDelete, nil, nil)
+	})
+}
+
+func (db *SQLiteDB) getUser(ctx context.Context, query string, arg any) (*models.User, error) {
+	var user models.User
+	err := db.conn().QueryRowContext(ctx, query, arg).Scan(&user.ID, &user.Email, scanTime(&user.CreatedAt))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (db *SQLiteDB) CreateCategory(ctx context.Context, userID, name string) (*models.Category, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	category := models.Category{ID: uuid.NewString(), UserID: userID, Name: name, CreatedAt: now()}
+
+	// The event and audit entry must be written with the category.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		_, err := tx.conn().ExecContext(ctx,
+			`INSERT INTO categories (id, user_id, name, created_at) VALUES ($1, $2, $3, $4)`,
+			category.ID, category.UserID, category.Name, sqliteTime(category.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		if err := tx.publishEvent(ctx, userID, models.EventCategoryCreated, category); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityCategory, category.ID, models.AuditCreate, nil, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (db *SQLiteDB) ListCategories(ctx context.Context, userID string) ([]models.Category, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, user_id, name, created_at FROM categories WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC, rowid DESC`
+
+	rows, err := db.conn().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.UserID, &category.Name, scanTime(&category.CreatedAt)); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (db *SQLiteDB) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
+	if err := parseIDs(&id); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, user_id, name, created_at FROM categories WHERE id = $1 AND deleted_at IS NULL`
+
+	var category models.Category
+	err := db.conn().QueryRowContext(ctx, query, id).Scan(&category.ID, &category.UserID, &category.Name, scanTime(&category.CreatedAt))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (db *SQLiteDB) GetCategoriesByIDs(ctx context.Context, ids []string) ([]models.Category, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(ids))
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		if err := parseIDs(&id); err != nil {
+			return nil, err
+		}
+		args[i] = id
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	query := `SELECT id, user_id, name, created_at, deleted_at FROM categories WHERE id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	rows, err := db.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.UserID, &category.Name, scanTime(&category.CreatedAt), scanNullTime(&category.DeletedAt)); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (db *SQLiteDB) DeleteCategory(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The event and audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		query := `
+			UPDATE categories SET deleted_at = $3
+			WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			RETURNING id, user_id, name, created_at, deleted_at
+		`
+		var category models.Category
+		err := tx.conn().QueryRowContext(ctx, query, id, userID, sqliteTime(now())).Scan(
+			&category.ID, &category.UserID, &category.Name, scanTime(&category.CreatedAt), scanNullTime(&category.DeletedAt),
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		before := category
+		before.DeletedAt = nil
+		if err := tx.publishEvent(ctx, userID, models.EventCategoryDeleted, models.DeletedResource{ID: category.ID}); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityCategory, category.ID, models.AuditDelete, before, category)
+	})
+}
+
+func (db *SQLiteDB) RestoreCategory(ctx context.Context, userID, id string) (*models.Category, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	var category models.Category
+	// The event and audit entry must be written with the restore.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		query := `SELECT id, user_id, name, created_at, deleted_at FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+		var before models.Category
+		err := tx.conn().QueryRowContext(ctx, query, id, userID).Scan(
+			&before.ID, &before.UserID, &before.Name, scanTime(&before.CreatedAt), scanNullTime(&before.DeletedAt),
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.conn().ExecContext(ctx, `UPDATE categories SET deleted_at = NULL WHERE id = $1`, id); err != nil {
+			return err
+		}
+
+		category = before
+		category.DeletedAt = nil
+		if err := tx.publishEvent(ctx, userID, models.EventCategoryRestored, category); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityCategory, category.ID, models.AuditUpdate, before, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (db *SQLiteDB) CreateTransaction(ctx context.Context, userID string, categoryID *string, amount float64, description *string, occurredAt time.Time) (*models.Transaction, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+	if db.tx == nil {
+		// The ownership check and the insert must see the same category, and
+		// the event and audit entry must be written with the transaction.
+		var transaction *models.Transaction
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			transaction, err = tx.CreateTransaction(ctx, userID, categoryID, amount, description, occurredAt)
+			return err
+		})
+		return transaction, err
+	}
+	if categoryID != nil {
+		if err := db.ValidateCategoryOwnership(ctx, *categoryID, userID); err != nil {
+			return nil, err
+		}
+		id, _ := parseID(*categoryID)
+		categoryID = &id
+	}
+
+	transaction := models.Transaction{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		CategoryID:  categoryID,
+		Amount:      money.Round(amount),
+		Description: description,
+		OccurredAt:  occurredAt.Round(time.Microsecond),
+		CreatedAt:   now(),
+	}
+
+	query := `INSERT INTO transactions (id, user_id, category_id, amount, description, occurred_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := db.conn().ExecContext(ctx, query,
+		transaction.ID,
+		transaction.UserID,
+		transaction.CategoryID,
+		transaction.Amount,
+		transaction.Description,
+		sqliteTime(transaction.OccurredAt),
+		sqliteTime(transaction.CreatedAt),
+	)
+	if err != nil {
+		return nil, wrapSQLiteError(err)
+	}
+
+	if err := db.publishEvent(ctx, userID, models.EventTransactionCreated, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.audit(ctx, userID, models.EntityTransaction, transaction.ID, models.AuditCreate, nil, transaction); err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+const sqliteTransactionColumns = `
+	t.id, t.user_id, t.category_id, t.amount, t.description, t.occurred_at, t.created_at,
+	c.name as category_name, t.deleted_at
+`
+
+func (db *SQLiteDB) ListTransactions(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+	if len(filter.CategoryIDs) > 0 {
+		categoryIDs := make([]string, len(filter.CategoryIDs))
+		copy(categoryIDs, filter.CategoryIDs)
+		for i := range categoryIDs {
+			if err := parseIDs(&categoryIDs[i]); err != nil {
+				return nil, err
+			}
+		}
+		filter.CategoryIDs = categoryIDs
+	}
+
+	qb := queryBuilder{sqlite: true}
+	applyTransactionFilter(&qb, userID, filter)
+
+	query := `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+	` + qb.clause() + ` ORDER BY t.occurred_at DESC, t.rowid`
+
+	return db.queryTransactions(ctx, query, qb.args...)
+}
+
+func (db *SQLiteDB) queryTransactions(ctx context.Context, query string, args ...any) ([]models.Transaction, error) {
+	rows, err := db.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(scanTransaction(&transaction)...); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+func (db *SQLiteDB) GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error) {
+	if err := parseIDs(&id); err != nil {
+		return nil, err
+	}
+	return db.getTransaction(ctx, id)
+}
+
+func (db *SQLiteDB) getTransaction(ctx context.Context, id string) (*models.Transaction, error) {
+	query := `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+		WHERE t.id = $1 AND t.deleted_at IS NULL
+	`
+
+	var transaction models.Transaction
+	err := db.conn().QueryRowContext(ctx, query, id).Scan(scanTransaction(&transaction)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// getUserTransaction returns one of the user's transactions, unless it is in
+// the trash.
+func (db *SQLiteDB) getUserTransaction(ctx context.Context, userID, id string) (*models.Transaction, error) {
+	transaction, err := db.getTransaction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.UserID != userID {
+		return nil, ErrTransactionNotFound
+	}
+	return transaction, nil
+}
+
+func (db *SQLiteDB) DeleteTransaction(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The event and audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		transaction, err := tx.getUserTransaction(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		return tx.trashTransaction(ctx, transaction)
+	})
+}
+
+// trashTransaction is DB.trashTransaction for SQLite.
+func (db *SQLiteDB) trashTransaction(ctx context.Context, transaction *models.Transaction) error {
+	trashed := *transaction
+	deletedAt := now()
+	trashed.DeletedAt = &deletedAt
+	if _, err := db.conn().ExecContext(ctx, `UPDATE transactions SET deleted_at = $2 WHERE id = $1`, transaction.ID, sqliteTime(deletedAt)); err != nil {
+		return err
+	}
+
+	if err := db.publishEvent(ctx, transaction.UserID, models.EventTransactionDeleted, models.DeletedResource{ID: transaction.ID}); err != nil {
+		return err
+	}
+	return db.audit(ctx, transaction.UserID, models.EntityTransaction, transaction.ID, models.AuditDelete, transaction, trashed)
+}
+
+func (db *SQLiteDB) RestoreTransaction(ctx context.Context, userID, id string) (*models.Transaction, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	var transaction *models.Transaction
+	// The event and audit entry must be written with the restore.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		query := `SELECT deleted_at FROM transactions WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+		var deletedAt *time.Time
+		err := tx.conn().QueryRowContext(ctx, query, id, userID).Scan(scanNullTime(&deletedAt))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTransactionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.conn().ExecContext(ctx, `UPDATE transactions SET deleted_at = NULL WHERE id = $1`, id); err != nil {
+			return err
+		}
+
+		transaction, err = tx.getTransaction(ctx, id)
+		if err != nil {
+			return err
+		}
+		before := *transaction
+		before.DeletedAt = deletedAt
+
+		if err := tx.publishEvent(ctx, userID, models.EventTransactionRestored, transaction); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityTransaction, transaction.ID, models.AuditUpdate, before, transaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (db *SQLiteDB) ListTrash(ctx context.Context, userID string) (*models.Trash, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	trash := &models.Trash{}
+
+	query := `
+		SELECT id, user_id, name, created_at, deleted_at
+		FROM categories
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, rowid DESC
+	`
+	rows, err := db.conn().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.UserID, &category.Name, scanTime(&category.CreatedAt), scanNullTime(&category.DeletedAt)); err != nil {
+			return nil, err
+		}
+		trash.Categories = append(trash.Categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+		WHERE t.user_id = $1 AND t.deleted_at IS NOT NULL
+		ORDER BY t.deleted_at DESC, t.rowid DESC
+	`
+	trash.Transactions, err = db.queryTransactions(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+func (db *SQLiteDB) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	// The audit entries must be written with the deletions.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		// Transactions go first, so that those purged with their category
+		// are not set uncategorized for nothing.
+		query := `
+			DELETE FROM transactions
+			WHERE deleted_at < $1
+			RETURNING id, user_id, category_id, amount, description, occurred_at, created_at, deleted_at
+		`
+		rows, err := tx.conn().QueryContext(ctx, query, sqliteTime(deletedBefore))
+		if err != nil {
+			return err
+		}
+		var transactions []models.Transaction
+		for rows.Next() {
+			var t models.Transaction
+			if err := rows.Scan(&t.ID, &t.UserID, &t.CategoryID, &t.Amount, &t.Description, scanTime(&t.OccurredAt), scanTime(&t.CreatedAt), scanNullTime(&t.DeletedAt)); err != nil {
+				rows.Close()
+				return err
+			}
+			transactions = append(transactions, t)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		query = `
+			DELETE FROM categories
+			WHERE deleted_at < $1
+			RETURNING id, user_id, name, created_at, deleted_at
+		`
+		rows, err = tx.conn().QueryContext(ctx, query, sqliteTime(deletedBefore))
+		if err != nil {
+			return err
+		}
+		var categories []models.Category
+		for rows.Next() {
+			var c models.Category
+			if err := rows.Scan(&c.ID, &c.UserID, &c.Name, scanTime(&c.CreatedAt), scanNullTime(&c.DeletedAt)); err != nil {
+				rows.Close()
+				return err
+			}
+			categories = append(categories, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, t := range transactions {
+			if err := tx.audit(ctx, t.UserID, models.EntityTransaction, t.ID, models.AuditDelete, t, nil); err != nil {
+				return err
+			}
+		}
+		for _, c := range categories {
+			if err := tx.audit(ctx, c.UserID, models.EntityCategory, c.ID, models.AuditDelete, c, nil); err != nil {
+				return err
+			}
+		}
+
+		purged = len(transactions) + len(categories)
+		return nil
+	})
+	return purged, err
+}
+
+const sqliteAttachmentColumns = `
+	a.id, a.user_id, a.transaction_id, a.filename, a.content_type, a.size, a.sha256,
+	a.storage_key, a.created_at
+`
+
+func scanSQLiteAttachment(a *models.Attachment) []any {
+	return []any{
+		&a.ID,
+		&a.UserID,
+		&a.TransactionID,
+		&a.Filename,
+		&a.ContentType,
+		&a.Size,
+		&a.SHA256,
+		&a.StorageKey,
+		scanTime(&a.CreatedAt),
+	}
+}
+
+func (db *SQLiteDB) CreateAttachment(ctx context.Context, userID, transactionID string, attachment models.Attachment) (*models.Attachment, error) {
+	if err := parseIDs(&userID, &transactionID); err != nil {
+		return nil, err
+	}
+
+	attachment.ID = uuid.NewString()
+	attachment.UserID = userID
+	attachment.TransactionID = transactionID
+	attachment.CreatedAt = now()
+
+	// The audit entry must be written with the attachment.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.getUserTransaction(ctx, userID, transactionID); err != nil {
+			return err
+		}
+		_, err := tx.conn().ExecContext(ctx, `
+			INSERT INTO attachments (id, user_id, transaction_id, filename, content_type, size, sha256, storage_key, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			attachment.ID, attachment.UserID, attachment.TransactionID, attachment.Filename, attachment.ContentType,
+			attachment.Size, attachment.SHA256, attachment.StorageKey, sqliteTime(attachment.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityAttachment, attachment.ID, models.AuditCreate, nil, attachment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (db *SQLiteDB) ListAttachments(ctx context.Context, userID, transactionID string) ([]models.Attachment, error) {
+	if err := parseIDs(&userID, &transactionID); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.getUserTransaction(ctx, userID, transactionID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteAttachmentColumns + `
+		FROM attachments a
+		WHERE a.transaction_id = $1
+		ORDER BY a.created_at, a.rowid
+	`
+	rows, err := db.conn().QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.Attachment
+	for rows.Next() {
+		var attachment models.Attachment
+		if err := rows.Scan(scanSQLiteAttachment(&attachment)...); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (db *SQLiteDB) GetAttachment(ctx context.Context, userID, id string) (*models.Attachment, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteAttachmentColumns + `
+		FROM attachments a
+		JOIN transactions t ON t.id = a.transaction_id
+		WHERE a.id = $1 AND a.user_id = $2 AND t.deleted_at IS NULL
+	`
+
+	var attachment models.Attachment
+	err := db.conn().QueryRowContext(ctx, query, id, userID).Scan(scanSQLiteAttachment(&attachment)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (db *SQLiteDB) DeleteAttachment(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		attachment, err := tx.GetAttachment(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		// The attachments_orphan_blob trigger queues the blob for removal.
+		if _, err := tx.conn().ExecContext(ctx, `DELETE FROM attachments WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityAttachment, attachment.ID, models.AuditDelete, attachment, nil)
+	})
+}
+
+func (db *SQLiteDB) ListOrphanedBlobs(ctx context.Context, limit int) ([]string, error) {
+	rows, err := db.conn().QueryContext(ctx, `SELECT storage_key FROM orphaned_blobs ORDER BY created_at, storage_key LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (db *SQLiteDB) ForgetOrphanedBlobs(ctx context.Context, keys []string) error {
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		for _, key := range keys {
+			if _, err := tx.conn().ExecContext(ctx, `DELETE FROM orphaned_blobs WHERE storage_key = $1`, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+const sqliteGoalColumns = `g.id, g.user_id, g.name, g.target_amount, g.target_date, g.created_at`
+
+func scanSQLiteGoal(g *models.Goal) []any {
+	return []any{&g.ID, &g.UserID, &g.Name, &g.TargetAmount, scanNullTime(&g.TargetDate), scanTime(&g.CreatedAt)}
+}
+
+const sqliteGoalContributionColumns = `
+	gc.id, gc.goal_id, gc.user_id, gc.transaction_id, gc.amount, gc.note, gc.contributed_at, gc.created_at
+`
+
+func scanSQLiteGoalContribution(c *models.GoalContribution) []any {
+	return []any{
+		&c.ID,
+		&c.GoalID,
+		&c.UserID,
+		&c.TransactionID,
+		&c.Amount,
+		&c.Note,
+		scanTime(&c.ContributedAt),
+		scanTime(&c.CreatedAt),
+	}
+}
+
+func (db *SQLiteDB) CreateGoal(ctx context.Context, userID string, goal models.Goal) (*models.Goal, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	goal.ID = uuid.NewString()
+	goal.UserID = userID
+	goal.TargetAmount = money.Round(goal.TargetAmount)
+	goal.TargetDate = roundTime(goal.TargetDate)
+	goal.CreatedAt = now()
+
+	// The audit entry must be written with the goal.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		_, err := tx.conn().ExecContext(ctx,
+			`INSERT INTO goals (id, user_id, name, target_amount, target_date, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			goal.ID, goal.UserID, goal.Name, goal.TargetAmount, sqliteNullTime(goal.TargetDate), sqliteTime(goal.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityGoal, goal.ID, models.AuditCreate, nil, goal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &goal, nil
+}
+
+func (db *SQLiteDB) ListGoals(ctx context.Context, userID string) ([]models.Goal, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteGoalColumns + `
+		FROM goals g
+		WHERE g.user_id = $1
+		ORDER BY g.created_at, g.rowid
+	`
+	rows, err := db.conn().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []models.Goal
+	for rows.Next() {
+		var goal models.Goal
+		if err := rows.Scan(scanSQLiteGoal(&goal)...); err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+
+	return goals, rows.Err()
+}
+
+func (db *SQLiteDB) GetGoal(ctx context.Context, userID, id string) (*models.Goal, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + sqliteGoalColumns + ` FROM goals g WHERE g.id = $1 AND g.user_id = $2`
+
+	var goal models.Goal
+	err := db.conn().QueryRowContext(ctx, query, id, userID).Scan(scanSQLiteGoal(&goal)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGoalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &goal, nil
+}
+
+func (db *SQLiteDB) DeleteGoal(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		goal, err := tx.GetGoal(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.conn().ExecContext(ctx, `DELETE FROM goals WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityGoal, goal.ID, models.AuditDelete, goal, nil)
+	})
+}
+
+func (db *SQLiteDB) CreateGoalContribution(ctx context.Context, userID, goalID string, contribution models.GoalContribution) (*models.GoalContribution, error) {
+	if err := parseIDs(&userID, &goalID); err != nil {
+		return nil, err
+	}
+	if contribution.TransactionID != nil {
+		id, err := parseID(*contribution.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		contribution.TransactionID = &id
+	}
+
+	contribution.ID = uuid.NewString()
+	contribution.GoalID = goalID
+	contribution.UserID = userID
+	contribution.CreatedAt = now()
+
+	// The transaction must not move to the trash before the contribution is
+	// written, and the audit entry must be written with it.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetGoal(ctx, userID, goalID); err != nil {
+			return err
+		}
+		if contribution.TransactionID != nil {
+			transaction, err := tx.getUserTransaction(ctx, userID, *contribution.TransactionID)
+			if err != nil {
+				return err
+			}
+			contribution.Amount = transaction.Amount
+			contribution.ContributedAt = transaction.OccurredAt
+		} else {
+			contribution.Amount = money.Round(contribution.Amount)
+			contribution.ContributedAt = contribution.ContributedAt.Round(time.Microsecond)
+		}
+
+		_, err := tx.conn().ExecContext(ctx, `
+			INSERT INTO goal_contributions (id, goal_id, user_id, transaction_id, amount, note, contributed_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			contribution.ID, contribution.GoalID, contribution.UserID, contribution.TransactionID, contribution.Amount,
+			contribution.Note, sqliteTime(contribution.ContributedAt), sqliteTime(contribution.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityGoalContribution, contribution.ID, models.AuditCreate, nil, contribution)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &contribution, nil
+}
+
+func (db *SQLiteDB) ListGoalContributions(ctx context.Context, userID, goalID string) ([]models.GoalContribution, error) {
+	if err := parseIDs(&userID, &goalID); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.GetGoal(ctx, userID, goalID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteGoalContributionColumns + `
+		FROM goal_contributions gc
+		LEFT JOIN transactions t ON t.id = gc.transaction_id
+		WHERE gc.goal_id = $1 AND t.deleted_at IS NULL
+		ORDER BY gc.contributed_at, gc.created_at, gc.rowid
+	`
+	rows, err := db.conn().QueryContext(ctx, query, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contributions []models.GoalContribution
+	for rows.Next() {
+		var contribution models.GoalContribution
+		if err := rows.Scan(scanSQLiteGoalContribution(&contribution)...); err != nil {
+			return nil, err
+		}
+		contributions = append(contributions, contribution)
+	}
+
+	return contributions, rows.Err()
+}
+
+func (db *SQLiteDB) DeleteGoalContribution(ctx context.Context, userID, goalID, id string) error {
+	if err := parseIDs(&userID, &goalID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetGoal(ctx, userID, goalID); err != nil {
+			return err
+		}
+
+		// RETURNING can't refer to the table by an alias.
+		query := `
+			DELETE FROM goal_contributions
+			WHERE id = $1 AND goal_id = $2
+				AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.id = transaction_id AND t.deleted_at IS NOT NULL)
+			RETURNING id, goal_id, user_id, transaction_id, amount, note, contributed_at, created_at
+		`
+		var contribution models.GoalContribution
+		err := tx.conn().QueryRowContext(ctx, query, id, goalID).Scan(scanSQLiteGoalContribution(&contribution)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGoalContributionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityGoalContribution, contribution.ID, models.AuditDelete, contribution, nil)
+	})
+}
+
+const sqliteAccountColumns = `a.id, a.user_id, a.name, a.type, a.created_at`
+
+func scanSQLiteAccount(a *models.Account) []any {
+	return []any{&a.ID, &a.UserID, &a.Name, &a.Type, scanTime(&a.CreatedAt)}
+}
+
+const sqliteValuationColumns = `v.id, v.account_id, v.user_id, v.value, v.valued_at, v.created_at`
+
+func scanSQLiteValuation(v *models.Valuation) []any {
+	return []any{&v.ID, &v.AccountID, &v.UserID, &v.Value, scanTime(&v.ValuedAt), scanTime(&v.CreatedAt)}
+}
+
+func (db *SQLiteDB) CreateAccount(ctx context.Context, userID string, account models.Account) (*models.Account, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	account.ID = uuid.NewString()
+	account.UserID = userID
+	account.CreatedAt = now()
+
+	// The audit entry must be written with the account.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		_, err := tx.conn().ExecContext(ctx,
+			`INSERT INTO accounts (id, user_id, name, type, created_at) VALUES ($1, $2, $3, $4, $5)`,
+			account.ID, account.UserID, account.Name, account.Type, sqliteTime(account.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityAccount, account.ID, models.AuditCreate, nil, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (db *SQLiteDB) ListAccounts(ctx context.Context, userID string) ([]models.Account, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteAccountColumns + `
+		FROM accounts a
+		WHERE a.user_id = $1
+		ORDER BY a.created_at, a.rowid
+	`
+	rows, err := db.conn().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []models.Account
+	for rows.Next() {
+		var account models.Account
+		if err := rows.Scan(scanSQLiteAccount(&account)...); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (db *SQLiteDB) GetAccount(ctx context.Context, userID, id string) (*models.Account, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + sqliteAccountColumns + ` FROM accounts a WHERE a.id = $1 AND a.user_id = $2`
+
+	var account models.Account
+	err := db.conn().QueryRowContext(ctx, query, id, userID).Scan(scanSQLiteAccount(&account)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (db *SQLiteDB) DeleteAccount(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		account, err := tx.GetAccount(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.conn().ExecContext(ctx, `DELETE FROM accounts WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityAccount, account.ID, models.AuditDelete, account, nil)
+	})
+}
+
+func (db *SQLiteDB) CreateValuation(ctx context.Context, userID, accountID string, valuation models.Valuation) (*models.Valuation, error) {
+	if err := parseIDs(&userID, &accountID); err != nil {
+		return nil, err
+	}
+
+	valuation.ID = uuid.NewString()
+	valuation.AccountID = accountID
+	valuation.UserID = userID
+	valuation.Value = money.Round(valuation.Value)
+	valuation.ValuedAt = valuation.ValuedAt.Round(time.Microsecond)
+	valuation.CreatedAt = now()
+
+	// The audit entry must be written with the valuation.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetAccount(ctx, userID, accountID); err != nil {
+			return err
+		}
+		_, err := tx.conn().ExecContext(ctx, `
+			INSERT INTO valuations (id, account_id, user_id, value, valued_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			valuation.ID, valuation.AccountID, valuation.UserID, valuation.Value,
+			sqliteTime(valuation.ValuedAt), sqliteTime(valuation.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityValuation, valuation.ID, models.AuditCreate, nil, valuation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &valuation, nil
+}
+
+func (db *SQLiteDB) ListValuations(ctx context.Context, userID string, filter models.ValuationFilter) ([]models.Valuation, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	qb := queryBuilder{sqlite: true}
+	qb.where("v.user_id = ?", userID)
+	if filter.AccountID != nil {
+		if _, err := db.GetAccount(ctx, userID, *filter.AccountID); err != nil {
+			return nil, err
+		}
+		qb.where("v.account_id = ?", *filter.AccountID)
+	}
+	if filter.To != nil {
+		qb.where("v.valued_at <= ?", *filter.To)
+	}
+
+	query := `SELECT ` + sqliteValuationColumns + ` FROM valuations v` + qb.clause() + ` ORDER BY v.valued_at, v.created_at, v.rowid`
+	rows, err := db.conn().QueryContext(ctx, query, qb.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var valuations []models.Valuation
+	for rows.Next() {
+		var valuation models.Valuation
+		if err := rows.Scan(scanSQLiteValuation(&valuation)...); err != nil {
+			return nil, err
+		}
+		valuations = append(valuations, valuation)
+	}
+
+	return valuations, rows.Err()
+}
+
+func (db *SQLiteDB) DeleteValuation(ctx context.Context, userID, accountID, id string) error {
+	if err := parseIDs(&userID, &accountID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetAccount(ctx, userID, accountID); err != nil {
+			return err
+		}
+
+		// RETURNING can't refer to the table by an alias.
+		query := `
+			DELETE FROM valuations
+			WHERE id = $1 AND account_id = $2
+			RETURNING id, account_id, user_id, value, valued_at, created_at
+		`
+		var valuation models.Valuation
+		err := tx.conn().QueryRowContext(ctx, query, id, accountID).Scan(scanSQLiteValuation(&valuation)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrValuationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityValuation, valuation.ID, models.AuditDelete, valuation, nil)
+	})
+}
+
+const sqliteDebtColumns = `
+	d.id, d.user_id, d.name, d.principal, d.annual_rate, d.term_months, d.payment, d.first_payment_date, d.created_at
+`
+
+func scanSQLiteDebt(d *models.Debt) []any {
+	return []any{
+		&d.ID,
+		&d.UserID,
+		&d.Name,
+		&d.Principal,
+		&d.AnnualRate,
+		&d.TermMonths,
+		&d.Payment,
+		scanTime(&d.FirstPaymentDate),
+		scanTime(&d.CreatedAt),
+	}
+}
+
+const sqliteDebtPaymentColumns = `
+	dp.id, dp.debt_id, dp.user_id, dp.transaction_id, dp.amount, dp.note, dp.paid_at, dp.created_at
+`
+
+func scanSQLiteDebtPayment(p *models.DebtPayment) []any {
+	return []any{
+		&p.ID,
+		&p.DebtID,
+		&p.UserID,
+		&p.TransactionID,
+		&p.Amount,
+		&p.Note,
+		scanTime(&p.PaidAt),
+		scanTime(&p.CreatedAt),
+	}
+}
+
+func (db *SQLiteDB) CreateDebt(ctx context.Context, userID string, debt models.Debt) (*models.Debt, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	debt.ID = uuid.NewString()
+	debt.UserID = userID
+	debt.Principal = money.Round(debt.Principal)
+	debt.AnnualRate = roundRate(debt.AnnualRate)
+	debt.Payment = money.Round(debt.Payment)
+	debt.FirstPaymentDate = debt.FirstPaymentDate.Round(time.Microsecond)
+	debt.CreatedAt = now()
+
+	// The audit entry must be written with the debt.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		_, err := tx.conn().ExecContext(ctx, `
+			INSERT INTO debts (id, user_id, name, principal, annual_rate, term_months, payment, first_payment_date, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			debt.ID, debt.UserID, debt.Name, debt.Principal, debt.AnnualRate, debt.TermMonths, debt.Payment,
+			sqliteTime(debt.FirstPaymentDate), sqliteTime(debt.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityDebt, debt.ID, models.AuditCreate, nil, debt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &debt, nil
+}
+
+func (db *SQLiteDB) ListDebts(ctx context.Context, userID string) ([]models.Debt, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteDebtColumns + `
+		FROM debts d
+		WHERE d.user_id = $1
+		ORDER BY d.created_at, d.rowid
+	`
+	rows, err := db.conn().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var debts []models.Debt
+	for rows.Next() {
+		var debt models.Debt
+		if err := rows.Scan(scanSQLiteDebt(&debt)...); err != nil {
+			return nil, err
+		}
+		debts = append(debts, debt)
+	}
+
+	return debts, rows.Err()
+}
+
+func (db *SQLiteDB) GetDebt(ctx context.Context, userID, id string) (*models.Debt, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + sqliteDebtColumns + ` FROM debts d WHERE d.id = $1 AND d.user_id = $2`
+
+	var debt models.Debt
+	err := db.conn().QueryRowContext(ctx, query, id, userID).Scan(scanSQLiteDebt(&debt)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDebtNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &debt, nil
+}
+
+func (db *SQLiteDB) DeleteDebt(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		debt, err := tx.GetDebt(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.conn().ExecContext(ctx, `DELETE FROM debts WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityDebt, debt.ID, models.AuditDelete, debt, nil)
+	})
+}
+
+func (db *SQLiteDB) CreateDebtPayment(ctx context.Context, userID, debtID string, payment models.DebtPayment) (*models.DebtPayment, error) {
+	if err := parseIDs(&userID, &debtID); err != nil {
+		return nil, err
+	}
+	if payment.TransactionID != nil {
+		id, err := parseID(*payment.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		payment.TransactionID = &id
+	}
+
+	payment.ID = uuid.NewString()
+	payment.DebtID = debtID
+	payment.UserID = userID
+	payment.CreatedAt = now()
+
+	// The transaction must not move to the trash before the payment is
+	// written, and the audit entry must be written with it.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetDebt(ctx, userID, debtID); err != nil {
+			return err
+		}
+		if payment.TransactionID != nil {
+			transaction, err := tx.getUserTransaction(ctx, userID, *payment.TransactionID)
+			if err != nil {
+				return err
+			}
+			payment.Amount = transaction.Amount
+			payment.PaidAt = transaction.OccurredAt
+		} else {
+			payment.Amount = money.Round(payment.Amount)
+			payment.PaidAt = payment.PaidAt.Round(time.Microsecond)
+		}
+
+		_, err := tx.conn().ExecContext(ctx, `
+			INSERT INTO debt_payments (id, debt_id, user_id, transaction_id, amount, note, paid_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			payment.ID, payment.DebtID, payment.UserID, payment.TransactionID, payment.Amount,
+			payment.Note, sqliteTime(payment.PaidAt), sqliteTime(payment.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityDebtPayment, payment.ID, models.AuditCreate, nil, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (db *SQLiteDB) ListDebtPayments(ctx context.Context, userID, debtID string) ([]models.DebtPayment, error) {
+	if err := parseIDs(&userID, &debtID); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.GetDebt(ctx, userID, debtID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteDebtPaymentColumns + `
+		FROM debt_payments dp
+		LEFT JOIN transactions t ON t.id = dp.transaction_id
+		WHERE dp.debt_id = $1 AND t.deleted_at IS NULL
+		ORDER BY dp.paid_at, dp.created_at, dp.rowid
+	`
+	rows, err := db.conn().QueryContext(ctx, query, debtID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []models.DebtPayment
+	for rows.Next() {
+		var payment models.DebtPayment
+		if err := rows.Scan(scanSQLiteDebtPayment(&payment)...); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func (db *SQLiteDB) DeleteDebtPayment(ctx context.Context, userID, debtID, id string) error {
+	if err := parseIDs(&userID, &debtID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetDebt(ctx, userID, debtID); err != nil {
+			return err
+		}
+
+		// RETURNING can't refer to the table by an alias.
+		query := `
+			DELETE FROM debt_payments
+			WHERE id = $1 AND debt_id = $2
+				AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.id = transaction_id AND t.deleted_at IS NOT NULL)
+			RETURNING id, debt_id, user_id, transaction_id, amount, note, paid_at, created_at
+		`
+		var payment models.DebtPayment
+		err := tx.conn().QueryRowContext(ctx, query, id, debtID).Scan(scanSQLiteDebtPayment(&payment)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDebtPaymentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityDebtPayment, payment.ID, models.AuditDelete, payment, nil)
+	})
+}
+
+const sqliteSecurityColumns = `s.id, s.user_id, s.symbol, s.name, s.created_at`
+
+func scanSQLiteSecurity(s *models.Security) []any {
+	return []any{&s.ID, &s.UserID, &s.Symbol, &s.Name, scanTime(&s.CreatedAt)}
+}
+
+const sqliteLotColumns = `
+	l.id, l.security_id, l.user_id, l.side, l.quantity, l.price, l.fees, l.traded_at, l.created_at
+`
+
+func scanSQLiteLot(l *models.Lot) []any {
+	return []any{
+		&l.ID,
+		&l.SecurityID,
+		&l.UserID,
+		&l.Side,
+		&l.Quantity,
+		&l.Price,
+		&l.Fees,
+		scanTime(&l.TradedAt),
+		scanTime(&l.CreatedAt),
+	}
+}
+
+const sqlitePriceColumns = `p.security_id, p.price, p.priced_at`
+
+func scanSQLitePrice(p *models.Price) []any {
+	return []any{&p.SecurityID, &p.Price, scanTime(&p.PricedAt)}
+}
+
+func (db *SQLiteDB) CreateSecurity(ctx context.Context, userID string, security models.Security) (*models.Security, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	security.ID = uuid.NewString()
+	security.UserID = userID
+	security.CreatedAt = now()
+
+	// The audit entry must be written with the security.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		_, err := tx.conn().ExecContext(ctx,
+			`INSERT INTO securities (id, user_id, symbol, name, created_at) VALUES ($1, $2, $3, $4, $5)`,
+			security.ID, security.UserID, security.Symbol, security.Name, sqliteTime(security.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntitySecurity, security.ID, models.AuditCreate, nil, security)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &security, nil
+}
+
+func (db *SQLiteDB) ListSecurities(ctx context.Context, userID string) ([]models.Security, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteSecurityColumns + `
+		FROM securities s
+		WHERE s.user_id = $1
+		ORDER BY s.created_at, s.rowid
+	`
+	rows, err := db.conn().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var securities []models.Security
+	for rows.Next() {
+		var security models.Security
+		if err := rows.Scan(scanSQLiteSecurity(&security)...); err != nil {
+			return nil, err
+		}
+		securities = append(securities, security)
+	}
+
+	return securities, rows.Err()
+}
+
+func (db *SQLiteDB) GetSecurity(ctx context.Context, userID, id string) (*models.Security, error) {
+	if err := parseIDs(&userID, &id); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + sqliteSecurityColumns + ` FROM securities s WHERE s.id = $1 AND s.user_id = $2`
+
+	var security models.Security
+	err := db.conn().QueryRowContext(ctx, query, id, userID).Scan(scanSQLiteSecurity(&security)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSecurityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &security, nil
+}
+
+func (db *SQLiteDB) DeleteSecurity(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		security, err := tx.GetSecurity(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.conn().ExecContext(ctx, `DELETE FROM securities WHERE id = $1`, id); err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntitySecurity, security.ID, models.AuditDelete, security, nil)
+	})
+}
+
+func (db *SQLiteDB) CreateLot(ctx context.Context, userID, securityID string, lot models.Lot) (*models.Lot, error) {
+	if err := parseIDs(&userID, &securityID); err != nil {
+		return nil, err
+	}
+
+	lot.ID = uuid.NewString()
+	lot.SecurityID = securityID
+	lot.UserID = userID
+	lot.Quantity = roundQuantity(lot.Quantity)
+	lot.Price = roundPrice(lot.Price)
+	lot.Fees = money.Round(lot.Fees)
+	lot.TradedAt = lot.TradedAt.Round(time.Microsecond)
+	lot.CreatedAt = now()
+
+	// The position must be checked with the lot in place, and the audit
+	// entry written with it.
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetSecurity(ctx, userID, securityID); err != nil {
+			return err
+		}
+		_, err := tx.conn().ExecContext(ctx, `
+			INSERT INTO lots (id, security_id, user_id, side, quantity, price, fees, traded_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			lot.ID, lot.SecurityID, lot.UserID, lot.Side, lot.Quantity, lot.Price, lot.Fees,
+			sqliteTime(lot.TradedAt), sqliteTime(lot.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		if lot.Side == models.LotSell {
+			if err := tx.checkPosition(ctx, userID, securityID); err != nil {
+				return err
+			}
+		}
+		return tx.audit(ctx, userID, models.EntityLot, lot.ID, models.AuditCreate, nil, lot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &lot, nil
+}
+
+// checkPosition checks that the sells of one of the user's securities never
+// exceed the units held.
+func (db *SQLiteDB) checkPosition(ctx context.Context, userID, securityID string) error {
+	lots, err := db.ListLots(ctx, userID, models.LotFilter{SecurityID: &securityID})
+	if err != nil {
+		return err
+	}
+	return checkPosition(lots)
+}
+
+func (db *SQLiteDB) ListLots(ctx context.Context, userID string, filter models.LotFilter) ([]models.Lot, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	qb := queryBuilder{sqlite: true}
+	qb.where("l.user_id = ?", userID)
+	if filter.SecurityID != nil {
+		if _, err := db.GetSecurity(ctx, userID, *filter.SecurityID); err != nil {
+			return nil, err
+		}
+		qb.where("l.security_id = ?", *filter.SecurityID)
+	}
+	if filter.To != nil {
+		qb.where("l.traded_at <= ?", *filter.To)
+	}
+
+	// 'buy' sorts before 'sell'.
+	query := `SELECT ` + sqliteLotColumns + ` FROM lots l` + qb.clause() + ` ORDER BY l.traded_at, l.side, l.created_at, l.rowid`
+	rows, err := db.conn().QueryContext(ctx, query, qb.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []models.Lot
+	for rows.Next() {
+		var lot models.Lot
+		if err := rows.Scan(scanSQLiteLot(&lot)...); err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, rows.Err()
+}
+
+func (db *SQLiteDB) DeleteLot(ctx context.Context, userID, securityID, id string) error {
+	if err := parseIDs(&userID, &securityID, &id); err != nil {
+		return err
+	}
+
+	// The position must be checked without the lot, and the audit entry
+	// written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		if _, err := tx.GetSecurity(ctx, userID, securityID); err != nil {
+			return err
+		}
+
+		// RETURNING can't refer to the table by an alias.
+		query := `
+			DELETE FROM lots
+			WHERE id = $1 AND security_id = $2
+			RETURNING id, security_id, user_id, side, quantity, price, fees, traded_at, created_at
+		`
+		var lot models.Lot
+		err := tx.conn().QueryRowContext(ctx, query, id, securityID).Scan(scanSQLiteLot(&lot)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrLotNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if lot.Side == models.LotBuy {
+			if err := tx.checkPosition(ctx, userID, securityID); err != nil {
+				return err
+			}
+		}
+		return tx.audit(ctx, userID, models.EntityLot, lot.ID, models.AuditDelete, lot, nil)
+	})
+}
+
+func (db *SQLiteDB) SavePrices(ctx context.Context, userID string, prices []models.Price) error {
+	if err := parseIDs(&userID); err != nil {
+		return err
+	}
+
+	// A price file is imported whole or not at all.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		owned := make(map[string]bool)
+		for _, price := range prices {
+			if owned[price.SecurityID] {
+				continue
+			}
+			if _, err := tx.GetSecurity(ctx, userID, price.SecurityID); err != nil {
+				return err
+			}
+			owned[price.SecurityID] = true
+		}
+
+		if len(prices) == 0 {
+			return nil
+		}
+
+		// Prepared once, as a price file may have many lines.
+		stmt, err := tx.conn().PrepareContext(ctx, `
+			INSERT INTO security_prices (security_id, price, priced_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (security_id, priced_at) DO UPDATE SET price = excluded.price`)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		defer stmt.Close()
+
+		saved := make([]models.Price, len(prices))
+		for i, price := range prices {
+			price.Price = roundPrice(price.Price)
+			price.PricedAt = price.PricedAt.Round(time.Microsecond)
+			if _, err := stmt.ExecContext(ctx, price.SecurityID, price.Price, sqliteTime(price.PricedAt)); err != nil {
+				return wrapSQLiteError(err)
+			}
+			saved[i] = price
+		}
+		return tx.audit(ctx, userID, models.EntityPriceImport, uuid.NewString(), models.AuditCreate, nil, newPriceImport(saved))
+	})
+}
+
+func (db *SQLiteDB) ListPrices(ctx context.Context, userID string, filter models.PriceFilter) ([]models.Price, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	qb := queryBuilder{sqlite: true}
+	qb.where("s.user_id = ?", userID)
+	if filter.SecurityID != nil {
+		if _, err := db.GetSecurity(ctx, userID, *filter.SecurityID); err != nil {
+			return nil, err
+		}
+		qb.where("p.security_id = ?", *filter.SecurityID)
+	}
+	if filter.From != nil {
+		qb.where("p.priced_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		qb.where("p.priced_at <= ?", *filter.To)
+	}
+
+	query := `
+		SELECT ` + sqlitePriceColumns + `
+		FROM security_prices p
+		JOIN securities s ON s.id = p.security_id` + qb.clause() + `
+		ORDER BY p.priced_at, p.security_id`
+	rows, err := db.conn().QueryContext(ctx, query, qb.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []models.Price
+	for rows.Next() {
+		var price models.Price
+		if err := rows.Scan(scanSQLitePrice(&price)...); err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, rows.Err()
+}
+
+func (db *SQLiteDB) ValidateCategoryOwnership(ctx context.Context, categoryID, userID string) error {
+	if err := parseIDs(&categoryID, &userID); err != nil {
+		return err
+	}
+
+	query := `SELECT 1 FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	var exists bool
+	err := db.conn().QueryRowContext(ctx, query, categoryID, userID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCategoryNotOwned
+	}
+	return err
+}
+
+func (db *SQLiteDB) GetSummary(ctx context.Context, userID string, from, to *time.Time) (*models.Summary, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	qb := queryBuilder{sqlite: true}
+	applyTransactionFilter(&qb, userID, models.TransactionFilter{From: from, To: to})
+
+	// Amounts are stored rounded to the cent, so rounding the sum removes
+	// any floating point drift and matches Postgres' exact decimals.
+	query := `
+		SELECT
+			c.id as category_id,
+			COALESCE(c.name, 'Uncategorized') as category_name,
+			ROUND(SUM(t.amount), 2) as total
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+	` + qb.clause() + ` GROUP BY c.id, c.name ORDER BY category_name`
+
+	rows, err := db.conn().QueryContext(ctx, query, qb.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.CategorySummary
+	for rows.Next() {
+		var summary models.CategorySummary
+		if err := rows.Scan(&summary.CategoryID, &summary.CategoryName, &summary.Total); err != nil {
+			return nil, err
+		}
+		categories = append(categories, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	current := time.Now()
+	summary := &models.Summary{
+		UserID:     userID,
+		From:       current.AddDate(0, 0, -30),
+		To:         current,
+		Categories: categories,
+	}
+	if from != nil {
+		summary.From = *from
+	}
+	if to != nil {
+		summary.To = *to
+	}
+
+	return summary, nil
+}
+
+func (db *SQLiteDB) FindDuplicateTransactions(ctx context.Context, userID string, windowDays int) ([]models.DuplicatePair, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+		WHERE t.user_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.rowid
+	`
+	transactions, err := db.queryTransactions(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn().QueryContext(ctx, `SELECT transaction_id, duplicate_id FROM dismissed_duplicates WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dismissed := make(map[[2]string]bool)
+	for rows.Next() {
+		var key [2]string
+		if err := rows.Scan(&key[0], &key[1]); err != nil {
+			return nil, err
+		}
+		dismissed[key] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pairDuplicates(transactions, windowDays, func(a, b string) bool {
+		return dismissed[pairKey(a, b)]
+	}), nil
+}
+
+func (db *SQLiteDB) MergeDuplicateTransactions(ctx context.Context, userID, keepID, discardID string) (*models.Transaction, error) {
+	if err := parseIDs(&userID, &keepID, &discardID); err != nil {
+		return nil, err
+	}
+	if keepID == discardID {
+		return nil, ErrSameTransaction
+	}
+
+	var transaction *models.Transaction
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		keep, err := tx.getUserTransaction(ctx, userID, keepID)
+		if err != nil {
+			return err
+		}
+		discard, err := tx.getUserTransaction(ctx, userID, discardID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE transactions
+			SET category_id = COALESCE(category_id, (SELECT d.category_id FROM transactions d WHERE d.id = $2)),
+				description = COALESCE(NULLIF(description, ''), (SELECT d.description FROM transactions d WHERE d.id = $2))
+			WHERE id = $1 AND user_id = $3
+				AND EXISTS (SELECT 1 FROM transactions d WHERE d.id = $2 AND d.user_id = $3)
+		`
+		result, err := tx.conn().ExecContext(ctx, query, keepID, discardID, userID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrTransactionNotFound
+		}
+
+		transaction, err = tx.getTransaction(ctx, keepID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.publishEvent(ctx, userID, models.EventTransactionUpdated, transaction); err != nil {
+			return err
+		}
+		if err := tx.audit(ctx, userID, models.EntityTransaction, keepID, models.AuditUpdate, keep, transaction); err != nil {
+			return err
+		}
+		return tx.trashTransaction(ctx, discard)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (db *SQLiteDB) DismissDuplicate(ctx context.Context, userID, transactionID, duplicateID string) error {
+	if err := parseIDs(&userID, &transactionID, &duplicateID); err != nil {
+		return err
+	}
+	if transactionID == duplicateID {
+		return ErrSameTransaction
+	}
+
+	// The audit entry must be written with the dismissal.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		var owned int
+		err := tx.conn().QueryRowContext(ctx, `SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND id IN ($2, $3) AND deleted_at IS NULL`, userID, transactionID, duplicateID).Scan(&owned)
+		if err != nil {
+			return err
+		}
+		if owned != 2 {
+			return ErrTransactionNotFound
+		}
+
+		key := pairKey(transactionID, duplicateID)
+		query := `
+			INSERT INTO dismissed_duplicates (transaction_id, duplicate_id, user_id, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`
+		result, err := tx.conn().ExecContext(ctx, query, key[0], key[1], userID, sqliteTime(time.Now()))
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			// Already dismissed: nothing changed.
+			return err
+		}
+
+		dismissed := dismissedDuplicate{TransactionID: key[0], DuplicateID: key[1]}
+		return tx.audit(ctx, userID, models.EntityDismissedDuplicate, dismissed.id(), models.AuditCreate, nil, dismissed)
+	})
+}
+
+func (db *SQLiteDB) CreateWebhookSubscription(ctx context.Context, userID, url, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	subscription := models.WebhookSubscription{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: slices.Clone(eventTypes),
+		CreatedAt:  now(),
+	}
+	types, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The audit entry must be written with the subscription.
+	err = db.inTx(ctx, func(tx *SQLiteDB) error {
+		_, err := tx.conn().ExecContext(ctx,
+			`INSERT INTO webhook_subscriptions (id, user_id, url, secret, event_types, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			subscription.ID, subscription.UserID, subscription.URL, subscription.Secret, string(types), sqliteTime(subscription.CreatedAt),
+		)
+		if err != nil {
+			return wrapSQLiteError(err)
+		}
+		return tx.audit(ctx, userID, models.EntityWebhook, subscription.ID, models.AuditCreate, nil, subscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (db *SQLiteDB) ListWebhookSubscriptions(ctx context.Context, userID string) ([]models.WebhookSubscription, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, user_id, url, secret, event_types, created_at
+		FROM webhook_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, rowid DESC
+	`
+	rows, err := db.conn().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []models.WebhookSubscription
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		if err := rows.Scan(
+			&subscription.ID,
+			&subscription.UserID,
+			&subscription.URL,
+			&subscription.Secret,
+			scanJSON(&subscription.EventTypes),
+			scanTime(&subscription.CreatedAt),
+		); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (db *SQLiteDB) DeleteWebhookSubscription(ctx context.Context, userID, id string) error {
+	if err := parseIDs(&userID, &id); err != nil {
+		return err
+	}
+
+	// The audit entry must be written with the deletion.
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		query := `
+			DELETE FROM webhook_subscriptions
+			WHERE id = $1 AND user_id = $2
+			RETURNING id, user_id, url, secret, event_types, created_at
+		`
+		var subscription models.WebhookSubscription
+		err := tx.conn().QueryRowContext(ctx, query, id, userID).Scan(
+			&subscription.ID,
+			&subscription.UserID,
+			&subscription.URL,
+			&subscription.Secret,
+			scanJSON(&subscription.EventTypes),
+			scanTime(&subscription.CreatedAt),
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWebhookNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return tx.audit(ctx, userID, models.EntityWebhook, subscription.ID, models.AuditDelete, subscription, nil)
+	})
+}
+
+const sqliteWebhookDeliveryColumns = `
+	d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.response_status, d.last_error, d.last_attempt_at, d.next_attempt_at, d.created_at
+`
+
+func (db *SQLiteDB) ListWebhookDeliveries(ctx context.Context, userID, subscriptionID string) ([]models.WebhookDelivery, error) {
+	if err := parseIDs(&userID, &subscriptionID); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	err := db.conn().QueryRowContext(ctx,
+		`SELECT 1 FROM webhook_subscriptions WHERE id = $1 AND user_id = $2`,
+		subscriptionID, userID,
+	).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + sqliteWebhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		ORDER BY d.created_at DESC, d.rowid DESC
+	`
+	rows, err := db.conn().QueryContext(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(scanSQLiteWebhookDelivery(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (db *SQLiteDB) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutgoingWebhook, error) {
+	var webhooks []models.OutgoingWebhook
+	err := db.inTx(ctx, func(tx *SQLiteDB) error {
+		query := `
+			SELECT ` + sqliteWebhookDeliveryColumns + `, s.url, s.secret
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= $1
+			ORDER BY d.next_attempt_at, d.rowid
+			LIMIT $2
+		`
+		rows, err := tx.conn().QueryContext(ctx, query, sqliteTime(now), limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var webhook models.OutgoingWebhook
+			if err := rows.Scan(append(scanSQLiteWebhookDelivery(&webhook.Delivery), &webhook.URL, &webhook.Secret)...); err != nil {
+				return err
+			}
+			webhooks = append(webhooks, webhook)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		leased := now.Add(lease).Round(time.Microsecond).UTC()
+		for i := range webhooks {
+			webhooks[i].Delivery.NextAttemptAt = &leased
+			_, err := tx.conn().ExecContext(ctx,
+				`UPDATE webhook_deliveries SET next_attempt_at = $2 WHERE id = $1`,
+				webhooks[i].Delivery.ID, sqliteTime(leased),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (db *SQLiteDB) RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error {
+	if err := parseIDs(&id); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, response_status = $3, last_error = $4,
+			last_attempt_at = $5, next_attempt_at = $6
+		WHERE id = $1
+	`
+	status, next := attemptStatus(attempt)
+	var nextAttemptAt *string
+	if next != nil {
+		t := sqliteTime(*next)
+		nextAttemptAt = &t
+	}
+
+	result, err := db.conn().ExecContext(ctx, query, id, status, attempt.ResponseStatus, attempt.Error, sqliteTime(attempt.At), nextAttemptAt)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+func (db *SQLiteDB) ListEvents(ctx context.Context, userID string, afterID int64, limit int) ([]models.Event, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, user_id, type, data, created_at
+		FROM events
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+	rows, err := db.conn().QueryContext(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		var data string
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Type, &data, scanTime(&event.CreatedAt)); err != nil {
+			return nil, err
+		}
+		event.Data = json.RawMessage(data)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (db *SQLiteDB) LastEventID(ctx context.Context, userID string) (int64, error) {
+	if err := parseIDs(&userID); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := db.conn().QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM events WHERE user_id = $1`, userID).Scan(&id)
+	return id, err
+}
+
+// WatchEvents only sees events published through this process, which is
+// the only one that can write to the database file.
+func (db *SQLiteDB) WatchEvents(ctx context.Context, userID string) (<-chan struct{}, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+	return db.hub.watch(ctx, userID), nil
+}
+
+// publishEvent is DB.publishEvent for SQLite. Writers are serialized, so
+// ids follow commit order without a lock.
+func (db *SQLiteDB) publishEvent(ctx context.Context, userID, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn().ExecContext(ctx,
+		`INSERT INTO events (user_id, type, data, created_at) VALUES ($1, $2, $3, $4)`,
+		userID, eventType, string(payload), sqliteTime(now()),
+	)
+	if err != nil {
+		return wrapSQLiteError(err)
+	}
+	if db.published != nil {
+		*db.published = append(*db.published, userID)
+	} else {
+		db.hub.notify(userID)
+	}
+
+	if !slices.Contains(models.WebhookEventTypes, eventType) {
+		return nil
+	}
+	return db.enqueueWebhookEvent(ctx, userID, eventType, data)
+}
+
+// enqueueWebhookEvent is DB.enqueueWebhookEvent for SQLite.
+func (db *SQLiteDB) enqueueWebhookEvent(ctx context.Context, userID, eventType string, data any) error {
+	eventID, payload, err := newWebhookEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.conn().QueryContext(ctx, `
+		SELECT s.id FROM webhook_subscriptions s
+		WHERE s.user_id = $1 AND EXISTS (SELECT 1 FROM json_each(s.event_types) WHERE json_each.value = $2)
+	`, userID, eventType)
+	if err != nil {
+		return err
+	}
+	var subscriptionIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		subscriptionIDs = append(subscriptionIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	createdAt := sqliteTime(now())
+	for _, subscriptionID := range subscriptionIDs {
+		_, err := db.conn().ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, next_attempt_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+		`, uuid.NewString(), subscriptionID, eventID, eventType, string(payload), createdAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *SQLiteDB) ListAuditEntries(ctx context.Context, userID string, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if err := parseIDs(&userID); err != nil {
+		return nil, err
+	}
+
+	qb := queryBuilder{sqlite: true}
+	applyAuditFilter(&qb, userID, filter)
+	qb.args = append(qb.args, filter.Limit)
+
+	query := `
+		SELECT id, user_id, actor, request_id, entity, entity_id, action, before, after, created_at
+		FROM audit_log
+	` + qb.clause() + ` ORDER BY id DESC LIMIT $` + strconv.Itoa(len(qb.args))
+
+	rows, err := db.conn().QueryContext(ctx, query, qb.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after sql.NullString
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Actor, &e.RequestID, &e.Entity, &e.EntityID, &e.Action, &before, &after, scanTime(&e.CreatedAt)); err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// audit is DB.audit for SQLite.
+func (db *SQLiteDB) audit(ctx context.Context, userID, entity, entityID, action string, before, after any) error {
+	entry, err := newAuditEntry(ctx, userID, entity, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn().ExecContext(ctx, `
+		INSERT INTO audit_log (user_id, actor, request_id, entity, entity_id, action, before, after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, entry.UserID, entry.Actor, entry.RequestID, entry.Entity, entry.EntityID, entry.Action,
+		nullJSON(entry.Before), nullJSON(entry.After), sqliteTime(now()))
+	return wrapSQLiteError(err)
+}
+
+// nullJSON stores a JSON snapshot as text, and a missing one as NULL.
+func nullJSON(data json.RawMessage) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+func scanSQLiteWebhookDelivery(d *models.WebhookDelivery) []any {
+	return []any{
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		scanJSON(&d.Payload),
+		&d.Status,
+		&d.Attempts,
+		&d.ResponseStatus,
+		&d.LastError,
+		scanNullTime(&d.LastAttemptAt),
+		scanNullTime(&d.NextAttemptAt),
+		scanTime(&d.CreatedAt),
+	}
+}
+
+// WithTx runs fn in a transaction, committing if fn returns nil and rolling
+// back otherwise. Every method of the Database passed to fn runs in that
+// transaction; fn must not use db itself until WithTx returns. Transactions
+// take SQLite's write lock when they begin, so they run one at a time and
+// never need retrying. Called on the Database passed to fn, WithTx starts a
+// nested transaction (a savepoint) that can roll back on its own.
+func (db *SQLiteDB) WithTx(ctx context.Context, fn func(tx Database) error) error {
+	return db.inTx(ctx, func(tx *SQLiteDB) error {
+		return fn(tx)
+	})
+}
+
+func (db *SQLiteDB) inTx(ctx context.Context, fn func(tx *SQLiteDB) error) error {
+	if db.tx != nil {
+		savepoint := fmt.Sprintf("sp%d", db.depth+1)
+		if _, err := db.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			return err
+		}
+		if err := fn(&SQLiteDB{db: db.db, logger: db.logger, tx: db.tx, depth: db.depth + 1, hub: db.hub, published: db.published}); err != nil {
+			if _, rbErr := db.tx.ExecContext(ctx, "ROLLBACK TO "+savepoint); rbErr != nil {
+				return errors.Join(err, rbErr)
+			}
+			db.tx.ExecContext(ctx, "RELEASE "+savepoint)
+			return err
+		}
+		_, err := db.tx.ExecContext(ctx, "RELEASE "+savepoint)
+		return err
+	}
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var published []string
+	if err := fn(&SQLiteDB{db: db.db, logger: db.logger, tx: tx, hub: db.hub, published: &published}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	db.hub.notify(published...)
+	return nil
+}
+
+// sqliteTime formats t for storage. Times are stored in UTC at microsecond
+// precision, as Postgres keeps them.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Round(time.Microsecond).Format(sqliteTimeLayout)
+}
+
+// sqliteNullTime is sqliteTime for a nullable column.
+func sqliteNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return sqliteTime(*t)
+}
+
+// timeScanner scans a stored timestamp into t.
+type timeScanner struct {
+	t *time.Time
+}
+
+func scanTime(t *time.Time) sql.Scanner {
+	return timeScanner{t: t}
+}
+
+func (s timeScanner) Scan(src any) error {
+	str, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+	t, err := time.Parse(sqliteTimeLayout, str)
+	if err != nil {
+		return err
+	}
+	*s.t = t
+	return nil
+}
+
+// nullTimeScanner scans a stored timestamp, or NULL, into t.
+type nullTimeScanner struct {
+	t **time.Time
+}
+
+func scanNullTime(t **time.Time) sql.Scanner {
+	return nullTimeScanner{t: t}
+}
+
+func (s nullTimeScanner) Scan(src any) error {
+	if src == nil {
+		*s.t = nil
+		return nil
+	}
+	var t time.Time
+	if err := scanTime(&t).Scan(src); err != nil {
+		return err
+	}
+	*s.t = &t
+	return nil
+}
+
+// jsonScanner scans a JSON text column into v.
+type jsonScanner struct {
+	v any
+}
+
+func scanJSON(v any) sql.Scanner {
+	return jsonScanner{v: v}
+}
+
+func (s jsonScanner) Scan(src any) error {
+	str, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("cannot scan %T as JSON", src)
+	}
+	return json.Unmarshal([]byte(str), s.v)
+}
+
+func scanTransaction(t *models.Transaction) []any {
+	return []any{
+		&t.ID,
+		&t.UserID,
+		&t.CategoryID,
+		&t.Amount,
+		&t.Description,
+		scanTime(&t.OccurredAt),
+		scanTime(&t.CreatedAt),
+		&t.CategoryName,
+		scanNullTime(&t.DeletedAt),
+	}
+}
+
+// wrapSQLiteError is wrapPgError for SQLite. SQLite doesn't name the
+// constraint that failed, so unique violations are told apart by the
+// columns in the message, and a foreign key can only fail for a missing
+// user: categories are checked before a transaction refers to one.
+func wrapSQLiteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	message := sqliteErr.Error()
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		switch {
+		case strings.Contains(message, "users.email"):
+			return ErrDuplicateEmail.wrap(err)
+		case strings.Contains(message, "categories.user_id, categories.name"):
+			return ErrDuplicateCategory.wrap(err)
+		case strings.Contains(message, "goal_contributions.transaction_id"):
+			return ErrTransactionContributed.wrap(err)
+		case strings.Contains(message, "debt_payments.transaction_id"):
+			return ErrTransactionPaysDebt.wrap(err)
+		case strings.Contains(message, "securities.user_id, securities.symbol"):
+			return ErrSymbolTaken.wrap(err)
+		}
+		return &Error{Kind: ErrConflict, Code: "already_exists", Message: "record already exists", Err: err}
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return ErrUserNotFound.wrap(err)
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		switch {
+		case strings.Contains(message, "amount"), strings.Contains(message, "principal"), strings.Contains(message, "payment"):
+			return ErrInvalidAmount.wrap(err)
+		case strings.Contains(message, "annual_rate"):
+			return ErrInvalidRate.wrap(err)
+		case strings.Contains(message, "term_months"):
+			return ErrInvalidTerm.wrap(err)
+		case strings.Contains(message, "value"):
+			return ErrInvalidValue.wrap(err)
+		case strings.Contains(message, "type IN"):
+			return ErrInvalidAccountType.wrap(err)
+		case strings.Contains(message, "side IN"):
+			return ErrInvalidSide.wrap(err)
+		case strings.Contains(message, "quantity"):
+			return ErrInvalidQuantity.wrap(err)
+		case strings.Contains(message, "price"):
+			return ErrInvalidPrice.wrap(err)
+		case strings.Contains(message, "fees"):
+			return ErrInvalidFees.wrap(err)
+		}
+		return &Error{Kind: ErrValidation, Code: "constraint_violation", Message: "value violates a constraint", Err: err}
+	}
+	return err
+}
+
+// now is the creation time for new rows, at the precision they are stored.
+func now() time.Time {
+	return time.Now().Round(time.Microsecond)
+}
@@ -2,40 +2,25 @@ package db
 
 import (
 	"context"
-	"strconv"
 	"time"
 
 	"fintrack-go/internal/models"
 )
 
 func (db *DB) GetSummary(ctx context.Context, userID string, from, to *time.Time) (*models.Summary, error) {
+	var qb queryBuilder
+	applyTransactionFilter(&qb, userID, models.TransactionFilter{From: from, To: to})
+
 	query := `
 		SELECT 
 			COALESCE(c.id, NULL) as category_id,
 			COALESCE(c.name, 'Uncategorized') as category_name,
 			COALESCE(SUM(t.amount), 0) as total
 		FROM transactions t
-		LEFT JOIN categories c ON t.category_id = c.id
-		WHERE t.user_id = $1
-	`
-	args := []interface{}{userID}
-	argCount := 1
-	
-	if from != nil {
-		argCount++
-		query += ` AND t.occurred_at >= $` + strconv.Itoa(argCount)
-		args = append(args, *from)
-	}
-	
-	if to != nil {
-		argCount++
-		query += ` AND t.occurred_at <= $` + strconv.Itoa(argCount)
-		args = append(args, *to)
-	}
-	
-	query += ` GROUP BY c.id, c.name ORDER BY category_name`
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+	` + qb.clause() + ` GROUP BY c.id, c.name ORDER BY category_name`
 	
-	rows, err := db.pool.Query(ctx, query, args...)
+	rows, err := db.conn().Query(ctx, query, qb.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracer(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := newQueryTracer(provider)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+	acquireCtx := tracer.TraceAcquireStart(ctx, nil, pgxpool.TraceAcquireStartData{})
+	tracer.TraceAcquireEnd(acquireCtx, nil, pgxpool.TraceAcquireEndData{})
+
+	sql := "\n\t\tselect id FROM users WHERE email = $1"
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{"secret@example.com"}})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: errors.New("boom")})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	acquire, query := spans[0], spans[1]
+	assert.Equal(t, "pool.acquire", acquire.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), acquire.Parent().SpanID())
+
+	assert.Equal(t, "SELECT", query.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Contains(t, query.Attributes(), attribute.String("db.query.text", sql))
+	assert.Equal(t, codes.Error, query.Status().Code)
+	for _, attr := range query.Attributes() {
+		assert.NotContains(t, attr.Value.Emit(), "secret@example.com", "query arguments must not be recorded")
+	}
+}
+
+func TestSQLOperation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT 1", "SELECT"},
+		{"\n\t\tinsert into users (email) VALUES ($1)", "INSERT"},
+		{"begin", "BEGIN"},
+		{"   ", "QUERY"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sqlOperation(tt.sql), tt.sql)
+	}
+}
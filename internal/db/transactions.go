@@ -3,25 +3,35 @@ package db
 import (
 	"context"
 	"errors"
-	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"fintrack-go/internal/models"
 )
 
 func (db *DB) CreateTransaction(ctx context.Context, userID string, categoryID *string, amount float64, description *string, occurredAt time.Time) (*models.Transaction, error) {
+	if db.tx == nil {
+		// The ownership check and the insert must see the same category, and
+		// the event and audit entry must be recorded with the transaction.
+		var transaction *models.Transaction
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			transaction, err = tx.CreateTransaction(ctx, userID, categoryID, amount, description, occurredAt)
+			return err
+		})
+		return transaction, err
+	}
+
 	if categoryID != nil {
 		if err := db.ValidateCategoryOwnership(ctx, *categoryID, userID); err != nil {
-			return nil, errors.New("category does not belong to user")
+			return nil, err
 		}
 	}
 	
 	query := `INSERT INTO transactions (user_id, category_id, amount, description, occurred_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, category_id, amount, description, occurred_at, created_at`
 	
 	var transaction models.Transaction
-	err := db.pool.QueryRow(ctx, query, userID, categoryID, amount, description, occurredAt).Scan(
+	err := db.conn().QueryRow(ctx, query, userID, categoryID, amount, description, occurredAt).Scan(
 		&transaction.ID,
 		&transaction.UserID,
 		&transaction.CategoryID,
@@ -31,44 +41,32 @@ func (db *DB) CreateTransaction(ctx context.Context, userID string, categoryID *
 		&transaction.CreatedAt,
 	)
 	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok {
-			if pgErr.Code == "23503" {
-				return nil, errors.New("user not found")
-			}
-		}
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.publishEvent(ctx, userID, models.EventTransactionCreated, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.audit(ctx, userID, models.EntityTransaction, transaction.ID, models.AuditCreate, nil, transaction); err != nil {
 		return nil, err
 	}
 	
 	return &transaction, nil
 }
 
-func (db *DB) ListTransactions(ctx context.Context, userID string, from, to *time.Time) ([]models.Transaction, error) {
+func (db *DB) ListTransactions(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
+	var qb queryBuilder
+	applyTransactionFilter(&qb, userID, filter)
+
 	query := `
 		SELECT 
 			t.id, t.user_id, t.category_id, t.amount, t.description, t.occurred_at, t.created_at,
 			c.name as category_name
 		FROM transactions t
-		LEFT JOIN categories c ON t.category_id = c.id
-		WHERE t.user_id = $1
-	`
-	args := []interface{}{userID}
-	argCount := 1
-	
-	if from != nil {
-		argCount++
-		query += ` AND t.occurred_at >= $` + strconv.Itoa(argCount)
-		args = append(args, *from)
-	}
-	
-	if to != nil {
-		argCount++
-		query += ` AND t.occurred_at <= $` + strconv.Itoa(argCount)
-		args = append(args, *to)
-	}
-	
-	query += ` ORDER BY t.occurred_at DESC`
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+	` + qb.clause() + ` ORDER BY t.occurred_at DESC`
 	
-	rows, err := db.pool.Query(ctx, query, args...)
+	rows, err := db.conn().Query(ctx, query, qb.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -103,13 +101,13 @@ func (db *DB) GetTransactionByID(ctx context.Context, id string) (*models.Transa
 			t.id, t.user_id, t.category_id, t.amount, t.description, t.occurred_at, t.created_at,
 			c.name as category_name
 		FROM transactions t
-		LEFT JOIN categories c ON t.category_id = c.id
-		WHERE t.id = $1
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+		WHERE t.id = $1 AND t.deleted_at IS NULL
 	`
 	
 	var transaction models.Transaction
 	var categoryName *string
-	err := db.pool.QueryRow(ctx, query, id).Scan(
+	err := db.conn().QueryRow(ctx, query, id).Scan(
 		&transaction.ID,
 		&transaction.UserID,
 		&transaction.CategoryID,
@@ -119,11 +117,11 @@ func (db *DB) GetTransactionByID(ctx context.Context, id string) (*models.Transa
 		&transaction.CreatedAt,
 		&categoryName,
 	)
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrTransactionNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, wrapPgError(err)
 	}
 	transaction.CategoryName = categoryName
 	
@@ -131,16 +129,92 @@ func (db *DB) GetTransactionByID(ctx context.Context, id string) (*models.Transa
 }
 
 func (db *DB) ValidateCategoryOwnership(ctx context.Context, categoryID, userID string) error {
-	query := `SELECT 1 FROM categories WHERE id = $1 AND user_id = $2`
+	query := `SELECT 1 FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 	
 	var exists bool
-	err := db.pool.QueryRow(ctx, query, categoryID, userID).Scan(&exists)
-	if err == pgx.ErrNoRows {
-		return errors.New("category does not belong to user")
+	err := db.conn().QueryRow(ctx, query, categoryID, userID).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrCategoryNotOwned
 	}
 	if err != nil {
-		return err
+		return wrapPgError(err)
 	}
 	
 	return nil
 }
+
+func (db *DB) DeleteTransaction(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The event and audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteTransaction(ctx, userID, id)
+		})
+	}
+
+	transaction, err := db.lockUserTransaction(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return db.trashTransaction(ctx, transaction)
+}
+
+// trashTransaction moves a transaction, locked by lockUserTransaction, to the
+// trash. Callers run it in a transaction.
+func (db *DB) trashTransaction(ctx context.Context, transaction *models.Transaction) error {
+	trashed := *transaction
+	err := db.conn().QueryRow(ctx, `UPDATE transactions SET deleted_at = NOW() WHERE id = $1 RETURNING deleted_at`, transaction.ID).Scan(&trashed.DeletedAt)
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	if err := db.publishEvent(ctx, transaction.UserID, models.EventTransactionDeleted, models.DeletedResource{ID: transaction.ID}); err != nil {
+		return err
+	}
+	return db.audit(ctx, transaction.UserID, models.EntityTransaction, transaction.ID, models.AuditDelete, transaction, trashed)
+}
+
+func (db *DB) RestoreTransaction(ctx context.Context, userID, id string) (*models.Transaction, error) {
+	if db.tx == nil {
+		// The event and audit entry must be recorded with the restore.
+		var transaction *models.Transaction
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			transaction, err = tx.RestoreTransaction(ctx, userID, id)
+			return err
+		})
+		return transaction, err
+	}
+
+	// old is the row as it was before the update.
+	query := `
+		UPDATE transactions t SET deleted_at = NULL
+		FROM transactions old
+		WHERE t.id = old.id AND t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NOT NULL
+		RETURNING old.deleted_at
+	`
+
+	var deletedAt time.Time
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(&deletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	transaction, err := db.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *transaction
+	before.DeletedAt = &deletedAt
+
+	if err := db.publishEvent(ctx, userID, models.EventTransactionRestored, transaction); err != nil {
+		return nil, err
+	}
+	if err := db.audit(ctx, userID, models.EntityTransaction, transaction.ID, models.AuditUpdate, before, transaction); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
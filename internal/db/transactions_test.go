@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"fintrack-go/internal/models"
 	"fintrack-go/tests/dbtestutil"
 )
 
@@ -138,7 +139,7 @@ func TestListTransactions(t *testing.T) {
 		_, err = db.CreateTransaction(ctx, user.ID, &category.ID, 30.0, nil, now.Add(-1*time.Hour))
 		require.NoError(t, err)
 
-		transactions, err := db.ListTransactions(ctx, user.ID, nil, nil)
+		transactions, err := db.ListTransactions(ctx, user.ID, models.TransactionFilter{})
 		require.NoError(t, err)
 		assert.Len(t, transactions, 3)
 		assert.Equal(t, 30.0, transactions[0].Amount)
@@ -157,7 +158,7 @@ func TestListTransactions(t *testing.T) {
 		user, err := db.CreateUser(ctx, "empty-list@example.com")
 		require.NoError(t, err)
 
-		transactions, err := db.ListTransactions(ctx, user.ID, nil, nil)
+		transactions, err := db.ListTransactions(ctx, user.ID, models.TransactionFilter{})
 		require.NoError(t, err)
 		assert.Empty(t, transactions)
 	})
@@ -186,7 +187,7 @@ func TestListTransactions(t *testing.T) {
 		_, err = db.CreateTransaction(ctx, user.ID, nil, 30.0, nil, now.Add(-12*time.Hour))
 		require.NoError(t, err)
 
-		transactions, err := db.ListTransactions(ctx, user.ID, &startDate, &endDate)
+		transactions, err := db.ListTransactions(ctx, user.ID, models.TransactionFilter{From: &startDate, To: &endDate})
 		require.NoError(t, err)
 		assert.Len(t, transactions, 1)
 		assert.Equal(t, 20.0, transactions[0].Amount)
@@ -212,11 +213,108 @@ func TestListTransactions(t *testing.T) {
 		_, err = db.CreateTransaction(ctx, user2.ID, nil, 20.0, nil, time.Now())
 		require.NoError(t, err)
 
-		user1Txns, err := db.ListTransactions(ctx, user1.ID, nil, nil)
+		user1Txns, err := db.ListTransactions(ctx, user1.ID, models.TransactionFilter{})
 		require.NoError(t, err)
 		assert.Len(t, user1Txns, 1)
 		assert.Equal(t, 10.0, user1Txns[0].Amount)
 	})
+
+	t.Run("amount range filter", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		user, err := db.CreateUser(ctx, "amount-filter@example.com")
+		require.NoError(t, err)
+
+		for _, amount := range []float64{5.0, 15.0, 25.0, 35.0} {
+			_, err = db.CreateTransaction(ctx, user.ID, nil, amount, nil, time.Now())
+			require.NoError(t, err)
+		}
+
+		minAmount := 15.0
+		maxAmount := 25.0
+		transactions, err := db.ListTransactions(ctx, user.ID, models.TransactionFilter{MinAmount: &minAmount, MaxAmount: &maxAmount})
+		require.NoError(t, err)
+		assert.Len(t, transactions, 2)
+		for _, txn := range transactions {
+			assert.GreaterOrEqual(t, txn.Amount, minAmount)
+			assert.LessOrEqual(t, txn.Amount, maxAmount)
+		}
+	})
+
+	t.Run("category filters", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		user, err := db.CreateUser(ctx, "category-filter@example.com")
+		require.NoError(t, err)
+
+		food, err := db.CreateCategory(ctx, user.ID, "Food")
+		require.NoError(t, err)
+		transport, err := db.CreateCategory(ctx, user.ID, "Transport")
+		require.NoError(t, err)
+		rent, err := db.CreateCategory(ctx, user.ID, "Rent")
+		require.NoError(t, err)
+
+		_, err = db.CreateTransaction(ctx, user.ID, &food.ID, 10.0, nil, time.Now())
+		require.NoError(t, err)
+		_, err = db.CreateTransaction(ctx, user.ID, &transport.ID, 20.0, nil, time.Now())
+		require.NoError(t, err)
+		_, err = db.CreateTransaction(ctx, user.ID, &rent.ID, 30.0, nil, time.Now())
+		require.NoError(t, err)
+		_, err = db.CreateTransaction(ctx, user.ID, nil, 40.0, nil, time.Now())
+		require.NoError(t, err)
+
+		transactions, err := db.ListTransactions(ctx, user.ID, models.TransactionFilter{CategoryIDs: []string{food.ID, transport.ID}})
+		require.NoError(t, err)
+		assert.Len(t, transactions, 2)
+
+		transactions, err = db.ListTransactions(ctx, user.ID, models.TransactionFilter{Uncategorized: true})
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+		assert.Nil(t, transactions[0].CategoryID)
+		assert.Equal(t, 40.0, transactions[0].Amount)
+	})
+
+	t.Run("has description filter", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := dbtestutil.CreateTestContext(t)
+		pool := dbtestutil.SetupTestDB(t)
+		defer dbtestutil.TeardownTestDB(t, pool)
+
+		db := &DB{pool: pool}
+		user, err := db.CreateUser(ctx, "desc-filter@example.com")
+		require.NoError(t, err)
+
+		desc := "Groceries"
+		empty := ""
+		_, err = db.CreateTransaction(ctx, user.ID, nil, 10.0, &desc, time.Now())
+		require.NoError(t, err)
+		_, err = db.CreateTransaction(ctx, user.ID, nil, 20.0, &empty, time.Now())
+		require.NoError(t, err)
+		_, err = db.CreateTransaction(ctx, user.ID, nil, 30.0, nil, time.Now())
+		require.NoError(t, err)
+
+		hasDescription := true
+		transactions, err := db.ListTransactions(ctx, user.ID, models.TransactionFilter{HasDescription: &hasDescription})
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+		assert.Equal(t, &desc, transactions[0].Description)
+
+		hasDescription = false
+		transactions, err = db.ListTransactions(ctx, user.ID, models.TransactionFilter{HasDescription: &hasDescription})
+		require.NoError(t, err)
+		assert.Len(t, transactions, 2)
+	})
 }
 
 func TestGetTransactionByID(t *testing.T) {
@@ -284,7 +382,7 @@ func TestTransactionSQLInjection(t *testing.T) {
 		db := &DB{pool: pool}
 		maliciousUserID := "'; SELECT * FROM users; --"
 
-		transactions, err := db.ListTransactions(ctx, maliciousUserID, nil, nil)
+		transactions, err := db.ListTransactions(ctx, maliciousUserID, models.TransactionFilter{})
 		require.NoError(t, err)
 		assert.Empty(t, transactions)
 	})
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"fintrack-go/internal/models"
+)
+
+func (db *DB) ListTrash(ctx context.Context, userID string) (*models.Trash, error) {
+	trash := &models.Trash{}
+
+	query := `
+		SELECT id, user_id, name, created_at, deleted_at
+		FROM categories
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+	rows, err := db.conn().Query(ctx, query, userID)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.UserID, &category.Name, &category.CreatedAt, &category.DeletedAt); err != nil {
+			return nil, err
+		}
+		trash.Categories = append(trash.Categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT
+			t.id, t.user_id, t.category_id, t.amount, t.description, t.occurred_at, t.created_at,
+			c.name as category_name, t.deleted_at
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id AND c.deleted_at IS NULL
+		WHERE t.user_id = $1 AND t.deleted_at IS NOT NULL
+		ORDER BY t.deleted_at DESC
+	`
+	rows, err = db.conn().Query(ctx, query, userID)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.ID, &t.UserID, &t.CategoryID, &t.Amount, &t.Description, &t.OccurredAt, &t.CreatedAt, &t.CategoryName, &t.DeletedAt); err != nil {
+			return nil, err
+		}
+		trash.Transactions = append(trash.Transactions, t)
+	}
+
+	return trash, rows.Err()
+}
+
+func (db *DB) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if db.tx == nil {
+		// The audit entries must be recorded with the deletions.
+		var purged int
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			purged, err = tx.PurgeDeleted(ctx, deletedBefore)
+			return err
+		})
+		return purged, err
+	}
+
+	// Transactions go first, so that those purged with their category are
+	// not set uncategorized for nothing.
+	rows, err := db.conn().Query(ctx, `
+		DELETE FROM transactions
+		WHERE deleted_at < $1
+		RETURNING id, user_id, category_id, amount, description, occurred_at, created_at, deleted_at
+	`, deletedBefore)
+	if err != nil {
+		return 0, wrapPgError(err)
+	}
+	var transactions []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.ID, &t.UserID, &t.CategoryID, &t.Amount, &t.Description, &t.OccurredAt, &t.CreatedAt, &t.DeletedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		transactions = append(transactions, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, wrapPgError(err)
+	}
+
+	rows, err = db.conn().Query(ctx, `
+		DELETE FROM categories
+		WHERE deleted_at < $1
+		RETURNING id, user_id, name, created_at, deleted_at
+	`, deletedBefore)
+	if err != nil {
+		return 0, wrapPgError(err)
+	}
+	var categories []models.Category
+	for rows.Next() {
+		var c models.Category
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Name, &c.CreatedAt, &c.DeletedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		categories = append(categories, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, wrapPgError(err)
+	}
+
+	for _, t := range transactions {
+		if err := db.audit(ctx, t.UserID, models.EntityTransaction, t.ID, models.AuditDelete, t, nil); err != nil {
+			return 0, err
+		}
+	}
+	for _, c := range categories {
+		if err := db.audit(ctx, c.UserID, models.EntityCategory, c.ID, models.AuditDelete, c, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(transactions) + len(categories), nil
+}
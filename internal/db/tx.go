@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxTxAttempts bounds how often WithTx runs a transaction that keeps
+// failing to serialize before giving up with ErrSerializationFailure.
+const maxTxAttempts = 10
+
+// WithTx runs fn in a serializable transaction, committing if fn returns nil
+// and rolling back otherwise. Every method of the Database passed to fn runs
+// in that transaction; fn must not use db itself until WithTx returns.
+//
+// A transaction that fails because it conflicted with a concurrent one is
+// retried, so fn may be called more than once and should have no effects
+// outside the database. Called on the Database passed to fn, WithTx starts a
+// nested transaction (a savepoint) that can roll back on its own.
+func (db *DB) WithTx(ctx context.Context, fn func(tx Database) error) error {
+	if db.tx != nil {
+		return pgx.BeginFunc(ctx, db.tx, func(tx pgx.Tx) error {
+			return fn(&DB{pool: db.pool, logger: db.logger, tx: tx, listener: db.listener})
+		})
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginTxFunc(ctx, db.pool, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(tx pgx.Tx) error {
+			return fn(&DB{pool: db.pool, logger: db.logger, tx: tx, listener: db.listener})
+		})
+		if !isSerializationFailure(err) {
+			return err
+		}
+		if attempt == maxTxAttempts {
+			return fmt.Errorf("%w: %w", ErrSerializationFailure, err)
+		}
+
+		db.logger.Debug().Err(err).Int("attempt", attempt).Msg("Retrying transaction after serialization failure")
+		if err := sleepBeforeRetry(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// isSerializationFailure reports whether err aborted a transaction only
+// because of a concurrent one, so that running it again can succeed.
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// serialization_failure and deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// sleepBeforeRetry waits a jittered, growing delay so that transactions
+// which conflicted don't collide again straight away.
+func sleepBeforeRetry(ctx context.Context, attempt int) error {
+	delay := time.Duration(attempt) * 5 * time.Millisecond
+	delay += rand.N(delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -2,23 +2,33 @@ package db
 
 import (
 	"context"
+	"errors"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"fintrack-go/internal/models"
 )
 
 func (db *DB) CreateUser(ctx context.Context, email string) (*models.User, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the user.
+		var user *models.User
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			user, err = tx.CreateUser(ctx, email)
+			return err
+		})
+		return user, err
+	}
+
 	query := `INSERT INTO users (email) VALUES ($1) RETURNING id, email, created_at`
 	
 	var user models.User
-	err := db.pool.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	err := db.conn().QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.CreatedAt)
 	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok {
-			if pgErr.Code == "23505" {
-				return nil, ErrDuplicateEmail
-			}
-		}
+		return nil, wrapPgError(err)
+	}
+
+	if err := db.audit(ctx, user.ID, models.EntityUser, user.ID, models.AuditCreate, nil, user); err != nil {
 		return nil, err
 	}
 	
@@ -29,12 +39,12 @@ func (db *DB) GetUserByID(ctx context.Context, id string) (*models.User, error)
 	query := `SELECT id, email, created_at FROM users WHERE id = $1`
 	
 	var user models.User
-	err := db.pool.QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.CreatedAt)
-	if err == pgx.ErrNoRows {
+	err := db.conn().QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrUserNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, wrapPgError(err)
 	}
 	
 	return &user, nil
@@ -44,13 +54,40 @@ func (db *DB) GetUserByEmail(ctx context.Context, email string) (*models.User, e
 	query := `SELECT id, email, created_at FROM users WHERE email = $1`
 	
 	var user models.User
-	err := db.pool.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.CreatedAt)
-	if err == pgx.ErrNoRows {
+	err := db.conn().QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrUserNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, wrapPgError(err)
 	}
 	
 	return &user, nil
 }
+
+func (db *DB) DeleteUser(ctx context.Context, id string) error {
+	if db.tx == nil {
+		// The redaction and audit entry must be written with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteUser(ctx, id)
+		})
+	}
+
+	// Everything the user owns goes with them, by ON DELETE CASCADE.
+	tag, err := db.conn().Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return wrapPgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	query := `
+		UPDATE audit_log SET before = NULL, after = NULL, request_id = NULL, actor = $2
+		WHERE user_id = $1
+	`
+	if _, err := db.conn().Exec(ctx, query, id, RedactedActor); err != nil {
+		return wrapPgError(err)
+	}
+	return db.audit(ctx, id, models.EntityUser, id, models.AuditDelete, nil, nil)
+}
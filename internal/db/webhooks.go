@@ -0,0 +1,274 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"fintrack-go/internal/models"
+)
+
+func (db *DB) CreateWebhookSubscription(ctx context.Context, userID, url, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	if db.tx == nil {
+		// The audit entry must be recorded with the subscription.
+		var subscription *models.WebhookSubscription
+		err := db.WithTx(ctx, func(tx Database) error {
+			var err error
+			subscription, err = tx.CreateWebhookSubscription(ctx, userID, url, secret, eventTypes)
+			return err
+		})
+		return subscription, err
+	}
+
+	query := `
+		INSERT INTO webhook_subscriptions (user_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, url, secret, event_types, created_at
+	`
+
+	var subscription models.WebhookSubscription
+	err := db.conn().QueryRow(ctx, query, userID, url, secret, eventTypes).Scan(
+		&subscription.ID,
+		&subscription.UserID,
+		&subscription.URL,
+		&subscription.Secret,
+		&subscription.EventTypes,
+		&subscription.CreatedAt,
+	)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	// The secret is left out of the snapshot, as it is of API responses.
+	if err := db.audit(ctx, userID, models.EntityWebhook, subscription.ID, models.AuditCreate, nil, subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (db *DB) ListWebhookSubscriptions(ctx context.Context, userID string) ([]models.WebhookSubscription, error) {
+	query := `
+		SELECT id, user_id, url, secret, event_types, created_at
+		FROM webhook_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+	`
+
+	rows, err := db.conn().Query(ctx, query, userID)
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+	defer rows.Close()
+
+	var subscriptions []models.WebhookSubscription
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		if err := rows.Scan(
+			&subscription.ID,
+			&subscription.UserID,
+			&subscription.URL,
+			&subscription.Secret,
+			&subscription.EventTypes,
+			&subscription.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (db *DB) DeleteWebhookSubscription(ctx context.Context, userID, id string) error {
+	if db.tx == nil {
+		// The audit entry must be recorded with the deletion.
+		return db.WithTx(ctx, func(tx Database) error {
+			return tx.DeleteWebhookSubscription(ctx, userID, id)
+		})
+	}
+
+	query := `
+		DELETE FROM webhook_subscriptions
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, user_id, url, secret, event_types, created_at
+	`
+	var subscription models.WebhookSubscription
+	err := db.conn().QueryRow(ctx, query, id, userID).Scan(
+		&subscription.ID,
+		&subscription.UserID,
+		&subscription.URL,
+		&subscription.Secret,
+		&subscription.EventTypes,
+		&subscription.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		return wrapPgError(err)
+	}
+
+	return db.audit(ctx, userID, models.EntityWebhook, subscription.ID, models.AuditDelete, subscription, nil)
+}
+
+const webhookDeliveryColumns = `
+	d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.response_status, d.last_error, d.last_attempt_at, d.next_attempt_at, d.created_at
+`
+
+func scanWebhookDelivery(d *models.WebhookDelivery) []any {
+	return []any{
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.ResponseStatus,
+		&d.LastError,
+		&d.LastAttemptAt,
+		&d.NextAttemptAt,
+		&d.CreatedAt,
+	}
+}
+
+func (db *DB) ListWebhookDeliveries(ctx context.Context, userID, subscriptionID string) ([]models.WebhookDelivery, error) {
+	var exists bool
+	err := db.conn().QueryRow(ctx,
+		`SELECT true FROM webhook_subscriptions WHERE id = $1 AND user_id = $2`,
+		subscriptionID, userID,
+	).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, wrapPgError(err)
+	}
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		ORDER BY d.created_at DESC, d.id
+	`
+	rows, err := db.conn().Query(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(scanWebhookDelivery(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (db *DB) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutgoingWebhook, error) {
+	// SKIP LOCKED lets dispatchers claim concurrently without waiting for
+	// or double-sending each other's deliveries.
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `, s.url, s.secret
+	`
+
+	rows, err := db.conn().Query(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.OutgoingWebhook
+	for rows.Next() {
+		var webhook models.OutgoingWebhook
+		if err := rows.Scan(append(scanWebhookDelivery(&webhook.Delivery), &webhook.URL, &webhook.Secret)...); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (db *DB) RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, response_status = $3, last_error = $4,
+			last_attempt_at = $5, next_attempt_at = $6
+		WHERE id = $1
+	`
+
+	status, next := attemptStatus(attempt)
+	tag, err := db.conn().Exec(ctx, query, id, status, attempt.ResponseStatus, attempt.Error, attempt.At, next)
+	if err != nil {
+		return wrapPgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+// enqueueWebhookEvent adds a delivery of the event for each of the user's
+// subscriptions to its type. Callers run it in the transaction making the
+// change, so the event is recorded if and only if the change is.
+func (db *DB) enqueueWebhookEvent(ctx context.Context, userID, eventType string, data any) error {
+	eventID, payload, err := newWebhookEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at)
+		SELECT id, $2, $3, $4, NOW()
+		FROM webhook_subscriptions
+		WHERE user_id = $1 AND $3 = ANY(event_types)
+	`
+	_, err = db.conn().Exec(ctx, query, userID, eventID, eventType, payload)
+	return wrapPgError(err)
+}
+
+// newWebhookEvent returns the id and body of a new event about data.
+func newWebhookEvent(eventType string, data any) (string, []byte, error) {
+	event := models.WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: now(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	return event.ID, payload, err
+}
+
+// attemptStatus returns the status and next attempt time a delivery has
+// after attempt.
+func attemptStatus(attempt models.WebhookAttempt) (string, *time.Time) {
+	switch {
+	case attempt.Delivered:
+		return models.DeliveryDelivered, nil
+	case attempt.NextAttemptAt != nil:
+		return models.DeliveryPending, attempt.NextAttemptAt
+	default:
+		return models.DeliveryFailed, nil
+	}
+}
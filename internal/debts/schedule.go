@@ -0,0 +1,257 @@
+// Package debts builds the amortisation schedules of debts, matches their
+// payments to installments and projects when they will be paid off.
+package debts
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/money"
+)
+
+// MaxScenarios is the most extra monthly amounts a report projects.
+const MaxScenarios = 5
+
+// Payment returns the monthly installment that repays principal in term
+// months at annualRate percent.
+func Payment(principal, annualRate float64, term int) float64 {
+	r := monthlyRate(annualRate)
+	if r == 0 {
+		return money.Round(principal / float64(term))
+	}
+	return money.Round(principal * r / (1 - math.Pow(1+r, -float64(term))))
+}
+
+// Interest returns a month's interest on balance at annualRate percent.
+func Interest(balance, annualRate float64) float64 {
+	return money.FromCents(interestOn(money.ToCents(balance), monthlyRate(annualRate)))
+}
+
+// Schedule returns debt's amortisation schedule as of asOf, with each of
+// the payments made by then matched to the installment whose due date is
+// nearest.
+func Schedule(debt models.Debt, payments []models.DebtPayment, asOf time.Time) models.DebtSchedule {
+	l := apply(debt, payments, asOf)
+
+	schedule := models.DebtSchedule{
+		DebtID:       debt.ID,
+		Installments: l.installments,
+		AsOf:         asOf,
+	}
+	var interest int64
+	for _, installment := range l.installments {
+		interest += money.ToCents(installment.Interest)
+	}
+	schedule.TotalInterest = money.FromCents(interest)
+	return schedule
+}
+
+// Report reports how much of debt's principal and interest its payments
+// have paid as of asOf, and when it will be paid off without extra payments
+// and with each of extras paid on top of every installment.
+func Report(debt models.Debt, payments []models.DebtPayment, asOf time.Time, extras []float64) models.DebtReport {
+	l := apply(debt, payments, asOf)
+
+	report := models.DebtReport{
+		DebtID:        debt.ID,
+		PrincipalPaid: money.FromCents(l.principalPaid),
+		InterestPaid:  money.FromCents(l.interestPaid),
+		Balance:       money.FromCents(l.balance),
+		InterestDue:   money.FromCents(l.interestDue),
+		Scenarios:     []models.PayoffScenario{},
+		AsOf:          asOf,
+	}
+	for _, installment := range l.installments {
+		switch installment.Status {
+		case models.InstallmentPaid:
+			report.InstallmentsPaid++
+		case models.InstallmentOverdue:
+			report.InstallmentsOverdue++
+		}
+	}
+
+	if l.balance == 0 {
+		report.PaidOff = true
+		report.PaidOffAt = l.paidOffAt
+		return report
+	}
+
+	base := project(debt, l, 0)
+	report.Scenarios = append(report.Scenarios, base)
+	for _, extra := range extras {
+		scenario := project(debt, l, money.ToCents(extra))
+		scenario.InterestSaved = money.Round(base.InterestRemaining - scenario.InterestRemaining)
+		scenario.InstallmentsSaved = base.RemainingInstallments - scenario.RemainingInstallments
+		report.Scenarios = append(report.Scenarios, scenario)
+	}
+	return report
+}
+
+// ledger is a debt's payments applied to its installments. Amounts are in
+// cents.
+type ledger struct {
+	installments []models.Installment
+	balance      int64
+	interestDue  int64
+	// charged is how many installments interest has been charged for.
+	charged       int
+	principalPaid int64
+	interestPaid  int64
+	paidOffAt     *time.Time
+}
+
+// apply matches the payments made by asOf to debt's installments and
+// applies them in order: each installment due by then, or paid early,
+// charges a month's interest on the balance, and its payments pay the
+// interest due before the principal.
+func apply(debt models.Debt, payments []models.DebtPayment, asOf time.Time) ledger {
+	r := monthlyRate(debt.AnnualRate)
+	l := ledger{
+		installments: plan(debt),
+		balance:      money.ToCents(debt.Principal),
+	}
+
+	sorted := slices.Clone(payments)
+	slices.SortStableFunc(sorted, func(a, b models.DebtPayment) int {
+		return a.PaidAt.Compare(b.PaidAt)
+	})
+	paid := make([]int64, len(l.installments))
+	made := make([][]models.DebtPayment, len(l.installments))
+	for _, p := range sorted {
+		if p.PaidAt.After(asOf) {
+			continue
+		}
+		i := nearest(l.installments, p.PaidAt)
+		paid[i] += money.ToCents(p.Amount)
+		made[i] = append(made[i], p)
+		l.installments[i].PaymentIDs = append(l.installments[i].PaymentIDs, p.ID)
+	}
+
+	for i, installment := range l.installments {
+		if l.balance == 0 || (installment.DueDate.After(asOf) && len(made[i]) == 0) {
+			break
+		}
+		l.interestDue += interestOn(l.balance, r)
+		l.charged++
+		for _, p := range made[i] {
+			amount := money.ToCents(p.Amount)
+			toInterest := min(amount, l.interestDue)
+			toPrincipal := min(amount-toInterest, l.balance)
+			l.interestDue -= toInterest
+			l.interestPaid += toInterest
+			l.balance -= toPrincipal
+			l.principalPaid += toPrincipal
+			if l.balance == 0 {
+				paidOffAt := p.PaidAt
+				l.paidOffAt = &paidOffAt
+				break
+			}
+		}
+	}
+
+	for i := range l.installments {
+		installment := &l.installments[i]
+		installment.Paid = money.FromCents(paid[i])
+		switch {
+		case l.balance == 0 && i == l.charged-1:
+			installment.Status = models.InstallmentPaid
+		case l.balance == 0 && i >= l.charged:
+			installment.Status = models.InstallmentSettled
+		case paid[i] >= money.ToCents(installment.Payment):
+			installment.Status = models.InstallmentPaid
+		case paid[i] > 0:
+			installment.Status = models.InstallmentPartial
+		case !installment.DueDate.After(asOf):
+			installment.Status = models.InstallmentOverdue
+		default:
+			installment.Status = models.InstallmentUpcoming
+		}
+	}
+	return l
+}
+
+// plan returns debt's installments as scheduled, before any payments.
+func plan(debt models.Debt) []models.Installment {
+	r := monthlyRate(debt.AnnualRate)
+	balance := money.ToCents(debt.Principal)
+	payment := money.ToCents(debt.Payment)
+
+	var installments []models.Installment
+	for i := 0; i < debt.TermMonths && balance > 0; i++ {
+		interest := interestOn(balance, r)
+		principal := min(max(payment-interest, 0), balance)
+		if i == debt.TermMonths-1 {
+			// Whatever is left is due with the last installment.
+			principal = balance
+		}
+		balance -= principal
+		installments = append(installments, models.Installment{
+			Number:     i + 1,
+			DueDate:    money.AddMonths(debt.FirstPaymentDate, i),
+			Payment:    money.FromCents(principal + interest),
+			Principal:  money.FromCents(principal),
+			Interest:   money.FromCents(interest),
+			Balance:    money.FromCents(balance),
+			PaymentIDs: []string{},
+		})
+	}
+	return installments
+}
+
+// project pays the installments after those already charged in l, each
+// with extra cents on top, until debt is paid off.
+func project(debt models.Debt, l ledger, extra int64) models.PayoffScenario {
+	r := monthlyRate(debt.AnnualRate)
+	balance, due := l.balance, l.interestDue
+	var interest int64
+
+	n := l.charged
+	for ; ; n++ {
+		charge := interestOn(balance, r)
+		interest += charge
+		due += charge
+		pay := money.ToCents(debt.Payment) + extra
+		if n >= debt.TermMonths-1 {
+			// Whatever is left is due with the last installment.
+			pay = balance + due
+		}
+		toInterest := min(pay, due)
+		due -= toInterest
+		balance -= min(pay-toInterest, balance)
+		if balance == 0 {
+			break
+		}
+	}
+
+	return models.PayoffScenario{
+		ExtraMonthly:          money.FromCents(extra),
+		PayoffDate:            money.AddMonths(debt.FirstPaymentDate, n),
+		RemainingInstallments: n - l.charged + 1,
+		InterestRemaining:     money.FromCents(interest),
+	}
+}
+
+// nearest returns the index of the installment whose due date is nearest
+// to t. Payments before the first or after the last belong to it.
+func nearest(installments []models.Installment, t time.Time) int {
+	for i := 0; i+1 < len(installments); i++ {
+		a, b := installments[i].DueDate, installments[i+1].DueDate
+		if t.Before(a.Add(b.Sub(a) / 2)) {
+			return i
+		}
+	}
+	return len(installments) - 1
+}
+
+// monthlyRate returns the monthly rate of annualRate percent.
+func monthlyRate(annualRate float64) float64 {
+	return annualRate / 100 / 12
+}
+
+// interestOn returns a month's interest, in cents, on balance cents at
+// monthly rate r.
+func interestOn(balance int64, r float64) int64 {
+	return int64(math.Round(float64(balance) * r))
+}
@@ -0,0 +1,150 @@
+package debts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/models"
+)
+
+var firstPayment = time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+// carLoan is 10000 over a year at 6%, or 0.5% a month.
+var carLoan = models.Debt{
+	ID:               "debt",
+	Principal:        10000,
+	AnnualRate:       6,
+	TermMonths:       12,
+	Payment:          860.66,
+	FirstPaymentDate: firstPayment,
+}
+
+func payment(id string, amount float64, paidAt time.Time) models.DebtPayment {
+	return models.DebtPayment{ID: id, Amount: amount, PaidAt: paidAt}
+}
+
+func TestPayment(t *testing.T) {
+	assert.Equal(t, 860.66, Payment(10000, 6, 12))
+	assert.Equal(t, 1199.1, Payment(200000, 6, 360))
+	assert.Equal(t, 333.33, Payment(1000, 0, 3), "no interest")
+	assert.Equal(t, 50.0, Interest(10000, 6))
+}
+
+func TestSchedule(t *testing.T) {
+	schedule := Schedule(carLoan, nil, firstPayment.AddDate(0, 0, -1))
+	assert.Equal(t, "debt", schedule.DebtID)
+	assert.Equal(t, 327.96, schedule.TotalInterest)
+	require.Len(t, schedule.Installments, 12)
+
+	first := schedule.Installments[0]
+	assert.Equal(t, 1, first.Number)
+	assert.Equal(t, firstPayment, first.DueDate)
+	assert.Equal(t, 860.66, first.Payment)
+	assert.Equal(t, 50.0, first.Interest)
+	assert.Equal(t, 810.66, first.Principal)
+	assert.Equal(t, 9189.34, first.Balance)
+	assert.Equal(t, models.InstallmentUpcoming, first.Status)
+	assert.Empty(t, first.PaymentIDs)
+
+	last := schedule.Installments[11]
+	assert.Equal(t, time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC), last.DueDate)
+	assert.Equal(t, 860.7, last.Payment, "clears the rounding")
+	assert.Zero(t, last.Balance)
+}
+
+func TestSchedule_Balloon(t *testing.T) {
+	debt := carLoan
+	debt.Payment = 500
+	schedule := Schedule(debt, nil, firstPayment)
+	require.Len(t, schedule.Installments, 12)
+	last := schedule.Installments[11]
+	assert.Greater(t, last.Payment, 4000.0, "what is left is due with the last installment")
+	assert.Zero(t, last.Balance)
+}
+
+func TestSchedule_EndOfMonth(t *testing.T) {
+	debt := carLoan
+	debt.FirstPaymentDate = time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	schedule := Schedule(debt, nil, debt.FirstPaymentDate)
+	assert.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), schedule.Installments[1].DueDate)
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), schedule.Installments[2].DueDate)
+}
+
+func TestReport(t *testing.T) {
+	payments := []models.DebtPayment{
+		payment("feb", 860.66, firstPayment.AddDate(0, 1, 2)), // two days late
+		payment("jan", 860.66, firstPayment.AddDate(0, 0, -1)),
+		// March is missed.
+		payment("apr", 400, firstPayment.AddDate(0, 3, 0)),
+		payment("future", 860.66, firstPayment.AddDate(0, 5, 0)),
+	}
+	asOf := firstPayment.AddDate(0, 3, 5)
+
+	schedule := Schedule(carLoan, payments, asOf)
+	statuses := make([]string, 0, 6)
+	for _, installment := range schedule.Installments[:6] {
+		statuses = append(statuses, installment.Status)
+	}
+	assert.Equal(t, []string{
+		models.InstallmentPaid, models.InstallmentPaid, models.InstallmentOverdue,
+		models.InstallmentPartial, models.InstallmentUpcoming, models.InstallmentUpcoming,
+	}, statuses)
+	assert.Equal(t, []string{"jan"}, schedule.Installments[0].PaymentIDs)
+	assert.Equal(t, []string{"feb"}, schedule.Installments[1].PaymentIDs, "matched to the nearest due date")
+	assert.Equal(t, 400.0, schedule.Installments[3].Paid)
+	assert.Empty(t, schedule.Installments[5].PaymentIDs, "not made yet")
+
+	report := Report(carLoan, payments, asOf, []float64{200, 1000})
+	assert.Equal(t, "debt", report.DebtID)
+	// The missed month's interest is paid by April's payment before any
+	// principal; the payment dated after asOf is not applied yet.
+	assert.Equal(t, 179.69, report.InterestPaid)
+	assert.Equal(t, 1941.63, report.PrincipalPaid)
+	assert.Equal(t, 8058.37, report.Balance)
+	assert.Zero(t, report.InterestDue)
+	assert.False(t, report.PaidOff)
+	assert.Equal(t, 2, report.InstallmentsPaid)
+	assert.Equal(t, 1, report.InstallmentsOverdue)
+
+	require.Len(t, report.Scenarios, 3)
+	base := report.Scenarios[0]
+	assert.Zero(t, base.ExtraMonthly)
+	assert.Equal(t, time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC), base.PayoffDate, "at the end of the term")
+	assert.Equal(t, 8, base.RemainingInstallments)
+	assert.Zero(t, base.InterestSaved)
+
+	faster := report.Scenarios[2]
+	assert.Equal(t, 1000.0, faster.ExtraMonthly)
+	assert.True(t, faster.PayoffDate.Before(base.PayoffDate))
+	assert.Equal(t, 5, faster.RemainingInstallments)
+	assert.Equal(t, 3, faster.InstallmentsSaved)
+	assert.Positive(t, faster.InterestSaved)
+	assert.InDelta(t, base.InterestRemaining-faster.InterestRemaining, faster.InterestSaved, 0.001)
+	assert.Less(t, report.Scenarios[1].InterestRemaining, base.InterestRemaining)
+}
+
+func TestReport_PaidOffEarly(t *testing.T) {
+	payments := []models.DebtPayment{
+		payment("jan", 860.66, firstPayment),
+		payment("lump", 9500, firstPayment.AddDate(0, 1, 0)),
+	}
+	asOf := firstPayment.AddDate(0, 6, 0)
+
+	report := Report(carLoan, payments, asOf, []float64{100})
+	assert.True(t, report.PaidOff)
+	require.NotNil(t, report.PaidOffAt)
+	assert.Equal(t, firstPayment.AddDate(0, 1, 0), *report.PaidOffAt)
+	assert.Zero(t, report.Balance)
+	assert.Equal(t, 10000.0, report.PrincipalPaid)
+	assert.Equal(t, 95.95, report.InterestPaid, "two months of interest")
+	assert.Empty(t, report.Scenarios)
+	assert.Zero(t, report.InstallmentsOverdue)
+
+	schedule := Schedule(carLoan, payments, asOf)
+	assert.Equal(t, models.InstallmentPaid, schedule.Installments[1].Status)
+	assert.Equal(t, models.InstallmentSettled, schedule.Installments[2].Status)
+	assert.Equal(t, models.InstallmentSettled, schedule.Installments[11].Status)
+}
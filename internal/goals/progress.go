@@ -0,0 +1,106 @@
+// Package goals reports the progress of savings goals from their
+// contributions.
+package goals
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/money"
+)
+
+// DefaultRateWindow is how many days of recent contributions the monthly
+// rate is taken over when none is given.
+const DefaultRateWindow = 90
+
+// MaxRateWindow is the longest rate window, in days.
+const MaxRateWindow = 3650
+
+// maxProjection is how many months ahead completion is projected at most.
+// At slower rates it is too far off to be worth a date.
+const maxProjection = 100 * 12
+
+// averageMonth is a twelfth of the average Gregorian year of 365.2425 days.
+const averageMonth = 31556952 * time.Second / 12
+
+// Progress reports how far goal is from its target at asOf, given all its
+// contributions in any order, and projects when it will be reached at the
+// rate contributed over the window days before asOf.
+func Progress(goal models.Goal, contributions []models.GoalContribution, asOf time.Time, window int) models.GoalProgress {
+	progress := models.GoalProgress{
+		GoalID:     goal.ID,
+		RateWindow: window,
+		AsOf:       asOf,
+	}
+
+	var saved, recent int64
+	since := asOf.Add(-time.Duration(window) * 24 * time.Hour)
+	for _, c := range contributions {
+		cents := money.ToCents(c.Amount)
+		saved += cents
+		if c.ContributedAt.After(since) && !c.ContributedAt.After(asOf) {
+			recent += cents
+		}
+	}
+	target := money.ToCents(goal.TargetAmount)
+
+	progress.Saved = money.FromCents(saved)
+	progress.Remaining = money.FromCents(max(target-saved, 0))
+	progress.Percent = math.Min(math.Round(float64(saved)/float64(target)*10000)/100, 100)
+	progress.MonthlyRate = money.Round(money.FromCents(recent) / months(time.Duration(window)*24*time.Hour))
+
+	if saved >= target {
+		progress.Completed = true
+		completedAt := completedAt(contributions, target)
+		progress.CompletedAt = &completedAt
+		progress.ProjectedCompletion = &completedAt
+	} else {
+		if goal.TargetDate != nil {
+			// Whatever is left is due within a month at the latest.
+			required := money.Round(progress.Remaining / math.Max(months(goal.TargetDate.Sub(asOf)), 1))
+			progress.RequiredMonthly = &required
+		}
+		// Compared before converting, as a Duration only spans 292 years.
+		if ahead := progress.Remaining / progress.MonthlyRate; progress.MonthlyRate > 0 && ahead <= maxProjection {
+			projected := day(asOf.Add(time.Duration(ahead * float64(averageMonth))))
+			progress.ProjectedCompletion = &projected
+		}
+	}
+
+	if goal.TargetDate != nil {
+		onTrack := progress.ProjectedCompletion != nil && !progress.ProjectedCompletion.After(*goal.TargetDate)
+		progress.OnTrack = &onTrack
+	}
+
+	return progress
+}
+
+// completedAt returns when the contributions, taken in date order, first
+// added up to target cents.
+func completedAt(contributions []models.GoalContribution, target int64) time.Time {
+	sorted := slices.Clone(contributions)
+	slices.SortStableFunc(sorted, func(a, b models.GoalContribution) int {
+		return a.ContributedAt.Compare(b.ContributedAt)
+	})
+
+	var saved int64
+	for _, c := range sorted {
+		saved += money.ToCents(c.Amount)
+		if saved >= target {
+			return c.ContributedAt
+		}
+	}
+	return sorted[len(sorted)-1].ContributedAt
+}
+
+// months returns d in average months.
+func months(d time.Duration) float64 {
+	return float64(d) / float64(averageMonth)
+}
+
+// day returns the start of t's day in UTC.
+func day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
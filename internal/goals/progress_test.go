@@ -0,0 +1,131 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/models"
+)
+
+var asOf = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func contribution(amount float64, daysAgo int) models.GoalContribution {
+	return models.GoalContribution{Amount: amount, ContributedAt: asOf.AddDate(0, 0, -daysAgo)}
+}
+
+func TestProgress(t *testing.T) {
+	targetDate := asOf.Add(6 * averageMonth)
+	goal := models.Goal{ID: "goal", TargetAmount: 1200, TargetDate: &targetDate}
+
+	progress := Progress(goal, []models.GoalContribution{
+		contribution(100, 200), // before the rate window
+		contribution(100.1, 60),
+		contribution(99.9, 10),
+	}, asOf, 90)
+
+	assert.Equal(t, "goal", progress.GoalID)
+	assert.Equal(t, 300.0, progress.Saved)
+	assert.Equal(t, 900.0, progress.Remaining)
+	assert.Equal(t, 25.0, progress.Percent)
+	assert.False(t, progress.Completed)
+	assert.Nil(t, progress.CompletedAt)
+	assert.Equal(t, 90, progress.RateWindow)
+	assert.Equal(t, 67.64, progress.MonthlyRate, "200 over 90 days")
+	require.NotNil(t, progress.RequiredMonthly)
+	assert.Equal(t, 150.0, *progress.RequiredMonthly, "900 over 6 months")
+	require.NotNil(t, progress.ProjectedCompletion)
+	assert.Equal(t, time.Date(2027, 7, 11, 0, 0, 0, 0, time.UTC), *progress.ProjectedCompletion, "900 at 67.64 a month")
+	require.NotNil(t, progress.OnTrack)
+	assert.False(t, *progress.OnTrack)
+	assert.Equal(t, asOf, progress.AsOf)
+}
+
+func TestProgress_OnTrack(t *testing.T) {
+	targetDate := asOf.AddDate(1, 0, 0)
+	goal := models.Goal{TargetAmount: 1000, TargetDate: &targetDate}
+
+	progress := Progress(goal, []models.GoalContribution{contribution(300, 29)}, asOf, 30)
+
+	require.NotNil(t, progress.ProjectedCompletion)
+	assert.True(t, progress.ProjectedCompletion.Before(targetDate))
+	require.NotNil(t, progress.OnTrack)
+	assert.True(t, *progress.OnTrack)
+}
+
+func TestProgress_Completed(t *testing.T) {
+	targetDate := asOf.AddDate(0, 1, 0)
+	goal := models.Goal{TargetAmount: 1000, TargetDate: &targetDate}
+	reached := contribution(700, 20)
+
+	// In any order, the target is reached by the second contribution by date.
+	progress := Progress(goal, []models.GoalContribution{contribution(50, 5), reached, contribution(600, 40)}, asOf, 90)
+
+	assert.True(t, progress.Completed)
+	assert.Equal(t, 1350.0, progress.Saved)
+	assert.Equal(t, 0.0, progress.Remaining)
+	assert.Equal(t, 100.0, progress.Percent, "at most 100")
+	require.NotNil(t, progress.CompletedAt)
+	assert.Equal(t, reached.ContributedAt, *progress.CompletedAt)
+	assert.Equal(t, progress.CompletedAt, progress.ProjectedCompletion)
+	assert.Nil(t, progress.RequiredMonthly)
+	require.NotNil(t, progress.OnTrack)
+	assert.True(t, *progress.OnTrack)
+}
+
+func TestProgress_WithoutRecentContributions(t *testing.T) {
+	goal := models.Goal{TargetAmount: 500}
+
+	progress := Progress(goal, []models.GoalContribution{contribution(100, 400)}, asOf, 90)
+
+	assert.Equal(t, 20.0, progress.Percent)
+	assert.Zero(t, progress.MonthlyRate)
+	assert.Nil(t, progress.ProjectedCompletion, "no rate to project from")
+	assert.Nil(t, progress.RequiredMonthly, "no target date")
+	assert.Nil(t, progress.OnTrack, "no target date")
+
+	progress = Progress(goal, nil, asOf, 90)
+	assert.Zero(t, progress.Saved)
+	assert.Equal(t, 500.0, progress.Remaining)
+}
+
+func TestProgress_TooSlowToProject(t *testing.T) {
+	targetDate := asOf.AddDate(5, 0, 0)
+	goal := models.Goal{TargetAmount: 10000, TargetDate: &targetDate}
+
+	// 9997 at 1.01 a month would take over 800 years, longer than a
+	// Duration can hold.
+	progress := Progress(goal, []models.GoalContribution{contribution(3, 10)}, asOf, 90)
+
+	assert.Equal(t, 1.01, progress.MonthlyRate)
+	assert.Nil(t, progress.ProjectedCompletion)
+	require.NotNil(t, progress.OnTrack)
+	assert.False(t, *progress.OnTrack)
+
+	progress = Progress(models.Goal{TargetAmount: 1000}, []models.GoalContribution{contribution(0.01, 10)}, asOf, 90)
+	assert.Nil(t, progress.ProjectedCompletion)
+}
+
+func TestProgress_PastTargetDate(t *testing.T) {
+	targetDate := asOf.AddDate(0, -2, 0)
+	goal := models.Goal{TargetAmount: 800, TargetDate: &targetDate}
+
+	progress := Progress(goal, []models.GoalContribution{contribution(200, 100)}, asOf, 90)
+
+	require.NotNil(t, progress.RequiredMonthly)
+	assert.Equal(t, 600.0, *progress.RequiredMonthly, "everything left is due now")
+	require.NotNil(t, progress.OnTrack)
+	assert.False(t, *progress.OnTrack)
+}
+
+func TestProgress_FutureContributions(t *testing.T) {
+	goal := models.Goal{TargetAmount: 1000}
+
+	// Scheduled contributions count as saved but not toward the recent rate.
+	progress := Progress(goal, []models.GoalContribution{contribution(100, -10)}, asOf, 90)
+
+	assert.Equal(t, 100.0, progress.Saved)
+	assert.Zero(t, progress.MonthlyRate)
+}
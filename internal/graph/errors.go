@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/validator"
+)
+
+// internalErrorCode is the code of errors whose cause is not shown.
+const internalErrorCode = "internal_error"
+
+// presentError turns an error returned by a resolver into a GraphQL error.
+// Like the REST API, the extensions carry a stable code: domain errors keep
+// theirs, with the field when they have one, and validation errors list every
+// field error as details. Any other error is logged and its message hidden.
+// Errors raised by the server itself, such as for an invalid query, are
+// left as they are.
+func (r *Resolver) presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Err == nil {
+		return gqlErr
+	}
+
+	var errs validator.Errors
+	var domainErr *db.Error
+	switch {
+	case errors.As(gqlErr.Err, &errs):
+		gqlErr.Message = errs.Error()
+		gqlErr.Extensions = map[string]any{"code": "validation_failed", "details": errs}
+	case errors.As(gqlErr.Err, &domainErr):
+		gqlErr.Message = capitalize(domainErr.Message)
+		gqlErr.Extensions = map[string]any{"code": domainErr.Code}
+		if domainErr.Field != "" {
+			gqlErr.Extensions["field"] = domainErr.Field
+		}
+	case errors.Is(gqlErr.Err, context.Canceled), errors.Is(gqlErr.Err, context.DeadlineExceeded):
+		gqlErr.Extensions = map[string]any{"code": "canceled"}
+	default:
+		r.Logger.Error().Err(gqlErr.Err).Str("path", gqlErr.Path.String()).Msg("GraphQL resolver failed")
+		gqlErr.Message = "Internal server error"
+		gqlErr.Extensions = map[string]any{"code": internalErrorCode}
+	}
+	return gqlErr
+}
+
+// recoverPanic logs a resolver panic and reports it as an internal error,
+// leaving the rest of the response intact.
+func (r *Resolver) recoverPanic(ctx context.Context, p any) error {
+	r.Logger.Error().Interface("panic", p).Msg("GraphQL resolver panicked")
+	return &gqlerror.Error{
+		Message:    "Internal server error",
+		Extensions: map[string]any{"code": internalErrorCode},
+	}
+}
+
+// capitalize turns an error string into a sentence for an error message.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
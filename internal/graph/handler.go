@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/rs/zerolog"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/graph/model"
+)
+
+// NewHandler returns the GraphQL endpoint over database. It takes queries as
+// JSON POST requests, and rejects those deeper than MaxDepth or more complex
+// than MaxComplexity. Each request gets its own loaders, so categories
+// are fetched once per batch of transactions rather than once each.
+func NewHandler(logger zerolog.Logger, database db.Database) http.Handler {
+	resolver := &Resolver{Logger: logger, db: database}
+
+	server := handler.New(newSchema(resolver))
+	server.AddTransport(transport.POST{})
+	server.SetQueryCache(lru.New[*ast.QueryDocument](100))
+	server.Use(extension.Introspection{})
+	server.Use(depthLimit{max: MaxDepth})
+	server.Use(extension.FixedComplexityLimit(MaxComplexity))
+	server.SetErrorPresenter(resolver.presentError)
+	server.SetRecoverFunc(resolver.recoverPanic)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := withLoaders(r.Context(), NewLoaders(database))
+		server.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// newSchema returns the schema served by resolver, with the cost of list
+// fields estimated for the complexity limit.
+func newSchema(resolver *Resolver) graphql.ExecutableSchema {
+	config := Config{Resolvers: resolver}
+	config.Complexity.User.Categories = listCost
+	config.Complexity.User.Transactions = func(child int, _ *model.TransactionFilterInput) int {
+		return listCost(child)
+	}
+	config.Complexity.Summary.Categories = listCost
+	return NewExecutableSchema(config)
+}
@@ -0,0 +1,220 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// countingDB counts the category lookups made through it.
+type countingDB struct {
+	db.Database
+	byID  atomic.Int32
+	byIDs atomic.Int32
+}
+
+func (c *countingDB) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
+	c.byID.Add(1)
+	return c.Database.GetCategoryByID(ctx, id)
+}
+
+func (c *countingDB) GetCategoriesByIDs(ctx context.Context, ids []string) ([]models.Category, error) {
+	c.byIDs.Add(1)
+	return c.Database.GetCategoriesByIDs(ctx, ids)
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func query(t *testing.T, h http.Handler, q string, variables map[string]any) (int, response) {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"query": q, "variables": variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	return rec.Code, resp
+}
+
+// seed creates a user with categories and a categorized transaction in each,
+// plus one uncategorized transaction.
+func seed(t *testing.T, database db.Database, categories int) *models.User {
+	t.Helper()
+	ctx := context.Background()
+
+	user, err := database.CreateUser(ctx, "graph@example.com")
+	require.NoError(t, err)
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < categories; i++ {
+		category, err := database.CreateCategory(ctx, user.ID, "Category "+string(rune('A'+i)))
+		require.NoError(t, err)
+		for j := 0; j < 2; j++ {
+			_, err = database.CreateTransaction(ctx, user.ID, &category.ID, float64(10*(i+1)), nil, base.AddDate(0, 0, i))
+			require.NoError(t, err)
+		}
+	}
+	_, err = database.CreateTransaction(ctx, user.ID, nil, 5, nil, base.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	return user
+}
+
+func TestHandler_BatchesCategoryLookups(t *testing.T) {
+	database := &countingDB{Database: db.NewMemoryDB()}
+	user := seed(t, database, 5)
+	h := NewHandler(zerolog.Nop(), database)
+
+	status, resp := query(t, h, `query($id: ID!) {
+		user(id: $id) {
+			email
+			transactions { amount category { name } }
+			summary { categories { categoryName total category { id name } } }
+		}
+	}`, map[string]any{"id": user.ID})
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, resp.Errors)
+
+	var data struct {
+		User struct {
+			Email        string
+			Transactions []struct {
+				Amount   float64
+				Category *struct{ Name string }
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &data))
+	assert.Equal(t, "graph@example.com", data.User.Email)
+	require.Len(t, data.User.Transactions, 11)
+	assert.Nil(t, data.User.Transactions[0].Category, "uncategorized transaction")
+	for _, transaction := range data.User.Transactions[1:] {
+		require.NotNil(t, transaction.Category)
+		assert.True(t, strings.HasPrefix(transaction.Category.Name, "Category "))
+	}
+
+	assert.Zero(t, database.byID.Load(), "categories must not be fetched one by one")
+	assert.LessOrEqual(t, database.byIDs.Load(), int32(2), "one batch per list, at most")
+}
+
+func TestHandler_UnknownUser(t *testing.T) {
+	h := NewHandler(zerolog.Nop(), db.NewMemoryDB())
+
+	status, resp := query(t, h, `{ user(id: "00000000-0000-4000-8000-000000000000") { id } }`, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"user": null}`, string(resp.Data))
+}
+
+func TestHandler_ValidationErrors(t *testing.T) {
+	database := db.NewMemoryDB()
+	user := seed(t, database, 1)
+	h := NewHandler(zerolog.Nop(), database)
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, resp := query(t, h, `{ user(id: "bad") { id } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "validation_failed", resp.Errors[0].Extensions["code"])
+		assert.Equal(t, []any{"user"}, resp.Errors[0].Path)
+	})
+
+	t.Run("reports every invalid filter field", func(t *testing.T) {
+		_, resp := query(t, h, `query($id: ID!) {
+			user(id: $id) {
+				transactions(filter: {
+					from: "2025-02-01T00:00:00Z", to: "2025-01-01T00:00:00Z",
+					categoryIds: ["00000000-0000-4000-8000-000000000001"], uncategorized: true
+				}) { id }
+			}
+		}`, map[string]any{"id": user.ID})
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "validation_failed", resp.Errors[0].Extensions["code"])
+
+		var fields []string
+		for _, detail := range resp.Errors[0].Extensions["details"].([]any) {
+			fields = append(fields, detail.(map[string]any)["field"].(string))
+		}
+		assert.Equal(t, []string{"from", "uncategorized"}, fields)
+	})
+}
+
+func TestHandler_ComplexityLimit(t *testing.T) {
+	h := NewHandler(zerolog.Nop(), db.NewMemoryDB())
+
+	// Each listing costs 1 + 10 * 11; aliases repeat it past the limit.
+	var q strings.Builder
+	q.WriteString(`{ user(id: "00000000-0000-4000-8000-000000000000") {`)
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&q, " t%d: transactions { id amount description occurredAt createdAt category { id userId name createdAt } }", i)
+	}
+	q.WriteString(" } }")
+
+	status, resp := query(t, h, q.String(), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, errComplexityLimit, resp.Errors[0].Extensions["code"])
+}
+
+func TestDepthLimit(t *testing.T) {
+	schema := newSchema(&Resolver{}).Schema()
+	limit := depthLimit{max: 4}
+
+	tests := []struct {
+		name  string
+		query string
+		err   bool
+	}{
+		{"within the limit", `{ user(id: "x") { transactions { category { name } } } }`, false},
+		{"too deep", `{ user(id: "x") { summary { categories { category { name } } } } }`, true},
+		{"through fragments", `
+			{ user(id: "x") { ...summary } }
+			fragment summary on User { summary { ... on Summary { categories { ...category } } } }
+			fragment category on CategorySummary { category { name } }`, true},
+		{"introspection is not counted", `{ user(id: "x") { transactions { category { __typename } } } }`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, errs := gqlparser.LoadQuery(schema, tt.query)
+			require.Empty(t, errs)
+
+			err := limit.MutateOperationContext(context.Background(), &graphql.OperationContext{
+				Doc:       doc,
+				Operation: doc.Operations[0],
+			})
+			if tt.err {
+				require.NotNil(t, err)
+				assert.Equal(t, errDepthLimit, err.Extensions["code"])
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
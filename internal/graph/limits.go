@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	// MaxDepth is how deeply an operation may nest fields, counting the
+	// root field. user → transactions → category → name is 4.
+	MaxDepth = 6
+
+	// MaxComplexity is the highest complexity an operation may have. Each
+	// field costs 1, and each list multiplies the cost of its elements by
+	// listComplexity, as an estimate of its length.
+	MaxComplexity = 1000
+
+	listComplexity = 10
+
+	errDepthLimit      = "DEPTH_LIMIT_EXCEEDED"
+	errComplexityLimit = "COMPLEXITY_LIMIT_EXCEEDED"
+)
+
+func init() {
+	// An operation over a limit is rejected as a whole, with 422 like an
+	// invalid one, rather than failing with 200.
+	errcode.RegisterErrorType(errDepthLimit, errcode.KindProtocol)
+	errcode.RegisterErrorType(errComplexityLimit, errcode.KindProtocol)
+}
+
+// listCost is the complexity of a list field whose elements cost child.
+func listCost(child int) int {
+	return 1 + listComplexity*child
+}
+
+// depthLimit rejects operations nesting fields deeper than max, before any
+// resolver runs. Introspection fields are not counted, so that tools can
+// fetch the schema.
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+func (d depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d depthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d depthLimit) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(opCtx.Operation.SelectionSet); depth > d.max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.max)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth returns how deeply set nests fields, looking through
+// fragments. The query has been validated, so fragments do not form cycles.
+func selectionDepth(set ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range set {
+		var d int
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			d = selectionDepth(s.Definition.SelectionSet)
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// loadWait is how long a loader waits for more keys before fetching. The
+// resolvers of a list's elements run concurrently, so this only needs to
+// cover the time it takes to start them.
+const loadWait = 2 * time.Millisecond
+
+// Loader batches the keys requested within loadWait of the first one into a
+// single fetch, and remembers every result for the rest of the request.
+type Loader[K comparable, V any] struct {
+	// fetch returns the values of keys. Keys missing from the map load as
+	// the zero value.
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	batches map[K]*batch[K, V]
+	pending *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+// NewLoader returns a loader that gets values with fetch.
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, batches: make(map[K]*batch[K, V])}
+}
+
+// Load returns the value of key, waiting for the batch it joins to be
+// fetched. A failed fetch fails every Load of its batch.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b, ok := l.batches[key]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &batch[K, V]{done: make(chan struct{})}
+			pending := l.pending
+			time.AfterFunc(loadWait, func() { l.dispatch(ctx, pending) })
+		}
+		b = l.pending
+		b.keys = append(b.keys, key)
+		l.batches[key] = b
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.values[key], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	l.pending = nil
+	l.mu.Unlock()
+
+	b.values, b.err = l.fetch(ctx, b.keys)
+	close(b.done)
+}
+
+// Loaders holds the loaders of one request.
+type Loaders struct {
+	Category *Loader[string, *models.Category]
+}
+
+// NewLoaders returns fresh loaders reading from database.
+func NewLoaders(database db.Database) *Loaders {
+	return &Loaders{
+		Category: NewLoader(func(ctx context.Context, ids []string) (map[string]*models.Category, error) {
+			categories, err := database.GetCategoriesByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]*models.Category, len(categories))
+			for i := range categories {
+				byID[categories[i].ID] = &categories[i]
+			}
+			return byID, nil
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+// withLoaders returns ctx carrying loaders.
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFor returns the loaders of the request ctx belongs to.
+func loadersFor(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
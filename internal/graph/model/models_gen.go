@@ -0,0 +1,22 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"time"
+)
+
+type Query struct {
+}
+
+// Narrows a transaction listing like the query parameters of GET /api/v1/transactions. Unset fields are not applied.
+type TransactionFilterInput struct {
+	From        *time.Time `json:"from,omitempty"`
+	To          *time.Time `json:"to,omitempty"`
+	MinAmount   *float64   `json:"minAmount,omitempty"`
+	MaxAmount   *float64   `json:"maxAmount,omitempty"`
+	CategoryIds []string   `json:"categoryIds,omitempty"`
+	// Only transactions without a category. Cannot be combined with categoryIds.
+	Uncategorized  *bool `json:"uncategorized,omitempty"`
+	HasDescription *bool `json:"hasDescription,omitempty"`
+}
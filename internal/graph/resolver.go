@@ -0,0 +1,56 @@
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate --config gqlgen.yml
+
+import (
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/graph/model"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+// Resolver is the root of the resolvers, holding what they share.
+type Resolver struct {
+	Logger zerolog.Logger
+	db     db.Database
+}
+
+// transactionFilter validates input like GET /api/v1/transactions validates
+// its query parameters, and converts it to a filter. Field errors are named
+// after the input fields.
+func transactionFilter(input *model.TransactionFilterInput) (models.TransactionFilter, error) {
+	if input == nil {
+		return models.TransactionFilter{}, nil
+	}
+
+	filter := models.TransactionFilter{
+		From:           input.From,
+		To:             input.To,
+		MinAmount:      input.MinAmount,
+		MaxAmount:      input.MaxAmount,
+		HasDescription: input.HasDescription,
+	}
+
+	var v validator.Validator
+	v.Check("from", nil, validator.ValidateDateRange(filter.From, filter.To))
+	v.Check("minAmount", nil, validator.ValidateAmountRange(filter.MinAmount, filter.MaxAmount))
+
+	for _, categoryID := range input.CategoryIds {
+		if err := validator.ValidateUUID(categoryID); err != nil {
+			v.Check("categoryIds", categoryID, err)
+			continue
+		}
+		filter.CategoryIDs = append(filter.CategoryIDs, categoryID)
+	}
+
+	if input.Uncategorized != nil {
+		filter.Uncategorized = *input.Uncategorized
+	}
+	if filter.Uncategorized && len(filter.CategoryIDs) > 0 {
+		v.Add("uncategorized", validator.RuleExclusive, "'uncategorized' cannot be combined with 'categoryIds'", nil)
+	}
+
+	return filter, v.Err()
+}
@@ -0,0 +1,100 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen
+
+import (
+	"context"
+	"errors"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/graph/model"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+	"time"
+)
+
+func (r *categorySummaryResolver) Category(ctx context.Context, obj *models.CategorySummary) (*models.Category, error) {
+	if obj.CategoryID == nil {
+		return nil, nil
+	}
+	return loadersFor(ctx).Category.Load(ctx, *obj.CategoryID)
+}
+
+func (r *queryResolver) User(ctx context.Context, id string) (*models.User, error) {
+	var v validator.Validator
+	v.Check("id", id, validator.ValidateUUID(id))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	user, err := r.db.GetUserByID(ctx, id)
+	if errors.Is(err, db.ErrUserNotFound) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *transactionResolver) Category(ctx context.Context, obj *models.Transaction) (*models.Category, error) {
+	if obj.CategoryID == nil {
+		return nil, nil
+	}
+	return loadersFor(ctx).Category.Load(ctx, *obj.CategoryID)
+}
+
+func (r *userResolver) Categories(ctx context.Context, obj *models.User) ([]models.Category, error) {
+	categories, err := r.db.ListCategories(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		categories = []models.Category{}
+	}
+	return categories, nil
+}
+
+func (r *userResolver) Transactions(ctx context.Context, obj *models.User, filter *model.TransactionFilterInput) ([]models.Transaction, error) {
+	transactionFilter, err := transactionFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := r.db.ListTransactions(ctx, obj.ID, transactionFilter)
+	if err != nil {
+		return nil, err
+	}
+	if transactions == nil {
+		transactions = []models.Transaction{}
+	}
+	return transactions, nil
+}
+
+func (r *userResolver) Summary(ctx context.Context, obj *models.User, from *time.Time, to *time.Time) (*models.Summary, error) {
+	var v validator.Validator
+	v.Check("from", nil, validator.ValidateDateRange(from, to))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	summary, err := r.db.GetSummary(ctx, obj.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Categories == nil {
+		summary.Categories = []models.CategorySummary{}
+	}
+	return summary, nil
+}
+
+func (r *Resolver) CategorySummary() CategorySummaryResolver { return &categorySummaryResolver{r} }
+
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+func (r *Resolver) Transaction() TransactionResolver { return &transactionResolver{r} }
+
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type categorySummaryResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type transactionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
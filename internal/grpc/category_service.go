@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/grpc/fintrackv1"
+	"fintrack-go/internal/validator"
+)
+
+type CategoryService struct {
+	fintrackv1.UnimplementedCategoryServiceServer
+	*Service
+	db db.Database
+}
+
+func NewCategoryService(logger zerolog.Logger, database db.Database) *CategoryService {
+	return &CategoryService{
+		Service: NewService(logger),
+		db:      database,
+	}
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, req *fintrackv1.CreateCategoryRequest) (*fintrackv1.CreateCategoryResponse, error) {
+	var v validator.Validator
+	v.Check("user_id", req.GetUserId(), validator.ValidateUUID(req.GetUserId()))
+	v.Check("name", req.GetName(), validator.ValidateCategoryName(req.GetName()))
+	if err := v.Err(); err != nil {
+		return nil, validationError(err)
+	}
+
+	category, err := s.db.CreateCategory(ctx, req.GetUserId(), req.GetName())
+	if err != nil {
+		return nil, s.dbError(err, "Failed to create category")
+	}
+
+	return &fintrackv1.CreateCategoryResponse{Category: categoryToProto(category)}, nil
+}
+
+func (s *CategoryService) ListCategories(ctx context.Context, req *fintrackv1.ListCategoriesRequest) (*fintrackv1.ListCategoriesResponse, error) {
+	var v validator.Validator
+	checkUserID(&v, req.GetUserId())
+	if err := v.Err(); err != nil {
+		return nil, validationError(err)
+	}
+
+	categories, err := s.db.ListCategories(ctx, req.GetUserId())
+	if err != nil {
+		return nil, s.dbError(err, "Failed to list categories")
+	}
+
+	resp := &fintrackv1.ListCategoriesResponse{
+		Categories: make([]*fintrackv1.Category, len(categories)),
+	}
+	for i := range categories {
+		resp.Categories[i] = categoryToProto(&categories[i])
+	}
+	return resp, nil
+}
@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"fintrack-go/internal/grpc/fintrackv1"
+	"fintrack-go/internal/models"
+)
+
+func userToProto(user *models.User) *fintrackv1.User {
+	return &fintrackv1.User{
+		Id:        user.ID,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+}
+
+func categoryToProto(category *models.Category) *fintrackv1.Category {
+	return &fintrackv1.Category{
+		Id:        category.ID,
+		UserId:    category.UserID,
+		Name:      category.Name,
+		CreatedAt: timestamppb.New(category.CreatedAt),
+	}
+}
+
+func transactionToProto(transaction *models.Transaction) *fintrackv1.Transaction {
+	return &fintrackv1.Transaction{
+		Id:           transaction.ID,
+		UserId:       transaction.UserID,
+		CategoryId:   transaction.CategoryID,
+		CategoryName: transaction.CategoryName,
+		Amount:       transaction.Amount,
+		Description:  transaction.Description,
+		OccurredAt:   timestamppb.New(transaction.OccurredAt),
+		CreatedAt:    timestamppb.New(transaction.CreatedAt),
+	}
+}
+
+func summaryToProto(summary *models.Summary) *fintrackv1.Summary {
+	categories := make([]*fintrackv1.CategorySummary, len(summary.Categories))
+	for i, category := range summary.Categories {
+		categories[i] = &fintrackv1.CategorySummary{
+			CategoryId:   category.CategoryID,
+			CategoryName: category.CategoryName,
+			Total:        category.Total,
+		}
+	}
+
+	return &fintrackv1.Summary{
+		UserId:     summary.UserID,
+		From:       timestamppb.New(summary.From),
+		To:         timestamppb.New(summary.To),
+		Categories: categories,
+	}
+}
@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/validator"
+)
+
+// errorDomain is the ErrorInfo domain of every error the API returns.
+const errorDomain = "fintrack"
+
+// codeForKind is the status code for each kind of domain error, matching the
+// HTTP status the REST API uses for it.
+var codeForKind = map[error]codes.Code{
+	db.ErrNotFound:   codes.NotFound,
+	db.ErrConflict:   codes.AlreadyExists,
+	db.ErrForbidden:  codes.PermissionDenied,
+	db.ErrValidation: codes.InvalidArgument,
+}
+
+// validationError returns an INVALID_ARGUMENT status for err, which must be
+// validator.Errors. Like the REST error details, it lists every field error,
+// as a BadRequest with one violation per field.
+func validationError(err error) error {
+	var errs validator.Errors
+	errors.As(err, &errs)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, fieldErr := range errs {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field,
+			Description: fieldErr.Message,
+			Reason:      fieldErr.Rule,
+		})
+	}
+
+	return statusWithDetails(codes.InvalidArgument, errs.Error(),
+		&errdetails.ErrorInfo{Reason: "validation_failed", Domain: errorDomain},
+		badRequest,
+	)
+}
+
+// dbError returns the status for an error from the database layer. Domain
+// errors get the code of their kind and keep their error code as the
+// ErrorInfo reason; any other error is logged and reported as INTERNAL with
+// message.
+func (s *Service) dbError(err error, message string) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var domainErr *db.Error
+	if !errors.As(err, &domainErr) {
+		s.Logger.Error().Err(err).Msg(message)
+		return status.Error(codes.Internal, message)
+	}
+
+	code, ok := codeForKind[domainErr.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+	// Retrying a transaction that kept conflicting may succeed, unlike other
+	// conflicts.
+	if errors.Is(err, db.ErrSerializationFailure) {
+		code = codes.Aborted
+	}
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: domainErr.Code, Domain: errorDomain},
+	}
+	if domainErr.Field != "" {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       domainErr.Field,
+				Description: domainErr.Message,
+				Reason:      domainErr.Code,
+			}},
+		})
+	}
+
+	return statusWithDetails(code, domainErr.Message, details...)
+}
+
+func statusWithDetails(code codes.Code, message string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, message)
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"fintrack-go/internal/db"
+)
+
+func TestService_dbError(t *testing.T) {
+	service := NewService(zerolog.Nop())
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantReason  string
+		wantMessage string
+	}{
+		{"not found", db.ErrUserNotFound, codes.NotFound, "user_not_found", "user not found"},
+		{"conflict", db.ErrDuplicateCategory, codes.AlreadyExists, "category_name_taken", "category name already exists for this user"},
+		{"validation", db.ErrSameTransaction, codes.InvalidArgument, "same_transaction", "a transaction cannot be a duplicate of itself"},
+		{"wrapped", fmt.Errorf("merging: %w", db.ErrTransactionNotFound), codes.NotFound, "transaction_not_found", "transaction not found"},
+		{"serialization failure", db.ErrSerializationFailure, codes.Aborted, "transaction_conflict", "transaction kept conflicting with concurrent transactions"},
+		{"other errors are internal", errors.New("connection reset"), codes.Internal, "", "Failed to do the thing"},
+		{"cancelled", fmt.Errorf("query: %w", context.Canceled), codes.Canceled, "", "query: context canceled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.dbError(tt.err, "Failed to do the thing")
+
+			st := status.Convert(err)
+			assert.Equal(t, tt.wantCode, st.Code())
+			assert.Equal(t, tt.wantMessage, st.Message())
+			if tt.wantReason == "" {
+				assert.Empty(t, st.Details())
+			} else {
+				info, _ := errorInfo(t, err)
+				assert.Equal(t, tt.wantReason, info.GetReason())
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: fintrack/v1/summary.proto
+
+package fintrackv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CategorySummary struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unset for the uncategorized total.
+	CategoryId    *string `protobuf:"bytes,1,opt,name=category_id,json=categoryId,proto3,oneof" json:"category_id,omitempty"`
+	CategoryName  string  `protobuf:"bytes,2,opt,name=category_name,json=categoryName,proto3" json:"category_name,omitempty"`
+	Total         float64 `protobuf:"fixed64,3,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CategorySummary) Reset() {
+	*x = CategorySummary{}
+	mi := &file_fintrack_v1_summary_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CategorySummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CategorySummary) ProtoMessage() {}
+
+func (x *CategorySummary) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_summary_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CategorySummary.ProtoReflect.Descriptor instead.
+func (*CategorySummary) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_summary_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CategorySummary) GetCategoryId() string {
+	if x != nil && x.CategoryId != nil {
+		return *x.CategoryId
+	}
+	return ""
+}
+
+func (x *CategorySummary) GetCategoryName() string {
+	if x != nil {
+		return x.CategoryName
+	}
+	return ""
+}
+
+func (x *CategorySummary) GetTotal() float64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type Summary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Categories    []*CategorySummary     `protobuf:"bytes,4,rep,name=categories,proto3" json:"categories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Summary) Reset() {
+	*x = Summary{}
+	mi := &file_fintrack_v1_summary_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Summary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Summary) ProtoMessage() {}
+
+func (x *Summary) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_summary_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Summary.ProtoReflect.Descriptor instead.
+func (*Summary) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_summary_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Summary) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Summary) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *Summary) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *Summary) GetCategories() []*CategorySummary {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+type GetSummaryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSummaryRequest) Reset() {
+	*x = GetSummaryRequest{}
+	mi := &file_fintrack_v1_summary_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSummaryRequest) ProtoMessage() {}
+
+func (x *GetSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_summary_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_summary_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSummaryRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetSummaryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetSummaryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type GetSummaryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Summary       *Summary               `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSummaryResponse) Reset() {
+	*x = GetSummaryResponse{}
+	mi := &file_fintrack_v1_summary_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSummaryResponse) ProtoMessage() {}
+
+func (x *GetSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_summary_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_summary_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSummaryResponse) GetSummary() *Summary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+var File_fintrack_v1_summary_proto protoreflect.FileDescriptor
+
+const file_fintrack_v1_summary_proto_rawDesc = "" +
+	"\n" +
+	"\x19fintrack/v1/summary.proto\x12\vfintrack.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x82\x01\n" +
+	"\x0fCategorySummary\x12$\n" +
+	"\vcategory_id\x18\x01 \x01(\tH\x00R\n" +
+	"categoryId\x88\x01\x01\x12#\n" +
+	"\rcategory_name\x18\x02 \x01(\tR\fcategoryName\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x01R\x05totalB\x0e\n" +
+	"\f_category_id\"\xbc\x01\n" +
+	"\aSummary\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12<\n" +
+	"\n" +
+	"categories\x18\x04 \x03(\v2\x1c.fintrack.v1.CategorySummaryR\n" +
+	"categories\"\x88\x01\n" +
+	"\x11GetSummaryRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"D\n" +
+	"\x12GetSummaryResponse\x12.\n" +
+	"\asummary\x18\x01 \x01(\v2\x14.fintrack.v1.SummaryR\asummary2_\n" +
+	"\x0eSummaryService\x12M\n" +
+	"\n" +
+	"GetSummary\x12\x1e.fintrack.v1.GetSummaryRequest\x1a\x1f.fintrack.v1.GetSummaryResponseB1Z/fintrack-go/internal/grpc/fintrackv1;fintrackv1b\x06proto3"
+
+var (
+	file_fintrack_v1_summary_proto_rawDescOnce sync.Once
+	file_fintrack_v1_summary_proto_rawDescData []byte
+)
+
+func file_fintrack_v1_summary_proto_rawDescGZIP() []byte {
+	file_fintrack_v1_summary_proto_rawDescOnce.Do(func() {
+		file_fintrack_v1_summary_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fintrack_v1_summary_proto_rawDesc), len(file_fintrack_v1_summary_proto_rawDesc)))
+	})
+	return file_fintrack_v1_summary_proto_rawDescData
+}
+
+var file_fintrack_v1_summary_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_fintrack_v1_summary_proto_goTypes = []any{
+	(*CategorySummary)(nil),       // 0: fintrack.v1.CategorySummary
+	(*Summary)(nil),               // 1: fintrack.v1.Summary
+	(*GetSummaryRequest)(nil),     // 2: fintrack.v1.GetSummaryRequest
+	(*GetSummaryResponse)(nil),    // 3: fintrack.v1.GetSummaryResponse
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_fintrack_v1_summary_proto_depIdxs = []int32{
+	4, // 0: fintrack.v1.Summary.from:type_name -> google.protobuf.Timestamp
+	4, // 1: fintrack.v1.Summary.to:type_name -> google.protobuf.Timestamp
+	0, // 2: fintrack.v1.Summary.categories:type_name -> fintrack.v1.CategorySummary
+	4, // 3: fintrack.v1.GetSummaryRequest.from:type_name -> google.protobuf.Timestamp
+	4, // 4: fintrack.v1.GetSummaryRequest.to:type_name -> google.protobuf.Timestamp
+	1, // 5: fintrack.v1.GetSummaryResponse.summary:type_name -> fintrack.v1.Summary
+	2, // 6: fintrack.v1.SummaryService.GetSummary:input_type -> fintrack.v1.GetSummaryRequest
+	3, // 7: fintrack.v1.SummaryService.GetSummary:output_type -> fintrack.v1.GetSummaryResponse
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_fintrack_v1_summary_proto_init() }
+func file_fintrack_v1_summary_proto_init() {
+	if File_fintrack_v1_summary_proto != nil {
+		return
+	}
+	file_fintrack_v1_summary_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fintrack_v1_summary_proto_rawDesc), len(file_fintrack_v1_summary_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fintrack_v1_summary_proto_goTypes,
+		DependencyIndexes: file_fintrack_v1_summary_proto_depIdxs,
+		MessageInfos:      file_fintrack_v1_summary_proto_msgTypes,
+	}.Build()
+	File_fintrack_v1_summary_proto = out.File
+	file_fintrack_v1_summary_proto_goTypes = nil
+	file_fintrack_v1_summary_proto_depIdxs = nil
+}
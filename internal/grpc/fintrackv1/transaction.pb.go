@@ -0,0 +1,512 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: fintrack/v1/transaction.proto
+
+package fintrackv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Transaction struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Unset for uncategorized transactions.
+	CategoryId    *string                `protobuf:"bytes,3,opt,name=category_id,json=categoryId,proto3,oneof" json:"category_id,omitempty"`
+	CategoryName  *string                `protobuf:"bytes,4,opt,name=category_name,json=categoryName,proto3,oneof" json:"category_name,omitempty"`
+	Amount        float64                `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Description   *string                `protobuf:"bytes,6,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_transaction_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Transaction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Transaction) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Transaction) GetCategoryId() string {
+	if x != nil && x.CategoryId != nil {
+		return *x.CategoryId
+	}
+	return ""
+}
+
+func (x *Transaction) GetCategoryName() string {
+	if x != nil && x.CategoryName != nil {
+		return *x.CategoryName
+	}
+	return ""
+}
+
+func (x *Transaction) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Transaction) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *Transaction) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *Transaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateTransactionRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UserId      string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CategoryId  *string                `protobuf:"bytes,2,opt,name=category_id,json=categoryId,proto3,oneof" json:"category_id,omitempty"`
+	Amount      float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Description *string                `protobuf:"bytes,4,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	// Defaults to the time of the request.
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransactionRequest) Reset() {
+	*x = CreateTransactionRequest{}
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransactionRequest) ProtoMessage() {}
+
+func (x *CreateTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransactionRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_transaction_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTransactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetCategoryId() string {
+	if x != nil && x.CategoryId != nil {
+		return *x.CategoryId
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+type CreateTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransactionResponse) Reset() {
+	*x = CreateTransactionResponse{}
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransactionResponse) ProtoMessage() {}
+
+func (x *CreateTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransactionResponse.ProtoReflect.Descriptor instead.
+func (*CreateTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_transaction_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateTransactionResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+// ListTransactionsRequest narrows the listing like the query parameters of
+// GET /api/v1/transactions. Unset fields are not applied.
+type ListTransactionsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UserId      string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	From        *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	MinAmount   *float64               `protobuf:"fixed64,4,opt,name=min_amount,json=minAmount,proto3,oneof" json:"min_amount,omitempty"`
+	MaxAmount   *float64               `protobuf:"fixed64,5,opt,name=max_amount,json=maxAmount,proto3,oneof" json:"max_amount,omitempty"`
+	CategoryIds []string               `protobuf:"bytes,6,rep,name=category_ids,json=categoryIds,proto3" json:"category_ids,omitempty"`
+	// Only transactions without a category. Cannot be combined with
+	// category_ids.
+	Uncategorized  bool  `protobuf:"varint,7,opt,name=uncategorized,proto3" json:"uncategorized,omitempty"`
+	HasDescription *bool `protobuf:"varint,8,opt,name=has_description,json=hasDescription,proto3,oneof" json:"has_description,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_transaction_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTransactionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetMinAmount() float64 {
+	if x != nil && x.MinAmount != nil {
+		return *x.MinAmount
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetMaxAmount() float64 {
+	if x != nil && x.MaxAmount != nil {
+		return *x.MaxAmount
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetCategoryIds() []string {
+	if x != nil {
+		return x.CategoryIds
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetUncategorized() bool {
+	if x != nil {
+		return x.Uncategorized
+	}
+	return false
+}
+
+func (x *ListTransactionsRequest) GetHasDescription() bool {
+	if x != nil && x.HasDescription != nil {
+		return *x.HasDescription
+	}
+	return false
+}
+
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fintrack_v1_transaction_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_fintrack_v1_transaction_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListTransactionsResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+var File_fintrack_v1_transaction_proto protoreflect.FileDescriptor
+
+const file_fintrack_v1_transaction_proto_rawDesc = "" +
+	"\n" +
+	"\x1dfintrack/v1/transaction.proto\x12\vfintrack.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xef\x02\n" +
+	"\vTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12$\n" +
+	"\vcategory_id\x18\x03 \x01(\tH\x00R\n" +
+	"categoryId\x88\x01\x01\x12(\n" +
+	"\rcategory_name\x18\x04 \x01(\tH\x01R\fcategoryName\x88\x01\x01\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x01R\x06amount\x12%\n" +
+	"\vdescription\x18\x06 \x01(\tH\x02R\vdescription\x88\x01\x01\x12;\n" +
+	"\voccurred_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB\x0e\n" +
+	"\f_category_idB\x10\n" +
+	"\x0e_category_nameB\x0e\n" +
+	"\f_description\"\xf5\x01\n" +
+	"\x18CreateTransactionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12$\n" +
+	"\vcategory_id\x18\x02 \x01(\tH\x00R\n" +
+	"categoryId\x88\x01\x01\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12%\n" +
+	"\vdescription\x18\x04 \x01(\tH\x01R\vdescription\x88\x01\x01\x12;\n" +
+	"\voccurred_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAtB\x0e\n" +
+	"\f_category_idB\x0e\n" +
+	"\f_description\"W\n" +
+	"\x19CreateTransactionResponse\x12:\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x18.fintrack.v1.TransactionR\vtransaction\"\xff\x02\n" +
+	"\x17ListTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\"\n" +
+	"\n" +
+	"min_amount\x18\x04 \x01(\x01H\x00R\tminAmount\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"max_amount\x18\x05 \x01(\x01H\x01R\tmaxAmount\x88\x01\x01\x12!\n" +
+	"\fcategory_ids\x18\x06 \x03(\tR\vcategoryIds\x12$\n" +
+	"\runcategorized\x18\a \x01(\bR\runcategorized\x12,\n" +
+	"\x0fhas_description\x18\b \x01(\bH\x02R\x0ehasDescription\x88\x01\x01B\r\n" +
+	"\v_min_amountB\r\n" +
+	"\v_max_amountB\x12\n" +
+	"\x10_has_description\"V\n" +
+	"\x18ListTransactionsResponse\x12:\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x18.fintrack.v1.TransactionR\vtransaction2\xdb\x01\n" +
+	"\x12TransactionService\x12b\n" +
+	"\x11CreateTransaction\x12%.fintrack.v1.CreateTransactionRequest\x1a&.fintrack.v1.CreateTransactionResponse\x12a\n" +
+	"\x10ListTransactions\x12$.fintrack.v1.ListTransactionsRequest\x1a%.fintrack.v1.ListTransactionsResponse0\x01B1Z/fintrack-go/internal/grpc/fintrackv1;fintrackv1b\x06proto3"
+
+var (
+	file_fintrack_v1_transaction_proto_rawDescOnce sync.Once
+	file_fintrack_v1_transaction_proto_rawDescData []byte
+)
+
+func file_fintrack_v1_transaction_proto_rawDescGZIP() []byte {
+	file_fintrack_v1_transaction_proto_rawDescOnce.Do(func() {
+		file_fintrack_v1_transaction_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fintrack_v1_transaction_proto_rawDesc), len(file_fintrack_v1_transaction_proto_rawDesc)))
+	})
+	return file_fintrack_v1_transaction_proto_rawDescData
+}
+
+var file_fintrack_v1_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_fintrack_v1_transaction_proto_goTypes = []any{
+	(*Transaction)(nil),               // 0: fintrack.v1.Transaction
+	(*CreateTransactionRequest)(nil),  // 1: fintrack.v1.CreateTransactionRequest
+	(*CreateTransactionResponse)(nil), // 2: fintrack.v1.CreateTransactionResponse
+	(*ListTransactionsRequest)(nil),   // 3: fintrack.v1.ListTransactionsRequest
+	(*ListTransactionsResponse)(nil),  // 4: fintrack.v1.ListTransactionsResponse
+	(*timestamppb.Timestamp)(nil),     // 5: google.protobuf.Timestamp
+}
+var file_fintrack_v1_transaction_proto_depIdxs = []int32{
+	5, // 0: fintrack.v1.Transaction.occurred_at:type_name -> google.protobuf.Timestamp
+	5, // 1: fintrack.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
+	5, // 2: fintrack.v1.CreateTransactionRequest.occurred_at:type_name -> google.protobuf.Timestamp
+	0, // 3: fintrack.v1.CreateTransactionResponse.transaction:type_name -> fintrack.v1.Transaction
+	5, // 4: fintrack.v1.ListTransactionsRequest.from:type_name -> google.protobuf.Timestamp
+	5, // 5: fintrack.v1.ListTransactionsRequest.to:type_name -> google.protobuf.Timestamp
+	0, // 6: fintrack.v1.ListTransactionsResponse.transaction:type_name -> fintrack.v1.Transaction
+	1, // 7: fintrack.v1.TransactionService.CreateTransaction:input_type -> fintrack.v1.CreateTransactionRequest
+	3, // 8: fintrack.v1.TransactionService.ListTransactions:input_type -> fintrack.v1.ListTransactionsRequest
+	2, // 9: fintrack.v1.TransactionService.CreateTransaction:output_type -> fintrack.v1.CreateTransactionResponse
+	4, // 10: fintrack.v1.TransactionService.ListTransactions:output_type -> fintrack.v1.ListTransactionsResponse
+	9, // [9:11] is the sub-list for method output_type
+	7, // [7:9] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_fintrack_v1_transaction_proto_init() }
+func file_fintrack_v1_transaction_proto_init() {
+	if File_fintrack_v1_transaction_proto != nil {
+		return
+	}
+	file_fintrack_v1_transaction_proto_msgTypes[0].OneofWrappers = []any{}
+	file_fintrack_v1_transaction_proto_msgTypes[1].OneofWrappers = []any{}
+	file_fintrack_v1_transaction_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fintrack_v1_transaction_proto_rawDesc), len(file_fintrack_v1_transaction_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fintrack_v1_transaction_proto_goTypes,
+		DependencyIndexes: file_fintrack_v1_transaction_proto_depIdxs,
+		MessageInfos:      file_fintrack_v1_transaction_proto_msgTypes,
+	}.Build()
+	File_fintrack_v1_transaction_proto = out.File
+	file_fintrack_v1_transaction_proto_goTypes = nil
+	file_fintrack_v1_transaction_proto_depIdxs = nil
+}
@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"fintrack-go/internal/db"
+)
+
+// HealthService implements the standard gRPC health check, reporting the
+// server as serving while the database answers, like GET /health.
+type HealthService struct {
+	grpc_health_v1.UnimplementedHealthServer
+	*Service
+	db db.Database
+}
+
+func NewHealthService(logger zerolog.Logger, database db.Database) *HealthService {
+	return &HealthService{
+		Service: NewService(logger),
+		db:      database,
+	}
+}
+
+func (s *HealthService) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if err := s.db.Ping(ctx); err != nil {
+		s.Logger.Error().Err(err).Msg("Health check failed")
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
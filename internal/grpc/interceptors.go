@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"fintrack-go/internal/db"
+)
+
+const (
+	tracerName = "fintrack-go/internal/grpc"
+
+	// requestIDKey is the metadata key of the request ID, the gRPC
+	// counterpart of the X-Request-ID header.
+	requestIDKey = "x-request-id"
+
+	// apiKeyKey is the metadata key of the API key, the gRPC counterpart of
+	// the X-API-Key header.
+	apiKeyKey = "x-api-key"
+)
+
+// unaryInterceptor observes each unary call: see observe.
+func unaryInterceptor(logger zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		err = observe(ctx, logger, info.FullMethod, func(ctx context.Context) error {
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// streamInterceptor observes each streaming call: see observe.
+func streamInterceptor(logger zerolog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return observe(stream.Context(), logger, info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		})
+	}
+}
+
+// observe runs call with what the REST middleware chain gives each request:
+// a request ID, taken from the incoming metadata or generated, and echoed in
+// the response header; the caller's identity for the audit log; a server
+// span continuing the caller's trace; an access log line; and recovery from
+// panics, reported as INTERNAL.
+func observe(ctx context.Context, logger zerolog.Logger, method string, call func(ctx context.Context) error) (err error) {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := uuid.New().String()
+	if ids := md.Get(requestIDKey); len(ids) > 0 && ids[0] != "" {
+		requestID = ids[0]
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+	ctx = db.WithAuditInfo(ctx, db.AuditInfo{Actor: callActor(ctx, md), RequestID: requestID})
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := otel.Tracer(tracerName).Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			attribute.String("rpc.method", method),
+			attribute.String("rpc.request.id", requestID),
+		),
+	)
+	defer span.End()
+
+	log := logger.With().Str("request_id", requestID).Logger()
+	ctx = log.WithContext(ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("method", method).Msg("gRPC handler panicked")
+			err = status.Error(codes.Internal, "Internal server error")
+		}
+
+		code := status.Code(err)
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+		if code != codes.OK {
+			span.SetStatus(otelcodes.Error, code.String())
+		}
+
+		log.Info().
+			Str("method", method).
+			Str("code", code.String()).
+			Dur("duration_ms", time.Since(start)).
+			Msg("gRPC request")
+	}()
+
+	return call(ctx)
+}
+
+// callActor identifies the caller as the REST API does: by API key, else by
+// IP address.
+func callActor(ctx context.Context, md metadata.MD) string {
+	if keys := md.Get(apiKeyKey); len(keys) > 0 && keys[0] != "" {
+		return db.APIKeyActor(keys[0])
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return db.SystemActor
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "ip:" + host
+}
+
+// contextStream is a ServerStream with a replaced context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier lets the trace propagator read incoming metadata.
+type metadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = metadataCarrier(nil)
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
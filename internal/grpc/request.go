@@ -0,0 +1,32 @@
+package grpc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"fintrack-go/internal/validator"
+)
+
+// timeField converts the optional timestamp field to a time, recording an
+// error on v if it is out of range.
+func timeField(v *validator.Validator, field string, ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		v.Add(field, validator.RuleFormat, "invalid '"+field+"' timestamp", nil)
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// checkUserID validates the required user_id field.
+func checkUserID(v *validator.Validator, userID string) {
+	if userID == "" {
+		v.Add("user_id", validator.RuleRequired, "user_id is required", nil)
+		return
+	}
+	v.Check("user_id", userID, validator.ValidateUUID(userID))
+}
@@ -0,0 +1,291 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/grpc/fintrackv1"
+	"fintrack-go/internal/models"
+)
+
+// newTestConn serves database over an in-memory listener and returns a
+// client connection to it.
+func newTestConn(t *testing.T, database db.Database) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(zerolog.Nop(), database)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// errorInfo returns the ErrorInfo and BadRequest details of err's status.
+func errorInfo(t *testing.T, err error) (*errdetails.ErrorInfo, *errdetails.BadRequest) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status error: %v", err)
+
+	var info *errdetails.ErrorInfo
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			badRequest = d
+		}
+	}
+	require.NotNil(t, info, "status has no ErrorInfo")
+	return info, badRequest
+}
+
+func violationFields(badRequest *errdetails.BadRequest) []string {
+	var fields []string
+	for _, violation := range badRequest.GetFieldViolations() {
+		fields = append(fields, violation.GetField())
+	}
+	return fields
+}
+
+func TestServer(t *testing.T) {
+	conn := newTestConn(t, db.NewMemoryDB())
+	ctx := context.Background()
+
+	users := fintrackv1.NewUserServiceClient(conn)
+	categories := fintrackv1.NewCategoryServiceClient(conn)
+	transactions := fintrackv1.NewTransactionServiceClient(conn)
+	summaries := fintrackv1.NewSummaryServiceClient(conn)
+
+	var userID, categoryID string
+
+	t.Run("create user", func(t *testing.T) {
+		var header metadata.MD
+		resp, err := users.CreateUser(ctx, &fintrackv1.CreateUserRequest{Email: "grpc@example.com"}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, "grpc@example.com", resp.GetUser().GetEmail())
+		assert.NotEmpty(t, resp.GetUser().GetId())
+		assert.NotEmpty(t, header.Get("x-request-id"))
+		userID = resp.GetUser().GetId()
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		_, err := users.CreateUser(ctx, &fintrackv1.CreateUserRequest{Email: "grpc@example.com"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		info, badRequest := errorInfo(t, err)
+		assert.Equal(t, "email_taken", info.GetReason())
+		assert.Equal(t, errorDomain, info.GetDomain())
+		assert.Equal(t, []string{"email"}, violationFields(badRequest))
+	})
+
+	t.Run("request ID is echoed", func(t *testing.T) {
+		var header metadata.MD
+		outgoing := metadata.AppendToOutgoingContext(ctx, "x-request-id", "req-123")
+		_, err := categories.ListCategories(outgoing, &fintrackv1.ListCategoriesRequest{UserId: userID}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"req-123"}, header.Get("x-request-id"))
+	})
+
+	t.Run("create and list categories", func(t *testing.T) {
+		created, err := categories.CreateCategory(ctx, &fintrackv1.CreateCategoryRequest{UserId: userID, Name: "Food"})
+		require.NoError(t, err)
+		categoryID = created.GetCategory().GetId()
+
+		_, err = categories.CreateCategory(ctx, &fintrackv1.CreateCategoryRequest{UserId: userID, Name: "Food"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		listed, err := categories.ListCategories(ctx, &fintrackv1.ListCategoriesRequest{UserId: userID})
+		require.NoError(t, err)
+		require.Len(t, listed.GetCategories(), 1)
+		assert.True(t, proto.Equal(created.GetCategory(), listed.GetCategories()[0]))
+	})
+
+	t.Run("create transactions", func(t *testing.T) {
+		base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		for i, amount := range []float64{10, 20, 30} {
+			req := &fintrackv1.CreateTransactionRequest{
+				UserId:     userID,
+				Amount:     amount,
+				OccurredAt: timestamppb.New(base.AddDate(0, 0, i)),
+			}
+			if i > 0 {
+				req.CategoryId = &categoryID
+			}
+			resp, err := transactions.CreateTransaction(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, amount, resp.GetTransaction().GetAmount())
+		}
+	})
+
+	t.Run("category of another user", func(t *testing.T) {
+		other, err := users.CreateUser(ctx, &fintrackv1.CreateUserRequest{Email: "other@example.com"})
+		require.NoError(t, err)
+
+		_, err = transactions.CreateTransaction(ctx, &fintrackv1.CreateTransactionRequest{
+			UserId:     other.GetUser().GetId(),
+			CategoryId: &categoryID,
+			Amount:     5,
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		info, _ := errorInfo(t, err)
+		assert.Equal(t, "category_not_owned", info.GetReason())
+	})
+
+	t.Run("stream transactions", func(t *testing.T) {
+		stream, err := transactions.ListTransactions(ctx, &fintrackv1.ListTransactionsRequest{UserId: userID})
+		require.NoError(t, err)
+
+		var amounts []float64
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			amounts = append(amounts, resp.GetTransaction().GetAmount())
+		}
+		assert.Equal(t, []float64{30, 20, 10}, amounts)
+	})
+
+	t.Run("stream filtered transactions", func(t *testing.T) {
+		minAmount := 15.0
+		stream, err := transactions.ListTransactions(ctx, &fintrackv1.ListTransactionsRequest{
+			UserId:      userID,
+			MinAmount:   &minAmount,
+			CategoryIds: []string{categoryID},
+			To:          timestamppb.New(time.Date(2025, 3, 2, 23, 0, 0, 0, time.UTC)),
+		})
+		require.NoError(t, err)
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, resp.GetTransaction().GetAmount())
+		assert.Equal(t, "Food", resp.GetTransaction().GetCategoryName())
+
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		resp, err := summaries.GetSummary(ctx, &fintrackv1.GetSummaryRequest{UserId: userID})
+		require.NoError(t, err)
+
+		totals := make(map[string]float64)
+		for _, category := range resp.GetSummary().GetCategories() {
+			totals[category.GetCategoryName()] = category.GetTotal()
+		}
+		assert.Equal(t, map[string]float64{"Food": 50, "Uncategorized": 10}, totals)
+	})
+}
+
+func TestServer_Validation(t *testing.T) {
+	conn := newTestConn(t, db.NewMemoryDB())
+	ctx := context.Background()
+
+	t.Run("reports every invalid field", func(t *testing.T) {
+		categoryID := "not-a-uuid"
+		_, err := fintrackv1.NewTransactionServiceClient(conn).CreateTransaction(ctx, &fintrackv1.CreateTransactionRequest{
+			UserId:     "bad",
+			CategoryId: &categoryID,
+			Amount:     -1,
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		info, badRequest := errorInfo(t, err)
+		assert.Equal(t, "validation_failed", info.GetReason())
+		assert.Equal(t, []string{"user_id", "category_id", "amount"}, violationFields(badRequest))
+		assert.Equal(t, "format", badRequest.GetFieldViolations()[0].GetReason())
+	})
+
+	t.Run("streaming call", func(t *testing.T) {
+		stream, err := fintrackv1.NewTransactionServiceClient(conn).ListTransactions(ctx, &fintrackv1.ListTransactionsRequest{
+			UserId:        "00000000-0000-4000-8000-000000000000",
+			Uncategorized: true,
+			CategoryIds:   []string{"00000000-0000-4000-8000-000000000001"},
+			From:          timestamppb.New(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)),
+			To:            timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, badRequest := errorInfo(t, err)
+		assert.Equal(t, []string{"from", "uncategorized"}, violationFields(badRequest))
+	})
+
+	t.Run("missing user_id", func(t *testing.T) {
+		_, err := fintrackv1.NewSummaryServiceClient(conn).GetSummary(ctx, &fintrackv1.GetSummaryRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, badRequest := errorInfo(t, err)
+		require.Len(t, badRequest.GetFieldViolations(), 1)
+		assert.Equal(t, "required", badRequest.GetFieldViolations()[0].GetReason())
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := fintrackv1.NewCategoryServiceClient(conn).CreateCategory(ctx, &fintrackv1.CreateCategoryRequest{
+			UserId: "00000000-0000-4000-8000-000000000000",
+			Name:   "Food",
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		info, _ := errorInfo(t, err)
+		assert.Equal(t, "user_not_found", info.GetReason())
+	})
+}
+
+func TestServer_Health(t *testing.T) {
+	conn := newTestConn(t, db.NewMemoryDB())
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestServer_Audit(t *testing.T) {
+	database := db.NewMemoryDB()
+	users := fintrackv1.NewUserServiceClient(newTestConn(t, database))
+
+	outgoing := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-audit", "x-api-key", "secret")
+	resp, err := users.CreateUser(outgoing, &fintrackv1.CreateUserRequest{Email: "audit@example.com"})
+	require.NoError(t, err)
+
+	entries, err := database.ListAuditEntries(context.Background(), resp.GetUser().GetId(), models.AuditFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, db.APIKeyActor("secret"), entries[0].Actor)
+	require.NotNil(t, entries[0].RequestID)
+	assert.Equal(t, "req-audit", *entries[0].RequestID)
+}
@@ -0,0 +1,46 @@
+// Package grpc serves the API over gRPC, alongside the REST API in
+// internal/http. Both validate requests with internal/validator and call the
+// same db.Database, so they accept the same input and fail the same way.
+package grpc
+
+import (
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/grpc/fintrackv1"
+)
+
+// Service holds what every gRPC service shares, like Handler does for the
+// REST handlers.
+type Service struct {
+	Logger zerolog.Logger
+}
+
+func NewService(logger zerolog.Logger) *Service {
+	return &Service{
+		Logger: logger,
+	}
+}
+
+// NewServer returns a gRPC server with every service registered, along with
+// the standard health and reflection services. opts are applied after the
+// server's own interceptors.
+func NewServer(logger zerolog.Logger, database db.Database, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptor(logger)),
+		grpc.ChainStreamInterceptor(streamInterceptor(logger)),
+	}, opts...)
+	server := grpc.NewServer(opts...)
+
+	fintrackv1.RegisterUserServiceServer(server, NewUserService(logger, database))
+	fintrackv1.RegisterCategoryServiceServer(server, NewCategoryService(logger, database))
+	fintrackv1.RegisterTransactionServiceServer(server, NewTransactionService(logger, database))
+	fintrackv1.RegisterSummaryServiceServer(server, NewSummaryService(logger, database))
+	grpc_health_v1.RegisterHealthServer(server, NewHealthService(logger, database))
+	reflection.Register(server)
+
+	return server
+}
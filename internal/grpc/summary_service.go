@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/grpc/fintrackv1"
+	"fintrack-go/internal/validator"
+)
+
+type SummaryService struct {
+	fintrackv1.UnimplementedSummaryServiceServer
+	*Service
+	db db.Database
+}
+
+func NewSummaryService(logger zerolog.Logger, database db.Database) *SummaryService {
+	return &SummaryService{
+		Service: NewService(logger),
+		db:      database,
+	}
+}
+
+func (s *SummaryService) GetSummary(ctx context.Context, req *fintrackv1.GetSummaryRequest) (*fintrackv1.GetSummaryResponse, error) {
+	var v validator.Validator
+	checkUserID(&v, req.GetUserId())
+	from := timeField(&v, "from", req.GetFrom())
+	to := timeField(&v, "to", req.GetTo())
+	v.Check("from", nil, validator.ValidateDateRange(from, to))
+	if err := v.Err(); err != nil {
+		return nil, validationError(err)
+	}
+
+	summary, err := s.db.GetSummary(ctx, req.GetUserId(), from, to)
+	if err != nil {
+		return nil, s.dbError(err, "Failed to get summary")
+	}
+
+	return &fintrackv1.GetSummaryResponse{Summary: summaryToProto(summary)}, nil
+}
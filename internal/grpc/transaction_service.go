@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/grpc/fintrackv1"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+type TransactionService struct {
+	fintrackv1.UnimplementedTransactionServiceServer
+	*Service
+	db db.Database
+}
+
+func NewTransactionService(logger zerolog.Logger, database db.Database) *TransactionService {
+	return &TransactionService{
+		Service: NewService(logger),
+		db:      database,
+	}
+}
+
+func (s *TransactionService) CreateTransaction(ctx context.Context, req *fintrackv1.CreateTransactionRequest) (*fintrackv1.CreateTransactionResponse, error) {
+	var v validator.Validator
+	v.Check("user_id", req.GetUserId(), validator.ValidateUUID(req.GetUserId()))
+	if req.CategoryId != nil {
+		v.Check("category_id", req.GetCategoryId(), validator.ValidateUUID(req.GetCategoryId()))
+	}
+	v.Check("amount", req.GetAmount(), validator.ValidateAmount(req.GetAmount()))
+	v.Check("description", nil, validator.ValidateDescription(req.Description))
+	occurredAt := timeField(&v, "occurred_at", req.GetOccurredAt())
+	if err := v.Err(); err != nil {
+		return nil, validationError(err)
+	}
+
+	if occurredAt == nil {
+		now := time.Now()
+		occurredAt = &now
+	}
+
+	transaction, err := s.db.CreateTransaction(ctx, req.GetUserId(), req.CategoryId, req.GetAmount(), req.Description, *occurredAt)
+	if err != nil {
+		return nil, s.dbError(err, "Failed to create transaction")
+	}
+
+	return &fintrackv1.CreateTransactionResponse{Transaction: transactionToProto(transaction)}, nil
+}
+
+// ListTransactions streams the transactions ListTransactions finds, one
+// message each.
+func (s *TransactionService) ListTransactions(req *fintrackv1.ListTransactionsRequest, stream fintrackv1.TransactionService_ListTransactionsServer) error {
+	var v validator.Validator
+	checkUserID(&v, req.GetUserId())
+
+	filter := models.TransactionFilter{
+		From:           timeField(&v, "from", req.GetFrom()),
+		To:             timeField(&v, "to", req.GetTo()),
+		MinAmount:      req.MinAmount,
+		MaxAmount:      req.MaxAmount,
+		Uncategorized:  req.GetUncategorized(),
+		HasDescription: req.HasDescription,
+	}
+	v.Check("from", nil, validator.ValidateDateRange(filter.From, filter.To))
+	v.Check("min_amount", nil, validator.ValidateAmountRange(filter.MinAmount, filter.MaxAmount))
+
+	for _, categoryID := range req.GetCategoryIds() {
+		if err := validator.ValidateUUID(categoryID); err != nil {
+			v.Check("category_ids", categoryID, err)
+			continue
+		}
+		filter.CategoryIDs = append(filter.CategoryIDs, categoryID)
+	}
+
+	if filter.Uncategorized && len(filter.CategoryIDs) > 0 {
+		v.Add("uncategorized", validator.RuleExclusive, "'uncategorized' cannot be combined with 'category_ids'", nil)
+	}
+
+	if err := v.Err(); err != nil {
+		return validationError(err)
+	}
+
+	transactions, err := s.db.ListTransactions(stream.Context(), req.GetUserId(), filter)
+	if err != nil {
+		return s.dbError(err, "Failed to list transactions")
+	}
+
+	for i := range transactions {
+		if err := stream.Send(&fintrackv1.ListTransactionsResponse{Transaction: transactionToProto(&transactions[i])}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
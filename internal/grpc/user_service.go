@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/grpc/fintrackv1"
+	"fintrack-go/internal/validator"
+)
+
+type UserService struct {
+	fintrackv1.UnimplementedUserServiceServer
+	*Service
+	db db.Database
+}
+
+func NewUserService(logger zerolog.Logger, database db.Database) *UserService {
+	return &UserService{
+		Service: NewService(logger),
+		db:      database,
+	}
+}
+
+func (s *UserService) CreateUser(ctx context.Context, req *fintrackv1.CreateUserRequest) (*fintrackv1.CreateUserResponse, error) {
+	var v validator.Validator
+	v.Check("email", req.GetEmail(), validator.ValidateEmail(req.GetEmail()))
+	if err := v.Err(); err != nil {
+		return nil, validationError(err)
+	}
+
+	user, err := s.db.CreateUser(ctx, req.GetEmail())
+	if err != nil {
+		return nil, s.dbError(err, "Failed to create user")
+	}
+
+	return &fintrackv1.CreateUserResponse{User: userToProto(user)}, nil
+}
@@ -0,0 +1,246 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/networth"
+	"fintrack-go/internal/validator"
+)
+
+type AccountHandler struct {
+	*Handler
+	db db.Database
+}
+
+func NewAccountHandler(logger zerolog.Logger, database db.Database) *AccountHandler {
+	return &AccountHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+	}
+}
+
+type CreateAccountRequest struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
+type CreateValuationRequest struct {
+	UserID   string     `json:"user_id"`
+	Value    float64    `json:"value"`
+	ValuedAt *time.Time `json:"valued_at,omitempty"`
+}
+
+func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	var req CreateAccountRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("name", req.Name, validator.ValidateAccountName(req.Name))
+	v.Check("type", req.Type, validator.ValidateOneOf("type", req.Type, models.AccountTypes))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	account, err := h.db.CreateAccount(r.Context(), req.UserID, models.Account{
+		Name: req.Name,
+		Type: req.Type,
+	})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create account")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, account)
+}
+
+func (h *AccountHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	accounts, err := h.db.ListAccounts(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list accounts")
+		return
+	}
+
+	if accounts == nil {
+		accounts = []models.Account{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, accounts)
+}
+
+func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := h.db.GetAccount(r.Context(), userID, id)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get account")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, account)
+}
+
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteAccount(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete account")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateValuation records an account's value, or for a liability what is
+// owed, as of valued_at, or now if it is not given.
+func (h *AccountHandler) CreateValuation(w http.ResponseWriter, r *http.Request) {
+	accountID := chi.URLParam(r, "id")
+	var req CreateValuationRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("id", accountID, validator.ValidateUUID(accountID))
+	v.Check("value", req.Value, validator.ValidateValue(req.Value))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	valuation := models.Valuation{Value: req.Value, ValuedAt: time.Now()}
+	if req.ValuedAt != nil {
+		valuation.ValuedAt = *req.ValuedAt
+	}
+
+	created, err := h.db.CreateValuation(r.Context(), req.UserID, accountID, valuation)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create valuation")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *AccountHandler) ListValuations(w http.ResponseWriter, r *http.Request) {
+	userID, accountID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	valuations, err := h.db.ListValuations(r.Context(), userID, models.ValuationFilter{AccountID: &accountID})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list valuations")
+		return
+	}
+
+	if valuations == nil {
+		valuations = []models.Valuation{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, valuations)
+}
+
+func (h *AccountHandler) DeleteValuation(w http.ResponseWriter, r *http.Request) {
+	valuationID := chi.URLParam(r, "valuation_id")
+	var v validator.Validator
+	v.Check("valuation_id", valuationID, validator.ValidateUUID(valuationID))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+	userID, accountID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteValuation(r.Context(), userID, accountID, valuationID); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete valuation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetNetWorth reports the user's assets, liabilities and net worth at each
+// interval from from to to, which default to the year up to now.
+func (h *AccountHandler) GetNetWorth(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	from := timeParam(&v, query, "from")
+	to := timeParam(&v, query, "to")
+	interval := models.IntervalMonth
+	if query.Has("interval") {
+		interval = query.Get("interval")
+		v.Check("interval", interval, validator.ValidateOneOf("interval", interval, models.NetWorthIntervals))
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	if to == nil {
+		now := time.Now().UTC()
+		to = &now
+	}
+	if from == nil {
+		start := to.AddDate(-1, 0, 0)
+		from = &start
+	}
+	v.Check("from", nil, validator.ValidateDateRange(from, to))
+	if !from.After(*to) && networth.Count(*from, *to, interval) > networth.MaxPoints {
+		v.Add("interval", validator.RuleMax, fmt.Sprintf("the report cannot have more than %d points; use a longer interval or a shorter range", networth.MaxPoints), interval)
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	accounts, err := h.db.ListAccounts(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list accounts")
+		return
+	}
+	valuations, err := h.db.ListValuations(r.Context(), userID, models.ValuationFilter{To: to})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list valuations")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, models.NetWorth{
+		UserID:   userID,
+		From:     *from,
+		To:       *to,
+		Interval: interval,
+		Points:   networth.Series(accounts, valuations, *from, *to, interval),
+	})
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func newAccountRouter(t *testing.T) (*db.MemoryDB, http.Handler, *models.User) {
+	t.Helper()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(context.Background(), "worth@example.com")
+	require.NoError(t, err)
+	return database, ContentType(SetupRoutes(zerolog.Nop(), database)), user
+}
+
+func TestAccountHandler(t *testing.T) {
+	database, router, user := newAccountRouter(t)
+	ctx := context.Background()
+
+	w := serve(router, http.MethodPost, "/api/v1/accounts", CreateAccountRequest{UserID: user.ID, Name: "House", Type: models.AccountProperty})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var account models.Account
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+	assert.Equal(t, "House", account.Name)
+	assert.Equal(t, models.AccountProperty, account.Type)
+
+	accountURL := "/api/v1/accounts/" + account.ID
+	query := "?user_id=" + user.ID
+
+	w = serve(router, http.MethodGet, "/api/v1/accounts"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var accounts []models.Account
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&accounts))
+	require.Len(t, accounts, 1)
+	assert.Equal(t, account.ID, accounts[0].ID)
+
+	w = serve(router, http.MethodGet, accountURL+query, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	valuedAt := time.Now().AddDate(0, -2, 0).UTC().Truncate(time.Second)
+	w = serve(router, http.MethodPost, accountURL+"/valuations", CreateValuationRequest{UserID: user.ID, Value: 300000, ValuedAt: &valuedAt})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var old models.Valuation
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&old))
+	assert.Equal(t, 300000.0, old.Value)
+	assert.True(t, valuedAt.Equal(old.ValuedAt))
+
+	w = serve(router, http.MethodPost, accountURL+"/valuations", CreateValuationRequest{UserID: user.ID, Value: 310000})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var current models.Valuation
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&current))
+	assert.WithinDuration(t, time.Now(), current.ValuedAt, time.Minute, "valued now by default")
+
+	w = serve(router, http.MethodGet, accountURL+"/valuations"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var valuations []models.Valuation
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&valuations))
+	require.Len(t, valuations, 2)
+	assert.Equal(t, old.ID, valuations[0].ID, "earliest first")
+
+	mortgage, err := database.CreateAccount(ctx, user.ID, models.Account{Name: "Mortgage", Type: models.AccountMortgage})
+	require.NoError(t, err)
+	_, err = database.CreateValuation(ctx, user.ID, mortgage.ID, models.Valuation{Value: 200000, ValuedAt: valuedAt})
+	require.NoError(t, err)
+
+	w = serve(router, http.MethodGet, "/api/v1/reports/net-worth"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report models.NetWorth
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	assert.Equal(t, user.ID, report.UserID)
+	assert.Equal(t, models.IntervalMonth, report.Interval)
+	require.Len(t, report.Points, 13, "a year of months")
+	assert.Zero(t, report.Points[0].NetWorth)
+	last := report.Points[12]
+	assert.Equal(t, 310000.0, last.Assets)
+	assert.Equal(t, 200000.0, last.Liabilities)
+	assert.Equal(t, 110000.0, last.NetWorth)
+
+	from := valuedAt.Format(time.RFC3339)
+	to := valuedAt.AddDate(0, 0, 14).Format(time.RFC3339)
+	w = serve(router, http.MethodGet, "/api/v1/reports/net-worth"+query+"&interval=week&from="+from+"&to="+to, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	require.Len(t, report.Points, 3)
+	assert.Equal(t, 100000.0, report.Points[0].NetWorth)
+
+	w = serve(router, http.MethodDelete, accountURL+"/valuations/"+old.ID+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodDelete, accountURL+"/valuations/"+old.ID+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "valuation_not_found")
+
+	t.Run("another user's account", func(t *testing.T) {
+		other, err := database.CreateUser(ctx, "other@example.com")
+		require.NoError(t, err)
+		for _, target := range []string{accountURL, accountURL + "/valuations"} {
+			w := serve(router, http.MethodGet, target+"?user_id="+other.ID, nil)
+			assert.Equal(t, http.StatusNotFound, w.Code, target)
+			assert.Contains(t, w.Body.String(), "account_not_found")
+		}
+	})
+
+	w = serve(router, http.MethodDelete, accountURL+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodGet, accountURL+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAccountHandler_Rejected(t *testing.T) {
+	_, router, user := newAccountRouter(t)
+	unknownID := "660e8400-e29b-41d4-a716-446655440000"
+
+	w := serve(router, http.MethodPost, "/api/v1/accounts", CreateAccountRequest{UserID: user.ID, Name: "Car", Type: models.AccountVehicle})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var account models.Account
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+	accountURL := "/api/v1/accounts/" + account.ID
+	query := "?user_id=" + user.ID
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   any
+		status int
+		code   string
+	}{
+		{name: "no name", method: http.MethodPost, target: "/api/v1/accounts", body: CreateAccountRequest{UserID: user.ID, Name: " ", Type: models.AccountCash}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "unknown type", method: http.MethodPost, target: "/api/v1/accounts", body: CreateAccountRequest{UserID: user.ID, Name: "Boat", Type: "boat"}, status: http.StatusBadRequest, code: "one_of"},
+		{name: "unknown user", method: http.MethodPost, target: "/api/v1/accounts", body: CreateAccountRequest{UserID: unknownID, Name: "Cash", Type: models.AccountCash}, status: http.StatusNotFound, code: "user_not_found"},
+		{name: "negative value", method: http.MethodPost, target: accountURL + "/valuations", body: CreateValuationRequest{UserID: user.ID, Value: -1}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "unknown account", method: http.MethodPost, target: "/api/v1/accounts/" + unknownID + "/valuations", body: CreateValuationRequest{UserID: user.ID, Value: 1}, status: http.StatusNotFound, code: "account_not_found"},
+		{name: "invalid account id", method: http.MethodPost, target: "/api/v1/accounts/invalid/valuations", body: CreateValuationRequest{UserID: user.ID, Value: 1}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "invalid valuation id", method: http.MethodDelete, target: accountURL + "/valuations/invalid" + query, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "unknown interval", method: http.MethodGet, target: "/api/v1/reports/net-worth" + query + "&interval=year", status: http.StatusBadRequest, code: "one_of"},
+		{name: "from after to", method: http.MethodGet, target: "/api/v1/reports/net-worth" + query + "&from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z", status: http.StatusBadRequest, code: "range"},
+		{name: "from after now", method: http.MethodGet, target: "/api/v1/reports/net-worth" + query + "&from=2999-01-01T00:00:00Z", status: http.StatusBadRequest, code: "range"},
+		{name: "too many points", method: http.MethodGet, target: "/api/v1/reports/net-worth" + query + "&interval=day&from=2020-01-01T00:00:00Z&to=2026-01-01T00:00:00Z", status: http.StatusBadRequest, code: "max"},
+		{name: "missing user_id", method: http.MethodGet, target: "/api/v1/reports/net-worth", status: http.StatusBadRequest, code: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.code)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/blob"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+// AttachmentLimits restricts what can be attached to a transaction.
+type AttachmentLimits struct {
+	// MaxSize is the most bytes an attachment may have.
+	MaxSize int64
+	// ContentTypes are the media types attachments may have, as sniffed from
+	// their content.
+	ContentTypes []string
+}
+
+// DefaultAttachmentLimits allow receipt photos and PDF invoices of up to
+// 10 MiB.
+var DefaultAttachmentLimits = AttachmentLimits{
+	MaxSize:      10 << 20,
+	ContentTypes: []string{"image/jpeg", "image/png", "image/webp", "application/pdf"},
+}
+
+type AttachmentHandler struct {
+	*Handler
+	db     db.Database
+	store  blob.Store
+	limits AttachmentLimits
+}
+
+func NewAttachmentHandler(logger zerolog.Logger, database db.Database, store blob.Store, limits AttachmentLimits) *AttachmentHandler {
+	return &AttachmentHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+		store:   store,
+		limits:  limits,
+	}
+}
+
+// UploadAttachment attaches the file in the multipart form field "file" to
+// a transaction. Its media type is sniffed from its content rather than
+// taken from the client.
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, transactionID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	part, ok := h.filePart(w, r)
+	if !ok {
+		return
+	}
+	defer part.Close()
+
+	filename := part.FileName()
+	var v validator.Validator
+	v.Check(fileField, filename, validator.ValidateAttachmentFilename(filename))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(part, h.limits.MaxSize+1))
+	if err != nil {
+		h.respondWithBodyError(w, err)
+		return
+	}
+	if int64(len(data)) > h.limits.MaxSize {
+		h.respondWithError(w, http.StatusRequestEntityTooLarge, "Attachments cannot exceed "+strconv.FormatInt(h.limits.MaxSize, 10)+" bytes", nil)
+		return
+	}
+	if len(data) == 0 {
+		v.Add(fileField, validator.RuleRequired, "file cannot be empty", nil)
+		h.respondWithValidationError(w, v.Err())
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !slices.Contains(h.limits.ContentTypes, contentType) {
+		h.writeError(w, http.StatusUnsupportedMediaType, "unsupported_attachment_type",
+			"Attachments must be one of "+strings.Join(h.limits.ContentTypes, ", ")+", got "+contentType, nil)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	key := "attachments/" + userID + "/" + uuid.NewString()
+	if err := h.store.Put(r.Context(), key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		h.Logger.Error().Err(err).Str("key", key).Msg("Failed to store attachment")
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to store attachment", nil)
+		return
+	}
+
+	attachment, err := h.db.CreateAttachment(r.Context(), userID, transactionID, models.Attachment{
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		StorageKey:  key,
+	})
+	if err != nil {
+		// Nothing refers to the blob, so the cleaner would never find it.
+		if err := h.store.Delete(context.WithoutCancel(r.Context()), key); err != nil {
+			h.Logger.Error().Err(err).Str("key", key).Msg("Failed to delete unused attachment blob")
+		}
+		h.respondWithDBError(w, err, "Failed to create attachment")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, attachment)
+}
+
+func (h *AttachmentHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	userID, transactionID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.db.ListAttachments(r.Context(), userID, transactionID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list attachments")
+		return
+	}
+	if attachments == nil {
+		attachments = []models.Attachment{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, attachments)
+}
+
+// DownloadAttachment responds with the attached file as it was uploaded.
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.db.GetAttachment(r.Context(), userID, id)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get attachment")
+		return
+	}
+
+	body, err := h.store.Get(r.Context(), attachment.StorageKey)
+	if err != nil {
+		h.Logger.Error().Err(err).Str("key", attachment.StorageKey).Msg("Failed to read attachment")
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to download attachment", nil)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		h.Logger.Debug().Err(err).Msg("Failed to write attachment")
+	}
+}
+
+// DeleteAttachment deletes an attachment. Its blob is removed from the store
+// by the cleaner.
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteAttachment(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete attachment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/blob"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// pdf is the start of a PDF file, which is enough to sniff its type.
+const pdf = "%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+
+// multipartBody returns a form with a file in field, and its content type.
+func multipartBody(t *testing.T, field, filename, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("note", "ignored"))
+	if field != "" {
+		part, err := form.CreateFormFile(field, filename)
+		require.NoError(t, err)
+		_, err = io.WriteString(part, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, form.Close())
+	return &body, form.FormDataContentType()
+}
+
+func upload(t *testing.T, router http.Handler, target, filename, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, contentType := multipartBody(t, "file", filename, content)
+	req := httptest.NewRequest(http.MethodPost, target, body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+type attachmentFixture struct {
+	database    *db.MemoryDB
+	store       *blob.MemoryStore
+	router      http.Handler
+	user        *models.User
+	transaction *models.Transaction
+}
+
+func newAttachmentFixture(t *testing.T, limits AttachmentLimits) attachmentFixture {
+	t.Helper()
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "receipts@example.com")
+	require.NoError(t, err)
+	transaction, err := database.CreateTransaction(ctx, user.ID, nil, 42.5, nil, time.Now())
+	require.NoError(t, err)
+	store := blob.NewMemoryStore()
+	routes := SetupRoutes(zerolog.Nop(), database, WithAttachments(store, limits))
+	return attachmentFixture{
+		database:    database,
+		store:       store,
+		router:      ContentType(routes),
+		user:        user,
+		transaction: transaction,
+	}
+}
+
+func (f attachmentFixture) uploadTarget(transactionID string) string {
+	return "/api/v1/transactions/" + transactionID + "/attachments?user_id=" + f.user.ID
+}
+
+func TestAttachmentHandler(t *testing.T) {
+	f := newAttachmentFixture(t, DefaultAttachmentLimits)
+
+	w := upload(t, f.router, f.uploadTarget(f.transaction.ID), "März receipt.pdf", pdf)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var attachment models.Attachment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&attachment))
+	assert.Equal(t, f.transaction.ID, attachment.TransactionID)
+	assert.Equal(t, "März receipt.pdf", attachment.Filename)
+	assert.Equal(t, "application/pdf", attachment.ContentType)
+	assert.Equal(t, int64(len(pdf)), attachment.Size)
+	assert.Len(t, attachment.SHA256, 64)
+	assert.NotContains(t, w.Body.String(), "storage_key")
+	assert.Equal(t, 1, f.store.Len())
+
+	w = serve(f.router, http.MethodGet, f.uploadTarget(f.transaction.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var attachments []models.Attachment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&attachments))
+	require.Len(t, attachments, 1)
+	assert.Equal(t, attachment.ID, attachments[0].ID)
+
+	download := "/api/v1/attachments/" + attachment.ID + "/download?user_id=" + f.user.ID
+	w = serve(f.router, http.MethodGet, download, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, pdf, w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, `attachment; filename*=utf-8''M%C3%A4rz%20receipt.pdf`, w.Header().Get("Content-Disposition"))
+
+	t.Run("another user's attachment", func(t *testing.T) {
+		other, err := f.database.CreateUser(context.Background(), "other@example.com")
+		require.NoError(t, err)
+		w := serve(f.router, http.MethodGet, "/api/v1/attachments/"+attachment.ID+"/download?user_id="+other.ID, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "attachment_not_found")
+		w = serve(f.router, http.MethodGet, "/api/v1/transactions/"+f.transaction.ID+"/attachments?user_id="+other.ID, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("trashed transaction", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, f.database.DeleteTransaction(ctx, f.user.ID, f.transaction.ID))
+		w := serve(f.router, http.MethodGet, download, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		_, err := f.database.RestoreTransaction(ctx, f.user.ID, f.transaction.ID)
+		require.NoError(t, err)
+		w = serve(f.router, http.MethodGet, download, nil)
+		assert.Equal(t, http.StatusOK, w.Code, "kept with the transaction in the trash")
+	})
+
+	w = serve(f.router, http.MethodDelete, "/api/v1/attachments/"+attachment.ID+"?user_id="+f.user.ID, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(f.router, http.MethodGet, download, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	keys, err := f.database.ListOrphanedBlobs(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1, "the blob is left to the cleaner")
+}
+
+func TestAttachmentHandler_UploadRejected(t *testing.T) {
+	limits := AttachmentLimits{MaxSize: 1024, ContentTypes: []string{"application/pdf", "image/png"}}
+
+	tests := []struct {
+		name     string
+		field    string
+		filename string
+		content  string
+		target   func(f attachmentFixture) string
+		status   int
+		code     string
+	}{
+		{name: "unsupported type", field: "file", filename: "notes.txt", content: "just text", status: http.StatusUnsupportedMediaType, code: "unsupported_attachment_type"},
+		{name: "type is sniffed, not taken from the name", field: "file", filename: "fake.pdf", content: "<html><script>", status: http.StatusUnsupportedMediaType, code: "unsupported_attachment_type"},
+		{name: "too large", field: "file", filename: "big.pdf", content: pdf + strings.Repeat("x", 1024), status: http.StatusRequestEntityTooLarge},
+		{name: "empty", field: "file", filename: "empty.pdf", content: "", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "missing file", field: "", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "wrong field", field: "upload", filename: "receipt.pdf", content: pdf, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "no filename", field: "file", filename: "", content: pdf, status: http.StatusBadRequest, code: "validation_failed"},
+		{
+			name: "unknown transaction", field: "file", filename: "receipt.pdf", content: pdf,
+			target: func(f attachmentFixture) string { return f.uploadTarget("660e8400-e29b-41d4-a716-446655440000") },
+			status: http.StatusNotFound, code: "transaction_not_found",
+		},
+		{
+			name: "invalid transaction id", field: "file", filename: "receipt.pdf", content: pdf,
+			target: func(f attachmentFixture) string { return f.uploadTarget("invalid") },
+			status: http.StatusBadRequest, code: "validation_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAttachmentFixture(t, limits)
+			target := f.uploadTarget(f.transaction.ID)
+			if tt.target != nil {
+				target = tt.target(f)
+			}
+
+			body, contentType := multipartBody(t, tt.field, tt.filename, tt.content)
+			req := httptest.NewRequest(http.MethodPost, target, body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			f.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			if tt.code != "" {
+				assert.Contains(t, w.Body.String(), tt.code)
+			}
+			assert.Zero(t, f.store.Len(), "nothing is left in the store")
+		})
+	}
+}
+
+func TestAttachmentHandler_RequestLimits(t *testing.T) {
+	f := newAttachmentFixture(t, AttachmentLimits{MaxSize: 1024, ContentTypes: []string{"application/pdf"}})
+
+	t.Run("JSON on the upload route", func(t *testing.T) {
+		w := serve(f.router, http.MethodPost, f.uploadTarget(f.transaction.ID), map[string]string{"file": pdf})
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("multipart elsewhere", func(t *testing.T) {
+		body, contentType := multipartBody(t, "file", "receipt.pdf", pdf)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		f.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("upload body over the limit", func(t *testing.T) {
+		w := upload(t, f.router, f.uploadTarget(f.transaction.ID), "big.pdf", pdf+strings.Repeat("x", maxMultipartOverhead+1024))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Zero(t, f.store.Len())
+	})
+
+	t.Run("JSON body over the limit", func(t *testing.T) {
+		w := serve(f.router, http.MethodPost, "/api/v1/categories", CreateCategoryRequest{UserID: f.user.ID, Name: strings.Repeat("x", maxRequestBodySize)})
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("JSON body over the limit without a length", func(t *testing.T) {
+		body := `{"user_id":"` + f.user.ID + `","name":"` + strings.Repeat("x", maxRequestBodySize) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", io.MultiReader(strings.NewReader(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+		w := httptest.NewRecorder()
+		f.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("idempotent uploads are replayed", func(t *testing.T) {
+		body, contentType := multipartBody(t, "file", "receipt.pdf", pdf)
+		send := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, f.uploadTarget(f.transaction.ID), bytes.NewReader(body.Bytes()))
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set(IdempotencyKeyHeader, "upload-1")
+			w := httptest.NewRecorder()
+			f.router.ServeHTTP(w, req)
+			return w
+		}
+		first := send()
+		require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+		second := send()
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, 1, f.store.Len())
+	})
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditHandler struct {
+	*Handler
+	db db.Database
+}
+
+func NewAuditHandler(logger zerolog.Logger, database db.Database) *AuditHandler {
+	return &AuditHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+	}
+}
+
+// ListAuditEntries lists the user's audit log, newest first. Pages after the
+// first are requested with before_id, the id of the last entry seen.
+func (h *AuditHandler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+
+	filter := models.AuditFilter{
+		Entity:    query.Get("entity"),
+		EntityID:  query.Get("entity_id"),
+		Action:    query.Get("action"),
+		Actor:     query.Get("actor"),
+		RequestID: query.Get("request_id"),
+		From:      timeParam(&v, query, "from"),
+		To:        timeParam(&v, query, "to"),
+		Limit:     defaultAuditLimit,
+	}
+	if filter.Entity != "" {
+		v.Check("entity", filter.Entity, validator.ValidateOneOf("entity", filter.Entity, models.AuditEntities))
+	}
+	if filter.Action != "" {
+		v.Check("action", filter.Action, validator.ValidateOneOf("action", filter.Action, models.AuditActions))
+	}
+	v.Check("from", nil, validator.ValidateDateRange(filter.From, filter.To))
+
+	if limit := intParam(&v, query, "limit"); limit != nil {
+		filter.Limit = *limit
+		v.Check("limit", *limit, validator.ValidateLimit(*limit, maxAuditLimit))
+	}
+	if beforeID := intParam(&v, query, "before_id"); beforeID != nil {
+		if *beforeID < 1 {
+			v.Add("before_id", validator.RuleMin, "'before_id' must be the id of an audit entry", *beforeID)
+		}
+		filter.BeforeID = int64(*beforeID)
+	}
+
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	entries, err := h.db.ListAuditEntries(r.Context(), userID, filter)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list audit entries")
+		return
+	}
+
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, entries)
+}
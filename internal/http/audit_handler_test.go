@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/models"
+)
+
+func TestAuditHandler_ListAuditEntries(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("success with filters", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewAuditHandler(logger, mockDB)
+
+		from := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+		filter := models.AuditFilter{
+			Entity:    models.EntityTransaction,
+			EntityID:  "770e8400-e29b-41d4-a716-446655440002",
+			Action:    models.AuditDelete,
+			Actor:     "ip:192.0.2.1",
+			RequestID: "req-123",
+			From:      &from,
+			BeforeID:  42,
+			Limit:     10,
+		}
+		expected := []models.AuditEntry{{
+			ID:       41,
+			UserID:   userID,
+			Actor:    "ip:192.0.2.1",
+			Entity:   models.EntityTransaction,
+			EntityID: filter.EntityID,
+			Action:   models.AuditDelete,
+			Before:   json.RawMessage(`{"amount":10}`),
+		}}
+		mockDB.On("ListAuditEntries", mock.Anything, userID, filter).Return(expected, nil)
+
+		q := url.Values{}
+		q.Set("user_id", userID)
+		q.Set("entity", filter.Entity)
+		q.Set("entity_id", filter.EntityID)
+		q.Set("action", filter.Action)
+		q.Set("actor", filter.Actor)
+		q.Set("request_id", filter.RequestID)
+		q.Set("from", from.Format(time.RFC3339))
+		q.Set("before_id", "42")
+		q.Set("limit", "10")
+		req := httptest.NewRequest(http.MethodGet, "/audit?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListAuditEntries(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertJSONContentType(t, w)
+		assert.Contains(t, w.Body.String(), `"before":{"amount":10},"after":null`)
+
+		var resp []models.AuditEntry
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp, 1)
+		assert.Equal(t, int64(41), resp[0].ID)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("empty log is an empty array", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewAuditHandler(logger, mockDB)
+		mockDB.On("ListAuditEntries", mock.Anything, userID, models.AuditFilter{Limit: defaultAuditLimit}).Return(nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/audit?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.ListAuditEntries(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("reports every invalid parameter", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewAuditHandler(logger, mockDB)
+
+		q := url.Values{}
+		q.Set("user_id", userID)
+		q.Set("entity", "budget")
+		q.Set("action", "read")
+		q.Set("from", "2024-02-01T00:00:00Z")
+		q.Set("to", "2024-01-01T00:00:00Z")
+		q.Set("limit", "5000")
+		q.Set("before_id", "0")
+		req := httptest.NewRequest(http.MethodGet, "/audit?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListAuditEntries(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp struct {
+			Error struct {
+				Details []struct {
+					Field string `json:"field"`
+					Rule  string `json:"rule"`
+				} `json:"details"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		var fields []string
+		for _, detail := range resp.Error.Details {
+			fields = append(fields, detail.Field+":"+detail.Rule)
+		}
+		assert.Equal(t, []string{"entity:one_of", "action:one_of", "from:range", "limit:max", "before_id:min"}, fields)
+		mockDB.AssertNotCalled(t, "ListAuditEntries")
+	})
+
+	t.Run("missing user_id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewAuditHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodGet, "/audit", nil)
+		w := httptest.NewRecorder()
+
+		handler.ListAuditEntries(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDB.AssertNotCalled(t, "ListAuditEntries")
+	})
+}
@@ -1,11 +1,11 @@
 package http
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/rs/zerolog"
 	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
 	"fintrack-go/internal/validator"
 )
 
@@ -28,39 +28,21 @@ type CreateCategoryRequest struct {
 
 func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	var req CreateCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Invalid request body", nil)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	if err := validator.ValidateUUID(req.UserID); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-			"field": "user_id",
-			"value": req.UserID,
-		})
-		return
-	}
-
-	if err := validator.ValidateCategoryName(req.Name); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-			"field": "name",
-			"value": req.Name,
-		})
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("name", req.Name, validator.ValidateCategoryName(req.Name))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
 		return
 	}
 
 	category, err := h.db.CreateCategory(r.Context(), req.UserID, req.Name)
 	if err != nil {
-		if err == db.ErrUserNotFound {
-			h.respondWithError(w, http.StatusNotFound, "User not found", nil)
-			return
-		}
-		if err == db.ErrDuplicateCategory {
-			h.respondWithError(w, http.StatusConflict, "Category name already exists for this user", nil)
-			return
-		}
-		h.Logger.Error().Err(err).Msg("Failed to create category")
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to create category", nil)
+		h.respondWithDBError(w, err, "Failed to create category")
 		return
 	}
 
@@ -69,25 +51,39 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 
 func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		h.respondWithError(w, http.StatusBadRequest, "user_id query parameter is required", nil)
-		return
-	}
 
-	if err := validator.ValidateUUID(userID); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-			"field": "user_id",
-			"value": userID,
-		})
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
 		return
 	}
 
 	categories, err := h.db.ListCategories(r.Context(), userID)
 	if err != nil {
-		h.Logger.Error().Err(err).Msg("Failed to list categories")
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to list categories", nil)
+		h.respondWithDBError(w, err, "Failed to list categories")
 		return
 	}
 
+	if categories == nil {
+		categories = []models.Category{}
+	}
+
 	h.respondWithJSON(w, http.StatusOK, categories)
 }
+
+// DeleteCategory moves a category to the trash. Its transactions keep it
+// until it is purged.
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteCategory(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete category")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -260,3 +260,51 @@ func TestCategoryHandler_ListCategories(t *testing.T) {
 		mockDB.AssertExpectations(t)
 	})
 }
+
+func TestCategoryHandler_DeleteCategory(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	id := "660e8400-e29b-41d4-a716-446655440001"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewCategoryHandler(logger, mockDB)
+		mockDB.On("DeleteCategory", mock.Anything, userID, id).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/categories/"+id+"?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteCategory(w, withURLParam(req, "id", id))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewCategoryHandler(logger, mockDB)
+		mockDB.On("DeleteCategory", mock.Anything, userID, id).Return(db.ErrCategoryNotFound)
+
+		req := httptest.NewRequest(http.MethodDelete, "/categories/"+id+"?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteCategory(w, withURLParam(req, "id", id))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "category_not_found")
+	})
+
+	t.Run("missing user_id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewCategoryHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodDelete, "/categories/"+id, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteCategory(w, withURLParam(req, "id", id))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDB.AssertNotCalled(t, "DeleteCategory")
+	})
+}
@@ -0,0 +1,315 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/debts"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+type DebtHandler struct {
+	*Handler
+	db db.Database
+}
+
+func NewDebtHandler(logger zerolog.Logger, database db.Database) *DebtHandler {
+	return &DebtHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+	}
+}
+
+// CreateDebtRequest describes a loan repaid in term_months monthly
+// installments from first_payment_date. Payment defaults to the installment
+// that repays the principal over the term.
+type CreateDebtRequest struct {
+	UserID           string     `json:"user_id"`
+	Name             string     `json:"name"`
+	Principal        float64    `json:"principal"`
+	AnnualRate       float64    `json:"annual_rate"`
+	TermMonths       int        `json:"term_months"`
+	Payment          *float64   `json:"payment,omitempty"`
+	FirstPaymentDate *time.Time `json:"first_payment_date"`
+}
+
+// CreateDebtPaymentRequest either links one of the user's transactions or
+// records a manual payment of amount.
+type CreateDebtPaymentRequest struct {
+	UserID        string     `json:"user_id"`
+	TransactionID *string    `json:"transaction_id,omitempty"`
+	Amount        *float64   `json:"amount,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	Note          *string    `json:"note,omitempty"`
+}
+
+func (h *DebtHandler) CreateDebt(w http.ResponseWriter, r *http.Request) {
+	var req CreateDebtRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("name", req.Name, validator.ValidateDebtName(req.Name))
+	v.Check("principal", req.Principal, validator.ValidateAmount(req.Principal))
+	v.Check("annual_rate", req.AnnualRate, validator.ValidateRate(req.AnnualRate))
+	v.Check("term_months", req.TermMonths, validator.ValidateTerm(req.TermMonths))
+	if req.FirstPaymentDate == nil {
+		v.Add("first_payment_date", validator.RuleRequired, "first_payment_date is required", nil)
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	payment := debts.Payment(req.Principal, req.AnnualRate, req.TermMonths)
+	if req.Payment != nil {
+		payment = *req.Payment
+		// A payment that doesn't cover the interest never repays anything.
+		interest := debts.Interest(req.Principal, req.AnnualRate)
+		if err := validator.ValidateAmount(payment); err != nil {
+			v.Check("payment", payment, err)
+		} else if payment <= interest {
+			v.Add("payment", validator.RuleMin, fmt.Sprintf("payment must exceed the monthly interest of %.2f", interest), payment)
+		}
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	debt, err := h.db.CreateDebt(r.Context(), req.UserID, models.Debt{
+		Name:             req.Name,
+		Principal:        req.Principal,
+		AnnualRate:       req.AnnualRate,
+		TermMonths:       req.TermMonths,
+		Payment:          payment,
+		FirstPaymentDate: *req.FirstPaymentDate,
+	})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create debt")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, debt)
+}
+
+func (h *DebtHandler) ListDebts(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	userDebts, err := h.db.ListDebts(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list debts")
+		return
+	}
+
+	if userDebts == nil {
+		userDebts = []models.Debt{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, userDebts)
+}
+
+func (h *DebtHandler) GetDebt(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	debt, err := h.db.GetDebt(r.Context(), userID, id)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get debt")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, debt)
+}
+
+func (h *DebtHandler) DeleteDebt(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteDebt(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete debt")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreatePayment records a payment toward a debt. One linked to a
+// transaction takes its amount and date from it; a manual one is dated now
+// unless paid_at is given.
+func (h *DebtHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	debtID := chi.URLParam(r, "id")
+	var req CreateDebtPaymentRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("id", debtID, validator.ValidateUUID(debtID))
+	if req.TransactionID != nil {
+		v.Check("transaction_id", *req.TransactionID, validator.ValidateUUID(*req.TransactionID))
+		if req.Amount != nil || req.PaidAt != nil {
+			v.Add("transaction_id", validator.RuleExclusive, "'transaction_id' cannot be combined with 'amount' or 'paid_at'", nil)
+		}
+	} else if req.Amount == nil {
+		v.Add("amount", validator.RuleRequired, "amount is required without transaction_id", nil)
+	} else {
+		v.Check("amount", *req.Amount, validator.ValidateAmount(*req.Amount))
+	}
+	v.Check("note", nil, validator.ValidateNote(req.Note))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	payment := models.DebtPayment{
+		TransactionID: req.TransactionID,
+		Note:          req.Note,
+	}
+	if req.TransactionID == nil {
+		payment.Amount = *req.Amount
+		payment.PaidAt = time.Now()
+		if req.PaidAt != nil {
+			payment.PaidAt = *req.PaidAt
+		}
+	}
+
+	created, err := h.db.CreateDebtPayment(r.Context(), req.UserID, debtID, payment)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create debt payment")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *DebtHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	userID, debtID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	payments, err := h.db.ListDebtPayments(r.Context(), userID, debtID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list debt payments")
+		return
+	}
+
+	if payments == nil {
+		payments = []models.DebtPayment{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, payments)
+}
+
+func (h *DebtHandler) DeletePayment(w http.ResponseWriter, r *http.Request) {
+	paymentID := chi.URLParam(r, "payment_id")
+	var v validator.Validator
+	v.Check("payment_id", paymentID, validator.ValidateUUID(paymentID))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+	userID, debtID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteDebtPayment(r.Context(), userID, debtID, paymentID); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete debt payment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSchedule returns a debt's amortisation schedule with the payments made
+// so far matched to its installments.
+func (h *DebtHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, debtID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	debt, payments, ok := h.debtWithPayments(w, r, userID, debtID)
+	if !ok {
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, debts.Schedule(*debt, payments, time.Now()))
+}
+
+// GetReport reports the principal and interest a debt's payments have paid
+// so far, and when it will be paid off as scheduled and with each
+// extra_monthly amount paid on top of every installment.
+func (h *DebtHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	userID, debtID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	var v validator.Validator
+	values := r.URL.Query()["extra_monthly"]
+	if len(values) > debts.MaxScenarios {
+		v.Add("extra_monthly", validator.RuleMax, fmt.Sprintf("extra_monthly cannot be given more than %d times", debts.MaxScenarios), len(values))
+	}
+	var extras []float64
+	for _, s := range values {
+		extra, ok := parseNumber(s)
+		if !ok {
+			v.Add("extra_monthly", validator.RuleType, "Invalid 'extra_monthly'. Must be a number", s)
+			continue
+		}
+		if err := validator.ValidateAmount(extra); err != nil {
+			v.Check("extra_monthly", extra, err)
+			continue
+		}
+		extras = append(extras, extra)
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	debt, payments, ok := h.debtWithPayments(w, r, userID, debtID)
+	if !ok {
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, debts.Report(*debt, payments, time.Now(), extras))
+}
+
+// debtWithPayments loads one of the user's debts and its payments, and
+// responds with the error if either fails.
+func (h *DebtHandler) debtWithPayments(w http.ResponseWriter, r *http.Request, userID, debtID string) (*models.Debt, []models.DebtPayment, bool) {
+	debt, err := h.db.GetDebt(r.Context(), userID, debtID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get debt")
+		return nil, nil, false
+	}
+	payments, err := h.db.ListDebtPayments(r.Context(), userID, debtID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list debt payments")
+		return nil, nil, false
+	}
+	return debt, payments, true
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func newDebtRouter(t *testing.T) (*db.MemoryDB, http.Handler, *models.User) {
+	t.Helper()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(context.Background(), "loans@example.com")
+	require.NoError(t, err)
+	return database, ContentType(SetupRoutes(zerolog.Nop(), database)), user
+}
+
+func TestDebtHandler(t *testing.T) {
+	database, router, user := newDebtRouter(t)
+	ctx := context.Background()
+	firstPayment := time.Now().UTC().AddDate(0, -2, -5).Truncate(time.Second)
+
+	w := serve(router, http.MethodPost, "/api/v1/debts", CreateDebtRequest{
+		UserID: user.ID, Name: "Car loan", Principal: 10000, AnnualRate: 6, TermMonths: 12, FirstPaymentDate: &firstPayment,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var debt models.Debt
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&debt))
+	assert.Equal(t, "Car loan", debt.Name)
+	assert.Equal(t, 860.66, debt.Payment, "the installment that repays the principal over the term")
+	assert.True(t, firstPayment.Equal(debt.FirstPaymentDate))
+
+	debtURL := "/api/v1/debts/" + debt.ID
+	query := "?user_id=" + user.ID
+
+	w = serve(router, http.MethodGet, "/api/v1/debts"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var userDebts []models.Debt
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&userDebts))
+	require.Len(t, userDebts, 1)
+	assert.Equal(t, debt.ID, userDebts[0].ID)
+
+	w = serve(router, http.MethodGet, debtURL+query, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	transaction, err := database.CreateTransaction(ctx, user.ID, nil, 860.66, nil, firstPayment.Add(time.Hour))
+	require.NoError(t, err)
+	w = serve(router, http.MethodPost, debtURL+"/payments", CreateDebtPaymentRequest{UserID: user.ID, TransactionID: &transaction.ID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var linked models.DebtPayment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&linked))
+	assert.Equal(t, 860.66, linked.Amount, "taken from the transaction")
+
+	w = serve(router, http.MethodPost, debtURL+"/payments", CreateDebtPaymentRequest{UserID: user.ID, TransactionID: &transaction.ID})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "transaction_already_paid")
+
+	amount := 860.66
+	paidAt := firstPayment.AddDate(0, 1, -1)
+	w = serve(router, http.MethodPost, debtURL+"/payments", CreateDebtPaymentRequest{UserID: user.ID, Amount: &amount, PaidAt: &paidAt})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var manual models.DebtPayment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&manual))
+
+	w = serve(router, http.MethodGet, debtURL+"/payments"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var payments []models.DebtPayment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&payments))
+	require.Len(t, payments, 2)
+	assert.Equal(t, linked.ID, payments[0].ID, "earliest first")
+
+	w = serve(router, http.MethodGet, debtURL+"/schedule"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var schedule models.DebtSchedule
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&schedule))
+	require.Len(t, schedule.Installments, 12)
+	assert.Equal(t, []string{linked.ID}, schedule.Installments[0].PaymentIDs)
+	assert.Equal(t, models.InstallmentPaid, schedule.Installments[0].Status)
+	assert.Equal(t, []string{manual.ID}, schedule.Installments[1].PaymentIDs, "matched to the nearest due date")
+	assert.Equal(t, models.InstallmentOverdue, schedule.Installments[2].Status)
+	assert.Equal(t, models.InstallmentUpcoming, schedule.Installments[3].Status)
+
+	w = serve(router, http.MethodGet, debtURL+"/report"+query+"&extra_monthly=500&extra_monthly=1000", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var report models.DebtReport
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	assert.Equal(t, 2, report.InstallmentsPaid)
+	assert.Equal(t, 1, report.InstallmentsOverdue)
+	assert.Equal(t, 95.95, report.InterestPaid)
+	assert.Equal(t, 1625.37, report.PrincipalPaid)
+	require.Len(t, report.Scenarios, 3)
+	assert.Zero(t, report.Scenarios[0].ExtraMonthly, "as scheduled")
+	assert.Equal(t, 500.0, report.Scenarios[1].ExtraMonthly)
+	assert.Positive(t, report.Scenarios[1].InstallmentsSaved)
+	assert.Greater(t, report.Scenarios[2].InterestSaved, report.Scenarios[1].InterestSaved)
+
+	w = serve(router, http.MethodDelete, debtURL+"/payments/"+manual.ID+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodDelete, debtURL+"/payments/"+manual.ID+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "debt_payment_not_found")
+
+	t.Run("another user's debt", func(t *testing.T) {
+		other, err := database.CreateUser(ctx, "other@example.com")
+		require.NoError(t, err)
+		for _, target := range []string{debtURL, debtURL + "/payments", debtURL + "/schedule", debtURL + "/report"} {
+			w := serve(router, http.MethodGet, target+"?user_id="+other.ID, nil)
+			assert.Equal(t, http.StatusNotFound, w.Code, target)
+			assert.Contains(t, w.Body.String(), "debt_not_found")
+		}
+	})
+
+	w = serve(router, http.MethodDelete, debtURL+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodGet, debtURL+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDebtHandler_Rejected(t *testing.T) {
+	_, router, user := newDebtRouter(t)
+	unknownID := "660e8400-e29b-41d4-a716-446655440000"
+	now := time.Now()
+	amount := 10.0
+	zero := 0.0
+	tooLow := 50.0
+
+	w := serve(router, http.MethodPost, "/api/v1/debts", CreateDebtRequest{
+		UserID: user.ID, Name: "Mortgage", Principal: 200000, AnnualRate: 4, TermMonths: 300, FirstPaymentDate: &now,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var debt models.Debt
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&debt))
+	debtURL := "/api/v1/debts/" + debt.ID
+	query := "?user_id=" + user.ID
+
+	loan := func(change func(*CreateDebtRequest)) CreateDebtRequest {
+		req := CreateDebtRequest{UserID: user.ID, Name: "Loan", Principal: 10000, AnnualRate: 6, TermMonths: 12, FirstPaymentDate: &now}
+		change(&req)
+		return req
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   any
+		status int
+		code   string
+	}{
+		{name: "no name", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.Name = " " }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "no principal", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.Principal = 0 }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "negative rate", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.AnnualRate = -1 }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "no term", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.TermMonths = 0 }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "no first payment date", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.FirstPaymentDate = nil }), status: http.StatusBadRequest, code: "required"},
+		{name: "payment of zero", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.Payment = &zero }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "payment below interest", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.Payment = &tooLow }), status: http.StatusBadRequest, code: "monthly interest of 50.00"},
+		{name: "unknown user", method: http.MethodPost, target: "/api/v1/debts", body: loan(func(r *CreateDebtRequest) { r.UserID = unknownID }), status: http.StatusNotFound, code: "user_not_found"},
+		{name: "payment without amount", method: http.MethodPost, target: debtURL + "/payments", body: CreateDebtPaymentRequest{UserID: user.ID}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "payment of zero amount", method: http.MethodPost, target: debtURL + "/payments", body: CreateDebtPaymentRequest{UserID: user.ID, Amount: &zero}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "transaction with amount", method: http.MethodPost, target: debtURL + "/payments", body: CreateDebtPaymentRequest{UserID: user.ID, TransactionID: &unknownID, Amount: &amount}, status: http.StatusBadRequest, code: "exclusive"},
+		{name: "transaction with date", method: http.MethodPost, target: debtURL + "/payments", body: CreateDebtPaymentRequest{UserID: user.ID, TransactionID: &unknownID, PaidAt: &now}, status: http.StatusBadRequest, code: "exclusive"},
+		{name: "unknown transaction", method: http.MethodPost, target: debtURL + "/payments", body: CreateDebtPaymentRequest{UserID: user.ID, TransactionID: &unknownID}, status: http.StatusNotFound, code: "transaction_not_found"},
+		{name: "unknown debt", method: http.MethodPost, target: "/api/v1/debts/" + unknownID + "/payments", body: CreateDebtPaymentRequest{UserID: user.ID, Amount: &amount}, status: http.StatusNotFound, code: "debt_not_found"},
+		{name: "invalid debt id", method: http.MethodPost, target: "/api/v1/debts/invalid/payments", body: CreateDebtPaymentRequest{UserID: user.ID, Amount: &amount}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "invalid payment id", method: http.MethodDelete, target: debtURL + "/payments/invalid" + query, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "extra not a number", method: http.MethodGet, target: debtURL + "/report" + query + "&extra_monthly=lots", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "extra not a finite number", method: http.MethodGet, target: debtURL + "/report" + query + "&extra_monthly=NaN", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "extra of zero", method: http.MethodGet, target: debtURL + "/report" + query + "&extra_monthly=0", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "too many extras", method: http.MethodGet, target: debtURL + "/report" + query + "&extra_monthly=1&extra_monthly=2&extra_monthly=3&extra_monthly=4&extra_monthly=5&extra_monthly=6", status: http.StatusBadRequest, code: "more than 5 times"},
+		{name: "missing user_id", method: http.MethodGet, target: "/api/v1/debts", status: http.StatusBadRequest, code: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.code)
+		})
+	}
+}
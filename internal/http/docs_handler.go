@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"fintrack-go/api"
+)
+
+// DocsHandler serves the OpenAPI document and a page for browsing it.
+type DocsHandler struct {
+	*Handler
+}
+
+func NewDocsHandler(logger zerolog.Logger) *DocsHandler {
+	return &DocsHandler{
+		Handler: NewHandler(logger),
+	}
+}
+
+func (h *DocsHandler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	h.write(w, "application/json", api.OpenAPI)
+}
+
+func (h *DocsHandler) Docs(w http.ResponseWriter, r *http.Request) {
+	h.write(w, "text/html; charset=utf-8", api.DocsPage)
+}
+
+func (h *DocsHandler) write(w http.ResponseWriter, contentType string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if _, err := w.Write(body); err != nil {
+		h.Logger.Error().Err(err).Msg("Failed to write documentation")
+	}
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+type DuplicateHandler struct {
+	*Handler
+	db db.Database
+}
+
+func NewDuplicateHandler(logger zerolog.Logger, database db.Database) *DuplicateHandler {
+	return &DuplicateHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+	}
+}
+
+type MergeDuplicatesRequest struct {
+	UserID    string `json:"user_id"`
+	KeepID    string `json:"keep_id"`
+	DiscardID string `json:"discard_id"`
+}
+
+type DismissDuplicateRequest struct {
+	UserID        string `json:"user_id"`
+	TransactionID string `json:"transaction_id"`
+	DuplicateID   string `json:"duplicate_id"`
+}
+
+func (h *DuplicateHandler) ListDuplicates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+
+	windowDays := db.DefaultDuplicateWindowDays
+	if windowStr := query.Get("window_days"); windowStr != "" {
+		days, err := strconv.Atoi(windowStr)
+		if err != nil {
+			v.Add("window_days", validator.RuleType, "Invalid 'window_days'. Must be a whole number", windowStr)
+		} else {
+			windowDays = days
+			v.Check("window_days", windowDays, validator.ValidateDuplicateWindow(windowDays))
+		}
+	}
+
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	pairs, err := h.db.FindDuplicateTransactions(r.Context(), userID, windowDays)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to find duplicate transactions")
+		return
+	}
+
+	if pairs == nil {
+		pairs = []models.DuplicatePair{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, pairs)
+}
+
+func (h *DuplicateHandler) MergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	var req MergeDuplicatesRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !h.validateIDs(w, [][2]string{{"user_id", req.UserID}, {"keep_id", req.KeepID}, {"discard_id", req.DiscardID}}) {
+		return
+	}
+
+	transaction, err := h.db.MergeDuplicateTransactions(r.Context(), req.UserID, req.KeepID, req.DiscardID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to merge duplicate transactions")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, transaction)
+}
+
+func (h *DuplicateHandler) DismissDuplicate(w http.ResponseWriter, r *http.Request) {
+	var req DismissDuplicateRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !h.validateIDs(w, [][2]string{{"user_id", req.UserID}, {"transaction_id", req.TransactionID}, {"duplicate_id", req.DuplicateID}}) {
+		return
+	}
+
+	if err := h.db.DismissDuplicate(r.Context(), req.UserID, req.TransactionID, req.DuplicateID); err != nil {
+		h.respondWithDBError(w, err, "Failed to dismiss duplicate")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// validateIDs checks each field/value pair and responds with every invalid
+// one, reporting whether all were valid.
+func (h *DuplicateHandler) validateIDs(w http.ResponseWriter, fields [][2]string) bool {
+	var v validator.Validator
+	for _, f := range fields {
+		v.Check(f[0], f[1], validator.ValidateUUID(f[1]))
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return false
+	}
+	return true
+}
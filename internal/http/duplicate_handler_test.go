@@ -0,0 +1,236 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func TestDuplicateHandler_ListDuplicates(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("success with default window", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+
+		expected := []models.DuplicatePair{
+			{
+				Transaction: models.Transaction{ID: "770e8400-e29b-41d4-a716-446655440001", UserID: userID, Amount: 12.5, OccurredAt: time.Now()},
+				Duplicate:   models.Transaction{ID: "770e8400-e29b-41d4-a716-446655440002", UserID: userID, Amount: 12.5, OccurredAt: time.Now()},
+				Similarity:  1,
+			},
+		}
+		mockDB.On("FindDuplicateTransactions", mock.Anything, userID, db.DefaultDuplicateWindowDays).Return(expected, nil)
+
+		q := url.Values{}
+		q.Set("user_id", userID)
+		req := httptest.NewRequest(http.MethodGet, "/transactions/duplicates?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListDuplicates(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertJSONContentType(t, w)
+
+		var resp []models.DuplicatePair
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp, 1)
+		assert.Equal(t, expected[0].Transaction.ID, resp[0].Transaction.ID)
+		assert.Equal(t, expected[0].Duplicate.ID, resp[0].Duplicate.ID)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("empty result is an empty array", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+		mockDB.On("FindDuplicateTransactions", mock.Anything, userID, 7).Return(nil, nil)
+
+		q := url.Values{}
+		q.Set("user_id", userID)
+		q.Set("window_days", "7")
+		req := httptest.NewRequest(http.MethodGet, "/transactions/duplicates?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListDuplicates(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		for _, window := range []string{"abc", "-1", "31"} {
+			mockDB := new(MockDBForHandler)
+			handler := NewDuplicateHandler(logger, mockDB)
+
+			q := url.Values{}
+			q.Set("user_id", userID)
+			q.Set("window_days", window)
+			req := httptest.NewRequest(http.MethodGet, "/transactions/duplicates?"+q.Encode(), nil)
+			w := httptest.NewRecorder()
+
+			handler.ListDuplicates(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, "window_days=%s", window)
+			mockDB.AssertNotCalled(t, "FindDuplicateTransactions", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("missing user_id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodGet, "/transactions/duplicates", nil)
+		w := httptest.NewRecorder()
+
+		handler.ListDuplicates(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+		mockDB.On("FindDuplicateTransactions", mock.Anything, mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+		q := url.Values{}
+		q.Set("user_id", userID)
+		req := httptest.NewRequest(http.MethodGet, "/transactions/duplicates?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListDuplicates(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestDuplicateHandler_MergeDuplicates(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	keepID := "770e8400-e29b-41d4-a716-446655440001"
+	discardID := "770e8400-e29b-41d4-a716-446655440002"
+
+	newRequest := func(body map[string]string) *http.Request {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/transactions/duplicates/merge", bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+		kept := &models.Transaction{ID: keepID, UserID: userID, Amount: 12.5}
+		mockDB.On("MergeDuplicateTransactions", mock.Anything, userID, keepID, discardID).Return(kept, nil)
+
+		w := httptest.NewRecorder()
+		handler.MergeDuplicates(w, newRequest(map[string]string{"user_id": userID, "keep_id": keepID, "discard_id": discardID}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp models.Transaction
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, keepID, resp.ID)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("invalid keep_id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+
+		w := httptest.NewRecorder()
+		handler.MergeDuplicates(w, newRequest(map[string]string{"user_id": userID, "keep_id": "bad", "discard_id": discardID}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		details := resp["error"].(map[string]interface{})["details"].([]interface{})
+		require.Len(t, details, 1)
+		assert.Equal(t, "keep_id", details[0].(map[string]interface{})["field"])
+	})
+
+	t.Run("same transaction", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+		mockDB.On("MergeDuplicateTransactions", mock.Anything, userID, keepID, keepID).Return(nil, db.ErrSameTransaction)
+
+		w := httptest.NewRecorder()
+		handler.MergeDuplicates(w, newRequest(map[string]string{"user_id": userID, "keep_id": keepID, "discard_id": keepID}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+		mockDB.On("MergeDuplicateTransactions", mock.Anything, userID, keepID, discardID).Return(nil, db.ErrTransactionNotFound)
+
+		w := httptest.NewRecorder()
+		handler.MergeDuplicates(w, newRequest(map[string]string{"user_id": userID, "keep_id": keepID, "discard_id": discardID}))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDuplicateHandler_DismissDuplicate(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	firstID := "770e8400-e29b-41d4-a716-446655440001"
+	secondID := "770e8400-e29b-41d4-a716-446655440002"
+
+	newRequest := func(body map[string]string) *http.Request {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/transactions/duplicates/dismiss", bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+		mockDB.On("DismissDuplicate", mock.Anything, userID, firstID, secondID).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.DismissDuplicate(w, newRequest(map[string]string{"user_id": userID, "transaction_id": firstID, "duplicate_id": secondID}))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+		mockDB.On("DismissDuplicate", mock.Anything, userID, firstID, secondID).Return(db.ErrTransactionNotFound)
+
+		w := httptest.NewRecorder()
+		handler.DismissDuplicate(w, newRequest(map[string]string{"user_id": userID, "transaction_id": firstID, "duplicate_id": secondID}))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewDuplicateHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodPost, "/transactions/duplicates/dismiss", bytes.NewBufferString("{"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.DismissDuplicate(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/validator"
+)
+
+// statusForKind is the HTTP status for each kind of domain error.
+var statusForKind = map[error]int{
+	db.ErrNotFound:   http.StatusNotFound,
+	db.ErrConflict:   http.StatusConflict,
+	db.ErrForbidden:  http.StatusForbidden,
+	db.ErrValidation: http.StatusBadRequest,
+}
+
+// respondWithDBError writes the response for an error from the database
+// layer. Domain errors get the status of their kind and keep their code, so
+// clients can rely on it; any other error is logged and reported as a 500
+// with message.
+func (h *Handler) respondWithDBError(w http.ResponseWriter, err error, message string) {
+	var domainErr *db.Error
+	if !errors.As(err, &domainErr) {
+		h.Logger.Error().Err(err).Msg(message)
+		h.respondWithError(w, http.StatusInternalServerError, message, nil)
+		return
+	}
+
+	status, ok := statusForKind[domainErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	var details any
+	if domainErr.Field != "" {
+		details = validator.Errors{{Field: domainErr.Field, Rule: domainErr.Code, Message: domainErr.Message}}
+	}
+
+	h.writeError(w, status, domainErr.Code, capitalize(domainErr.Message), details)
+}
+
+// capitalize turns an error string into a sentence for a response message.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+)
+
+func TestHandler_respondWithDBError(t *testing.T) {
+	handler := NewHandler(zerolog.Nop())
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+		wantField   string
+	}{
+		{
+			name:        "not found",
+			err:         db.ErrUserNotFound,
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "user_not_found",
+			wantMessage: "User not found",
+		},
+		{
+			name:        "conflict",
+			err:         db.ErrDuplicateEmail,
+			wantStatus:  http.StatusConflict,
+			wantCode:    "email_taken",
+			wantMessage: "Email already exists",
+			wantField:   "email",
+		},
+		{
+			name:        "validation",
+			err:         db.ErrCategoryNotOwned,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "category_not_owned",
+			wantMessage: "Category does not belong to user",
+			wantField:   "category_id",
+		},
+		{
+			name:        "forbidden",
+			err:         &db.Error{Kind: db.ErrForbidden, Code: "not_yours", Message: "not yours"},
+			wantStatus:  http.StatusForbidden,
+			wantCode:    "not_yours",
+			wantMessage: "Not yours",
+		},
+		{
+			name:        "wrapped",
+			err:         fmt.Errorf("creating transaction: %w", db.ErrTransactionNotFound),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "transaction_not_found",
+			wantMessage: "Transaction not found",
+		},
+		{
+			name:        "other errors are internal",
+			err:         errors.New("connection reset"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    "Internal Server Error",
+			wantMessage: "Failed to do the thing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.respondWithDBError(w, tt.err, "Failed to do the thing")
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assertJSONContentType(t, w)
+
+			var resp ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, tt.wantCode, resp.Error.Code)
+			assert.Equal(t, tt.wantMessage, resp.Error.Message)
+			if tt.wantField == "" {
+				assert.Nil(t, resp.Error.Details)
+			} else {
+				require.IsType(t, []any{}, resp.Error.Details)
+				details := resp.Error.Details.([]any)
+				require.Len(t, details, 1)
+				assert.Equal(t, tt.wantField, details[0].(map[string]any)["field"])
+				assert.Equal(t, tt.wantCode, details[0].(map[string]any)["rule"])
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+const (
+	// eventsPageSize is how many events are read at a time when catching up.
+	eventsPageSize = 100
+	// eventsHeartbeat is how often an idle stream sends a comment, so that
+	// proxies don't time the connection out.
+	eventsHeartbeat = 15 * time.Second
+	// eventsRetry is how long clients are told to wait before reconnecting.
+	eventsRetry = 3 * time.Second
+)
+
+type EventsHandler struct {
+	*Handler
+	db       db.Database
+	shutdown context.Context
+}
+
+// NewEventsHandler returns a handler whose streams end when shutdown is
+// done, as well as when their clients go away.
+func NewEventsHandler(logger zerolog.Logger, database db.Database, shutdown context.Context) *EventsHandler {
+	return &EventsHandler{
+		Handler:  NewHandler(logger),
+		db:       database,
+		shutdown: shutdown,
+	}
+}
+
+// Stream sends the user's events as server-sent events, starting after the
+// one named by the Last-Event-ID header, or with those that happen from now
+// on if there is none, and then as they happen.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	lastEventID := r.Header.Get("Last-Event-ID")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	var cursor int64
+	if lastEventID != "" {
+		var err error
+		cursor, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || cursor < 0 {
+			v.Add("Last-Event-ID", validator.RuleFormat, "Last-Event-ID must be the id of an event", lastEventID)
+		}
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.db.GetUserByID(ctx, userID); err != nil {
+		h.respondWithDBError(w, err, "Failed to stream events")
+		return
+	}
+
+	// Watch before looking for events, so that none published in between
+	// goes unnoticed.
+	wake, err := h.db.WatchEvents(ctx, userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to stream events")
+		return
+	}
+	if lastEventID == "" {
+		cursor, err = h.db.LastEventID(ctx, userID)
+		if err != nil {
+			h.respondWithDBError(w, err, "Failed to stream events")
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop nginx and the like from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventsRetry.Milliseconds())
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	logger := h.Logger.With().Str("user_id", userID).Logger()
+	for {
+		if cursor, err = h.sendEvents(ctx, w, userID, cursor); err != nil {
+			if ctx.Err() == nil {
+				logger.Error().Err(err).Msg("Failed to stream events")
+			}
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.shutdown.Done():
+			return
+		case _, ok := <-wake:
+			if !ok {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendEvents writes the user's events after cursor and returns the id of the
+// last one written.
+func (h *EventsHandler) sendEvents(ctx context.Context, w http.ResponseWriter, userID string, cursor int64) (int64, error) {
+	for {
+		events, err := h.db.ListEvents(ctx, userID, cursor, eventsPageSize)
+		if err != nil {
+			return cursor, err
+		}
+		for _, event := range events {
+			if err := writeEvent(w, event); err != nil {
+				return cursor, err
+			}
+			cursor = event.ID
+		}
+		if len(events) < eventsPageSize {
+			return cursor, nil
+		}
+	}
+}
+
+// writeEvent writes event in the text/event-stream format. Its data is the
+// event as JSON, which has no line breaks to split over several data lines.
+func writeEvent(w http.ResponseWriter, event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// sseEvent is an event read from a text/event-stream.
+type sseEvent struct {
+	ID   string
+	Type string
+	Data string
+}
+
+// openEventStream connects to the events endpoint of server and returns a
+// function reading the next event from it.
+func openEventStream(t *testing.T, server *httptest.Server, userID, lastEventID string) func() sseEvent {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/events?user_id="+userID, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() sseEvent {
+		t.Helper()
+		var event sseEvent
+		for {
+			select {
+			case line, ok := <-lines:
+				require.True(t, ok, "stream ended")
+				name, value, _ := strings.Cut(line, ": ")
+				switch name {
+				case "id":
+					event.ID = value
+				case "event":
+					event.Type = value
+				case "data":
+					event.Data = value
+				case "":
+					if event.Type != "" {
+						return event
+					}
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for an event")
+			}
+		}
+	}
+}
+
+func TestEventsHandler_Stream(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	server := httptest.NewServer(SetupRoutes(zerolog.Nop(), database))
+	t.Cleanup(server.Close)
+
+	user, err := database.CreateUser(ctx, "events@example.com")
+	require.NoError(t, err)
+	category, err := database.CreateCategory(ctx, user.ID, "Food")
+	require.NoError(t, err)
+
+	t.Run("replays events after Last-Event-ID, then streams new ones", func(t *testing.T) {
+		next := openEventStream(t, server, user.ID, "0")
+
+		event := next()
+		assert.Equal(t, models.EventCategoryCreated, event.Type)
+		var decoded models.Event
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &decoded))
+		assert.Equal(t, event.ID, strconv.FormatInt(decoded.ID, 10))
+		assert.Equal(t, user.ID, decoded.UserID)
+		assert.Contains(t, string(decoded.Data), category.ID)
+
+		transaction, err := database.CreateTransaction(ctx, user.ID, &category.ID, 12.5, nil, time.Now())
+		require.NoError(t, err)
+
+		event = next()
+		assert.Equal(t, models.EventTransactionCreated, event.Type)
+		assert.Contains(t, event.Data, transaction.ID)
+	})
+
+	t.Run("without Last-Event-ID only new events are sent", func(t *testing.T) {
+		next := openEventStream(t, server, user.ID, "")
+
+		_, err := database.CreateCategory(ctx, user.ID, "Rent")
+		require.NoError(t, err)
+
+		event := next()
+		assert.Equal(t, models.EventCategoryCreated, event.Type)
+		assert.Contains(t, event.Data, `"name":"Rent"`)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			userID      string
+			lastEventID string
+			status      int
+		}{
+			{"missing user_id", "", "", http.StatusBadRequest},
+			{"invalid Last-Event-ID", user.ID, "abc", http.StatusBadRequest},
+			{"negative Last-Event-ID", user.ID, "-1", http.StatusBadRequest},
+			{"unknown user", "00000000-0000-4000-8000-000000000000", "", http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/events?user_id="+tt.userID, nil)
+				if tt.lastEventID != "" {
+					req.Header.Set("Last-Event-ID", tt.lastEventID)
+				}
+				w := httptest.NewRecorder()
+
+				NewEventsHandler(zerolog.Nop(), database, context.Background()).Stream(w, req)
+
+				assert.Equal(t, tt.status, w.Code)
+				assertJSONContentType(t, w)
+			})
+		}
+	})
+}
+
+func TestEventsHandler_StreamEndsOnShutdown(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "shutdown@example.com")
+	require.NoError(t, err)
+	_, err = database.CreateCategory(ctx, user.ID, "Food")
+	require.NoError(t, err)
+
+	shutdown, cancel := context.WithCancel(ctx)
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/events?user_id="+user.ID, nil)
+	req.Header.Set("Last-Event-ID", "0")
+	w := httptest.NewRecorder()
+
+	// A stream that has been shut down still sends what it has caught up on.
+	NewEventsHandler(zerolog.Nop(), database, shutdown).Stream(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "retry: 3000\n\n"))
+	assert.Contains(t, w.Body.String(), "\nevent: category.created\ndata: {")
+}
@@ -0,0 +1,248 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/goals"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+type GoalHandler struct {
+	*Handler
+	db  db.Database
+	now func() time.Time
+}
+
+func NewGoalHandler(logger zerolog.Logger, database db.Database) *GoalHandler {
+	return &GoalHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+		now:     time.Now,
+	}
+}
+
+type CreateGoalRequest struct {
+	UserID       string     `json:"user_id"`
+	Name         string     `json:"name"`
+	TargetAmount float64    `json:"target_amount"`
+	TargetDate   *time.Time `json:"target_date,omitempty"`
+}
+
+// CreateGoalContributionRequest either links one of the user's transactions
+// or records a manual contribution of amount.
+type CreateGoalContributionRequest struct {
+	UserID        string     `json:"user_id"`
+	TransactionID *string    `json:"transaction_id,omitempty"`
+	Amount        *float64   `json:"amount,omitempty"`
+	ContributedAt *time.Time `json:"contributed_at,omitempty"`
+	Note          *string    `json:"note,omitempty"`
+}
+
+func (h *GoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	var req CreateGoalRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("name", req.Name, validator.ValidateGoalName(req.Name))
+	v.Check("target_amount", req.TargetAmount, validator.ValidateAmount(req.TargetAmount))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	goal, err := h.db.CreateGoal(r.Context(), req.UserID, models.Goal{
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		TargetDate:   req.TargetDate,
+	})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create goal")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, goal)
+}
+
+func (h *GoalHandler) ListGoals(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	userGoals, err := h.db.ListGoals(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list goals")
+		return
+	}
+
+	if userGoals == nil {
+		userGoals = []models.Goal{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, userGoals)
+}
+
+func (h *GoalHandler) GetGoal(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	goal, err := h.db.GetGoal(r.Context(), userID, id)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get goal")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, goal)
+}
+
+func (h *GoalHandler) DeleteGoal(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteGoal(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete goal")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateContribution records a contribution to a goal. One linked to a
+// transaction takes its amount and date from it; a manual one is dated now
+// unless contributed_at is given.
+func (h *GoalHandler) CreateContribution(w http.ResponseWriter, r *http.Request) {
+	goalID := chi.URLParam(r, "id")
+	var req CreateGoalContributionRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("id", goalID, validator.ValidateUUID(goalID))
+	if req.TransactionID != nil {
+		v.Check("transaction_id", *req.TransactionID, validator.ValidateUUID(*req.TransactionID))
+		if req.Amount != nil || req.ContributedAt != nil {
+			v.Add("transaction_id", validator.RuleExclusive, "'transaction_id' cannot be combined with 'amount' or 'contributed_at'", nil)
+		}
+	} else if req.Amount == nil {
+		v.Add("amount", validator.RuleRequired, "amount is required without transaction_id", nil)
+	} else {
+		v.Check("amount", *req.Amount, validator.ValidateAmount(*req.Amount))
+	}
+	v.Check("note", nil, validator.ValidateNote(req.Note))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	contribution := models.GoalContribution{
+		TransactionID: req.TransactionID,
+		Note:          req.Note,
+	}
+	if req.TransactionID == nil {
+		contribution.Amount = *req.Amount
+		contribution.ContributedAt = h.now()
+		if req.ContributedAt != nil {
+			contribution.ContributedAt = *req.ContributedAt
+		}
+	}
+
+	created, err := h.db.CreateGoalContribution(r.Context(), req.UserID, goalID, contribution)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create goal contribution")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *GoalHandler) ListContributions(w http.ResponseWriter, r *http.Request) {
+	userID, goalID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	contributions, err := h.db.ListGoalContributions(r.Context(), userID, goalID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list goal contributions")
+		return
+	}
+
+	if contributions == nil {
+		contributions = []models.GoalContribution{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, contributions)
+}
+
+func (h *GoalHandler) DeleteContribution(w http.ResponseWriter, r *http.Request) {
+	contributionID := chi.URLParam(r, "contribution_id")
+	var v validator.Validator
+	v.Check("contribution_id", contributionID, validator.ValidateUUID(contributionID))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+	userID, goalID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteGoalContribution(r.Context(), userID, goalID, contributionID); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete goal contribution")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetProgress reports how far a goal is from its target, the monthly
+// contribution still needed to reach it by its target date, and when it
+// will be reached at the rate of the contributions of the last window_days.
+func (h *GoalHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	userID, goalID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	var v validator.Validator
+	window := goals.DefaultRateWindow
+	if days := intParam(&v, r.URL.Query(), "window_days"); days != nil {
+		window = *days
+		v.Check("window_days", window, validator.ValidateRateWindow(window, goals.MaxRateWindow))
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	goal, err := h.db.GetGoal(r.Context(), userID, goalID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get goal")
+		return
+	}
+	contributions, err := h.db.ListGoalContributions(r.Context(), userID, goalID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list goal contributions")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, goals.Progress(*goal, contributions, h.now(), window))
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func newGoalRouter(t *testing.T) (*db.MemoryDB, http.Handler, *models.User) {
+	t.Helper()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(context.Background(), "savings@example.com")
+	require.NoError(t, err)
+	return database, ContentType(SetupRoutes(zerolog.Nop(), database)), user
+}
+
+func TestGoalHandler(t *testing.T) {
+	database, router, user := newGoalRouter(t)
+	ctx := context.Background()
+	targetDate := time.Now().UTC().AddDate(1, 0, 0).Truncate(time.Second)
+
+	w := serve(router, http.MethodPost, "/api/v1/goals", CreateGoalRequest{
+		UserID: user.ID, Name: "Emergency fund", TargetAmount: 10000, TargetDate: &targetDate,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var goal models.Goal
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&goal))
+	assert.Equal(t, "Emergency fund", goal.Name)
+	assert.Equal(t, 10000.0, goal.TargetAmount)
+	require.NotNil(t, goal.TargetDate)
+	assert.True(t, targetDate.Equal(*goal.TargetDate))
+
+	goalURL := "/api/v1/goals/" + goal.ID
+	query := "?user_id=" + user.ID
+
+	w = serve(router, http.MethodGet, "/api/v1/goals"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var goals []models.Goal
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&goals))
+	require.Len(t, goals, 1)
+	assert.Equal(t, goal.ID, goals[0].ID)
+
+	w = serve(router, http.MethodGet, goalURL+query, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	transaction, err := database.CreateTransaction(ctx, user.ID, nil, 500, nil, time.Now().AddDate(0, 0, -10))
+	require.NoError(t, err)
+	w = serve(router, http.MethodPost, goalURL+"/contributions", CreateGoalContributionRequest{
+		UserID: user.ID, TransactionID: &transaction.ID,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var linked models.GoalContribution
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&linked))
+	assert.Equal(t, 500.0, linked.Amount, "taken from the transaction")
+
+	w = serve(router, http.MethodPost, goalURL+"/contributions", CreateGoalContributionRequest{
+		UserID: user.ID, TransactionID: &transaction.ID,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "transaction_already_contributed")
+
+	amount := 250.0
+	contributedAt := time.Now().AddDate(0, 0, -40)
+	w = serve(router, http.MethodPost, goalURL+"/contributions", CreateGoalContributionRequest{
+		UserID: user.ID, Amount: &amount, ContributedAt: &contributedAt,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var manual models.GoalContribution
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&manual))
+
+	w = serve(router, http.MethodGet, goalURL+"/contributions"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var contributions []models.GoalContribution
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&contributions))
+	require.Len(t, contributions, 2)
+	assert.Equal(t, manual.ID, contributions[0].ID, "earliest first")
+
+	w = serve(router, http.MethodGet, goalURL+"/progress"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var progress models.GoalProgress
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&progress))
+	assert.Equal(t, 750.0, progress.Saved)
+	assert.Equal(t, 9250.0, progress.Remaining)
+	assert.Equal(t, 90, progress.RateWindow)
+	assert.NotNil(t, progress.RequiredMonthly)
+	assert.NotNil(t, progress.ProjectedCompletion)
+
+	w = serve(router, http.MethodGet, goalURL+"/progress"+query+"&window_days=30", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&progress))
+	assert.Equal(t, 30, progress.RateWindow)
+
+	w = serve(router, http.MethodDelete, goalURL+"/contributions/"+manual.ID+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodDelete, goalURL+"/contributions/"+manual.ID+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "goal_contribution_not_found")
+
+	t.Run("another user's goal", func(t *testing.T) {
+		other, err := database.CreateUser(ctx, "other@example.com")
+		require.NoError(t, err)
+		for _, target := range []string{goalURL, goalURL + "/contributions", goalURL + "/progress"} {
+			w := serve(router, http.MethodGet, target+"?user_id="+other.ID, nil)
+			assert.Equal(t, http.StatusNotFound, w.Code, target)
+			assert.Contains(t, w.Body.String(), "goal_not_found")
+		}
+	})
+
+	w = serve(router, http.MethodDelete, goalURL+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodGet, goalURL+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGoalHandler_PinnedClock(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "clock@example.com")
+	require.NoError(t, err)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	targetDate := now.AddDate(1, 0, 0)
+	goal, err := database.CreateGoal(ctx, user.ID, models.Goal{Name: "Car", TargetAmount: 1200, TargetDate: &targetDate})
+	require.NoError(t, err)
+	for _, c := range []models.GoalContribution{
+		{Amount: 100, ContributedAt: now.AddDate(0, 0, -200)},
+		{Amount: 100.1, ContributedAt: now.AddDate(0, 0, -60)},
+	} {
+		_, err := database.CreateGoalContribution(ctx, user.ID, goal.ID, c)
+		require.NoError(t, err)
+	}
+
+	handler := NewGoalHandler(zerolog.Nop(), database)
+	handler.now = func() time.Time { return now }
+	router := chi.NewRouter()
+	router.Post("/goals/{id}/contributions", handler.CreateContribution)
+	router.Get("/goals/{id}/progress", handler.GetProgress)
+
+	amount := 99.9
+	w := serve(router, http.MethodPost, "/goals/"+goal.ID+"/contributions", CreateGoalContributionRequest{UserID: user.ID, Amount: &amount})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var contribution models.GoalContribution
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&contribution))
+	assert.True(t, now.Equal(contribution.ContributedAt), "dated now")
+
+	w = serve(router, http.MethodGet, "/goals/"+goal.ID+"/progress?user_id="+user.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var progress models.GoalProgress
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&progress))
+	assert.True(t, now.Equal(progress.AsOf))
+	assert.Equal(t, 67.64, progress.MonthlyRate, "200 over 90 days")
+	require.NotNil(t, progress.ProjectedCompletion)
+	assert.Equal(t, time.Date(2027, 7, 11, 0, 0, 0, 0, time.UTC), *progress.ProjectedCompletion, "900 at 67.64 a month")
+	require.NotNil(t, progress.OnTrack)
+	assert.False(t, *progress.OnTrack)
+}
+
+func TestGoalHandler_Rejected(t *testing.T) {
+	_, router, user := newGoalRouter(t)
+	unknownID := "660e8400-e29b-41d4-a716-446655440000"
+	amount := 10.0
+	zero := 0.0
+	now := time.Now()
+
+	w := serve(router, http.MethodPost, "/api/v1/goals", CreateGoalRequest{UserID: user.ID, Name: "Laptop", TargetAmount: 1500})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var goal models.Goal
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&goal))
+	goalURL := "/api/v1/goals/" + goal.ID
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   any
+		status int
+		code   string
+	}{
+		{name: "no name", method: http.MethodPost, target: "/api/v1/goals", body: CreateGoalRequest{UserID: user.ID, Name: " ", TargetAmount: 100}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "no target amount", method: http.MethodPost, target: "/api/v1/goals", body: CreateGoalRequest{UserID: user.ID, Name: "Car"}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "unknown user", method: http.MethodPost, target: "/api/v1/goals", body: CreateGoalRequest{UserID: unknownID, Name: "Car", TargetAmount: 100}, status: http.StatusNotFound, code: "user_not_found"},
+		{name: "contribution without amount", method: http.MethodPost, target: goalURL + "/contributions", body: CreateGoalContributionRequest{UserID: user.ID}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "contribution of zero", method: http.MethodPost, target: goalURL + "/contributions", body: CreateGoalContributionRequest{UserID: user.ID, Amount: &zero}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "transaction with amount", method: http.MethodPost, target: goalURL + "/contributions", body: CreateGoalContributionRequest{UserID: user.ID, TransactionID: &unknownID, Amount: &amount}, status: http.StatusBadRequest, code: "exclusive"},
+		{name: "transaction with date", method: http.MethodPost, target: goalURL + "/contributions", body: CreateGoalContributionRequest{UserID: user.ID, TransactionID: &unknownID, ContributedAt: &now}, status: http.StatusBadRequest, code: "exclusive"},
+		{name: "unknown transaction", method: http.MethodPost, target: goalURL + "/contributions", body: CreateGoalContributionRequest{UserID: user.ID, TransactionID: &unknownID}, status: http.StatusNotFound, code: "transaction_not_found"},
+		{name: "unknown goal", method: http.MethodPost, target: "/api/v1/goals/" + unknownID + "/contributions", body: CreateGoalContributionRequest{UserID: user.ID, Amount: &amount}, status: http.StatusNotFound, code: "goal_not_found"},
+		{name: "invalid goal id", method: http.MethodPost, target: "/api/v1/goals/invalid/contributions", body: CreateGoalContributionRequest{UserID: user.ID, Amount: &amount}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "invalid contribution id", method: http.MethodDelete, target: goalURL + "/contributions/invalid?user_id=" + user.ID, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "window too short", method: http.MethodGet, target: goalURL + "/progress?window_days=0&user_id=" + user.ID, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "window not a number", method: http.MethodGet, target: goalURL + "/progress?window_days=month&user_id=" + user.ID, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "missing user_id", method: http.MethodGet, target: "/api/v1/goals", status: http.StatusBadRequest, code: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.code)
+		})
+	}
+}
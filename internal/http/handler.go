@@ -26,16 +26,22 @@ func NewHandler(logger zerolog.Logger) *Handler {
 }
 
 func (h *Handler) respondWithError(w http.ResponseWriter, code int, message string, details ...any) {
-	errResp := ErrorResponse{}
-	errResp.Error.Code = http.StatusText(code)
-	errResp.Error.Message = message
-	
+	var detail any
 	if len(details) > 0 {
-		errResp.Error.Details = details[0]
+		detail = details[0]
 	}
-	
+	h.writeError(w, code, http.StatusText(code), message, detail)
+}
+
+// writeError writes an ErrorResponse with the given status and error code.
+func (h *Handler) writeError(w http.ResponseWriter, status int, code, message string, details any) {
+	errResp := ErrorResponse{}
+	errResp.Error.Code = code
+	errResp.Error.Message = message
+	errResp.Error.Details = details
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
+	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(errResp); err != nil {
 		h.Logger.Error().Err(err).Msg("Failed to encode error response")
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"fintrack-go/internal/db"
 	"fintrack-go/internal/models"
 )
 
@@ -28,16 +29,164 @@ func (m *MockPoolForHealth) Ping(ctx context.Context) error {
 func (m *MockPoolForHealth) Close() {}
 
 func (m *MockPoolForHealth) GetUserByEmail(ctx context.Context, email string) (*models.User, error) { return nil, nil }
+func (m *MockPoolForHealth) DeleteUser(ctx context.Context, id string) error { return nil }
 func (m *MockPoolForHealth) GetUserByID(ctx context.Context, id string) (*models.User, error) { return nil, nil }
 func (m *MockPoolForHealth) CreateUser(ctx context.Context, email string) (*models.User, error) { return nil, nil }
 func (m *MockPoolForHealth) CreateCategory(ctx context.Context, userID, name string) (*models.Category, error) { return nil, nil }
 func (m *MockPoolForHealth) ListCategories(ctx context.Context, userID string) ([]models.Category, error) { return nil, nil }
 func (m *MockPoolForHealth) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) { return nil, nil }
+func (m *MockPoolForHealth) GetCategoriesByIDs(ctx context.Context, ids []string) ([]models.Category, error) {
+	return nil, nil
+}
 func (m *MockPoolForHealth) CreateTransaction(ctx context.Context, userID string, categoryID *string, amount float64, description *string, occurredAt time.Time) (*models.Transaction, error) { return nil, nil }
-func (m *MockPoolForHealth) ListTransactions(ctx context.Context, userID string, from, to *time.Time) ([]models.Transaction, error) { return nil, nil }
+func (m *MockPoolForHealth) ListTransactions(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error) { return nil, nil }
 func (m *MockPoolForHealth) GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error) { return nil, nil }
 func (m *MockPoolForHealth) ValidateCategoryOwnership(ctx context.Context, categoryID, userID string) error { return nil }
 func (m *MockPoolForHealth) GetSummary(ctx context.Context, userID string, from, to *time.Time) (*models.Summary, error) { return nil, nil }
+func (m *MockPoolForHealth) FindDuplicateTransactions(ctx context.Context, userID string, windowDays int) ([]models.DuplicatePair, error) { return nil, nil }
+func (m *MockPoolForHealth) MergeDuplicateTransactions(ctx context.Context, userID, keepID, discardID string) (*models.Transaction, error) { return nil, nil }
+func (m *MockPoolForHealth) DismissDuplicate(ctx context.Context, userID, transactionID, duplicateID string) error { return nil }
+func (m *MockPoolForHealth) CreateWebhookSubscription(ctx context.Context, userID, url, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListWebhookSubscriptions(ctx context.Context, userID string) ([]models.WebhookSubscription, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteWebhookSubscription(ctx context.Context, userID, id string) error { return nil }
+func (m *MockPoolForHealth) ListWebhookDeliveries(ctx context.Context, userID, subscriptionID string) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutgoingWebhook, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error { return nil }
+func (m *MockPoolForHealth) ListEvents(ctx context.Context, userID string, afterID int64, limit int) ([]models.Event, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) LastEventID(ctx context.Context, userID string) (int64, error) { return 0, nil }
+func (m *MockPoolForHealth) WatchEvents(ctx context.Context, userID string) (<-chan struct{}, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListAuditEntries(ctx context.Context, userID string, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteCategory(ctx context.Context, userID, id string) error { return nil }
+func (m *MockPoolForHealth) RestoreCategory(ctx context.Context, userID, id string) (*models.Category, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteTransaction(ctx context.Context, userID, id string) error { return nil }
+func (m *MockPoolForHealth) RestoreTransaction(ctx context.Context, userID, id string) (*models.Transaction, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListTrash(ctx context.Context, userID string) (*models.Trash, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return 0, nil
+}
+func (m *MockPoolForHealth) CreateAttachment(ctx context.Context, userID, transactionID string, attachment models.Attachment) (*models.Attachment, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListAttachments(ctx context.Context, userID, transactionID string) ([]models.Attachment, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) GetAttachment(ctx context.Context, userID, id string) (*models.Attachment, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteAttachment(ctx context.Context, userID, id string) error { return nil }
+func (m *MockPoolForHealth) ListOrphanedBlobs(ctx context.Context, limit int) ([]string, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ForgetOrphanedBlobs(ctx context.Context, keys []string) error { return nil }
+func (m *MockPoolForHealth) CreateGoal(ctx context.Context, userID string, goal models.Goal) (*models.Goal, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListGoals(ctx context.Context, userID string) ([]models.Goal, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) GetGoal(ctx context.Context, userID, id string) (*models.Goal, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteGoal(ctx context.Context, userID, id string) error { return nil }
+func (m *MockPoolForHealth) CreateGoalContribution(ctx context.Context, userID, goalID string, contribution models.GoalContribution) (*models.GoalContribution, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListGoalContributions(ctx context.Context, userID, goalID string) ([]models.GoalContribution, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteGoalContribution(ctx context.Context, userID, goalID, id string) error {
+	return nil
+}
+func (m *MockPoolForHealth) CreateAccount(ctx context.Context, userID string, account models.Account) (*models.Account, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListAccounts(ctx context.Context, userID string) ([]models.Account, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) GetAccount(ctx context.Context, userID, id string) (*models.Account, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteAccount(ctx context.Context, userID, id string) error {
+	return nil
+}
+func (m *MockPoolForHealth) CreateValuation(ctx context.Context, userID, accountID string, valuation models.Valuation) (*models.Valuation, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListValuations(ctx context.Context, userID string, filter models.ValuationFilter) ([]models.Valuation, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteValuation(ctx context.Context, userID, accountID, id string) error {
+	return nil
+}
+func (m *MockPoolForHealth) CreateDebt(ctx context.Context, userID string, debt models.Debt) (*models.Debt, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListDebts(ctx context.Context, userID string) ([]models.Debt, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) GetDebt(ctx context.Context, userID, id string) (*models.Debt, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteDebt(ctx context.Context, userID, id string) error {
+	return nil
+}
+func (m *MockPoolForHealth) CreateDebtPayment(ctx context.Context, userID, debtID string, payment models.DebtPayment) (*models.DebtPayment, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListDebtPayments(ctx context.Context, userID, debtID string) ([]models.DebtPayment, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteDebtPayment(ctx context.Context, userID, debtID, id string) error {
+	return nil
+}
+func (m *MockPoolForHealth) CreateSecurity(ctx context.Context, userID string, security models.Security) (*models.Security, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListSecurities(ctx context.Context, userID string) ([]models.Security, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) GetSecurity(ctx context.Context, userID, id string) (*models.Security, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteSecurity(ctx context.Context, userID, id string) error {
+	return nil
+}
+func (m *MockPoolForHealth) CreateLot(ctx context.Context, userID, securityID string, lot models.Lot) (*models.Lot, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) ListLots(ctx context.Context, userID string, filter models.LotFilter) ([]models.Lot, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) DeleteLot(ctx context.Context, userID, securityID, id string) error {
+	return nil
+}
+func (m *MockPoolForHealth) SavePrices(ctx context.Context, userID string, prices []models.Price) error {
+	return nil
+}
+func (m *MockPoolForHealth) ListPrices(ctx context.Context, userID string, filter models.PriceFilter) ([]models.Price, error) {
+	return nil, nil
+}
+func (m *MockPoolForHealth) WithTx(ctx context.Context, fn func(tx db.Database) error) error { return fn(m) }
 
 func TestHealthHandler_Health(t *testing.T) {
 	logger := zerolog.Nop()
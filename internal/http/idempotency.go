@@ -0,0 +1,273 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// DefaultIdempotencyTTL is how long a stored response can be replayed.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyRecord is what is kept for one Idempotency-Key: the fingerprint
+// of the request that first used it and, once that request has finished, its
+// response.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore keeps idempotency records, scoped by user and key.
+type IdempotencyStore interface {
+	// Reserve claims key for a new request with the given fingerprint. If the
+	// key is already in use it returns the existing record and false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error)
+	// Complete stores the response for a reserved key.
+	Complete(ctx context.Context, key string, record IdempotencyRecord) error
+	// Release forgets a reserved key so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyMiddleware makes POST requests carrying an Idempotency-Key safe
+// to retry: the first response is stored and replayed for later requests with
+// the same key and body.
+type IdempotencyMiddleware struct {
+	*Handler
+	store IdempotencyStore
+	ttl   time.Duration
+}
+
+func NewIdempotencyMiddleware(logger zerolog.Logger, store IdempotencyStore, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		Handler: NewHandler(logger),
+		store:   store,
+		ttl:     ttl,
+	}
+}
+
+func (m *IdempotencyMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			m.respondWithError(w, http.StatusBadRequest, "Idempotency-Key cannot exceed 255 characters", nil)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			m.respondWithBodyError(w, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := idempotencyUserScope(r, body) + ":" + key
+		fingerprint := idempotencyFingerprint(r, body)
+
+		record, reserved, err := m.store.Reserve(r.Context(), storeKey, fingerprint, m.ttl)
+		if err != nil {
+			m.Logger.Error().Err(err).Msg("Failed to reserve idempotency key")
+			m.respondWithError(w, http.StatusInternalServerError, "Failed to process request", nil)
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				m.respondWithError(w, http.StatusUnprocessableEntity, "Idempotency-Key has already been used for a different request", nil)
+			case !record.Completed:
+				m.respondWithError(w, http.StatusConflict, "A request with this Idempotency-Key is still being processed", nil)
+			default:
+				replayIdempotentResponse(w, record)
+			}
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		served := false
+		defer func() {
+			// A handler that panicked never finished the request, so the key
+			// is released for a retry while the panic goes on to the
+			// recoverer.
+			if !served {
+				m.release(r.Context(), storeKey)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+		served = true
+
+		// Server errors and rate limiting are not stored so the client can
+		// retry with the same key.
+		if rec.status >= http.StatusInternalServerError || rec.status == http.StatusTooManyRequests {
+			m.release(r.Context(), storeKey)
+			return
+		}
+
+		// The replay gets its own request ID from the RequestID middleware.
+		header := w.Header().Clone()
+		header.Del("X-Request-ID")
+
+		err = m.store.Complete(r.Context(), storeKey, IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  rec.status,
+			Header:      header,
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			m.Logger.Error().Err(err).Msg("Failed to store idempotent response")
+		}
+	})
+}
+
+// release forgets a reserved key so the request can be retried with it.
+func (m *IdempotencyMiddleware) release(ctx context.Context, key string) {
+	if err := m.store.Release(ctx, key); err != nil {
+		m.Logger.Error().Err(err).Msg("Failed to release idempotency key")
+	}
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, record *IdempotencyRecord) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// idempotencyUserScope returns the user_id from a JSON request body, or from
+// the query for requests such as uploads whose body isn't JSON, so keys
+// chosen by different users never collide. Requests without one, such as
+// user creation, share an anonymous scope.
+func idempotencyUserScope(r *http.Request, body []byte) string {
+	var payload struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.UserID != "" {
+		return payload.UserID
+	}
+	return r.URL.Query().Get("user_id")
+}
+
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore held in process memory. It is
+// suitable for a single server instance.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]*memoryIdempotencyEntry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]*memoryIdempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if entry, ok := s.records[key]; ok && now.Before(entry.expiresAt) {
+		record := entry.record
+		return &record, false, nil
+	}
+
+	s.records[key] = &memoryIdempotencyEntry{
+		record:    IdempotencyRecord{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.records[key]; ok {
+		entry.record = record
+	}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep drops expired records at most once a minute. Callers hold s.mu.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.records {
+		if !now.Before(entry.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
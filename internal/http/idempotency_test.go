@@ -0,0 +1,199 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotentTestHandler(store IdempotencyStore, status int, calls *int32) http.Handler {
+	m := NewIdempotencyMiddleware(zerolog.Nop(), store, time.Hour)
+	return m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"call":` + strconv.Itoa(int(n)) + `}`))
+	}))
+}
+
+func postWithKey(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	body := `{"user_id":"550e8400-e29b-41d4-a716-446655440000","amount":10}`
+
+	t.Run("replays stored response", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+		first := postWithKey(handler, "key-1", body)
+		second := postWithKey(handler, "key-1", body)
+
+		assert.Equal(t, int32(1), calls)
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+		assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("different body with same key is rejected", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+		postWithKey(handler, "key-1", body)
+		w := postWithKey(handler, "key-1", `{"user_id":"550e8400-e29b-41d4-a716-446655440000","amount":20}`)
+
+		assert.Equal(t, int32(1), calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "different request")
+	})
+
+	t.Run("keys are scoped per user", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+		postWithKey(handler, "key-1", body)
+		w := postWithKey(handler, "key-1", `{"user_id":"660e8400-e29b-41d4-a716-446655440000","amount":20}`)
+
+		assert.Equal(t, int32(2), calls)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+		postWithKey(handler, "", body)
+		postWithKey(handler, "", body)
+
+		assert.Equal(t, int32(2), calls)
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusInternalServerError, &calls)
+
+		postWithKey(handler, "key-1", body)
+		postWithKey(handler, "key-1", body)
+
+		assert.Equal(t, int32(2), calls)
+	})
+
+	t.Run("rate limited responses are not stored", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusTooManyRequests, &calls)
+
+		postWithKey(handler, "key-1", body)
+		postWithKey(handler, "key-1", body)
+
+		assert.Equal(t, int32(2), calls)
+	})
+
+	t.Run("panicking handlers release the key", func(t *testing.T) {
+		var calls int32
+		m := NewIdempotencyMiddleware(zerolog.Nop(), NewMemoryIdempotencyStore(), time.Hour)
+		handler := m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				panic("handler failed")
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		assert.PanicsWithValue(t, "handler failed", func() { postWithKey(handler, "key-1", body) }, "the panic reaches the recoverer")
+		w := postWithKey(handler, "key-1", body)
+
+		assert.Equal(t, int32(2), calls)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("client errors are stored", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusBadRequest, &calls)
+
+		postWithKey(handler, "key-1", body)
+		w := postWithKey(handler, "key-1", body)
+
+		assert.Equal(t, int32(1), calls)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("in-flight request returns conflict", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore()
+		m := NewIdempotencyMiddleware(zerolog.Nop(), store, time.Hour)
+
+		var inner *httptest.ResponseRecorder
+		handler := m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A retry arrives while the original is still running.
+			inner = postWithKey(m.Handle(http.NotFoundHandler()), "key-1", body)
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		postWithKey(handler, "key-1", body)
+
+		require.NotNil(t, inner)
+		assert.Equal(t, http.StatusConflict, inner.Code)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+		w := postWithKey(handler, string(make([]byte, 256)), body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, int32(0), calls)
+	})
+
+	t.Run("GET requests pass through", func(t *testing.T) {
+		var calls int32
+		handler := newIdempotentTestHandler(NewMemoryIdempotencyStore(), http.StatusOK, &calls)
+
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions", nil)
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		assert.Equal(t, int32(2), calls)
+	})
+}
+
+func TestMemoryIdempotencyStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	_, reserved, err := store.Reserve(ctx, "key", "fp", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	record, reserved, err := store.Reserve(ctx, "key", "fp", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "fp", record.Fingerprint)
+
+	now = now.Add(2 * time.Minute)
+	_, reserved, err = store.Reserve(ctx, "key", "fp", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
@@ -2,12 +2,15 @@ package http
 
 import (
 	"context"
+	"mime"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/hlog"
+	"fintrack-go/internal/db"
 )
 
 type contextKey string
@@ -28,6 +31,29 @@ func RequestID(next http.Handler) http.Handler {
 	})
 }
 
+// Audit records who is making the request, and its request ID, in its
+// context, so the changes it makes are attributed in the audit log. Clients
+// are identified by their X-API-Key header, else by IP address.
+func Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := db.AuditInfo{Actor: requestActor(r)}
+		info.RequestID, _ = r.Context().Value(RequestIDKey).(string)
+
+		next.ServeHTTP(w, r.WithContext(db.WithAuditInfo(r.Context(), info)))
+	})
+}
+
+func requestActor(r *http.Request) string {
+	if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+		return db.APIKeyActor(apiKey)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
 func Logger(logger zerolog.Logger) func(http.Handler) http.Handler {
 	return hlog.NewHandler(logger)
 }
@@ -44,11 +70,19 @@ func AccessLog(next http.Handler) http.Handler {
 	})(next)
 }
 
+// ContentType requires request bodies to be JSON, except on the upload
+// routes, which take multipart/form-data instead, and sets JSON as the
+// response's default content type.
 func ContentType(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodDelete {
 			contentType := r.Header.Get("Content-Type")
-			if contentType != "application/json" {
+			if isUpload(r) {
+				if mediaType, params, err := mime.ParseMediaType(contentType); err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+					http.Error(w, "Content-Type must be multipart/form-data", http.StatusUnsupportedMediaType)
+					return
+				}
+			} else if contentType != "application/json" {
 				http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
 				return
 			}
@@ -59,13 +93,35 @@ func ContentType(next http.Handler) http.Handler {
 	})
 }
 
+// LimitRequestBody caps request bodies at maxRequestBodySize, or at upload
+// bytes on the upload routes. A body declared larger is refused with a 413
+// before it is read; reading past the limit of any other fails with an
+// *http.MaxBytesError, which handlers also report as a 413.
+func LimitRequestBody(logger zerolog.Logger, upload int64) func(http.Handler) http.Handler {
+	h := NewHandler(logger)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := int64(maxRequestBodySize)
+			if isUpload(r) {
+				limit = upload
+			}
+			if r.ContentLength > limit {
+				h.respondWithBodyError(w, &http.MaxBytesError{Limit: limit})
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func CORSMiddleware(enabled bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if enabled {
 				w.Header().Set("Access-Control-Allow-Origin", "*")
 				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Idempotency-Key, X-API-Key")
 				
 				if r.Method == http.MethodOptions {
 					w.WriteHeader(http.StatusOK)
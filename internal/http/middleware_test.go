@@ -8,6 +8,10 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
 )
 
 func TestMiddleware_RequestID(t *testing.T) {
@@ -50,6 +54,45 @@ func TestMiddleware_RequestID_FromHeader(t *testing.T) {
 	handler.ServeHTTP(w, req)
 }
 
+func TestMiddleware_Audit(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		actor  string
+	}{
+		{name: "api key", apiKey: "secret-key", actor: db.APIKeyActor("secret-key")},
+		{name: "ip address", actor: "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := db.NewMemoryDB()
+			var userID string
+			handler := RequestID(Audit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, err := database.CreateUser(r.Context(), "audit@example.com")
+				require.NoError(t, err)
+				userID = user.ID
+			})))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("X-Request-ID", "audit-request")
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries, err := database.ListAuditEntries(req.Context(), userID, models.AuditFilter{Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.actor, entries[0].Actor)
+			assert.NotContains(t, entries[0].Actor, "secret-key")
+			require.NotNil(t, entries[0].RequestID)
+			assert.Equal(t, "audit-request", *entries[0].RequestID)
+		})
+	}
+}
+
 func TestMiddleware_Logger(t *testing.T) {
 	logger := zerolog.Nop()
 
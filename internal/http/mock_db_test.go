@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/mock"
+	"fintrack-go/internal/db"
 	"fintrack-go/internal/models"
 )
 
@@ -41,6 +42,11 @@ func (m *MockDBForHandler) GetUserByEmail(ctx context.Context, email string) (*m
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockDBForHandler) DeleteUser(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockDBForHandler) CreateCategory(ctx context.Context, userID, name string) (*models.Category, error) {
 	args := m.Called(ctx, userID, name)
 	if args.Get(0) == nil {
@@ -57,6 +63,14 @@ func (m *MockDBForHandler) ListCategories(ctx context.Context, userID string) ([
 	return args.Get(0).([]models.Category), args.Error(1)
 }
 
+func (m *MockDBForHandler) GetCategoriesByIDs(ctx context.Context, ids []string) ([]models.Category, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
 func (m *MockDBForHandler) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -73,8 +87,8 @@ func (m *MockDBForHandler) CreateTransaction(ctx context.Context, userID string,
 	return args.Get(0).(*models.Transaction), args.Error(1)
 }
 
-func (m *MockDBForHandler) ListTransactions(ctx context.Context, userID string, from, to *time.Time) ([]models.Transaction, error) {
-	args := m.Called(ctx, userID, from, to)
+func (m *MockDBForHandler) ListTransactions(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
+	args := m.Called(ctx, userID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -101,3 +115,395 @@ func (m *MockDBForHandler) GetSummary(ctx context.Context, userID string, from,
 	}
 	return args.Get(0).(*models.Summary), args.Error(1)
 }
+
+func (m *MockDBForHandler) FindDuplicateTransactions(ctx context.Context, userID string, windowDays int) ([]models.DuplicatePair, error) {
+	args := m.Called(ctx, userID, windowDays)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DuplicatePair), args.Error(1)
+}
+
+func (m *MockDBForHandler) MergeDuplicateTransactions(ctx context.Context, userID, keepID, discardID string) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, keepID, discardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockDBForHandler) DismissDuplicate(ctx context.Context, userID, transactionID, duplicateID string) error {
+	args := m.Called(ctx, userID, transactionID, duplicateID)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateWebhookSubscription(ctx context.Context, userID, url, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, userID, url, secret, eventTypes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListWebhookSubscriptions(ctx context.Context, userID string) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteWebhookSubscription(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) ListWebhookDeliveries(ctx context.Context, userID, subscriptionID string) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, userID, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDBForHandler) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutgoingWebhook, error) {
+	args := m.Called(ctx, now, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OutgoingWebhook), args.Error(1)
+}
+
+func (m *MockDBForHandler) RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error {
+	args := m.Called(ctx, id, attempt)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) ListEvents(ctx context.Context, userID string, afterID int64, limit int) ([]models.Event, error) {
+	args := m.Called(ctx, userID, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockDBForHandler) LastEventID(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBForHandler) WatchEvents(ctx context.Context, userID string) (<-chan struct{}, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan struct{}), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListAuditEntries(ctx context.Context, userID string, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AuditEntry), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteCategory(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) RestoreCategory(ctx context.Context, userID, id string) (*models.Category, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteTransaction(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) RestoreTransaction(ctx context.Context, userID, id string) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListTrash(ctx context.Context, userID string) (*models.Trash, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Trash), args.Error(1)
+}
+
+func (m *MockDBForHandler) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDBForHandler) CreateAttachment(ctx context.Context, userID, transactionID string, attachment models.Attachment) (*models.Attachment, error) {
+	args := m.Called(ctx, userID, transactionID, attachment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListAttachments(ctx context.Context, userID, transactionID string) ([]models.Attachment, error) {
+	args := m.Called(ctx, userID, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func (m *MockDBForHandler) GetAttachment(ctx context.Context, userID, id string) (*models.Attachment, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteAttachment(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) ListOrphanedBlobs(ctx context.Context, limit int) ([]string, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDBForHandler) ForgetOrphanedBlobs(ctx context.Context, keys []string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateGoal(ctx context.Context, userID string, goal models.Goal) (*models.Goal, error) {
+	args := m.Called(ctx, userID, goal)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Goal), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListGoals(ctx context.Context, userID string) ([]models.Goal, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Goal), args.Error(1)
+}
+
+func (m *MockDBForHandler) GetGoal(ctx context.Context, userID, id string) (*models.Goal, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Goal), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteGoal(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateGoalContribution(ctx context.Context, userID, goalID string, contribution models.GoalContribution) (*models.GoalContribution, error) {
+	args := m.Called(ctx, userID, goalID, contribution)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GoalContribution), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListGoalContributions(ctx context.Context, userID, goalID string) ([]models.GoalContribution, error) {
+	args := m.Called(ctx, userID, goalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GoalContribution), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteGoalContribution(ctx context.Context, userID, goalID, id string) error {
+	args := m.Called(ctx, userID, goalID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateAccount(ctx context.Context, userID string, account models.Account) (*models.Account, error) {
+	args := m.Called(ctx, userID, account)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Account), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListAccounts(ctx context.Context, userID string) ([]models.Account, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Account), args.Error(1)
+}
+
+func (m *MockDBForHandler) GetAccount(ctx context.Context, userID, id string) (*models.Account, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Account), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteAccount(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateValuation(ctx context.Context, userID, accountID string, valuation models.Valuation) (*models.Valuation, error) {
+	args := m.Called(ctx, userID, accountID, valuation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Valuation), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListValuations(ctx context.Context, userID string, filter models.ValuationFilter) ([]models.Valuation, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Valuation), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteValuation(ctx context.Context, userID, accountID, id string) error {
+	args := m.Called(ctx, userID, accountID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateDebt(ctx context.Context, userID string, debt models.Debt) (*models.Debt, error) {
+	args := m.Called(ctx, userID, debt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Debt), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListDebts(ctx context.Context, userID string) ([]models.Debt, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Debt), args.Error(1)
+}
+
+func (m *MockDBForHandler) GetDebt(ctx context.Context, userID, id string) (*models.Debt, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Debt), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteDebt(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateDebtPayment(ctx context.Context, userID, debtID string, payment models.DebtPayment) (*models.DebtPayment, error) {
+	args := m.Called(ctx, userID, debtID, payment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DebtPayment), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListDebtPayments(ctx context.Context, userID, debtID string) ([]models.DebtPayment, error) {
+	args := m.Called(ctx, userID, debtID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DebtPayment), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteDebtPayment(ctx context.Context, userID, debtID, id string) error {
+	args := m.Called(ctx, userID, debtID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateSecurity(ctx context.Context, userID string, security models.Security) (*models.Security, error) {
+	args := m.Called(ctx, userID, security)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Security), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListSecurities(ctx context.Context, userID string) ([]models.Security, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Security), args.Error(1)
+}
+
+func (m *MockDBForHandler) GetSecurity(ctx context.Context, userID, id string) (*models.Security, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Security), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteSecurity(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) CreateLot(ctx context.Context, userID, securityID string, lot models.Lot) (*models.Lot, error) {
+	args := m.Called(ctx, userID, securityID, lot)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Lot), args.Error(1)
+}
+
+func (m *MockDBForHandler) ListLots(ctx context.Context, userID string, filter models.LotFilter) ([]models.Lot, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Lot), args.Error(1)
+}
+
+func (m *MockDBForHandler) DeleteLot(ctx context.Context, userID, securityID, id string) error {
+	args := m.Called(ctx, userID, securityID, id)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) SavePrices(ctx context.Context, userID string, prices []models.Price) error {
+	args := m.Called(ctx, userID, prices)
+	return args.Error(0)
+}
+
+func (m *MockDBForHandler) ListPrices(ctx context.Context, userID string, filter models.PriceFilter) ([]models.Price, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Price), args.Error(1)
+}
+
+// WithTx runs fn against the mock itself, so expectations set on it apply
+// inside transactions too.
+func (m *MockDBForHandler) WithTx(ctx context.Context, fn func(tx db.Database) error) error {
+	return fn(m)
+}
@@ -0,0 +1,651 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/api"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/metrics"
+)
+
+// openAPISpec checks requests and responses against api/openapi.json.
+type openAPISpec struct {
+	doc      map[string]any
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+const openAPIResource = "openapi.json"
+
+// specHeaders are the response headers the spec has to declare wherever
+// they are sent.
+var specHeaders = []string{
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+	"Retry-After", "Idempotent-Replayed",
+}
+
+func loadOpenAPISpec(t *testing.T) *openAPISpec {
+	t.Helper()
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(api.OpenAPI))
+	require.NoError(t, err)
+	require.Equal(t, "3.1.0", doc.(map[string]any)["openapi"])
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	require.NoError(t, compiler.AddResource(openAPIResource, doc))
+
+	return &openAPISpec{
+		doc:      doc.(map[string]any),
+		compiler: compiler,
+		schemas:  make(map[string]*jsonschema.Schema),
+	}
+}
+
+// operation returns the operation documented for method on the route
+// pattern, and its JSON pointer.
+func (s *openAPISpec) operation(t *testing.T, method, pattern string) (map[string]any, string) {
+	t.Helper()
+
+	paths := s.doc["paths"].(map[string]any)
+	item, ok := paths[pattern].(map[string]any)
+	require.True(t, ok, "path %s is not documented", pattern)
+	op, ok := item[strings.ToLower(method)].(map[string]any)
+	require.True(t, ok, "%s %s is not documented", method, pattern)
+	return op, "#/paths/" + escapePointer(pattern) + "/" + strings.ToLower(method)
+}
+
+// resolve follows a local $ref, returning the object and its pointer.
+func (s *openAPISpec) resolve(t *testing.T, obj map[string]any, pointer string) (map[string]any, string) {
+	t.Helper()
+
+	ref, ok := obj["$ref"].(string)
+	if !ok {
+		return obj, pointer
+	}
+	require.True(t, strings.HasPrefix(ref, "#/"), "unsupported $ref %s", ref)
+
+	var target any = s.doc
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		target = target.(map[string]any)[token]
+	}
+	require.NotNil(t, target, "dangling $ref %s", ref)
+	return target.(map[string]any), ref
+}
+
+func (s *openAPISpec) validate(t *testing.T, schemaPointer string, body []byte) {
+	t.Helper()
+
+	schema, ok := s.schemas[schemaPointer]
+	if !ok {
+		var err error
+		schema, err = s.compiler.Compile(openAPIResource + schemaPointer)
+		require.NoError(t, err)
+		s.schemas[schemaPointer] = schema
+	}
+
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	require.NoError(t, err, "body is not JSON: %s", body)
+	assert.NoError(t, schema.Validate(value), "body: %s", body)
+}
+
+// validateRequest checks a JSON request body against the operation's
+// request schema.
+func (s *openAPISpec) validateRequest(t *testing.T, method, pattern string, body []byte) {
+	t.Helper()
+
+	op, pointer := s.operation(t, method, pattern)
+	requestBody, ok := op["requestBody"].(map[string]any)
+	require.True(t, ok, "%s %s has no documented request body", method, pattern)
+	_, ok = requestBody["content"].(map[string]any)["application/json"]
+	require.True(t, ok, "%s %s doesn't accept JSON", method, pattern)
+	s.validate(t, pointer+"/requestBody/content/application~1json/schema", body)
+}
+
+// validateResponse checks that the status, content type, headers and body
+// of a response are documented for the operation.
+func (s *openAPISpec) validateResponse(t *testing.T, method, pattern string, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	op, pointer := s.operation(t, method, pattern)
+	raw, ok := op["responses"].(map[string]any)[strconv.Itoa(w.Code)].(map[string]any)
+	require.True(t, ok, "%s %s: status %d is not documented", method, pattern, w.Code)
+	response, pointer := s.resolve(t, raw, pointer+"/responses/"+strconv.Itoa(w.Code))
+
+	headers, _ := response["headers"].(map[string]any)
+	for _, name := range specHeaders {
+		if w.Header().Get(name) != "" {
+			assert.Contains(t, headers, name, "%s %s %d: header not documented", method, pattern, w.Code)
+		}
+	}
+
+	content, ok := response["content"].(map[string]any)
+	if !ok {
+		assert.Empty(t, w.Body.Bytes(), "%s %s %d: body not documented", method, pattern, w.Code)
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	require.NoError(t, err)
+	require.Contains(t, content, mediaType, "%s %s %d: content type not documented", method, pattern, w.Code)
+	if mediaType == "application/json" {
+		s.validate(t, pointer+"/content/application~1json/schema", w.Body.Bytes())
+	}
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// routePattern normalises a chi route pattern to the form used as an
+// OpenAPI path.
+func routePattern(pattern string) string {
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+	router := SetupRoutes(zerolog.Nop(), db.NewMemoryDB(), WithMetrics(metrics.New()))
+
+	var routes []string
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, method+" "+routePattern(route))
+		return nil
+	})
+	require.NoError(t, err)
+
+	var documented []string
+	for path, item := range spec.doc["paths"].(map[string]any) {
+		for method := range item.(map[string]any) {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	slices.Sort(routes)
+	slices.Sort(documented)
+	assert.Equal(t, routes, documented)
+}
+
+// formBody is a request body sent as it is with its content type, rather
+// than marshalled to JSON.
+type formBody struct {
+	data        []byte
+	contentType string
+}
+
+func TestOpenAPI_ResponsesMatchSpec(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+	// Shut down from the start, so event streams end once caught up.
+	shutdown, cancel := context.WithCancel(context.Background())
+	cancel()
+	routes := SetupRoutes(zerolog.Nop(), db.NewMemoryDB(),
+		WithMetrics(metrics.New()),
+		WithShutdown(shutdown),
+		WithRateLimits(NewMemoryRateLimitStore(), RateLimitPolicies{
+			Summary: RateLimitPolicy{Requests: 3, Window: time.Minute},
+		}),
+	)
+	// Mirror the middleware main puts in front of the routes.
+	router := ContentType(routes)
+
+	exercised := make(map[string]bool)
+	do := func(t *testing.T, method, target string, body any, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+
+		var reqBody []byte
+		contentType := "application/json"
+		switch body := body.(type) {
+		case nil:
+		case formBody:
+			reqBody, contentType = body.data, body.contentType
+		default:
+			var err error
+			reqBody, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+
+		req := httptest.NewRequest(method, target, bytes.NewReader(reqBody))
+		if body != nil {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+
+		rctx := chi.NewRouteContext()
+		require.True(t, routes.Match(rctx, method, req.URL.Path), "%s %s doesn't match a route", method, target)
+		pattern := routePattern(rctx.RoutePattern())
+		exercised[method+" "+pattern] = true
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if body != nil && w.Code < 300 && contentType == "application/json" {
+			spec.validateRequest(t, method, pattern, reqBody)
+		}
+		spec.validateResponse(t, method, pattern, w)
+		return w
+	}
+	decodeID := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var resp struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.ID
+	}
+	userQuery := func(userID string, params ...string) string {
+		q := url.Values{"user_id": {userID}}
+		for i := 0; i+1 < len(params); i += 2 {
+			q.Add(params[i], params[i+1])
+		}
+		return "?" + q.Encode()
+	}
+
+	var userID, categoryID, firstID, secondID, thirdID string
+
+	t.Run("system", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/health", nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/metrics", nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/openapi.json", nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/docs", nil, nil).Code)
+	})
+
+	t.Run("users", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "spec@example.com"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		userID = decodeID(t, w)
+
+		assert.Equal(t, http.StatusConflict, do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "spec@example.com"}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "nope"}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "a@example.com", "name": "A"}, nil).Code)
+
+		unsupported := do(t, http.MethodPost, "/api/v1/users", nil, http.Header{"Content-Type": {"text/plain"}})
+		assert.Equal(t, http.StatusUnsupportedMediaType, unsupported.Code)
+	})
+
+	t.Run("idempotency", func(t *testing.T) {
+		key := http.Header{IdempotencyKeyHeader: {"spec-key"}}
+		assert.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "replay@example.com"}, key).Code)
+
+		replayed := do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "replay@example.com"}, key)
+		assert.Equal(t, http.StatusCreated, replayed.Code)
+		assert.Equal(t, "true", replayed.Header().Get("Idempotent-Replayed"))
+
+		reused := do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "other@example.com"}, key)
+		assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	})
+
+	t.Run("categories", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/categories"+userQuery(userID), nil, nil).Code)
+
+		w := do(t, http.MethodPost, "/api/v1/categories", map[string]any{"user_id": userID, "name": "Food"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		categoryID = decodeID(t, w)
+
+		assert.Equal(t, http.StatusConflict, do(t, http.MethodPost, "/api/v1/categories", map[string]any{"user_id": userID, "name": "Food"}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/categories", map[string]any{"user_id": "00000000-0000-4000-8000-000000000000", "name": "Food"}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/categories"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/categories", nil, nil).Code)
+	})
+
+	t.Run("transactions", func(t *testing.T) {
+		occurredAt := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+		create := func(body map[string]any) string {
+			t.Helper()
+			w := do(t, http.MethodPost, "/api/v1/transactions", body, nil)
+			require.Equal(t, http.StatusCreated, w.Code)
+			return decodeID(t, w)
+		}
+
+		firstID = create(map[string]any{"user_id": userID, "category_id": categoryID, "amount": 12.5, "description": "Lunch", "occurred_at": occurredAt})
+		secondID = create(map[string]any{"user_id": userID, "amount": 12.5, "occurred_at": occurredAt.Add(time.Hour)})
+		thirdID = create(map[string]any{"user_id": userID, "amount": 12.5, "occurred_at": occurredAt.Add(2 * time.Hour)})
+
+		invalid := do(t, http.MethodPost, "/api/v1/transactions", map[string]any{"user_id": "bad", "amount": -1}, nil)
+		assert.Equal(t, http.StatusBadRequest, invalid.Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/transactions", map[string]any{"user_id": "00000000-0000-4000-8000-000000000000", "amount": 1}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/transactions", map[string]any{"user_id": userID, "category_id": "00000000-0000-4000-8000-000000000000", "amount": 1}, nil).Code)
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/transactions"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/transactions"+userQuery(userID, "category_id", categoryID, "min_amount", "1", "has_description", "true"), nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/transactions"+userQuery(userID, "from", "2030-01-01T00:00:00Z"), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/transactions"+userQuery(userID, "uncategorized", "maybe"), nil, nil).Code)
+	})
+
+	t.Run("duplicates", func(t *testing.T) {
+		w := do(t, http.MethodGet, "/api/v1/transactions/duplicates"+userQuery(userID), nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, "[]\n", w.Body.String())
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/transactions/duplicates"+userQuery(userID, "window_days", "90"), nil, nil).Code)
+
+		merged := do(t, http.MethodPost, "/api/v1/transactions/duplicates/merge", map[string]any{"user_id": userID, "keep_id": secondID, "discard_id": firstID}, nil)
+		assert.Equal(t, http.StatusOK, merged.Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/transactions/duplicates/merge", map[string]any{"user_id": userID, "keep_id": secondID, "discard_id": firstID}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/transactions/duplicates/merge", map[string]any{"user_id": userID, "keep_id": secondID, "discard_id": secondID}, nil).Code)
+
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodPost, "/api/v1/transactions/duplicates/dismiss", map[string]any{"user_id": userID, "transaction_id": secondID, "duplicate_id": thirdID}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/transactions/duplicates/dismiss", map[string]any{"user_id": userID, "transaction_id": secondID, "duplicate_id": firstID}, nil).Code)
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		form := func(filename, content string) formBody {
+			body, contentType := multipartBody(t, "file", filename, content)
+			return formBody{data: body.Bytes(), contentType: contentType}
+		}
+		attachments := "/api/v1/transactions/" + thirdID + "/attachments" + userQuery(userID)
+
+		w := do(t, http.MethodPost, attachments, form("receipt.pdf", pdf), nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		attachmentID := decodeID(t, w)
+		assert.Equal(t, http.StatusUnsupportedMediaType, do(t, http.MethodPost, attachments, form("notes.txt", "plain text"), nil).Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, do(t, http.MethodPost, attachments, map[string]any{"file": pdf}, nil).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, do(t, http.MethodPost, attachments, form("big.pdf", pdf+strings.Repeat("x", int(DefaultAttachmentLimits.MaxSize))), nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, attachments, form("", pdf), nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/transactions/00000000-0000-4000-8000-000000000000/attachments"+userQuery(userID), form("receipt.pdf", pdf), nil).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, do(t, http.MethodPost, "/api/v1/categories", map[string]any{"user_id": userID, "name": strings.Repeat("x", maxRequestBodySize)}, nil).Code)
+
+		w = do(t, http.MethodGet, attachments, nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), attachmentID)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/transactions/00000000-0000-4000-8000-000000000000/attachments"+userQuery(userID), nil, nil).Code)
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/attachments/"+attachmentID+"/download"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/attachments/bad/download"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/attachments/"+attachmentID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/attachments/"+attachmentID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/attachments/"+attachmentID+"/download"+userQuery(userID), nil, nil).Code)
+	})
+
+	t.Run("goals", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/api/v1/goals", map[string]any{"user_id": userID, "name": "Emergency fund", "target_amount": 10000, "target_date": "2030-01-01T00:00:00Z"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		goalID := decodeID(t, w)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/goals", map[string]any{"user_id": userID, "name": "", "target_amount": 0}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/goals", map[string]any{"user_id": "00000000-0000-4000-8000-000000000000", "name": "Car", "target_amount": 1}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/goals"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/goals", nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/goals/"+goalID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/goals/00000000-0000-4000-8000-000000000000"+userQuery(userID), nil, nil).Code)
+
+		contributions := "/api/v1/goals/" + goalID + "/contributions"
+		assert.Equal(t, http.StatusCreated, do(t, http.MethodPost, contributions, map[string]any{"user_id": userID, "transaction_id": secondID, "note": "Bonus"}, nil).Code)
+		assert.Equal(t, http.StatusConflict, do(t, http.MethodPost, contributions, map[string]any{"user_id": userID, "transaction_id": secondID}, nil).Code)
+		w = do(t, http.MethodPost, contributions, map[string]any{"user_id": userID, "amount": 250, "contributed_at": time.Now().AddDate(0, 0, -7)}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		contributionID := decodeID(t, w)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, contributions, map[string]any{"user_id": userID, "transaction_id": secondID, "amount": 1}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, contributions+userQuery(userID), nil, nil).Code)
+
+		progress := "/api/v1/goals/" + goalID + "/progress"
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, progress+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, progress+userQuery(userID, "window_days", "30"), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, progress+userQuery(userID, "window_days", "0"), nil, nil).Code)
+
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, contributions+"/"+contributionID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, contributions+"/"+contributionID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/goals/"+goalID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/goals/"+goalID+userQuery(userID), nil, nil).Code)
+	})
+
+	t.Run("accounts", func(t *testing.T) {
+		// Its own user, as the net worth report shares the summary's rate limit.
+		w := do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "worth@example.com"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		ownerID := decodeID(t, w)
+
+		w = do(t, http.MethodPost, "/api/v1/accounts", map[string]any{"user_id": ownerID, "name": "House", "type": "property"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		accountID := decodeID(t, w)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/accounts", map[string]any{"user_id": ownerID, "name": "Boat", "type": "boat"}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/accounts", map[string]any{"user_id": "00000000-0000-4000-8000-000000000000", "name": "Car", "type": "vehicle"}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/accounts"+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/accounts", nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/accounts/"+accountID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/accounts/00000000-0000-4000-8000-000000000000"+userQuery(ownerID), nil, nil).Code)
+
+		valuations := "/api/v1/accounts/" + accountID + "/valuations"
+		w = do(t, http.MethodPost, valuations, map[string]any{"user_id": ownerID, "value": 250000, "valued_at": "2026-01-01T00:00:00Z"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		valuationID := decodeID(t, w)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, valuations, map[string]any{"user_id": ownerID, "value": -1}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, valuations+userQuery(ownerID), nil, nil).Code)
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/reports/net-worth"+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/reports/net-worth"+userQuery(ownerID, "from", "2026-01-01T00:00:00Z", "to", "2026-03-01T00:00:00Z", "interval", "week"), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/reports/net-worth"+userQuery(ownerID, "interval", "year"), nil, nil).Code)
+
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, valuations+"/"+valuationID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, valuations+"/"+valuationID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/accounts/"+accountID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/accounts/"+accountID+userQuery(ownerID), nil, nil).Code)
+	})
+
+	t.Run("debts", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/api/v1/debts", map[string]any{"user_id": userID, "name": "Car loan", "principal": 12000, "annual_rate": 5.9, "term_months": 48, "first_payment_date": "2026-01-15T00:00:00Z"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		debtID := decodeID(t, w)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/debts", map[string]any{"user_id": userID, "name": "Loan", "principal": 1000, "annual_rate": 100, "term_months": 0}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/debts", map[string]any{"user_id": "00000000-0000-4000-8000-000000000000", "name": "Loan", "principal": 1000, "term_months": 12, "first_payment_date": "2026-01-15T00:00:00Z"}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/debts"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/debts", nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/debts/"+debtID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/debts/00000000-0000-4000-8000-000000000000"+userQuery(userID), nil, nil).Code)
+
+		payments := "/api/v1/debts/" + debtID + "/payments"
+		assert.Equal(t, http.StatusCreated, do(t, http.MethodPost, payments, map[string]any{"user_id": userID, "transaction_id": secondID}, nil).Code)
+		assert.Equal(t, http.StatusConflict, do(t, http.MethodPost, payments, map[string]any{"user_id": userID, "transaction_id": secondID}, nil).Code)
+		w = do(t, http.MethodPost, payments, map[string]any{"user_id": userID, "amount": 281.5, "paid_at": "2026-02-14T00:00:00Z", "note": "February"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		paymentID := decodeID(t, w)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, payments, map[string]any{"user_id": userID, "transaction_id": secondID, "amount": 1}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, payments+userQuery(userID), nil, nil).Code)
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/debts/"+debtID+"/schedule"+userQuery(userID), nil, nil).Code)
+		report := "/api/v1/debts/" + debtID + "/report"
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, report+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, report+userQuery(userID, "extra_monthly", "100", "extra_monthly", "250.50"), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, report+userQuery(userID, "extra_monthly", "-5"), nil, nil).Code)
+
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, payments+"/"+paymentID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, payments+"/"+paymentID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/debts/"+debtID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/debts/"+debtID+userQuery(userID), nil, nil).Code)
+	})
+
+	t.Run("securities", func(t *testing.T) {
+		// Its own user, as the portfolio report shares the summary's rate limit.
+		w := do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "investor@example.com"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		ownerID := decodeID(t, w)
+
+		w = do(t, http.MethodPost, "/api/v1/securities", map[string]any{"user_id": ownerID, "symbol": "vwrl", "name": "Vanguard FTSE All-World"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		securityID := decodeID(t, w)
+		assert.Equal(t, http.StatusConflict, do(t, http.MethodPost, "/api/v1/securities", map[string]any{"user_id": ownerID, "symbol": "VWRL", "name": "Again"}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/securities", map[string]any{"user_id": ownerID, "symbol": "", "name": ""}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/securities", map[string]any{"user_id": "00000000-0000-4000-8000-000000000000", "symbol": "MSFT", "name": "Microsoft"}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/securities"+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/securities", nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/securities/"+securityID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/securities/00000000-0000-4000-8000-000000000000"+userQuery(ownerID), nil, nil).Code)
+
+		lots := "/api/v1/securities/" + securityID + "/lots"
+		w = do(t, http.MethodPost, lots, map[string]any{"user_id": ownerID, "side": "buy", "quantity": 12.5, "price": 98.42, "fees": 1.5, "traded_at": "2026-01-05T10:00:00Z"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		buyID := decodeID(t, w)
+		w = do(t, http.MethodPost, lots, map[string]any{"user_id": ownerID, "side": "sell", "quantity": 2.5, "price": 104, "traded_at": "2026-02-05T10:00:00Z"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		sellID := decodeID(t, w)
+		assert.Equal(t, http.StatusConflict, do(t, http.MethodPost, lots, map[string]any{"user_id": ownerID, "side": "sell", "quantity": 100, "price": 104}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, lots, map[string]any{"user_id": ownerID, "side": "short", "quantity": 0, "price": -1}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, lots+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusConflict, do(t, http.MethodDelete, lots+"/"+buyID+userQuery(ownerID), nil, nil).Code)
+
+		form := func(content string) formBody {
+			body, contentType := multipartBody(t, "file", "prices.csv", content)
+			return formBody{data: body.Bytes(), contentType: contentType}
+		}
+		prices := "/api/v1/securities/prices" + userQuery(ownerID)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodPost, prices, form("symbol,date,price\nVWRL,2026-02-27,101.12\nvwrl,2026-03-31T16:30:00Z,103.4\n"), nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, prices, form("symbol,date,price\nMSFT,2026-02-27,1\n"), nil).Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, do(t, http.MethodPost, prices, map[string]any{"file": "symbol,date,price"}, nil).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, do(t, http.MethodPost, prices, form("symbol,date,price\n"+strings.Repeat("VWRL,2026-02-27,101.12\n", maxPriceFileSize/20)), nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/securities/"+securityID+"/prices"+userQuery(ownerID, "from", "2026-03-01T00:00:00Z"), nil, nil).Code)
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/reports/portfolio"+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/reports/portfolio"+userQuery(ownerID, "method", "average", "as_of", "2026-03-01T00:00:00Z"), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/reports/portfolio"+userQuery(ownerID, "method", "lifo"), nil, nil).Code)
+
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, lots+"/"+sellID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, lots+"/"+sellID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/securities/"+securityID+userQuery(ownerID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/securities/"+securityID+userQuery(ownerID), nil, nil).Code)
+	})
+
+	t.Run("graphql", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/graphql", map[string]any{
+			"query":     `query($id: ID!) { user(id: $id) { email transactions { amount category { name } } } }`,
+			"variables": map[string]any{"id": userID},
+		}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"email":"spec@example.com"`)
+
+		w = do(t, http.MethodPost, "/graphql", map[string]any{"query": `{ user(id: "bad") { id } }`}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"validation_failed"`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, do(t, http.MethodPost, "/graphql", map[string]any{"query": `{ users { id } }`}, nil).Code)
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		subscribe := map[string]any{
+			"user_id":     userID,
+			"url":         "https://example.com/hooks",
+			"secret":      "0123456789abcdef-secret",
+			"event_types": []string{"category.created", "transaction.created"},
+		}
+		w := do(t, http.MethodPost, "/api/v1/webhooks", subscribe, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		webhookID := decodeID(t, w)
+
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/webhooks", map[string]any{"user_id": userID, "url": "example.com", "secret": "short", "event_types": []string{}}, nil).Code)
+		subscribe["user_id"] = "00000000-0000-4000-8000-000000000000"
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/webhooks", subscribe, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/webhooks"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/webhooks", nil, nil).Code)
+
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/categories", map[string]any{"user_id": userID, "name": "Hooks"}, nil).Code)
+		w = do(t, http.MethodGet, "/api/v1/webhooks/"+webhookID+"/deliveries"+userQuery(userID), nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"event_type":"category.created"`)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/webhooks/bad/deliveries"+userQuery(userID), nil, nil).Code)
+
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/webhooks/"+webhookID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/webhooks/"+webhookID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/webhooks/"+webhookID+"/deliveries"+userQuery(userID), nil, nil).Code)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		w := do(t, http.MethodGet, "/api/v1/events"+userQuery(userID), nil, http.Header{"Last-Event-Id": {"0"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "event: transaction.deleted\n")
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/events"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/events"+userQuery(userID), nil, http.Header{"Last-Event-Id": {"latest"}}).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/events"+userQuery("00000000-0000-4000-8000-000000000000"), nil, nil).Code)
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		w := do(t, http.MethodGet, "/api/v1/audit"+userQuery(userID), nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"action":"delete"`)
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/audit"+userQuery(userID, "entity", "transaction", "action", "create", "limit", "1"), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/audit"+userQuery(userID, "entity", "budget"), nil, nil).Code)
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/transactions/"+thirdID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/transactions/"+thirdID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodDelete, "/api/v1/transactions/bad"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/api/v1/categories/"+categoryID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/api/v1/categories/"+categoryID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodDelete, "/api/v1/categories/"+categoryID, nil, nil).Code)
+
+		w := do(t, http.MethodGet, "/api/v1/trash"+userQuery(userID), nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), firstID, "discarded by the merge")
+		assert.Contains(t, w.Body.String(), thirdID)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/trash", nil, nil).Code)
+
+		assert.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/restore", map[string]any{"user_id": userID, "entity": "category", "id": categoryID}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/restore", map[string]any{"user_id": userID, "entity": "transaction", "id": thirdID}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/restore", map[string]any{"user_id": userID, "entity": "transaction", "id": thirdID}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/restore", map[string]any{"user_id": userID, "entity": "user", "id": userID}, nil).Code)
+	})
+
+	t.Run("privacy", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/api/v1/exports", map[string]any{"user_id": userID}, nil)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		var export struct {
+			ID            string `json:"id"`
+			DownloadToken string `json:"download_token"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/exports", map[string]any{"user_id": "00000000-0000-4000-8000-000000000000"}, nil).Code)
+
+		require.Eventually(t, func() bool {
+			return do(t, http.MethodGet, "/api/v1/exports/"+export.ID+"/download?token="+export.DownloadToken, nil, nil).Code == http.StatusOK
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/exports/"+export.ID+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/api/v1/exports/"+export.ID+"/download?token=wrong", nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/exports/"+export.ID+"/download", nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/exports/bad"+userQuery(userID), nil, nil).Code)
+
+		w = do(t, http.MethodPost, "/api/v1/users", map[string]any{"email": "erase@example.com"}, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+		erasedID := decodeID(t, w)
+		assert.Equal(t, http.StatusAccepted, do(t, http.MethodPost, "/api/v1/erasure", map[string]any{"user_id": erasedID}, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodPost, "/api/v1/erasure/confirm", map[string]any{"user_id": erasedID, "email": "wrong@example.com"}, nil).Code)
+		assert.Equal(t, http.StatusAccepted, do(t, http.MethodPost, "/api/v1/erasure", map[string]any{"user_id": erasedID}, nil).Code)
+		assert.Equal(t, http.StatusNoContent, do(t, http.MethodPost, "/api/v1/erasure/confirm", map[string]any{"user_id": erasedID, "email": "erase@example.com"}, nil).Code)
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodPost, "/api/v1/erasure", map[string]any{"user_id": erasedID}, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/audit"+userQuery(erasedID), nil, nil).Code)
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/summary"+userQuery(userID), nil, nil).Code)
+		assert.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/summary"+userQuery(userID, "from", "2030-01-01T00:00:00Z"), nil, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, do(t, http.MethodGet, "/api/v1/summary"+userQuery(userID, "from", "2025-02-01T00:00:00Z", "to", "2025-01-01T00:00:00Z"), nil, nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, do(t, http.MethodGet, "/api/v1/summary"+userQuery(userID), nil, nil).Code)
+	})
+
+	for path, item := range spec.doc["paths"].(map[string]any) {
+		for method := range item.(map[string]any) {
+			assert.True(t, exercised[strings.ToUpper(method)+" "+path], "%s %s is not exercised", strings.ToUpper(method), path)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/privacy"
+	"fintrack-go/internal/validator"
+)
+
+type PrivacyHandler struct {
+	*Handler
+	exporter *privacy.Exporter
+	eraser   *privacy.Eraser
+}
+
+func NewPrivacyHandler(logger zerolog.Logger, exporter *privacy.Exporter, eraser *privacy.Eraser) *PrivacyHandler {
+	return &PrivacyHandler{
+		Handler:  NewHandler(logger),
+		exporter: exporter,
+		eraser:   eraser,
+	}
+}
+
+type CreateExportRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type RequestErasureRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type ConfirmErasureRequest struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// CreateExport starts building an archive of the user's data and responds
+// with the export, whose download token is not shown again.
+func (h *PrivacyHandler) CreateExport(w http.ResponseWriter, r *http.Request) {
+	var req CreateExportRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	export, err := h.exporter.Start(r.Context(), req.UserID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to start export")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusAccepted, export)
+}
+
+func (h *PrivacyHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.exporter.Get(r.Context(), userID, id)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get export")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, export)
+}
+
+// DownloadExport responds with the ZIP archive of a ready export. It is
+// authorized by the export's download token rather than a user ID, so the
+// link can be handed to a browser.
+func (h *PrivacyHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	token := r.URL.Query().Get("token")
+
+	var v validator.Validator
+	v.Check("id", id, validator.ValidateUUID(id))
+	if token == "" {
+		v.Add("token", validator.RuleRequired, "token is required", nil)
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	archive, export, err := h.exporter.Download(r.Context(), id, token)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to download export")
+		return
+	}
+	defer archive.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="fintrack-export-`+export.CreatedAt.Format("2006-01-02")+`.zip"`)
+	w.Header().Set("Content-Length", strconv.FormatInt(archive.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		h.Logger.Debug().Err(err).Msg("Failed to write export")
+	}
+}
+
+// RequestErasure asks to erase the user. Nothing is erased until it is
+// confirmed with the user's email address.
+func (h *PrivacyHandler) RequestErasure(w http.ResponseWriter, r *http.Request) {
+	var req RequestErasureRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	request, err := h.eraser.Request(r.Context(), req.UserID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to request erasure")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusAccepted, request)
+}
+
+// ConfirmErasure erases the user if the email address given is theirs:
+// everything they own is deleted and their audit entries are redacted.
+func (h *PrivacyHandler) ConfirmErasure(w http.ResponseWriter, r *http.Request) {
+	var req ConfirmErasureRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("email", req.Email, validator.ValidateEmail(req.Email))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	if err := h.eraser.Confirm(r.Context(), req.UserID, req.Email); err != nil {
+		h.respondWithDBError(w, err, "Failed to erase user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/blob"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/privacy"
+)
+
+func newPrivacyRouter(t *testing.T, database db.Database) chi.Router {
+	t.Helper()
+	exporter := privacy.NewExporter(zerolog.Nop(), database, blob.NewMemoryStore(), privacy.ExportConfig{TTL: time.Hour})
+	t.Cleanup(exporter.Close)
+	eraser := privacy.NewEraser(zerolog.Nop(), database, exporter, privacy.ErasureConfig{ConfirmationTTL: time.Minute})
+	return SetupRoutes(zerolog.Nop(), database, WithPrivacy(exporter, eraser))
+}
+
+func serve(router http.Handler, method, target string, body any) *httptest.ResponseRecorder {
+	var reqBody bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&reqBody).Encode(body)
+	}
+	req := httptest.NewRequest(method, target, &reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPrivacyHandler_Export(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "export@example.com")
+	require.NoError(t, err)
+	_, err = database.CreateCategory(ctx, user.ID, "Food")
+	require.NoError(t, err)
+	router := newPrivacyRouter(t, database)
+
+	w := serve(router, http.MethodPost, "/api/v1/exports", CreateExportRequest{UserID: user.ID})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var started models.Export
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&started))
+	assert.NotEmpty(t, started.DownloadToken)
+
+	require.Eventually(t, func() bool {
+		w := serve(router, http.MethodGet, "/api/v1/exports/"+started.ID+"?user_id="+user.ID, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var export models.Export
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&export))
+		assert.Empty(t, export.DownloadToken)
+		return export.Status == models.ExportReady
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w = serve(router, http.MethodGet, "/api/v1/exports/"+started.ID+"/download?token="+started.DownloadToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=")
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.ElementsMatch(t, []string{"profile.json", "categories.json", "categories.csv", "transactions.json", "transactions.csv", "goals.json", "accounts.json", "debts.json", "securities.json"}, names)
+
+	t.Run("wrong token", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/v1/exports/"+started.ID+"/download?token=wrong", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "export_not_found")
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		w := serve(router, http.MethodGet, "/api/v1/exports/"+started.ID+"/download", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("another user's export", func(t *testing.T) {
+		other, err := database.CreateUser(ctx, "other@example.com")
+		require.NoError(t, err)
+		w := serve(router, http.MethodGet, "/api/v1/exports/"+started.ID+"?user_id="+other.ID, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		w := serve(router, http.MethodPost, "/api/v1/exports", CreateExportRequest{UserID: "550e8400-e29b-41d4-a716-446655440000"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid user_id", func(t *testing.T) {
+		w := serve(router, http.MethodPost, "/api/v1/exports", CreateExportRequest{UserID: "invalid"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPrivacyHandler_Erasure(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "erase@example.com")
+	require.NoError(t, err)
+	router := newPrivacyRouter(t, database)
+
+	w := serve(router, http.MethodPost, "/api/v1/erasure", RequestErasureRequest{UserID: user.ID})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var request models.ErasureRequest
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&request))
+	assert.Equal(t, user.ID, request.UserID)
+	assert.NotContains(t, w.Body.String(), "token", "nothing in the response confirms the erasure")
+
+	w = serve(router, http.MethodPost, "/api/v1/erasure/confirm", ConfirmErasureRequest{UserID: user.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPost, "/api/v1/erasure/confirm", ConfirmErasureRequest{UserID: user.ID, Email: "someone@example.com"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_erasure_confirmation")
+	w = serve(router, http.MethodPost, "/api/v1/erasure/confirm", ConfirmErasureRequest{UserID: user.ID, Email: user.Email})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "cancelled by the wrong address")
+	_, err = database.GetUserByID(ctx, user.ID)
+	require.NoError(t, err, "not erased until confirmed")
+
+	w = serve(router, http.MethodPost, "/api/v1/erasure", RequestErasureRequest{UserID: user.ID})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	w = serve(router, http.MethodPost, "/api/v1/erasure/confirm", ConfirmErasureRequest{UserID: user.ID, Email: user.Email})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	_, err = database.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+
+	entries, err := database.ListAuditEntries(ctx, user.ID, models.AuditFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.AuditDelete, entries[0].Action)
+	assert.Equal(t, db.RedactedActor, entries[1].Actor)
+
+	w = serve(router, http.MethodPost, "/api/v1/erasure", RequestErasureRequest{UserID: user.ID})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
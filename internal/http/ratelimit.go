@@ -0,0 +1,227 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Route groups that can be given their own rate limit policy.
+const (
+	RouteGroupUsers        = "users"
+	RouteGroupCategories   = "categories"
+	RouteGroupTransactions = "transactions"
+	RouteGroupSummary      = "summary"
+	RouteGroupGraphQL      = "graphql"
+	RouteGroupWebhooks     = "webhooks"
+	RouteGroupEvents       = "events"
+	RouteGroupAudit        = "audit"
+	RouteGroupTrash        = "trash"
+	RouteGroupPrivacy      = "privacy"
+	RouteGroupAttachments  = "attachments"
+	RouteGroupGoals        = "goals"
+	RouteGroupAccounts     = "accounts"
+	RouteGroupDebts        = "debts"
+	RouteGroupSecurities   = "securities"
+)
+
+// RateLimitPolicy allows Requests per Window for each client, refilled
+// continuously, so a client may burst up to Requests at once. A policy with
+// zero Requests does not limit.
+type RateLimitPolicy struct {
+	Requests int
+	Window   time.Duration
+}
+
+func (p RateLimitPolicy) enabled() bool {
+	return p.Requests > 0 && p.Window > 0
+}
+
+// RateLimitPolicies holds a policy for each route group.
+type RateLimitPolicies struct {
+	Users        RateLimitPolicy
+	Categories   RateLimitPolicy
+	Transactions RateLimitPolicy
+	Summary      RateLimitPolicy
+	GraphQL      RateLimitPolicy
+	Webhooks     RateLimitPolicy
+	Events       RateLimitPolicy
+	Audit        RateLimitPolicy
+	Trash        RateLimitPolicy
+	Privacy      RateLimitPolicy
+	Attachments  RateLimitPolicy
+	Goals        RateLimitPolicy
+	Accounts     RateLimitPolicy
+	Debts        RateLimitPolicy
+	Securities   RateLimitPolicy
+}
+
+// RateLimitResult is the state of a client's bucket after taking a token.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until a token is available, when not Allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps token buckets by key.
+type RateLimitStore interface {
+	// Take removes a token from the bucket for key, if one is available.
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+// RateLimiter rejects requests with 429 once a client has used up its
+// policy, and reports the client's remaining budget in RateLimit-* headers.
+type RateLimiter struct {
+	*Handler
+	store   RateLimitStore
+	keyFunc func(r *http.Request) string
+}
+
+func NewRateLimiter(logger zerolog.Logger, store RateLimitStore) *RateLimiter {
+	return &RateLimiter{
+		Handler: NewHandler(logger),
+		store:   store,
+		keyFunc: RateLimitClientKey,
+	}
+}
+
+// Limit returns middleware applying policy to the requests of a route group.
+// Each client gets a separate bucket per group.
+func (l *RateLimiter) Limit(group string, policy RateLimitPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !policy.enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := l.store.Take(r.Context(), group+":"+l.keyFunc(r), policy)
+			if err != nil {
+				// Fail open: an unavailable store shouldn't take the API down.
+				l.Logger.Error().Err(err).Str("group", group).Msg("Failed to check rate limit")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(policy.Requests))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			h.Set("RateLimit-Policy", strconv.Itoa(policy.Requests)+";w="+strconv.Itoa(ceilSeconds(policy.Window)))
+
+			if !result.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				l.respondWithError(w, http.StatusTooManyRequests, "Rate limit exceeded, retry later", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitClientKey identifies the client making a request: by API key when
+// one is sent, then by the user_id query parameter, and otherwise by IP. The
+// body is not read, so requests naming their user only in a JSON body, such
+// as POSTs, are counted per API key or IP: clients sharing an address should
+// send an API key to be limited separately.
+func RateLimitClientKey(r *http.Request) string {
+	if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+		return "key:" + apiKey
+	}
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore is a RateLimitStore held in process memory. Each server
+// instance counts separately.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	window time.Duration
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(policy.Requests)
+	perSecond := capacity / policy.Window.Seconds()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	b.window = policy.Window
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	var result RateLimitResult
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsDuration((1 - b.tokens) / perSecond)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsDuration((capacity - b.tokens) / perSecond)
+
+	return result, nil
+}
+
+// sweep drops buckets that have had time to refill completely, since they
+// are indistinguishable from new ones. It runs at most once a minute. Callers
+// hold s.mu.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.window {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
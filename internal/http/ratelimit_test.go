@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store unavailable")
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	policy := RateLimitPolicy{Requests: 2, Window: time.Minute}
+
+	get := func(handler http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("allows up to the limit then rejects", func(t *testing.T) {
+		limiter := NewRateLimiter(zerolog.Nop(), NewMemoryRateLimitStore())
+		handler := limiter.Limit(RouteGroupTransactions, policy)(ok)
+
+		first := get(handler, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", first.Header().Get("RateLimit-Policy"))
+
+		second := get(handler, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, "0", second.Header().Get("RateLimit-Remaining"))
+
+		third := get(handler, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusTooManyRequests, third.Code)
+		assert.Equal(t, "30", third.Header().Get("Retry-After"))
+		assert.Equal(t, "60", third.Header().Get("RateLimit-Reset"))
+		assert.Contains(t, third.Body.String(), "Rate limit exceeded")
+	})
+
+	t.Run("clients are limited separately", func(t *testing.T) {
+		limiter := NewRateLimiter(zerolog.Nop(), NewMemoryRateLimitStore())
+		handler := limiter.Limit(RouteGroupTransactions, policy)(ok)
+
+		get(handler, "10.0.0.1:1234")
+		get(handler, "10.0.0.1:1234")
+
+		assert.Equal(t, http.StatusOK, get(handler, "10.0.0.2:1234").Code)
+	})
+
+	t.Run("groups are limited separately", func(t *testing.T) {
+		limiter := NewRateLimiter(zerolog.Nop(), NewMemoryRateLimitStore())
+		transactions := limiter.Limit(RouteGroupTransactions, policy)(ok)
+		summary := limiter.Limit(RouteGroupSummary, policy)(ok)
+
+		get(transactions, "10.0.0.1:1234")
+		get(transactions, "10.0.0.1:1234")
+
+		assert.Equal(t, http.StatusOK, get(summary, "10.0.0.1:1234").Code)
+	})
+
+	t.Run("zero policy does not limit", func(t *testing.T) {
+		limiter := NewRateLimiter(zerolog.Nop(), NewMemoryRateLimitStore())
+		handler := limiter.Limit(RouteGroupTransactions, RateLimitPolicy{})(ok)
+
+		for i := 0; i < 5; i++ {
+			w := get(handler, "10.0.0.1:1234")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		}
+	})
+
+	t.Run("store errors fail open", func(t *testing.T) {
+		limiter := NewRateLimiter(zerolog.Nop(), failingRateLimitStore{})
+		handler := limiter.Limit(RouteGroupTransactions, policy)(ok)
+
+		assert.Equal(t, http.StatusOK, get(handler, "10.0.0.1:1234").Code)
+	})
+}
+
+func TestRateLimitClientKey(t *testing.T) {
+	t.Run("api key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?user_id=abc", nil)
+		req.Header.Set(APIKeyHeader, "secret")
+		assert.Equal(t, "key:secret", RateLimitClientKey(req))
+	})
+
+	t.Run("user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?user_id=abc", nil)
+		assert.Equal(t, "user:abc", RateLimitClientKey(req))
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:5555"
+		assert.Equal(t, "ip:192.0.2.1", RateLimitClientKey(req))
+	})
+}
+
+func TestMemoryRateLimitStore_Refill(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRateLimitStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	policy := RateLimitPolicy{Requests: 2, Window: 2 * time.Second}
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(ctx, "k", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err := store.Take(ctx, "k", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	now = now.Add(time.Second)
+	result, err = store.Take(ctx, "k", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"fintrack-go/internal/validator"
+)
+
+// decodeJSON decodes the request body into dst, rejecting fields dst doesn't
+// have. On failure it responds with a 400, or a 413 for a body over the
+// limit, and returns false.
+func (h *Handler) decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil {
+		return true
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		h.respondWithBodyError(w, err)
+		return false
+	}
+
+	var v validator.Validator
+	var typeErr *json.UnmarshalTypeError
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field, _ = strconv.Unquote(field)
+		v.Add(field, validator.RuleUnknownField, "unknown field '"+field+"'", nil)
+	} else if errors.As(err, &typeErr) && typeErr.Field != "" {
+		v.Add(typeErr.Field, validator.RuleType, typeErr.Field+" must be a "+typeErr.Type.String(), nil)
+	}
+
+	if v.Valid() {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body", nil)
+	} else {
+		h.respondWithValidationError(w, v.Err())
+	}
+	return false
+}
+
+// respondWithBodyError responds to a failure reading the request body: a
+// 413 if it was larger than allowed, otherwise a 400.
+func (h *Handler) respondWithBodyError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		h.respondWithError(w, http.StatusRequestEntityTooLarge, "Request body cannot exceed "+strconv.FormatInt(maxBytesErr.Limit, 10)+" bytes", nil)
+		return
+	}
+	h.respondWithError(w, http.StatusBadRequest, "Invalid request body", nil)
+}
+
+// respondWithValidationError responds with a 400 listing every field error
+// in err, which must be validator.Errors.
+func (h *Handler) respondWithValidationError(w http.ResponseWriter, err error) {
+	var errs validator.Errors
+	errors.As(err, &errs)
+	h.writeError(w, http.StatusBadRequest, "validation_failed", errs.Error(), errs)
+}
+
+// fileField is the multipart form field holding an uploaded file.
+const fileField = "file"
+
+// filePart returns the part of the multipart form holding the file, skipping
+// any other fields. On failure it responds and returns false.
+func (h *Handler) filePart(w http.ResponseWriter, r *http.Request) (*multipart.Part, bool) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Request body must be a multipart form", nil)
+		return nil, false
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			var v validator.Validator
+			v.Add(fileField, validator.RuleRequired, "file is required", nil)
+			h.respondWithValidationError(w, v.Err())
+			return nil, false
+		}
+		if err != nil {
+			h.respondWithBodyError(w, err)
+			return nil, false
+		}
+		if part.FormName() == fileField {
+			return part, true
+		}
+		part.Close()
+	}
+}
+
+// checkUserIDParam validates the required user_id query parameter.
+func checkUserIDParam(v *validator.Validator, userID string) {
+	if userID == "" {
+		v.Add("user_id", validator.RuleRequired, "user_id query parameter is required", nil)
+		return
+	}
+	v.Check("user_id", userID, validator.ValidateUUID(userID))
+}
+
+// resourceParams validates the user_id query parameter and the id of the
+// user's resource in the path. On failure it responds with a 400 and returns
+// false.
+func (h *Handler) resourceParams(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	userID := r.URL.Query().Get("user_id")
+	id := chi.URLParam(r, "id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	v.Check("id", id, validator.ValidateUUID(id))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return "", "", false
+	}
+	return userID, id, true
+}
+
+// timeParam parses the optional RFC 3339 query parameter name.
+func timeParam(v *validator.Validator, query url.Values, name string) *time.Time {
+	s := query.Get(name)
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		v.Add(name, validator.RuleFormat, "Invalid '"+name+"' date format. Use RFC3339", s)
+		return nil
+	}
+	return &t
+}
+
+// parseNumber parses a decimal number. NaN and infinities, which
+// strconv.ParseFloat accepts, are not numbers any amount can be.
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// floatParam parses the optional numeric query parameter name.
+func floatParam(v *validator.Validator, query url.Values, name string) *float64 {
+	s := query.Get(name)
+	if s == "" {
+		return nil
+	}
+	f, ok := parseNumber(s)
+	if !ok {
+		v.Add(name, validator.RuleType, "Invalid '"+name+"'. Must be a number", s)
+		return nil
+	}
+	return &f
+}
+
+// intParam parses the optional whole-number query parameter name.
+func intParam(v *validator.Validator, query url.Values, name string) *int {
+	s := query.Get(name)
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		v.Add(name, validator.RuleType, "Invalid '"+name+"'. Must be a whole number", s)
+		return nil
+	}
+	return &n
+}
+
+// boolParam parses the optional boolean query parameter name.
+func boolParam(v *validator.Validator, query url.Values, name string) *bool {
+	s := query.Get(name)
+	if s == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.Add(name, validator.RuleType, "Invalid '"+name+"'. Must be true or false", s)
+		return nil
+	}
+	return &b
+}
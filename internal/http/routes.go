@@ -1,38 +1,284 @@
 package http
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
+	"fintrack-go/internal/blob"
 	"fintrack-go/internal/db"
+	"fintrack-go/internal/graph"
+	"fintrack-go/internal/metrics"
+	"fintrack-go/internal/privacy"
 )
 
 const maxRequestBodySize = 1 << 20
 
-func SetupRoutes(logger zerolog.Logger, database db.Database) chi.Router {
+// maxMultipartOverhead is how much larger than the file it carries an
+// upload's body may be, for the multipart boundaries and part headers.
+const maxMultipartOverhead = 64 << 10
+
+// uploadRoutes holds the routes that take a multipart/form-data upload
+// rather than JSON, so middleware running before routing can tell them
+// apart. Their handlers are registered in SetupRoutes.
+var uploadRoutes = func() *chi.Mux {
+	r := chi.NewRouter()
+	r.Post("/api/v1/transactions/{id}/attachments", http.NotFound)
+	r.Post("/api/v1/securities/prices", http.NotFound)
+	return r
+}()
+
+// isUpload reports whether r is for one of the uploadRoutes.
+func isUpload(r *http.Request) bool {
+	return uploadRoutes.Match(chi.NewRouteContext(), r.Method, r.URL.Path)
+}
+
+type routeOptions struct {
+	idempotencyStore IdempotencyStore
+	idempotencyTTL   time.Duration
+	rateLimitStore   RateLimitStore
+	rateLimits       RateLimitPolicies
+	metrics          *metrics.Metrics
+	shutdown         context.Context
+	exporter         *privacy.Exporter
+	eraser           *privacy.Eraser
+	blobStore        blob.Store
+	attachmentLimits AttachmentLimits
+}
+
+// RouteOption customises the router built by SetupRoutes.
+type RouteOption func(*routeOptions)
+
+// WithIdempotencyStore replaces the default in-memory Idempotency-Key store
+// and how long responses are kept.
+func WithIdempotencyStore(store IdempotencyStore, ttl time.Duration) RouteOption {
+	return func(o *routeOptions) {
+		o.idempotencyStore = store
+		o.idempotencyTTL = ttl
+	}
+}
+
+// WithRateLimits limits each route group according to policies, counting
+// requests in store. Without it no rate limits apply.
+func WithRateLimits(store RateLimitStore, policies RateLimitPolicies) RouteOption {
+	return func(o *routeOptions) {
+		o.rateLimitStore = store
+		o.rateLimits = policies
+	}
+}
+
+// WithMetrics records request metrics in m and serves them at /metrics,
+// along with the domain metrics of a Database wrapped with
+// m.InstrumentDatabase.
+func WithMetrics(m *metrics.Metrics) RouteOption {
+	return func(o *routeOptions) {
+		o.metrics = m
+	}
+}
+
+// WithShutdown ends the event streams, which otherwise last as long as their
+// clients stay connected, when ctx is done. Servers cancel it as they shut
+// down.
+func WithShutdown(ctx context.Context) RouteOption {
+	return func(o *routeOptions) {
+		o.shutdown = ctx
+	}
+}
+
+// WithPrivacy serves data exports from exporter and erasures from eraser.
+// Without it they are kept with the default lifetimes, exports in the blob
+// store set by WithAttachments, and exports being built are not stopped when
+// the server shuts down.
+func WithPrivacy(exporter *privacy.Exporter, eraser *privacy.Eraser) RouteOption {
+	return func(o *routeOptions) {
+		o.exporter = exporter
+		o.eraser = eraser
+	}
+}
+
+// WithAttachments keeps attached files in store, within limits. Without it
+// they are kept in memory, within DefaultAttachmentLimits.
+func WithAttachments(store blob.Store, limits AttachmentLimits) RouteOption {
+	return func(o *routeOptions) {
+		o.blobStore = store
+		o.attachmentLimits = limits
+	}
+}
+
+func SetupRoutes(logger zerolog.Logger, database db.Database, opts ...RouteOption) chi.Router {
+	options := routeOptions{
+		idempotencyTTL:   DefaultIdempotencyTTL,
+		shutdown:         context.Background(),
+		attachmentLimits: DefaultAttachmentLimits,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.idempotencyStore == nil {
+		options.idempotencyStore = NewMemoryIdempotencyStore()
+	}
+	if options.rateLimitStore == nil {
+		options.rateLimitStore = NewMemoryRateLimitStore()
+	}
+	if options.blobStore == nil {
+		options.blobStore = blob.NewMemoryStore()
+	}
+
 	r := chi.NewRouter()
 
+	if options.metrics != nil {
+		r.Use(options.metrics.Middleware)
+		r.Method(http.MethodGet, "/metrics", options.metrics.Handler())
+	}
+
+	if options.exporter == nil {
+		options.exporter = privacy.NewExporter(logger, database, options.blobStore, privacy.ExportConfig{TTL: privacy.DefaultExportTTL})
+	}
+	if options.eraser == nil {
+		options.eraser = privacy.NewEraser(logger, database, options.exporter, privacy.ErasureConfig{ConfirmationTTL: privacy.DefaultConfirmationTTL})
+	}
+
 	healthHandler := NewHealthHandler(logger, database)
 	userHandler := NewUserHandler(logger, database)
 	categoryHandler := NewCategoryHandler(logger, database)
 	transactionHandler := NewTransactionHandler(logger, database)
+	duplicateHandler := NewDuplicateHandler(logger, database)
 	summaryHandler := NewSummaryHandler(logger, database)
+	webhookHandler := NewWebhookHandler(logger, database)
+	eventsHandler := NewEventsHandler(logger, database, options.shutdown)
+	auditHandler := NewAuditHandler(logger, database)
+	trashHandler := NewTrashHandler(logger, database)
+	privacyHandler := NewPrivacyHandler(logger, options.exporter, options.eraser)
+	attachmentHandler := NewAttachmentHandler(logger, database, options.blobStore, options.attachmentLimits)
+	goalHandler := NewGoalHandler(logger, database)
+	accountHandler := NewAccountHandler(logger, database)
+	debtHandler := NewDebtHandler(logger, database)
+	securityHandler := NewSecurityHandler(logger, database)
+	docsHandler := NewDocsHandler(logger)
+	idempotency := NewIdempotencyMiddleware(logger, options.idempotencyStore, options.idempotencyTTL)
+	limiter := NewRateLimiter(logger, options.rateLimitStore)
 
 	r.Get("/health", healthHandler.Health)
+	r.Get("/openapi.json", docsHandler.OpenAPI)
+	r.Get("/docs", docsHandler.Docs)
+	r.With(limiter.Limit(RouteGroupGraphQL, options.rateLimits.GraphQL)).
+		Post("/graphql", http.MaxBytesHandler(graph.NewHandler(logger, database), maxRequestBodySize).ServeHTTP)
 
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Post("/users", userHandler.CreateUser)
+		r.Use(LimitRequestBody(logger, options.attachmentLimits.MaxSize+maxMultipartOverhead))
+		r.Use(idempotency.Handle)
+
+		r.With(limiter.Limit(RouteGroupUsers, options.rateLimits.Users)).Post("/users", userHandler.CreateUser)
 
 		r.Route("/categories", func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupCategories, options.rateLimits.Categories))
 			r.Post("/", categoryHandler.CreateCategory)
 			r.Get("/", categoryHandler.ListCategories)
+			r.Delete("/{id}", categoryHandler.DeleteCategory)
 		})
 
 		r.Route("/transactions", func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupTransactions, options.rateLimits.Transactions))
 			r.Post("/", transactionHandler.CreateTransaction)
 			r.Get("/", transactionHandler.ListTransactions)
+			r.Delete("/{id}", transactionHandler.DeleteTransaction)
+			r.Get("/duplicates", duplicateHandler.ListDuplicates)
+			r.Post("/duplicates/merge", duplicateHandler.MergeDuplicates)
+			r.Post("/duplicates/dismiss", duplicateHandler.DismissDuplicate)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupSummary, options.rateLimits.Summary))
+			r.Get("/summary", summaryHandler.GetSummary)
+			r.Get("/reports/net-worth", accountHandler.GetNetWorth)
+			r.Get("/reports/portfolio", securityHandler.GetPortfolio)
+		})
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupWebhooks, options.rateLimits.Webhooks))
+			r.Post("/", webhookHandler.CreateWebhook)
+			r.Get("/", webhookHandler.ListWebhooks)
+			r.Delete("/{id}", webhookHandler.DeleteWebhook)
+			r.Get("/{id}/deliveries", webhookHandler.ListDeliveries)
+		})
+
+		r.With(limiter.Limit(RouteGroupEvents, options.rateLimits.Events)).Get("/events", eventsHandler.Stream)
+
+		r.With(limiter.Limit(RouteGroupAudit, options.rateLimits.Audit)).Get("/audit", auditHandler.ListAuditEntries)
+
+		r.Group(func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupTrash, options.rateLimits.Trash))
+			r.Get("/trash", trashHandler.ListTrash)
+			r.Post("/restore", trashHandler.Restore)
 		})
 
-		r.Get("/summary", summaryHandler.GetSummary)
+		r.Group(func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupPrivacy, options.rateLimits.Privacy))
+			r.Post("/exports", privacyHandler.CreateExport)
+			r.Get("/exports/{id}", privacyHandler.GetExport)
+			r.Get("/exports/{id}/download", privacyHandler.DownloadExport)
+			r.Post("/erasure", privacyHandler.RequestErasure)
+			r.Post("/erasure/confirm", privacyHandler.ConfirmErasure)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupAttachments, options.rateLimits.Attachments))
+			r.Post("/transactions/{id}/attachments", attachmentHandler.UploadAttachment)
+			r.Get("/transactions/{id}/attachments", attachmentHandler.ListAttachments)
+			r.Get("/attachments/{id}/download", attachmentHandler.DownloadAttachment)
+			r.Delete("/attachments/{id}", attachmentHandler.DeleteAttachment)
+		})
+
+		r.Route("/goals", func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupGoals, options.rateLimits.Goals))
+			r.Post("/", goalHandler.CreateGoal)
+			r.Get("/", goalHandler.ListGoals)
+			r.Get("/{id}", goalHandler.GetGoal)
+			r.Delete("/{id}", goalHandler.DeleteGoal)
+			r.Post("/{id}/contributions", goalHandler.CreateContribution)
+			r.Get("/{id}/contributions", goalHandler.ListContributions)
+			r.Delete("/{id}/contributions/{contribution_id}", goalHandler.DeleteContribution)
+			r.Get("/{id}/progress", goalHandler.GetProgress)
+		})
+
+		r.Route("/accounts", func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupAccounts, options.rateLimits.Accounts))
+			r.Post("/", accountHandler.CreateAccount)
+			r.Get("/", accountHandler.ListAccounts)
+			r.Get("/{id}", accountHandler.GetAccount)
+			r.Delete("/{id}", accountHandler.DeleteAccount)
+			r.Post("/{id}/valuations", accountHandler.CreateValuation)
+			r.Get("/{id}/valuations", accountHandler.ListValuations)
+			r.Delete("/{id}/valuations/{valuation_id}", accountHandler.DeleteValuation)
+		})
+
+		r.Route("/debts", func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupDebts, options.rateLimits.Debts))
+			r.Post("/", debtHandler.CreateDebt)
+			r.Get("/", debtHandler.ListDebts)
+			r.Get("/{id}", debtHandler.GetDebt)
+			r.Delete("/{id}", debtHandler.DeleteDebt)
+			r.Post("/{id}/payments", debtHandler.CreatePayment)
+			r.Get("/{id}/payments", debtHandler.ListPayments)
+			r.Delete("/{id}/payments/{payment_id}", debtHandler.DeletePayment)
+			r.Get("/{id}/schedule", debtHandler.GetSchedule)
+			r.Get("/{id}/report", debtHandler.GetReport)
+		})
+
+		r.Route("/securities", func(r chi.Router) {
+			r.Use(limiter.Limit(RouteGroupSecurities, options.rateLimits.Securities))
+			r.Post("/", securityHandler.CreateSecurity)
+			r.Get("/", securityHandler.ListSecurities)
+			r.Post("/prices", securityHandler.ImportPrices)
+			r.Get("/{id}", securityHandler.GetSecurity)
+			r.Delete("/{id}", securityHandler.DeleteSecurity)
+			r.Post("/{id}/lots", securityHandler.CreateLot)
+			r.Get("/{id}/lots", securityHandler.ListLots)
+			r.Delete("/{id}/lots/{lot_id}", securityHandler.DeleteLot)
+			r.Get("/{id}/prices", securityHandler.ListPrices)
+		})
 	})
 
 	return r
@@ -7,8 +7,10 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"fintrack-go/internal/db"
+	"fintrack-go/internal/metrics"
 )
 
 func TestSetupRoutes(t *testing.T) {
@@ -115,3 +117,19 @@ func TestSetupRoutes(t *testing.T) {
 		assert.NotEqual(t, http.StatusNotFound, w.Code)
 	})
 }
+
+func TestSetupRoutes_WithMetrics(t *testing.T) {
+	mockDB := new(MockDBForHandler)
+	mockDB.On("Ping", mock.Anything).Return(nil)
+
+	router := SetupRoutes(zerolog.Nop(), mockDB, WithMetrics(metrics.New()))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `fintrack_http_requests_total{method="GET",route="/health",status="200"} 1`)
+}
@@ -0,0 +1,438 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/portfolio"
+	"fintrack-go/internal/validator"
+)
+
+// maxPriceFileSize is the most bytes a price file may have.
+const maxPriceFileSize = 5 << 20
+
+// maxPriceFileErrors is how many of a price file's invalid lines are
+// reported before giving up on the rest.
+const maxPriceFileErrors = 10
+
+type SecurityHandler struct {
+	*Handler
+	db db.Database
+}
+
+func NewSecurityHandler(logger zerolog.Logger, database db.Database) *SecurityHandler {
+	return &SecurityHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+	}
+}
+
+// CreateSecurityRequest describes a security held at a brokerage. Its
+// symbol is stored in upper case, and price files refer to it by it in any
+// case.
+type CreateSecurityRequest struct {
+	UserID string `json:"user_id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+// CreateLotRequest describes a buy or sell of quantity units at price each.
+type CreateLotRequest struct {
+	UserID   string     `json:"user_id"`
+	Side     string     `json:"side"`
+	Quantity float64    `json:"quantity"`
+	Price    float64    `json:"price"`
+	Fees     *float64   `json:"fees,omitempty"`
+	TradedAt *time.Time `json:"traded_at,omitempty"`
+}
+
+// ImportPricesResponse reports how many prices a price file held.
+type ImportPricesResponse struct {
+	Imported int `json:"imported"`
+}
+
+func (h *SecurityHandler) CreateSecurity(w http.ResponseWriter, r *http.Request) {
+	var req CreateSecurityRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("symbol", req.Symbol, validator.ValidateSymbol(req.Symbol))
+	v.Check("name", req.Name, validator.ValidateSecurityName(req.Name))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	security, err := h.db.CreateSecurity(r.Context(), req.UserID, models.Security{
+		Symbol: strings.ToUpper(req.Symbol),
+		Name:   req.Name,
+	})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create security")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, security)
+}
+
+func (h *SecurityHandler) ListSecurities(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	securities, err := h.db.ListSecurities(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list securities")
+		return
+	}
+
+	if securities == nil {
+		securities = []models.Security{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, securities)
+}
+
+func (h *SecurityHandler) GetSecurity(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	security, err := h.db.GetSecurity(r.Context(), userID, id)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to get security")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, security)
+}
+
+func (h *SecurityHandler) DeleteSecurity(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteSecurity(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete security")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateLot records a buy or sell of a security, traded now unless
+// traded_at is given. A sell can't take the units held below zero at any
+// time.
+func (h *SecurityHandler) CreateLot(w http.ResponseWriter, r *http.Request) {
+	securityID := chi.URLParam(r, "id")
+	var req CreateLotRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("id", securityID, validator.ValidateUUID(securityID))
+	v.Check("side", req.Side, validator.ValidateOneOf("side", req.Side, models.LotSides))
+	v.Check("quantity", req.Quantity, validator.ValidateQuantity(req.Quantity))
+	v.Check("price", req.Price, validator.ValidatePrice(req.Price))
+	if req.Fees != nil {
+		v.Check("fees", *req.Fees, validator.ValidateFees(*req.Fees))
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	lot := models.Lot{Side: req.Side, Quantity: req.Quantity, Price: req.Price, TradedAt: time.Now()}
+	if req.Fees != nil {
+		lot.Fees = *req.Fees
+	}
+	if req.TradedAt != nil {
+		lot.TradedAt = *req.TradedAt
+	}
+
+	created, err := h.db.CreateLot(r.Context(), req.UserID, securityID, lot)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create lot")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *SecurityHandler) ListLots(w http.ResponseWriter, r *http.Request) {
+	userID, securityID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	lots, err := h.db.ListLots(r.Context(), userID, models.LotFilter{SecurityID: &securityID})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list lots")
+		return
+	}
+
+	if lots == nil {
+		lots = []models.Lot{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, lots)
+}
+
+func (h *SecurityHandler) DeleteLot(w http.ResponseWriter, r *http.Request) {
+	lotID := chi.URLParam(r, "lot_id")
+	var v validator.Validator
+	v.Check("lot_id", lotID, validator.ValidateUUID(lotID))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+	userID, securityID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteLot(r.Context(), userID, securityID, lotID); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete lot")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPrices returns a security's prices from from to to, earliest first.
+func (h *SecurityHandler) ListPrices(w http.ResponseWriter, r *http.Request) {
+	userID, securityID, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var v validator.Validator
+	from := timeParam(&v, query, "from")
+	to := timeParam(&v, query, "to")
+	v.Check("from", nil, validator.ValidateDateRange(from, to))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	prices, err := h.db.ListPrices(r.Context(), userID, models.PriceFilter{SecurityID: &securityID, From: from, To: to})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list prices")
+		return
+	}
+
+	if prices == nil {
+		prices = []models.Price{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, prices)
+}
+
+// ImportPrices saves the prices in the CSV file in the multipart form field
+// "file" for the user's securities. The file has a header row naming its
+// symbol, date and price columns, in any order, and one price per row. A
+// date is either a day, taken as midnight UTC, or an RFC 3339 time. Either
+// every price is saved or, if any row is invalid, none is.
+func (h *SecurityHandler) ImportPrices(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	part, ok := h.filePart(w, r)
+	if !ok {
+		return
+	}
+	defer part.Close()
+
+	data, err := io.ReadAll(io.LimitReader(part, maxPriceFileSize+1))
+	if err != nil {
+		h.respondWithBodyError(w, err)
+		return
+	}
+	if len(data) > maxPriceFileSize {
+		h.respondWithError(w, http.StatusRequestEntityTooLarge, "Price files cannot exceed "+strconv.Itoa(maxPriceFileSize)+" bytes", nil)
+		return
+	}
+
+	securities, err := h.db.ListSecurities(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list securities")
+		return
+	}
+	bySymbol := make(map[string]string, len(securities))
+	for _, s := range securities {
+		bySymbol[s.Symbol] = s.ID
+	}
+
+	prices, err := parsePriceFile(data, bySymbol)
+	if err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	if err := h.db.SavePrices(r.Context(), userID, prices); err != nil {
+		h.respondWithDBError(w, err, "Failed to save prices")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, ImportPricesResponse{Imported: len(prices)})
+}
+
+// parsePriceFile parses a price file for the securities in bySymbol, keyed
+// by their symbols in upper case. It returns validator.Errors naming the
+// lines that are invalid.
+func parsePriceFile(data []byte, bySymbol map[string]string) ([]models.Price, error) {
+	var v validator.Validator
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		v.Add(fileField, validator.RuleRequired, "file cannot be empty", nil)
+		return nil, v.Err()
+	}
+	if err != nil {
+		v.Add(fileField, validator.RuleFormat, "file is not valid CSV: "+err.Error(), nil)
+		return nil, v.Err()
+	}
+	columns := map[string]int{"symbol": -1, "date": -1, "price": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range []string{"symbol", "date", "price"} {
+		if columns[name] < 0 {
+			v.Add(fileField, validator.RuleRequired, "line 1: the header has no '"+name+"' column", nil)
+		}
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	var prices []models.Price
+	invalid := 0
+	for line := 2; invalid < maxPriceFileErrors; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			v.Add(fileField, validator.RuleFormat, "file is not valid CSV: "+err.Error(), nil)
+			break
+		}
+		reject := func(format string, args ...any) {
+			v.Add(fileField, validator.RuleFormat, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...), nil)
+			invalid++
+		}
+
+		symbol := strings.ToUpper(strings.TrimSpace(record[columns["symbol"]]))
+		securityID, ok := bySymbol[symbol]
+		if !ok {
+			reject("unknown symbol '%s'", symbol)
+			continue
+		}
+		pricedAt, ok := parsePriceDate(strings.TrimSpace(record[columns["date"]]))
+		if !ok {
+			reject("invalid date '%s'. Use YYYY-MM-DD or RFC3339", record[columns["date"]])
+			continue
+		}
+		price, ok := parseNumber(strings.TrimSpace(record[columns["price"]]))
+		if !ok {
+			reject("invalid price '%s'. Must be a number", record[columns["price"]])
+			continue
+		}
+		if err := validator.ValidatePrice(price); err != nil {
+			reject("%s", err.Error())
+			continue
+		}
+
+		prices = append(prices, models.Price{SecurityID: securityID, Price: price, PricedAt: pricedAt})
+	}
+
+	return prices, v.Err()
+}
+
+// parsePriceDate parses a price file's date, either a day or an RFC 3339
+// time.
+func parsePriceDate(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
+}
+
+// GetPortfolio values the user's securities as of as_of, or now if it is
+// not given, with the cost basis decided by method, fifo by default.
+func (h *SecurityHandler) GetPortfolio(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	asOf := timeParam(&v, query, "as_of")
+	method := models.CostFIFO
+	if query.Has("method") {
+		method = query.Get("method")
+		v.Check("method", method, validator.ValidateOneOf("method", method, models.CostMethods))
+	}
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	if asOf == nil {
+		now := time.Now().UTC()
+		asOf = &now
+	}
+
+	securities, err := h.db.ListSecurities(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list securities")
+		return
+	}
+	lots, err := h.db.ListLots(r.Context(), userID, models.LotFilter{To: asOf})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list lots")
+		return
+	}
+	prices, err := h.db.ListPrices(r.Context(), userID, models.PriceFilter{To: asOf})
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list prices")
+		return
+	}
+
+	report := portfolio.Report(securities, lots, prices, *asOf, method)
+	report.UserID = userID
+	h.respondWithJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,269 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func newSecurityRouter(t *testing.T) (*db.MemoryDB, http.Handler, *models.User) {
+	t.Helper()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(context.Background(), "broker@example.com")
+	require.NoError(t, err)
+	return database, ContentType(SetupRoutes(zerolog.Nop(), database)), user
+}
+
+func TestSecurityHandler(t *testing.T) {
+	database, router, user := newSecurityRouter(t)
+	ctx := context.Background()
+	query := "?user_id=" + user.ID
+	day := func(d int) *time.Time {
+		t := time.Date(2026, time.January, d, 15, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	w := serve(router, http.MethodPost, "/api/v1/securities", CreateSecurityRequest{UserID: user.ID, Symbol: "aapl", Name: "Apple Inc."})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var security models.Security
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&security))
+	assert.Equal(t, "AAPL", security.Symbol, "stored in upper case")
+
+	w = serve(router, http.MethodPost, "/api/v1/securities", CreateSecurityRequest{UserID: user.ID, Symbol: "AAPL", Name: "Apple again"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "symbol_taken")
+
+	w = serve(router, http.MethodGet, "/api/v1/securities"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var securities []models.Security
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&securities))
+	require.Len(t, securities, 1)
+	assert.Equal(t, security.ID, securities[0].ID)
+
+	securityURL := "/api/v1/securities/" + security.ID
+	w = serve(router, http.MethodGet, securityURL+query, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	fees := 5.0
+	for _, lot := range []CreateLotRequest{
+		{UserID: user.ID, Side: models.LotBuy, Quantity: 10, Price: 100, Fees: &fees, TradedAt: day(5)},
+		{UserID: user.ID, Side: models.LotBuy, Quantity: 10, Price: 120, TradedAt: day(6)},
+		{UserID: user.ID, Side: models.LotSell, Quantity: 15, Price: 150, Fees: &fees, TradedAt: day(7)},
+	} {
+		w = serve(router, http.MethodPost, securityURL+"/lots", lot)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w = serve(router, http.MethodPost, securityURL+"/lots", CreateLotRequest{UserID: user.ID, Side: models.LotSell, Quantity: 6, Price: 150, TradedAt: day(8)})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "insufficient_quantity")
+
+	w = serve(router, http.MethodGet, securityURL+"/lots"+query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var lots []models.Lot
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&lots))
+	require.Len(t, lots, 3)
+	assert.Equal(t, 100.0, lots[0].Price, "earliest first")
+	assert.Equal(t, 0.0, lots[1].Fees, "no fees by default")
+
+	w = serve(router, http.MethodDelete, securityURL+"/lots/"+lots[0].ID+query, nil)
+	assert.Equal(t, http.StatusConflict, w.Code, "the sell would exceed the units held")
+	assert.Contains(t, w.Body.String(), "insufficient_quantity")
+
+	w = upload(t, router, "/api/v1/securities/prices"+query, "prices.csv",
+		"\ufeffDate,Symbol,Price\n2026-01-07,aapl,140\n2026-01-08T16:00:00Z,AAPL,130\n")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var imported ImportPricesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&imported))
+	assert.Equal(t, 2, imported.Imported)
+
+	w = serve(router, http.MethodGet, securityURL+"/prices"+query+"&to=2026-01-07T23:59:59Z", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var prices []models.Price
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&prices))
+	require.Len(t, prices, 1)
+	assert.Equal(t, 140.0, prices[0].Price)
+
+	tests := []struct {
+		method         string
+		query          string
+		costBasis      float64
+		realisedGain   float64
+		price          float64
+		marketValue    float64
+		unrealisedGain float64
+	}{
+		{method: models.CostFIFO, costBasis: 600, realisedGain: 640, price: 130, marketValue: 650, unrealisedGain: 50},
+		{method: models.CostAverage, costBasis: 551.25, realisedGain: 591.25, price: 130, marketValue: 650, unrealisedGain: 98.75},
+		{method: models.CostFIFO, query: "&as_of=2026-01-07T23:00:00Z", costBasis: 600, realisedGain: 640, price: 140, marketValue: 700, unrealisedGain: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+tt.query, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/api/v1/reports/portfolio"+query+"&method="+tt.method+tt.query, nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var report models.Portfolio
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+			assert.Equal(t, user.ID, report.UserID)
+			assert.Equal(t, tt.method, report.Method)
+			require.Len(t, report.Holdings, 1)
+			holding := report.Holdings[0]
+			assert.Equal(t, "AAPL", holding.Symbol)
+			assert.Equal(t, 5.0, holding.Quantity)
+			require.NotNil(t, holding.Price)
+			assert.Equal(t, tt.price, *holding.Price)
+			assert.Equal(t, tt.costBasis, report.CostBasis)
+			assert.Equal(t, tt.realisedGain, report.RealisedGain)
+			assert.Equal(t, tt.marketValue, report.MarketValue)
+			assert.Equal(t, tt.unrealisedGain, report.UnrealisedGain)
+			assert.Zero(t, report.Unpriced)
+		})
+	}
+
+	t.Run("another user's security", func(t *testing.T) {
+		other, err := database.CreateUser(ctx, "other@example.com")
+		require.NoError(t, err)
+		for _, target := range []string{securityURL, securityURL + "/lots", securityURL + "/prices"} {
+			w := serve(router, http.MethodGet, target+"?user_id="+other.ID, nil)
+			assert.Equal(t, http.StatusNotFound, w.Code, target)
+			assert.Contains(t, w.Body.String(), "security_not_found")
+		}
+
+		w := upload(t, router, "/api/v1/securities/prices?user_id="+other.ID, "prices.csv", "symbol,date,price\nAAPL,2026-01-09,1\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unknown symbol 'AAPL'")
+	})
+
+	w = serve(router, http.MethodDelete, securityURL+"/lots/"+lots[2].ID+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodDelete, securityURL+"/lots/"+lots[2].ID+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "lot_not_found")
+
+	w = serve(router, http.MethodDelete, securityURL+query, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodGet, securityURL+query, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSecurityHandler_ImportPricesRejected(t *testing.T) {
+	database, router, user := newSecurityRouter(t)
+	_, err := database.CreateSecurity(context.Background(), user.ID, models.Security{Symbol: "VWRL", Name: "Vanguard FTSE All-World"})
+	require.NoError(t, err)
+	target := "/api/v1/securities/prices?user_id=" + user.ID
+
+	tests := []struct {
+		name    string
+		content string
+		status  int
+		errors  []string
+	}{
+		{name: "empty", content: "", status: http.StatusBadRequest, errors: []string{"file cannot be empty"}},
+		{name: "no header", content: "VWRL,2026-01-02,101.5\n", status: http.StatusBadRequest, errors: []string{"no 'symbol' column"}},
+		{
+			name:    "invalid lines",
+			content: "symbol,date,price\nVWRL,2026-01-02,101.5\nMSFT,2026-01-02,1\nVWRL,02/01/2026,1\nVWRL,2026-01-03,abc\nVWRL,2026-01-04,-1\nVWRL,2026-01-05,NaN\n",
+			status:  http.StatusBadRequest,
+			errors: []string{
+				"line 3: unknown symbol 'MSFT'",
+				"line 4: invalid date '02/01/2026'",
+				"line 5: invalid price 'abc'",
+				"line 6: ",
+				"line 7: invalid price 'NaN'",
+			},
+		},
+		{name: "ragged", content: "symbol,date,price\nVWRL,2026-01-02\n", status: http.StatusBadRequest, errors: []string{"not valid CSV"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := upload(t, router, target, "prices.csv", tt.content)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			for _, msg := range tt.errors {
+				assert.Contains(t, w.Body.String(), msg)
+			}
+		})
+	}
+
+	prices, err := database.ListPrices(context.Background(), user.ID, models.PriceFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, prices, "a file with an invalid line saves nothing")
+
+	t.Run("too many errors", func(t *testing.T) {
+		content := "symbol,date,price\n" + strings.Repeat("NOPE,2026-01-02,1\n", 20)
+		w := upload(t, router, target, "prices.csv", content)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var resp ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Len(t, resp.Error.Details, maxPriceFileErrors, "later lines are not reported")
+	})
+
+	t.Run("no file", func(t *testing.T) {
+		body, contentType := multipartBody(t, "", "", "")
+		req := httptest.NewRequest(http.MethodPost, target, body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "file is required")
+	})
+}
+
+func TestSecurityHandler_Rejected(t *testing.T) {
+	database, router, user := newSecurityRouter(t)
+	unknownID := "660e8400-e29b-41d4-a716-446655440000"
+	security, err := database.CreateSecurity(context.Background(), user.ID, models.Security{Symbol: "VWRL", Name: "Vanguard FTSE All-World"})
+	require.NoError(t, err)
+	securityURL := "/api/v1/securities/" + security.ID
+	query := "?user_id=" + user.ID
+
+	lot := func(change func(*CreateLotRequest)) CreateLotRequest {
+		req := CreateLotRequest{UserID: user.ID, Side: models.LotBuy, Quantity: 1, Price: 100}
+		change(&req)
+		return req
+	}
+	negative := -1.0
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   any
+		status int
+		code   string
+	}{
+		{name: "no symbol", method: http.MethodPost, target: "/api/v1/securities", body: CreateSecurityRequest{UserID: user.ID, Name: "Nameless"}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "invalid symbol", method: http.MethodPost, target: "/api/v1/securities", body: CreateSecurityRequest{UserID: user.ID, Symbol: "A B", Name: "Spaced"}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "no name", method: http.MethodPost, target: "/api/v1/securities", body: CreateSecurityRequest{UserID: user.ID, Symbol: "MSFT"}, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "unknown user", method: http.MethodPost, target: "/api/v1/securities", body: CreateSecurityRequest{UserID: unknownID, Symbol: "MSFT", Name: "Microsoft"}, status: http.StatusNotFound, code: "user_not_found"},
+		{name: "unknown side", method: http.MethodPost, target: securityURL + "/lots", body: lot(func(r *CreateLotRequest) { r.Side = "short" }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "no quantity", method: http.MethodPost, target: securityURL + "/lots", body: lot(func(r *CreateLotRequest) { r.Quantity = 0 }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "negative price", method: http.MethodPost, target: securityURL + "/lots", body: lot(func(r *CreateLotRequest) { r.Price = -1 }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "negative fees", method: http.MethodPost, target: securityURL + "/lots", body: lot(func(r *CreateLotRequest) { r.Fees = &negative }), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "sell without units", method: http.MethodPost, target: securityURL + "/lots", body: lot(func(r *CreateLotRequest) { r.Side = models.LotSell }), status: http.StatusConflict, code: "insufficient_quantity"},
+		{name: "unknown security", method: http.MethodPost, target: "/api/v1/securities/" + unknownID + "/lots", body: lot(func(r *CreateLotRequest) {}), status: http.StatusNotFound, code: "security_not_found"},
+		{name: "invalid security id", method: http.MethodGet, target: "/api/v1/securities/invalid/lots" + query, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "invalid lot id", method: http.MethodDelete, target: securityURL + "/lots/invalid" + query, status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "invalid price range", method: http.MethodGet, target: securityURL + "/prices" + query + "&from=yesterday", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "unknown method", method: http.MethodGet, target: "/api/v1/reports/portfolio" + query + "&method=lifo", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "invalid as_of", method: http.MethodGet, target: "/api/v1/reports/portfolio" + query + "&as_of=soon", status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "missing user_id", method: http.MethodGet, target: "/api/v1/securities", status: http.StatusBadRequest, code: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.code)
+		})
+	}
+}
@@ -1,12 +1,12 @@
 package http
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/rs/zerolog"
 	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
 	"fintrack-go/internal/validator"
 )
 
@@ -32,42 +32,19 @@ type CreateTransactionRequest struct {
 
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 	var req CreateTransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Invalid request body", nil)
-		return
-	}
-
-	if err := validator.ValidateUUID(req.UserID); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-			"field": "user_id",
-			"value": req.UserID,
-		})
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
 	if req.CategoryID != nil {
-		if err := validator.ValidateUUID(*req.CategoryID); err != nil {
-			h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-				"field": "category_id",
-				"value": *req.CategoryID,
-			})
-			return
-		}
-	}
-
-	if err := validator.ValidateAmount(req.Amount); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]any{
-			"field": "amount",
-			"value": req.Amount,
-		})
-		return
+		v.Check("category_id", *req.CategoryID, validator.ValidateUUID(*req.CategoryID))
 	}
-
-	if err := validator.ValidateDescription(req.Description); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-			"field": "description",
-			"value": "",
-		})
+	v.Check("amount", req.Amount, validator.ValidateAmount(req.Amount))
+	v.Check("description", nil, validator.ValidateDescription(req.Description))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
 		return
 	}
 
@@ -78,8 +55,7 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 
 	transaction, err := h.db.CreateTransaction(r.Context(), req.UserID, req.CategoryID, req.Amount, req.Description, occurredAt)
 	if err != nil {
-		h.Logger.Error().Err(err).Msg("Failed to create transaction")
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to create transaction", nil)
+		h.respondWithDBError(w, err, "Failed to create transaction")
 		return
 	}
 
@@ -87,50 +63,67 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 }
 
 func (h *TransactionHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		h.respondWithError(w, http.StatusBadRequest, "user_id query parameter is required", nil)
-		return
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+
+	filter := models.TransactionFilter{
+		From:           timeParam(&v, query, "from"),
+		To:             timeParam(&v, query, "to"),
+		MinAmount:      floatParam(&v, query, "min_amount"),
+		MaxAmount:      floatParam(&v, query, "max_amount"),
+		HasDescription: boolParam(&v, query, "has_description"),
 	}
+	v.Check("from", nil, validator.ValidateDateRange(filter.From, filter.To))
+	v.Check("min_amount", nil, validator.ValidateAmountRange(filter.MinAmount, filter.MaxAmount))
 
-	if err := validator.ValidateUUID(userID); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-			"field": "user_id",
-			"value": userID,
-		})
-		return
+	for _, categoryID := range query["category_id"] {
+		if err := validator.ValidateUUID(categoryID); err != nil {
+			v.Check("category_id", categoryID, err)
+			continue
+		}
+		filter.CategoryIDs = append(filter.CategoryIDs, categoryID)
 	}
 
-	var from, to *time.Time
-	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
-		t, err := time.Parse(time.RFC3339, fromStr)
-		if err != nil {
-			h.respondWithError(w, http.StatusBadRequest, "Invalid 'from' date format. Use RFC3339", nil)
-			return
-		}
-		from = &t
+	if uncategorized := boolParam(&v, query, "uncategorized"); uncategorized != nil {
+		filter.Uncategorized = *uncategorized
 	}
 
-	if toStr := r.URL.Query().Get("to"); toStr != "" {
-		t, err := time.Parse(time.RFC3339, toStr)
-		if err != nil {
-			h.respondWithError(w, http.StatusBadRequest, "Invalid 'to' date format. Use RFC3339", nil)
-			return
-		}
-		to = &t
+	if filter.Uncategorized && len(filter.CategoryIDs) > 0 {
+		v.Add("uncategorized", validator.RuleExclusive, "'uncategorized' cannot be combined with 'category_id'", nil)
 	}
 
-	if err := validator.ValidateDateRange(from, to); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), nil)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
 		return
 	}
 
-	transactions, err := h.db.ListTransactions(r.Context(), userID, from, to)
+	transactions, err := h.db.ListTransactions(r.Context(), userID, filter)
 	if err != nil {
-		h.Logger.Error().Err(err).Msg("Failed to list transactions")
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to list transactions", nil)
+		h.respondWithDBError(w, err, "Failed to list transactions")
 		return
 	}
 
+	if transactions == nil {
+		transactions = []models.Transaction{}
+	}
+
 	h.respondWithJSON(w, http.StatusOK, transactions)
 }
+
+// DeleteTransaction moves a transaction to the trash.
+func (h *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteTransaction(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
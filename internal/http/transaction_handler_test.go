@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"fintrack-go/internal/db"
 	"fintrack-go/internal/models"
 )
 
@@ -234,6 +235,118 @@ func TestTransactionHandler_CreateTransaction(t *testing.T) {
 		assert.Contains(t, errObj["message"], "Failed to create transaction")
 		mockDB.AssertExpectations(t)
 	})
+
+	t.Run("category of another user", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		mockDB.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, db.ErrCategoryNotOwned)
+
+		reqBody := map[string]interface{}{
+			"user_id":     "550e8400-e29b-41d4-a716-446655440000",
+			"category_id": "660e8400-e29b-41d4-a716-446655440001",
+			"amount":      10.0,
+		}
+		body, _ := json.Marshal(reqBody)
+
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.CreateTransaction(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp map[string]interface{}
+		err := json.NewDecoder(w.Body).Decode(&resp)
+		require.NoError(t, err)
+
+		errObj := resp["error"].(map[string]interface{})
+		assert.Equal(t, "category_not_owned", errObj["code"])
+		assert.Contains(t, errObj["message"], "does not belong to user")
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("reports every invalid field", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		reqBody := map[string]interface{}{
+			"user_id":     "invalid-uuid",
+			"category_id": "also-invalid",
+			"amount":      -5.0,
+		}
+		body, _ := json.Marshal(reqBody)
+
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.CreateTransaction(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "validation_failed", resp.Error.Code)
+
+		details := resp.Error.Details.([]interface{})
+		require.Len(t, details, 3)
+		var fields []string
+		for _, d := range details {
+			fieldErr := d.(map[string]interface{})
+			fields = append(fields, fieldErr["field"].(string))
+			assert.NotEmpty(t, fieldErr["rule"])
+			assert.NotEmpty(t, fieldErr["message"])
+		}
+		assert.Equal(t, []string{"user_id", "category_id", "amount"}, fields)
+		assert.Equal(t, "invalid-uuid", details[0].(map[string]interface{})["value"])
+		mockDB.AssertNotCalled(t, "CreateTransaction")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		body := `{"user_id": "550e8400-e29b-41d4-a716-446655440000", "amount": 10, "ammount": 10}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.CreateTransaction(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "validation_failed", resp.Error.Code)
+		details := resp.Error.Details.([]interface{})
+		require.Len(t, details, 1)
+		assert.Equal(t, "ammount", details[0].(map[string]interface{})["field"])
+		assert.Equal(t, "unknown_field", details[0].(map[string]interface{})["rule"])
+		mockDB.AssertNotCalled(t, "CreateTransaction")
+	})
+
+	t.Run("wrong field type", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		body := `{"user_id": "550e8400-e29b-41d4-a716-446655440000", "amount": "ten"}`
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.CreateTransaction(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		details := resp.Error.Details.([]interface{})
+		require.Len(t, details, 1)
+		assert.Equal(t, "amount", details[0].(map[string]interface{})["field"])
+		assert.Equal(t, "type", details[0].(map[string]interface{})["rule"])
+	})
 }
 
 func TestTransactionHandler_ListTransactions(t *testing.T) {
@@ -248,7 +361,7 @@ func TestTransactionHandler_ListTransactions(t *testing.T) {
 			{ID: "770e8400-e29b-41d4-a716-446655440002", UserID: userID, Amount: 10.0},
 			{ID: "770e8400-e29b-41d4-a716-446655440003", UserID: userID, Amount: 20.0},
 		}
-		mockDB.On("ListTransactions", mock.Anything, userID, (*time.Time)(nil), (*time.Time)(nil)).Return(expectedTxns, nil)
+		mockDB.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{}).Return(expectedTxns, nil)
 
 		q := url.Values{}
 		q.Set("user_id", userID)
@@ -277,7 +390,7 @@ func TestTransactionHandler_ListTransactions(t *testing.T) {
 		// Truncate to seconds to match RFC3339 precision used in query params
 		startDate := time.Now().Add(-48 * time.Hour).Truncate(time.Second).UTC()
 		endDate := time.Now().Add(-24 * time.Hour).Truncate(time.Second).UTC()
-		mockDB.On("ListTransactions", mock.Anything, userID, &startDate, &endDate).Return(expectedTxns, nil)
+		mockDB.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{From: &startDate, To: &endDate}).Return(expectedTxns, nil)
 
 		q := url.Values{}
 		q.Set("user_id", userID)
@@ -362,8 +475,183 @@ func TestTransactionHandler_ListTransactions(t *testing.T) {
 		errObj := resp["error"].(map[string]interface{})
 		assert.Contains(t, errObj["message"], "'from' date must be before or equal to 'to' date")
 	})
+
+	t.Run("with amount, category and description filters", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		userID := "550e8400-e29b-41d4-a716-446655440000"
+		categoryA := "660e8400-e29b-41d4-a716-446655440001"
+		categoryB := "660e8400-e29b-41d4-a716-446655440002"
+		minAmount := 10.0
+		maxAmount := 50.5
+		hasDescription := true
+		expectedFilter := models.TransactionFilter{
+			MinAmount:      &minAmount,
+			MaxAmount:      &maxAmount,
+			CategoryIDs:    []string{categoryA, categoryB},
+			HasDescription: &hasDescription,
+		}
+		mockDB.On("ListTransactions", mock.Anything, userID, expectedFilter).Return([]models.Transaction{}, nil)
+
+		q := url.Values{}
+		q.Set("user_id", userID)
+		q.Set("min_amount", "10")
+		q.Set("max_amount", "50.5")
+		q.Add("category_id", categoryA)
+		q.Add("category_id", categoryB)
+		q.Set("has_description", "true")
+		req := httptest.NewRequest(http.MethodGet, "/transactions?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListTransactions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("uncategorized only", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		userID := "550e8400-e29b-41d4-a716-446655440000"
+		mockDB.On("ListTransactions", mock.Anything, userID, models.TransactionFilter{Uncategorized: true}).Return([]models.Transaction{}, nil)
+
+		q := url.Values{}
+		q.Set("user_id", userID)
+		q.Set("uncategorized", "true")
+		req := httptest.NewRequest(http.MethodGet, "/transactions?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListTransactions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		userID := "550e8400-e29b-41d4-a716-446655440000"
+		tests := []struct {
+			name    string
+			params  map[string]string
+			message string
+		}{
+			{"non-numeric min_amount", map[string]string{"min_amount": "abc"}, "Invalid 'min_amount'"},
+			{"non-numeric max_amount", map[string]string{"max_amount": "abc"}, "Invalid 'max_amount'"},
+			{"NaN min_amount", map[string]string{"min_amount": "NaN"}, "Invalid 'min_amount'"},
+			{"infinite min_amount", map[string]string{"min_amount": "-Inf"}, "Invalid 'min_amount'"},
+			{"infinite max_amount", map[string]string{"max_amount": "Inf"}, "Invalid 'max_amount'"},
+			{"overflowing max_amount", map[string]string{"max_amount": "1e400"}, "Invalid 'max_amount'"},
+			{"min greater than max", map[string]string{"min_amount": "20", "max_amount": "10"}, "'min_amount' must be less than or equal to 'max_amount'"},
+			{"invalid category_id", map[string]string{"category_id": "not-a-uuid"}, "invalid UUID format"},
+			{"invalid uncategorized", map[string]string{"uncategorized": "maybe"}, "Invalid 'uncategorized'"},
+			{"uncategorized with category_id", map[string]string{"uncategorized": "true", "category_id": "660e8400-e29b-41d4-a716-446655440001"}, "cannot be combined"},
+			{"invalid has_description", map[string]string{"has_description": "maybe"}, "Invalid 'has_description'"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockDB := new(MockDBForHandler)
+				handler := NewTransactionHandler(logger, mockDB)
+
+				q := url.Values{}
+				q.Set("user_id", userID)
+				for k, v := range tt.params {
+					q.Set(k, v)
+				}
+				req := httptest.NewRequest(http.MethodGet, "/transactions?"+q.Encode(), nil)
+				w := httptest.NewRecorder()
+
+				handler.ListTransactions(w, req)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+
+				var resp map[string]interface{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				require.NoError(t, err)
+
+				errObj := resp["error"].(map[string]interface{})
+				assert.Contains(t, errObj["message"], tt.message)
+				mockDB.AssertNotCalled(t, "ListTransactions", mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("reports every invalid filter", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		q := url.Values{}
+		q.Set("from", "yesterday")
+		q.Set("min_amount", "abc")
+		q.Set("has_description", "maybe")
+		req := httptest.NewRequest(http.MethodGet, "/transactions?"+q.Encode(), nil)
+		w := httptest.NewRecorder()
+
+		handler.ListTransactions(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		details := resp.Error.Details.([]interface{})
+		var fields []string
+		for _, d := range details {
+			fields = append(fields, d.(map[string]interface{})["field"].(string))
+		}
+		assert.Equal(t, []string{"user_id", "from", "min_amount", "has_description"}, fields)
+		mockDB.AssertNotCalled(t, "ListTransactions", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestTransactionHandler_DeleteTransaction(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	id := "770e8400-e29b-41d4-a716-446655440002"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+		mockDB.On("DeleteTransaction", mock.Anything, userID, id).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/transactions/"+id+"?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteTransaction(w, withURLParam(req, "id", id))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+		mockDB.On("DeleteTransaction", mock.Anything, userID, id).Return(db.ErrTransactionNotFound)
+
+		req := httptest.NewRequest(http.MethodDelete, "/transactions/"+id+"?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteTransaction(w, withURLParam(req, "id", id))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "transaction_not_found")
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTransactionHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodDelete, "/transactions/bad?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteTransaction(w, withURLParam(req, "id", "bad"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDB.AssertNotCalled(t, "DeleteTransaction")
+	})
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+type TrashHandler struct {
+	*Handler
+	db db.Database
+}
+
+func NewTrashHandler(logger zerolog.Logger, database db.Database) *TrashHandler {
+	return &TrashHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+	}
+}
+
+type RestoreRequest struct {
+	UserID string `json:"user_id"`
+	Entity string `json:"entity"`
+	ID     string `json:"id"`
+}
+
+// ListTrash lists the user's deleted categories and transactions, most
+// recently deleted first.
+func (h *TrashHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	trash, err := h.db.ListTrash(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list trash")
+		return
+	}
+
+	if trash.Categories == nil {
+		trash.Categories = []models.Category{}
+	}
+	if trash.Transactions == nil {
+		trash.Transactions = []models.Transaction{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, trash)
+}
+
+// Restore takes a category or transaction out of the trash and responds with
+// it.
+func (h *TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	var req RestoreRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("entity", req.Entity, validator.ValidateOneOf("entity", req.Entity, models.TrashEntities))
+	v.Check("id", req.ID, validator.ValidateUUID(req.ID))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	var restored any
+	var err error
+	switch req.Entity {
+	case models.EntityCategory:
+		restored, err = h.db.RestoreCategory(r.Context(), req.UserID, req.ID)
+	case models.EntityTransaction:
+		restored, err = h.db.RestoreTransaction(r.Context(), req.UserID, req.ID)
+	}
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to restore "+req.Entity)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, restored)
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func TestTrashHandler_ListTrash(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTrashHandler(logger, mockDB)
+		deletedAt := time.Now().UTC().Truncate(time.Second)
+		mockDB.On("ListTrash", mock.Anything, userID).Return(&models.Trash{
+			Transactions: []models.Transaction{{
+				ID:        "770e8400-e29b-41d4-a716-446655440002",
+				UserID:    userID,
+				Amount:    12.5,
+				DeletedAt: &deletedAt,
+			}},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/trash?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.ListTrash(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertJSONContentType(t, w)
+		assert.Contains(t, w.Body.String(), `"categories":[]`, "empty lists are arrays")
+
+		var resp models.Trash
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Transactions, 1)
+		require.NotNil(t, resp.Transactions[0].DeletedAt)
+		assert.True(t, deletedAt.Equal(*resp.Transactions[0].DeletedAt))
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("missing user_id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTrashHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodGet, "/trash", nil)
+		w := httptest.NewRecorder()
+
+		handler.ListTrash(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDB.AssertNotCalled(t, "ListTrash")
+	})
+}
+
+func TestTrashHandler_Restore(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	id := "660e8400-e29b-41d4-a716-446655440001"
+
+	restore := func(handler *TrashHandler, body any) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/restore", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.Restore(w, req)
+		return w
+	}
+
+	t.Run("category", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTrashHandler(logger, mockDB)
+		mockDB.On("RestoreCategory", mock.Anything, userID, id).Return(&models.Category{ID: id, UserID: userID, Name: "Food"}, nil)
+
+		w := restore(handler, RestoreRequest{UserID: userID, Entity: models.EntityCategory, ID: id})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"Food"`)
+		assert.NotContains(t, w.Body.String(), "deleted_at")
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTrashHandler(logger, mockDB)
+		mockDB.On("RestoreTransaction", mock.Anything, userID, id).Return(&models.Transaction{ID: id, UserID: userID, Amount: 12.5}, nil)
+
+		w := restore(handler, RestoreRequest{UserID: userID, Entity: models.EntityTransaction, ID: id})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"amount":12.5`)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("not in the trash", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTrashHandler(logger, mockDB)
+		mockDB.On("RestoreTransaction", mock.Anything, userID, id).Return(nil, db.ErrTransactionNotFound)
+
+		w := restore(handler, RestoreRequest{UserID: userID, Entity: models.EntityTransaction, ID: id})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "transaction_not_found")
+	})
+
+	t.Run("reports every invalid field", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewTrashHandler(logger, mockDB)
+
+		w := restore(handler, RestoreRequest{UserID: "bad", Entity: models.EntityUser, ID: "bad"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var resp struct {
+			Error struct {
+				Details []struct {
+					Field string `json:"field"`
+					Rule  string `json:"rule"`
+				} `json:"details"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		var fields []string
+		for _, detail := range resp.Error.Details {
+			fields = append(fields, detail.Field+":"+detail.Rule)
+		}
+		assert.Equal(t, []string{"user_id:format", "entity:one_of", "id:format"}, fields)
+		mockDB.AssertNotCalled(t, "RestoreCategory")
+		mockDB.AssertNotCalled(t, "RestoreTransaction")
+	})
+}
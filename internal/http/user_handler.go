@@ -1,7 +1,6 @@
 package http
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/rs/zerolog"
@@ -27,27 +26,20 @@ type CreateUserRequest struct {
 
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Invalid request body", nil)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	if err := validator.ValidateEmail(req.Email); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, err.Error(), map[string]string{
-			"field": "email",
-			"value": req.Email,
-		})
+	var v validator.Validator
+	v.Check("email", req.Email, validator.ValidateEmail(req.Email))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
 		return
 	}
 
 	user, err := h.db.CreateUser(r.Context(), req.Email)
 	if err != nil {
-		if err == db.ErrDuplicateEmail {
-			h.respondWithError(w, http.StatusConflict, "Email already exists", nil)
-			return
-		}
-		h.Logger.Error().Err(err).Msg("Failed to create user")
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to create user", nil)
+		h.respondWithDBError(w, err, "Failed to create user")
 		return
 	}
 
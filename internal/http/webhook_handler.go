@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+type WebhookHandler struct {
+	*Handler
+	db db.Database
+}
+
+func NewWebhookHandler(logger zerolog.Logger, database db.Database) *WebhookHandler {
+	return &WebhookHandler{
+		Handler: NewHandler(logger),
+		db:      database,
+	}
+}
+
+type CreateWebhookRequest struct {
+	UserID     string   `json:"user_id"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var v validator.Validator
+	v.Check("user_id", req.UserID, validator.ValidateUUID(req.UserID))
+	v.Check("url", req.URL, validator.ValidateWebhookURL(req.URL))
+	v.Check("secret", nil, validator.ValidateWebhookSecret(req.Secret))
+	v.Check("event_types", req.EventTypes, validator.ValidateEventTypes(req.EventTypes, models.WebhookEventTypes))
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	subscription, err := h.db.CreateWebhookSubscription(r.Context(), req.UserID, req.URL, req.Secret, req.EventTypes)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to create webhook")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	var v validator.Validator
+	checkUserIDParam(&v, userID)
+	if err := v.Err(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	subscriptions, err := h.db.ListWebhookSubscriptions(r.Context(), userID)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list webhooks")
+		return
+	}
+
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteWebhookSubscription(r.Context(), userID, id); err != nil {
+		h.respondWithDBError(w, err, "Failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries lists a subscription's deliveries, newest first, with the
+// outcome of the latest attempt at each.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.resourceParams(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.db.ListWebhookDeliveries(r.Context(), userID, id)
+	if err != nil {
+		h.respondWithDBError(w, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	h.respondWithJSON(w, http.StatusOK, deliveries)
+}
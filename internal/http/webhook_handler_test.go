@@ -0,0 +1,242 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// withURLParam adds a chi path parameter to req, as routing would.
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	secret := "0123456789abcdef-secret"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+
+		expected := &models.WebhookSubscription{
+			ID:         "880e8400-e29b-41d4-a716-446655440001",
+			UserID:     userID,
+			URL:        "https://example.com/hooks",
+			Secret:     secret,
+			EventTypes: []string{models.EventTransactionCreated},
+			CreatedAt:  time.Now(),
+		}
+		mockDB.On("CreateWebhookSubscription", mock.Anything, userID, "https://example.com/hooks", secret,
+			[]string{models.EventTransactionCreated}).Return(expected, nil)
+
+		body, _ := json.Marshal(map[string]any{
+			"user_id":     userID,
+			"url":         "https://example.com/hooks",
+			"secret":      secret,
+			"event_types": []string{models.EventTransactionCreated},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		handler.CreateWebhook(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assertJSONContentType(t, w)
+		assert.NotContains(t, w.Body.String(), secret, "the secret is never returned")
+
+		var resp models.WebhookSubscription
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, expected.ID, resp.ID)
+		assert.Equal(t, expected.EventTypes, resp.EventTypes)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("reports every invalid field", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+
+		body, _ := json.Marshal(map[string]any{
+			"user_id":     userID,
+			"url":         "ftp://example.com",
+			"secret":      "short",
+			"event_types": []string{"budget.exceeded"},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		handler.CreateWebhook(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.NotContains(t, w.Body.String(), "short", "the secret is not echoed")
+
+		var resp struct {
+			Error struct {
+				Details []struct {
+					Field string `json:"field"`
+					Rule  string `json:"rule"`
+				} `json:"details"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		var fields []string
+		for _, detail := range resp.Error.Details {
+			fields = append(fields, detail.Field+":"+detail.Rule)
+		}
+		assert.Equal(t, []string{"url:format", "secret:min_length", "event_types:one_of"}, fields)
+		mockDB.AssertNotCalled(t, "CreateWebhookSubscription")
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+		mockDB.On("CreateWebhookSubscription", mock.Anything, userID, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, db.ErrUserNotFound)
+
+		body, _ := json.Marshal(map[string]any{
+			"user_id":     userID,
+			"url":         "https://example.com/hooks",
+			"secret":      secret,
+			"event_types": []string{models.EventCategoryCreated},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		handler.CreateWebhook(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestWebhookHandler_ListWebhooks(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("empty result is an empty array", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+		mockDB.On("ListWebhookSubscriptions", mock.Anything, userID).Return(nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/webhooks?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.ListWebhooks(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("missing user_id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+		w := httptest.NewRecorder()
+
+		handler.ListWebhooks(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	id := "880e8400-e29b-41d4-a716-446655440001"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+		mockDB.On("DeleteWebhookSubscription", mock.Anything, userID, id).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/webhooks/"+id+"?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteWebhook(w, withURLParam(req, "id", id))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+		mockDB.On("DeleteWebhookSubscription", mock.Anything, userID, id).Return(db.ErrWebhookNotFound)
+
+		req := httptest.NewRequest(http.MethodDelete, "/webhooks/"+id+"?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteWebhook(w, withURLParam(req, "id", id))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "webhook_not_found")
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		mockDB := new(MockDBForHandler)
+		handler := NewWebhookHandler(logger, mockDB)
+
+		req := httptest.NewRequest(http.MethodDelete, "/webhooks/bad?user_id="+userID, nil)
+		w := httptest.NewRecorder()
+
+		handler.DeleteWebhook(w, withURLParam(req, "id", "bad"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	id := "880e8400-e29b-41d4-a716-446655440001"
+
+	mockDB := new(MockDBForHandler)
+	handler := NewWebhookHandler(logger, mockDB)
+	status := http.StatusInternalServerError
+	lastError := "receiver responded with 500"
+	next := time.Now().Add(time.Minute)
+	mockDB.On("ListWebhookDeliveries", mock.Anything, userID, id).Return([]models.WebhookDelivery{{
+		ID:             "990e8400-e29b-41d4-a716-446655440001",
+		SubscriptionID: id,
+		EventID:        "990e8400-e29b-41d4-a716-446655440002",
+		EventType:      models.EventTransactionCreated,
+		Payload:        json.RawMessage(`{"type":"transaction.created"}`),
+		Status:         models.DeliveryPending,
+		Attempts:       1,
+		ResponseStatus: &status,
+		LastError:      &lastError,
+		NextAttemptAt:  &next,
+	}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/"+id+"/deliveries?user_id="+userID, nil)
+	w := httptest.NewRecorder()
+
+	handler.ListDeliveries(w, withURLParam(req, "id", id))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []models.WebhookDelivery
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp, 1)
+	assert.Equal(t, models.DeliveryPending, resp[0].Status)
+	assert.JSONEq(t, `{"type":"transaction.created"}`, string(resp[0].Payload))
+	require.NotNil(t, resp[0].LastError)
+	assert.Equal(t, lastError, *resp[0].LastError)
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// instrumentedDatabase counts domain events as they succeed and passes every
+// call through to the wrapped Database.
+type instrumentedDatabase struct {
+	db.Database
+	metrics *Metrics
+
+	// counted holds the increments to make once the transaction the
+	// Database belongs to commits. It is nil outside a transaction.
+	counted *[]increment
+}
+
+// increment adds n to counter.
+type increment struct {
+	counter prometheus.Counter
+	n       float64
+}
+
+// InstrumentDatabase wraps database so successful creates, merges and price
+// imports are counted, including those made in a transaction once it commits. Every
+// transport and worker should share the one wrapped Database.
+func (m *Metrics) InstrumentDatabase(database db.Database) db.Database {
+	return &instrumentedDatabase{Database: database, metrics: m}
+}
+
+// count increments c, or does so when the transaction commits.
+func (d *instrumentedDatabase) count(c prometheus.Counter) {
+	d.add(c, 1)
+}
+
+// add adds n to c, or does so when the transaction commits.
+func (d *instrumentedDatabase) add(c prometheus.Counter, n float64) {
+	if d.counted != nil {
+		*d.counted = append(*d.counted, increment{counter: c, n: n})
+		return
+	}
+	c.Add(n)
+}
+
+func (d *instrumentedDatabase) WithTx(ctx context.Context, fn func(tx db.Database) error) error {
+	if d.counted != nil {
+		// Nested transactions commit with the outer one.
+		return d.Database.WithTx(ctx, func(tx db.Database) error {
+			return fn(&instrumentedDatabase{Database: tx, metrics: d.metrics, counted: d.counted})
+		})
+	}
+
+	var counted []increment
+	err := d.Database.WithTx(ctx, func(tx db.Database) error {
+		// A retried transaction starts over, and so do its counts.
+		counted = counted[:0]
+		return fn(&instrumentedDatabase{Database: tx, metrics: d.metrics, counted: &counted})
+	})
+	if err == nil {
+		for _, i := range counted {
+			i.counter.Add(i.n)
+		}
+	}
+	return err
+}
+
+func (d *instrumentedDatabase) CreateUser(ctx context.Context, email string) (*models.User, error) {
+	user, err := d.Database.CreateUser(ctx, email)
+	if err == nil {
+		d.count(d.metrics.usersCreated)
+	}
+	return user, err
+}
+
+func (d *instrumentedDatabase) CreateCategory(ctx context.Context, userID, name string) (*models.Category, error) {
+	category, err := d.Database.CreateCategory(ctx, userID, name)
+	if err == nil {
+		d.count(d.metrics.categoriesCreated)
+	}
+	return category, err
+}
+
+func (d *instrumentedDatabase) CreateTransaction(ctx context.Context, userID string, categoryID *string, amount float64, description *string, occurredAt time.Time) (*models.Transaction, error) {
+	transaction, err := d.Database.CreateTransaction(ctx, userID, categoryID, amount, description, occurredAt)
+	if err == nil {
+		d.count(d.metrics.transactionsCreated)
+	}
+	return transaction, err
+}
+
+func (d *instrumentedDatabase) MergeDuplicateTransactions(ctx context.Context, userID, keepID, discardID string) (*models.Transaction, error) {
+	transaction, err := d.Database.MergeDuplicateTransactions(ctx, userID, keepID, discardID)
+	if err == nil {
+		d.count(d.metrics.duplicatesMerged)
+	}
+	return transaction, err
+}
+
+func (d *instrumentedDatabase) SavePrices(ctx context.Context, userID string, prices []models.Price) error {
+	err := d.Database.SavePrices(ctx, userID, prices)
+	if err == nil {
+		d.add(d.metrics.pricesImported, float64(len(prices)))
+	}
+	return err
+}
@@ -0,0 +1,273 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/sql/migrations"
+	sqlitemigrations "fintrack-go/sql/sqlite"
+	"fintrack-go/tests/dbtestutil"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"001_accounts.sql":      {Data: []byte("CREATE TABLE accounts (id INT PRIMARY KEY);")},
+		"001_accounts.down.sql": {Data: []byte("DROP TABLE accounts;")},
+		"002_notes.sql":         {Data: []byte("CREATE TABLE notes (id INT PRIMARY KEY);\nCREATE INDEX idx_notes_id ON notes(id);")},
+		"002_notes.down.sql":    {Data: []byte("DROP TABLE notes;")},
+		"010_tags.sql":          {Data: []byte("CREATE TABLE tags (id INT PRIMARY KEY);")},
+		"010_tags.down.sql":     {Data: []byte("DROP TABLE tags;")},
+		"README.md":             {Data: []byte("ignored")},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	got, err := Load(testFS())
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, 1, got[0].Version)
+	assert.Equal(t, "accounts", got[0].Name)
+	assert.Equal(t, "DROP TABLE accounts;", got[0].Down)
+	assert.Len(t, got[0].Checksum, 64)
+	assert.Equal(t, 2, got[1].Version)
+	assert.Equal(t, 10, got[2].Version)
+	assert.NotEqual(t, got[0].Checksum, got[1].Checksum)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fs      fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "missing down",
+			fs:      fstest.MapFS{"001_init.sql": {Data: []byte("SELECT 1")}},
+			wantErr: "has no down file",
+		},
+		{
+			name:    "missing up",
+			fs:      fstest.MapFS{"001_init.down.sql": {Data: []byte("SELECT 1")}},
+			wantErr: "has no up file",
+		},
+		{
+			name:    "bad file name",
+			fs:      fstest.MapFS{"init.sql": {Data: []byte("SELECT 1")}},
+			wantErr: "invalid migration file name",
+		},
+		{
+			name: "duplicate version",
+			fs: fstest.MapFS{
+				"001_init.sql":       {Data: []byte("SELECT 1")},
+				"001_other.down.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: "different names",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Load(tt.fs)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	t.Parallel()
+
+	got, err := Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, got)
+	for i, m := range got {
+		assert.Equal(t, i+1, m.Version, "migration versions should be consecutive")
+	}
+}
+
+func TestLoad_EmbeddedSQLiteMatchesPostgres(t *testing.T) {
+	t.Parallel()
+
+	postgres, err := Load(migrations.FS)
+	require.NoError(t, err)
+	sqlite, err := Load(sqlitemigrations.FS)
+	require.NoError(t, err)
+
+	require.Len(t, sqlite, len(postgres), "every migration needs a SQLite version")
+	for i := range postgres {
+		assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+}
+
+// setupSchemaPool returns a pool whose connections use a fresh schema, so
+// migrations under test don't touch the application tables.
+func setupSchemaPool(t *testing.T) *pgxpool.Pool {
+	ctx := dbtestutil.CreateTestContext(t)
+	schema := "migrate_test_" + uuid.NewString()[:8]
+
+	admin := dbtestutil.SetupTestDB(t)
+	_, err := admin.Exec(ctx, fmt.Sprintf("CREATE SCHEMA %s", schema))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec(context.Background(), fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		admin.Close()
+	})
+
+	config, err := pgxpool.ParseConfig(dbtestutil.GetTestDatabaseURL())
+	require.NoError(t, err)
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func appliedVersions(t *testing.T, m *Migrator) []int {
+	statuses, err := m.Status(dbtestutil.CreateTestContext(t))
+	require.NoError(t, err)
+
+	var versions []int
+	for _, s := range statuses {
+		if s.Applied {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigrator(t *testing.T) {
+	pool := setupSchemaPool(t)
+	m, err := New(pool, testFS(), zerolog.Nop())
+	require.NoError(t, err)
+	ctx := dbtestutil.CreateTestContext(t)
+
+	t.Run("up applies everything once", func(t *testing.T) {
+		require.NoError(t, m.Up(ctx))
+		assert.Equal(t, []int{1, 2, 10}, appliedVersions(t, m))
+
+		require.NoError(t, m.Up(ctx), "re-running up should be a no-op")
+		assert.Equal(t, []int{1, 2, 10}, appliedVersions(t, m))
+	})
+
+	t.Run("down reverts the latest", func(t *testing.T) {
+		require.NoError(t, m.Down(ctx, 2))
+		assert.Equal(t, []int{1}, appliedVersions(t, m))
+
+		var exists bool
+		require.NoError(t, pool.QueryRow(ctx, `SELECT to_regclass('notes') IS NOT NULL`).Scan(&exists))
+		assert.False(t, exists)
+	})
+
+	t.Run("to moves in both directions", func(t *testing.T) {
+		require.NoError(t, m.To(ctx, 2))
+		assert.Equal(t, []int{1, 2}, appliedVersions(t, m))
+
+		require.NoError(t, m.To(ctx, 0))
+		assert.Empty(t, appliedVersions(t, m))
+
+		assert.ErrorIs(t, m.To(ctx, 3), ErrNoSuchVersion)
+	})
+
+	t.Run("modified migration is rejected", func(t *testing.T) {
+		require.NoError(t, m.Up(ctx))
+
+		changed := testFS()
+		changed["002_notes.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE notes (id BIGINT PRIMARY KEY);")}
+		m2, err := New(pool, changed, zerolog.Nop())
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, m2.Up(ctx), ErrChecksumMismatch)
+	})
+
+	t.Run("unknown applied version is rejected", func(t *testing.T) {
+		fewer := testFS()
+		delete(fewer, "010_tags.sql")
+		delete(fewer, "010_tags.down.sql")
+		m2, err := New(pool, fewer, zerolog.Nop())
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, m2.Up(ctx), ErrUnknownVersion)
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		require.NoError(t, m.To(ctx, 0))
+
+		broken := testFS()
+		broken["002_notes.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE notes (id INT PRIMARY KEY);\nSELECT nope;")}
+		m2, err := New(pool, broken, zerolog.Nop())
+		require.NoError(t, err)
+
+		assert.Error(t, m2.Up(ctx))
+		assert.Equal(t, []int{1}, appliedVersions(t, m2))
+
+		var exists bool
+		require.NoError(t, pool.QueryRow(ctx, `SELECT to_regclass('notes') IS NOT NULL`).Scan(&exists))
+		assert.False(t, exists)
+	})
+}
+
+func TestMigrator_Baseline(t *testing.T) {
+	pool := setupSchemaPool(t)
+	m, err := New(pool, testFS(), zerolog.Nop())
+	require.NoError(t, err)
+	ctx := dbtestutil.CreateTestContext(t)
+
+	_, err = pool.Exec(ctx, "CREATE TABLE accounts (id INT PRIMARY KEY)")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Baseline(ctx, 1))
+	assert.Equal(t, []int{1}, appliedVersions(t, m))
+
+	require.NoError(t, m.Up(ctx))
+	assert.Equal(t, []int{1, 2, 10}, appliedVersions(t, m))
+
+	assert.ErrorIs(t, m.Baseline(ctx, 1), ErrAlreadyTracked)
+}
+
+func TestMigrator_ConcurrentUp(t *testing.T) {
+	pool := setupSchemaPool(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m, err := New(pool, testFS(), zerolog.Nop())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = m.Up(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	var count int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count))
+	assert.Equal(t, 3, count)
+}
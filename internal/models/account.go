@@ -0,0 +1,86 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Account types. Assets count toward a user's net worth and liabilities
+// against it.
+const (
+	AccountCash           = "cash"
+	AccountInvestment     = "investment"
+	AccountProperty       = "property"
+	AccountVehicle        = "vehicle"
+	AccountOtherAsset     = "other_asset"
+	AccountCreditCard     = "credit_card"
+	AccountLoan           = "loan"
+	AccountMortgage       = "mortgage"
+	AccountOtherLiability = "other_liability"
+)
+
+var AssetTypes = []string{AccountCash, AccountInvestment, AccountProperty, AccountVehicle, AccountOtherAsset}
+
+var LiabilityTypes = []string{AccountCreditCard, AccountLoan, AccountMortgage, AccountOtherLiability}
+
+var AccountTypes = slices.Concat(AssetTypes, LiabilityTypes)
+
+// Account is something a user owns or owes, such as a bank account, a house
+// or a car loan. Its value over time is given by its valuations.
+type Account struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Liability reports whether the account is owed rather than owned.
+func (a Account) Liability() bool {
+	return slices.Contains(LiabilityTypes, a.Type)
+}
+
+// Valuation is an account's balance or value, or for a liability what is
+// owed, as of ValuedAt. It holds until the account's next valuation.
+type Valuation struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"account_id"`
+	UserID    string    `json:"user_id"`
+	Value     float64   `json:"value"`
+	ValuedAt  time.Time `json:"valued_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValuationFilter narrows a user's valuations. Nil fields are not applied.
+type ValuationFilter struct {
+	AccountID *string
+	// To leaves out valuations after it.
+	To *time.Time
+}
+
+// Net worth report intervals.
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+var NetWorthIntervals = []string{IntervalDay, IntervalWeek, IntervalMonth}
+
+// NetWorthPoint totals the latest valuation of each of a user's accounts as
+// of Date.
+type NetWorthPoint struct {
+	Date        time.Time `json:"date"`
+	Assets      float64   `json:"assets"`
+	Liabilities float64   `json:"liabilities"`
+	NetWorth    float64   `json:"net_worth"`
+}
+
+// NetWorth is a user's net worth at each Interval from From to To.
+type NetWorth struct {
+	UserID   string          `json:"user_id"`
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Interval string          `json:"interval"`
+	Points   []NetWorthPoint `json:"points"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Attachment is a file, such as a receipt or an invoice, kept with a
+// transaction. Its content is in the blob store under StorageKey, which is
+// never returned by the API.
+type Attachment struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	TransactionID string    `json:"transaction_id"`
+	Filename      string    `json:"filename"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	SHA256        string    `json:"sha256"`
+	StorageKey    string    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
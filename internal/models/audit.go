@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited actions.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Audited entities, named after the resources the API returns.
+const (
+	EntityUser               = "user"
+	EntityCategory           = "category"
+	EntityTransaction        = "transaction"
+	EntityDismissedDuplicate = "dismissed_duplicate"
+	EntityWebhook            = "webhook_subscription"
+	EntityAttachment         = "attachment"
+	EntityGoal               = "goal"
+	EntityGoalContribution   = "goal_contribution"
+	EntityAccount            = "account"
+	EntityValuation          = "valuation"
+	EntityDebt               = "debt"
+	EntityDebtPayment        = "debt_payment"
+	EntitySecurity           = "security"
+	EntityLot                = "lot"
+	EntityPriceImport        = "price_import"
+)
+
+var AuditActions = []string{AuditCreate, AuditUpdate, AuditDelete}
+
+var AuditEntities = []string{EntityUser, EntityCategory, EntityTransaction, EntityDismissedDuplicate, EntityWebhook, EntityAttachment, EntityGoal, EntityGoalContribution, EntityAccount, EntityValuation, EntityDebt, EntityDebtPayment, EntitySecurity, EntityLot, EntityPriceImport}
+
+// AuditEntry records a change to one of a user's entities. Before is nil for
+// a creation and After for a permanent deletion. Moving to the trash is a
+// deletion whose After has deleted_at set, and restoring is an update. The
+// entries of an erased user are redacted to what changed and when.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	UserID    string          `json:"user_id"`
+	Actor     string          `json:"actor"`
+	RequestID *string         `json:"request_id"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows a user's audit log. Empty fields are not applied.
+// Entries are listed newest first, up to Limit, and only those older than
+// BeforeID when it is set, to page through the log.
+type AuditFilter struct {
+	Entity    string
+	EntityID  string
+	Action    string
+	Actor     string
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+	BeforeID  int64
+	Limit     int
+}
@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// Debt is a loan a user repays in monthly installments, such as a mortgage
+// or a car loan.
+type Debt struct {
+	ID        string  `json:"id"`
+	UserID    string  `json:"user_id"`
+	Name      string  `json:"name"`
+	Principal float64 `json:"principal"`
+	// AnnualRate is the nominal yearly interest rate in percent. A twelfth
+	// of it is charged each month on what is left of the principal.
+	AnnualRate float64 `json:"annual_rate"`
+	TermMonths int     `json:"term_months"`
+	// Payment is the installment due each month. Whatever is left of the
+	// principal is due with the last one.
+	Payment float64 `json:"payment"`
+	// FirstPaymentDate is when the first installment is due. The others are
+	// due on the same day of each following month, or on its last day if it
+	// is shorter.
+	FirstPaymentDate time.Time `json:"first_payment_date"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// DebtPayment is money paid toward a debt. It is either entered by hand or
+// taken from one of the user's transactions, in which case it has the
+// transaction's amount and date.
+type DebtPayment struct {
+	ID            string    `json:"id"`
+	DebtID        string    `json:"debt_id"`
+	UserID        string    `json:"user_id"`
+	TransactionID *string   `json:"transaction_id,omitempty"`
+	Amount        float64   `json:"amount"`
+	Note          *string   `json:"note,omitempty"`
+	PaidAt        time.Time `json:"paid_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Installment states.
+const (
+	InstallmentPaid     = "paid"
+	InstallmentPartial  = "partial"
+	InstallmentOverdue  = "overdue"
+	InstallmentUpcoming = "upcoming"
+	// InstallmentSettled is an installment that was not needed because the
+	// debt was paid off early.
+	InstallmentSettled = "settled"
+)
+
+// Installment is one month of a debt's amortisation schedule: what is due,
+// how it splits into principal and interest, and the principal left after
+// it is paid, together with the payments matched to it.
+type Installment struct {
+	Number    int       `json:"number"`
+	DueDate   time.Time `json:"due_date"`
+	Payment   float64   `json:"payment"`
+	Principal float64   `json:"principal"`
+	Interest  float64   `json:"interest"`
+	Balance   float64   `json:"balance"`
+	// Paid is the sum of the payments matched to the installment, those
+	// nearest to its due date.
+	Paid       float64  `json:"paid"`
+	PaymentIDs []string `json:"payment_ids"`
+	Status     string   `json:"status"`
+}
+
+// DebtSchedule is a debt's amortisation schedule as of AsOf.
+type DebtSchedule struct {
+	DebtID        string        `json:"debt_id"`
+	TotalInterest float64       `json:"total_interest"`
+	Installments  []Installment `json:"installments"`
+	AsOf          time.Time     `json:"as_of"`
+}
+
+// PayoffScenario is when a debt is paid off if ExtraMonthly is paid on top
+// of each installment from the next one on.
+type PayoffScenario struct {
+	ExtraMonthly          float64   `json:"extra_monthly"`
+	PayoffDate            time.Time `json:"payoff_date"`
+	RemainingInstallments int       `json:"remaining_installments"`
+	// InterestRemaining is the interest still to be charged.
+	InterestRemaining float64 `json:"interest_remaining"`
+	// InterestSaved and InstallmentsSaved compare the scenario to paying no
+	// extra.
+	InterestSaved     float64 `json:"interest_saved"`
+	InstallmentsSaved int     `json:"installments_saved"`
+}
+
+// DebtReport is how much of a debt's principal and interest has been paid
+// as of AsOf, and when it will be paid off.
+type DebtReport struct {
+	DebtID        string  `json:"debt_id"`
+	PrincipalPaid float64 `json:"principal_paid"`
+	InterestPaid  float64 `json:"interest_paid"`
+	// Balance is what is left of the principal, and InterestDue the
+	// interest charged but not yet paid.
+	Balance     float64    `json:"balance"`
+	InterestDue float64    `json:"interest_due"`
+	PaidOff     bool       `json:"paid_off"`
+	PaidOffAt   *time.Time `json:"paid_off_at,omitempty"`
+	// InstallmentsPaid counts the installments paid in full and
+	// InstallmentsOverdue those due without any payment.
+	InstallmentsPaid    int `json:"installments_paid"`
+	InstallmentsOverdue int `json:"installments_overdue"`
+	// Scenarios project the payoff without extra payments and with each
+	// extra monthly amount asked for. A paid-off debt has none.
+	Scenarios []PayoffScenario `json:"scenarios"`
+	AsOf      time.Time        `json:"as_of"`
+}
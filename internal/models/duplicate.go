@@ -0,0 +1,9 @@
+package models
+
+// DuplicatePair is two transactions that look like the same expense entered
+// twice. Transaction is the one recorded first.
+type DuplicatePair struct {
+	Transaction Transaction `json:"transaction"`
+	Duplicate   Transaction `json:"duplicate"`
+	Similarity  float64     `json:"similarity"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types, named after the resource and what happened to it.
+const (
+	EventTransactionCreated  = "transaction.created"
+	EventTransactionUpdated  = "transaction.updated"
+	EventTransactionDeleted  = "transaction.deleted"
+	EventTransactionRestored = "transaction.restored"
+	EventCategoryCreated     = "category.created"
+	EventCategoryDeleted     = "category.deleted"
+	EventCategoryRestored    = "category.restored"
+)
+
+// Event is a change to a user's ledger. IDs increase in the order a user's
+// events were committed, so a client that has seen an event can ask for
+// those after it.
+type Event struct {
+	ID        int64           `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// DeletedResource is the data of a deletion event.
+type DeletedResource struct {
+	ID string `json:"id"`
+}
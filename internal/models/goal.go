@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Goal is an amount a user is saving toward, such as an emergency fund,
+// optionally by TargetDate.
+type Goal struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Name         string     `json:"name"`
+	TargetAmount float64    `json:"target_amount"`
+	TargetDate   *time.Time `json:"target_date,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// GoalContribution is money put toward a goal. It is either entered by hand
+// or taken from one of the user's transactions, in which case it has the
+// transaction's amount and date.
+type GoalContribution struct {
+	ID            string    `json:"id"`
+	GoalID        string    `json:"goal_id"`
+	UserID        string    `json:"user_id"`
+	TransactionID *string   `json:"transaction_id,omitempty"`
+	Amount        float64   `json:"amount"`
+	Note          *string   `json:"note,omitempty"`
+	ContributedAt time.Time `json:"contributed_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// GoalProgress is how far a goal is from its target as of AsOf, and when it
+// will be reached at the rate of recent contributions.
+type GoalProgress struct {
+	GoalID    string  `json:"goal_id"`
+	Saved     float64 `json:"saved"`
+	Remaining float64 `json:"remaining"`
+	// Percent is Saved as a percentage of the target, at most 100.
+	Percent     float64    `json:"percent"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// MonthlyRate is the average contributed per month over the RateWindow
+	// days before AsOf.
+	MonthlyRate float64 `json:"monthly_rate"`
+	RateWindow  int     `json:"rate_window_days"`
+	// RequiredMonthly is what must be contributed each month to reach the
+	// target by its date. It is nil for goals without a target date and
+	// those already reached.
+	RequiredMonthly *float64 `json:"required_monthly,omitempty"`
+	// ProjectedCompletion is the day the target is reached at MonthlyRate.
+	// It is nil when nothing was contributed recently.
+	ProjectedCompletion *time.Time `json:"projected_completion,omitempty"`
+	// OnTrack reports whether ProjectedCompletion is no later than the
+	// target date. It is nil for goals without one.
+	OnTrack *bool     `json:"on_track,omitempty"`
+	AsOf    time.Time `json:"as_of"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Export statuses.
+const (
+	ExportPending = "pending"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+)
+
+// Export is an archive of everything a user has stored, built in the
+// background. DownloadToken is only returned when the export is started.
+type Export struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	Status        string     `json:"status"`
+	Error         *string    `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	DownloadToken string     `json:"download_token,omitempty"`
+}
+
+// ErasureRequest is a pending request to erase a user. It takes effect when
+// it is confirmed with the user's email address before ExpiresAt.
+type ErasureRequest struct {
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// Security is a share, fund unit or other instrument a user holds at a
+// brokerage. Symbol identifies it in imported price files.
+type Security struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Symbol    string    `json:"symbol"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Lot sides.
+const (
+	LotBuy  = "buy"
+	LotSell = "sell"
+)
+
+var LotSides = []string{LotBuy, LotSell}
+
+// Lot is a buy or sell of Quantity units of a security at Price each. Fees
+// are paid on top of a buy and out of a sell's proceeds.
+type Lot struct {
+	ID         string    `json:"id"`
+	SecurityID string    `json:"security_id"`
+	UserID     string    `json:"user_id"`
+	Side       string    `json:"side"`
+	Quantity   float64   `json:"quantity"`
+	Price      float64   `json:"price"`
+	Fees       float64   `json:"fees"`
+	TradedAt   time.Time `json:"traded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LotFilter narrows a user's lots. Nil fields are not applied.
+type LotFilter struct {
+	SecurityID *string
+	// To leaves out lots traded after it.
+	To *time.Time
+}
+
+// Price is a security's price as of PricedAt, imported from a price file.
+type Price struct {
+	SecurityID string    `json:"security_id"`
+	Price      float64   `json:"price"`
+	PricedAt   time.Time `json:"priced_at"`
+}
+
+// PriceFilter narrows the prices of a user's securities. Nil fields are not
+// applied.
+type PriceFilter struct {
+	SecurityID *string
+	From       *time.Time
+	To         *time.Time
+}
+
+// Cost basis methods, which decide the cost of the units a sell disposes
+// of.
+const (
+	// CostFIFO disposes of the units bought first.
+	CostFIFO = "fifo"
+	// CostAverage disposes of units at the average cost of those held.
+	CostAverage = "average"
+)
+
+var CostMethods = []string{CostFIFO, CostAverage}
+
+// Holding is a user's position in a security as of a portfolio's AsOf.
+type Holding struct {
+	SecurityID string  `json:"security_id"`
+	Symbol     string  `json:"symbol"`
+	Name       string  `json:"name"`
+	Quantity   float64 `json:"quantity"`
+	// CostBasis is what the units held cost, fees included.
+	CostBasis float64 `json:"cost_basis"`
+	// Price is the security's latest price, and MarketValue and
+	// UnrealisedGain are nil while units are held without one.
+	Price          *float64   `json:"price,omitempty"`
+	PricedAt       *time.Time `json:"priced_at,omitempty"`
+	MarketValue    *float64   `json:"market_value,omitempty"`
+	UnrealisedGain *float64   `json:"unrealised_gain,omitempty"`
+	// RealisedGain is what the sells made over the cost of the units they
+	// disposed of, net of fees.
+	RealisedGain float64 `json:"realised_gain"`
+}
+
+// Portfolio values a user's holdings as of AsOf, with the cost basis
+// decided by Method.
+type Portfolio struct {
+	UserID   string    `json:"user_id"`
+	Method   string    `json:"method"`
+	Holdings []Holding `json:"holdings"`
+	// MarketValue and UnrealisedGain total the holdings with a price, and
+	// CostBasis and RealisedGain all of them. Unpriced counts the holdings
+	// left out for lack of a price.
+	CostBasis      float64   `json:"cost_basis"`
+	MarketValue    float64   `json:"market_value"`
+	UnrealisedGain float64   `json:"unrealised_gain"`
+	RealisedGain   float64   `json:"realised_gain"`
+	Unpriced       int       `json:"unpriced"`
+	AsOf           time.Time `json:"as_of"`
+}
@@ -11,6 +11,7 @@ type Transaction struct {
 	Description  *string    `json:"description,omitempty"`
 	OccurredAt   time.Time  `json:"occurred_at"`
 	CreatedAt    time.Time  `json:"created_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateTransactionRequest struct {
@@ -20,3 +21,15 @@ type CreateTransactionRequest struct {
 	Description *string `json:"description,omitempty"`
 	OccurredAt  *time.Time `json:"occurred_at,omitempty"`
 }
+
+// TransactionFilter narrows a transaction listing. Nil or empty fields are
+// not applied. Uncategorized takes precedence over CategoryIDs.
+type TransactionFilter struct {
+	From           *time.Time
+	To             *time.Time
+	MinAmount      *float64
+	MaxAmount      *float64
+	CategoryIDs    []string
+	Uncategorized  bool
+	HasDescription *bool
+}
@@ -0,0 +1,11 @@
+package models
+
+// Trash holds a user's deleted categories and transactions, most recently
+// deleted first, until they are restored or purged.
+type Trash struct {
+	Categories   []Category    `json:"categories"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Entities that can be deleted to the trash and restored.
+var TrashEntities = []string{EntityCategory, EntityTransaction}
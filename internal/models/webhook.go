@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventTypes lists the event types a subscription can ask for.
+var WebhookEventTypes = []string{EventTransactionCreated, EventCategoryCreated}
+
+// Webhook delivery statuses. A pending delivery is waiting for its next
+// attempt; a failed one ran out of attempts.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookSubscription asks for a user's events of EventTypes to be posted to
+// URL, signed with Secret. The secret is never returned by the API.
+type WebhookSubscription struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookEvent is the body posted to subscribers. Data is the created
+// resource, as the REST API returns it.
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// WebhookDelivery is one event on its way to one subscription, and the log
+// of the attempts to deliver it.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	// ResponseStatus and LastError describe the latest attempt.
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	// NextAttemptAt is set while the delivery is pending.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// OutgoingWebhook is a claimed delivery with where to send it.
+type OutgoingWebhook struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// WebhookAttempt is the outcome of sending a delivery. A delivery that was
+// not Delivered is tried again at NextAttemptAt, or fails for good when
+// that is nil.
+type WebhookAttempt struct {
+	At             time.Time
+	Delivered      bool
+	ResponseStatus *int
+	Error          *string
+	NextAttemptAt  *time.Time
+}
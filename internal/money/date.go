@@ -0,0 +1,15 @@
+package money
+
+import "time"
+
+// AddMonths returns t n months later. A month after the 31st is the last day
+// of a shorter month rather than the start of the next, as with due dates and
+// monthly statements.
+func AddMonths(t time.Time, n int) time.Time {
+	date := t.AddDate(0, n, 0)
+	if date.Day() != t.Day() {
+		// Went past the end of the month, so go back to its last day.
+		date = date.AddDate(0, 0, -date.Day())
+	}
+	return date
+}
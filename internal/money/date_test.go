@@ -0,0 +1,18 @@
+package money
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddMonths(t *testing.T) {
+	jan31 := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), AddMonths(jan31, 1), "the last day of a shorter month")
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), AddMonths(jan31, 2), "from the original day, not the clamped one")
+	assert.Equal(t, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC), AddMonths(jan31, 25), "leap year")
+	assert.Equal(t, time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC), AddMonths(jan31, -2))
+	assert.Equal(t, jan31, AddMonths(jan31, 0))
+}
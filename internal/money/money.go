@@ -0,0 +1,72 @@
+// Package money does the arithmetic shared by the reports on amounts: sums
+// are taken in whole cents so they don't drift, and amounts are rounded the
+// way the database stores them.
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+var (
+	// ErrNaN is returned by ToScale for NaN.
+	ErrNaN = errors.New("money: value is NaN")
+	// ErrRange is returned by ToScale for an infinity or a value whose
+	// rounding doesn't fit an int64.
+	ErrRange = errors.New("money: value out of range")
+)
+
+// ToCents rounds amount to whole cents the way Postgres stores a float8
+// parameter in a DECIMAL(10, 2) column: from its shortest decimal form,
+// rounding halves away from zero. Rounding amount*100 as a float instead
+// would turn 10.005 into 10.00 rather than 10.01. Amounts that ToScale
+// rejects give the value it returns with its error: 0 for NaN, and the
+// nearest int64 otherwise.
+func ToCents(amount float64) int64 {
+	cents, _ := ToScale(amount, 2)
+	return cents
+}
+
+// FromCents returns cents as an amount.
+func FromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// Round rounds amount to the cent.
+func Round(amount float64) float64 {
+	return FromCents(ToCents(amount))
+}
+
+// ToScale rounds v to places decimals the way ToCents rounds to cents, and
+// returns it in units of the last decimal. Like strconv.ParseInt, it returns
+// 0 and ErrNaN for NaN, and for an infinity or a result that doesn't fit an
+// int64 it returns math.MaxInt64 or math.MinInt64, by sign, and ErrRange.
+func ToScale(v float64, places int) (int64, error) {
+	switch {
+	case math.IsNaN(v):
+		return 0, ErrNaN
+	case math.IsInf(v, 0):
+		return clamp(v), ErrRange
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'f', -1, 64))
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)))
+
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() != 0 && new(big.Int).Abs(new(big.Int).Lsh(rem, 1)).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(rem.Sign())))
+	}
+	if !quo.IsInt64() {
+		return clamp(v), ErrRange
+	}
+	return quo.Int64(), nil
+}
+
+// clamp returns the int64 furthest from zero with v's sign.
+func clamp(v float64) int64 {
+	if v < 0 {
+		return math.MinInt64
+	}
+	return math.MaxInt64
+}
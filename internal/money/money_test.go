@@ -0,0 +1,76 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToCents(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   int64
+	}{
+		{amount: 0, want: 0},
+		{amount: 12.34, want: 1234},
+		{amount: 10.005, want: 1001},
+		{amount: -10.005, want: -1001},
+		{amount: 1.004999, want: 100},
+		{amount: 0.1 + 0.2, want: 30},
+		{amount: 99999999.99, want: 9999999999},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ToCents(tt.amount), "%v", tt.amount)
+	}
+}
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, 10.01, Round(10.005))
+	assert.Equal(t, 67.64, Round(67.6372))
+	assert.Equal(t, 12.5, FromCents(1250))
+}
+
+func TestToScale(t *testing.T) {
+	tests := []struct {
+		v      float64
+		places int
+		want   int64
+	}{
+		{v: 1.2345675, places: 6, want: 1234568},
+		{v: 12.345, places: 1, want: 123},
+		{v: 12.4, places: 0, want: 12},
+		{v: -9.2e12, places: 6, want: -9200000000000000000},
+	}
+
+	for _, tt := range tests {
+		got, err := ToScale(tt.v, tt.places)
+		require.NoError(t, err, "%v", tt.v)
+		assert.Equal(t, tt.want, got, "%v", tt.v)
+	}
+}
+
+func TestToScale_Invalid(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want int64
+		err  error
+	}{
+		{v: math.NaN(), want: 0, err: ErrNaN},
+		{v: math.Inf(1), want: math.MaxInt64, err: ErrRange},
+		{v: math.Inf(-1), want: math.MinInt64, err: ErrRange},
+		{v: 1e17, want: math.MaxInt64, err: ErrRange},
+		{v: -math.MaxFloat64, want: math.MinInt64, err: ErrRange},
+	}
+
+	for _, tt := range tests {
+		got, err := ToScale(tt.v, 2)
+		assert.ErrorIs(t, err, tt.err, "%v", tt.v)
+		assert.Equal(t, tt.want, got, "%v", tt.v)
+	}
+
+	assert.Equal(t, int64(0), ToCents(math.NaN()))
+	assert.Equal(t, int64(math.MaxInt64), ToCents(math.Inf(1)))
+}
@@ -0,0 +1,88 @@
+// Package networth reports a user's net worth over time from the valuations
+// of their accounts.
+package networth
+
+import (
+	"time"
+
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/money"
+)
+
+// MaxPoints is the most points a series may have.
+const MaxPoints = 1000
+
+// Count returns how many points Series reports from from to to at
+// interval, stopping once it is past MaxPoints.
+func Count(from, to time.Time, interval string) int {
+	n := 1
+	for step(from, interval, n-1).Before(to) && n <= MaxPoints {
+		n++
+	}
+	return n
+}
+
+// Series reports the net worth of accounts at from, at every interval after
+// it and at to, given their valuations earliest first. An account counts at
+// its latest valuation as of each point, the last of any on the same date,
+// and not at all before its first.
+func Series(accounts []models.Account, valuations []models.Valuation, from, to time.Time, interval string) []models.NetWorthPoint {
+	liability := make(map[string]bool, len(accounts))
+	for _, a := range accounts {
+		liability[a.ID] = a.Liability()
+	}
+
+	var points []models.NetWorthPoint
+	for i := 0; ; i++ {
+		date := step(from, interval, i)
+		if !date.Before(to) {
+			date = to
+		}
+		points = append(points, at(liability, valuations, date))
+		if date.Equal(to) {
+			return points
+		}
+	}
+}
+
+// at totals the latest valuation as of date of each of the accounts in
+// liability.
+func at(liability map[string]bool, valuations []models.Valuation, date time.Time) models.NetWorthPoint {
+	latest := make(map[string]models.Valuation)
+	for _, v := range valuations {
+		if _, ok := liability[v.AccountID]; !ok || v.ValuedAt.After(date) {
+			continue
+		}
+		if prev, ok := latest[v.AccountID]; !ok || !v.ValuedAt.Before(prev.ValuedAt) {
+			latest[v.AccountID] = v
+		}
+	}
+
+	var assets, liabilities int64
+	for accountID, v := range latest {
+		if liability[accountID] {
+			liabilities += money.ToCents(v.Value)
+		} else {
+			assets += money.ToCents(v.Value)
+		}
+	}
+
+	return models.NetWorthPoint{
+		Date:        date,
+		Assets:      money.FromCents(assets),
+		Liabilities: money.FromCents(liabilities),
+		NetWorth:    money.FromCents(assets - liabilities),
+	}
+}
+
+// step returns the ith interval after from.
+func step(from time.Time, interval string, i int) time.Time {
+	switch interval {
+	case models.IntervalDay:
+		return from.AddDate(0, 0, i)
+	case models.IntervalWeek:
+		return from.AddDate(0, 0, 7*i)
+	default:
+		return money.AddMonths(from, i)
+	}
+}
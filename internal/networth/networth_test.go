@@ -0,0 +1,98 @@
+package networth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/models"
+)
+
+var from = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func valuation(accountID string, value float64, at time.Time) models.Valuation {
+	return models.Valuation{AccountID: accountID, Value: value, ValuedAt: at}
+}
+
+func TestSeries(t *testing.T) {
+	accounts := []models.Account{
+		{ID: "checking", Type: models.AccountCash},
+		{ID: "house", Type: models.AccountProperty},
+		{ID: "mortgage", Type: models.AccountMortgage},
+		{ID: "card", Type: models.AccountCreditCard},
+	}
+	valuations := []models.Valuation{
+		valuation("house", 300000, from.AddDate(0, 0, -30)),
+		valuation("mortgage", 200000, from.AddDate(0, 0, -30)),
+		valuation("checking", 1000.1, from),
+		valuation("card", 250.2, from.AddDate(0, 0, 10)),
+		valuation("mortgage", 199000, from.AddDate(0, 1, 0)),
+		valuation("checking", 1500, from.AddDate(0, 1, 0)),
+		valuation("checking", 1600, from.AddDate(0, 1, 0)), // a correction on the same day
+		valuation("unknown", 1000000, from),
+	}
+
+	points := Series(accounts, valuations, from, from.AddDate(0, 2, 15), models.IntervalMonth)
+	require.Len(t, points, 4)
+
+	assert.Equal(t, from, points[0].Date)
+	assert.Equal(t, 301000.1, points[0].Assets)
+	assert.Equal(t, 200000.0, points[0].Liabilities)
+	assert.Equal(t, 101000.1, points[0].NetWorth)
+
+	assert.Equal(t, from.AddDate(0, 1, 0), points[1].Date)
+	assert.Equal(t, 301600.0, points[1].Assets, "the last valuation of the day")
+	assert.Equal(t, 199250.2, points[1].Liabilities)
+	assert.Equal(t, 102349.8, points[1].NetWorth)
+
+	assert.Equal(t, from.AddDate(0, 2, 0), points[2].Date)
+	assert.Equal(t, points[1].NetWorth, points[2].NetWorth, "valuations hold until the next")
+	assert.Equal(t, from.AddDate(0, 2, 15), points[3].Date, "ends at to")
+}
+
+func TestSeries_BeforeAnyValuation(t *testing.T) {
+	accounts := []models.Account{{ID: "checking", Type: models.AccountCash}}
+	valuations := []models.Valuation{valuation("checking", 500, from.AddDate(0, 0, 3))}
+
+	points := Series(accounts, valuations, from, from.AddDate(0, 0, 3), models.IntervalDay)
+	require.Len(t, points, 4)
+	assert.Zero(t, points[0].NetWorth)
+	assert.Zero(t, points[2].NetWorth)
+	assert.Equal(t, 500.0, points[3].NetWorth)
+
+	points = Series(accounts, nil, from, from, models.IntervalWeek)
+	require.Len(t, points, 1)
+	assert.Zero(t, points[0].Assets)
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		to       time.Time
+		interval string
+		want     int
+	}{
+		{name: "same instant", to: from, interval: models.IntervalDay, want: 1},
+		{name: "a year of months", to: from.AddDate(1, 0, 0), interval: models.IntervalMonth, want: 13},
+		{name: "partial last week", to: from.AddDate(0, 0, 10), interval: models.IntervalWeek, want: 3},
+		{name: "too many days", to: from.AddDate(5, 0, 0), interval: models.IntervalDay, want: MaxPoints + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Count(from, tt.to, tt.interval))
+			if tt.want <= MaxPoints {
+				assert.Len(t, Series(nil, nil, from, tt.to, tt.interval), tt.want)
+			}
+		})
+	}
+}
+
+func TestStep_EndOfMonth(t *testing.T) {
+	start := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), step(start, models.IntervalMonth, 1))
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), step(start, models.IntervalMonth, 2))
+	assert.Equal(t, time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC), step(start, models.IntervalMonth, 3))
+}
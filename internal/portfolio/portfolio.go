@@ -0,0 +1,166 @@
+// Package portfolio values a user's securities from their lots and imported
+// prices, without any live market data.
+package portfolio
+
+import (
+	"slices"
+	"time"
+
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/money"
+)
+
+// Report values securities as of asOf from their lots and prices, with the
+// cost of the units each sell disposes of decided by method. Lots traded
+// and prices dated after asOf are left out, and each security is valued at
+// its latest price as of asOf.
+func Report(securities []models.Security, lots []models.Lot, prices []models.Price, asOf time.Time, method string) models.Portfolio {
+	latest := make(map[string]models.Price)
+	for _, p := range prices {
+		if p.PricedAt.After(asOf) {
+			continue
+		}
+		if prev, ok := latest[p.SecurityID]; !ok || !p.PricedAt.Before(prev.PricedAt) {
+			latest[p.SecurityID] = p
+		}
+	}
+
+	bySecurity := make(map[string][]models.Lot)
+	for _, lot := range lots {
+		if !lot.TradedAt.After(asOf) {
+			bySecurity[lot.SecurityID] = append(bySecurity[lot.SecurityID], lot)
+		}
+	}
+
+	portfolio := models.Portfolio{Method: method, Holdings: []models.Holding{}, AsOf: asOf}
+	var costBasis, marketValue, unrealised, realised int64
+	for _, s := range securities {
+		h := holding(s, bySecurity[s.ID], method)
+		if p, ok := latest[s.ID]; ok {
+			h.Price = &p.Price
+			h.PricedAt = &p.PricedAt
+		}
+		switch {
+		case h.Price != nil || h.Quantity == 0:
+			var value float64
+			if h.Price != nil {
+				value = money.Round(h.Quantity * *h.Price)
+			}
+			gain := money.FromCents(money.ToCents(value) - money.ToCents(h.CostBasis))
+			h.MarketValue, h.UnrealisedGain = &value, &gain
+			marketValue += money.ToCents(value)
+			unrealised += money.ToCents(gain)
+		default:
+			portfolio.Unpriced++
+		}
+		costBasis += money.ToCents(h.CostBasis)
+		realised += money.ToCents(h.RealisedGain)
+		portfolio.Holdings = append(portfolio.Holdings, h)
+	}
+
+	portfolio.CostBasis = money.FromCents(costBasis)
+	portfolio.MarketValue = money.FromCents(marketValue)
+	portfolio.UnrealisedGain = money.FromCents(unrealised)
+	portfolio.RealisedGain = money.FromCents(realised)
+	return portfolio
+}
+
+// parcel is units bought together, which FIFO disposes of in order.
+type parcel struct {
+	quantity int64
+	cost     float64
+}
+
+// holding replays a security's lots to find the units held, what they
+// cost and what the sells realised. A buy's fees add to its cost and a
+// sell's come out of its proceeds. Units sold beyond those held, which the
+// database doesn't allow, cost nothing.
+func holding(s models.Security, lots []models.Lot, method string) models.Holding {
+	slices.SortStableFunc(lots, func(a, b models.Lot) int {
+		if c := a.TradedAt.Compare(b.TradedAt); c != 0 {
+			return c
+		}
+		// Buys come before sells traded at the same time.
+		return boolInt(a.Side == models.LotSell) - boolInt(b.Side == models.LotSell)
+	})
+
+	// Quantities are counted in millionths, as they are stored, so that
+	// selling everything leaves exactly nothing. Stored quantities are
+	// finite and fit, so ToScale can't fail.
+	var parcels []parcel
+	var realised float64
+	for _, lot := range lots {
+		quantity, _ := money.ToScale(lot.Quantity, 6)
+		if lot.Side == models.LotBuy {
+			parcels = append(parcels, parcel{quantity: quantity, cost: lot.Quantity*lot.Price + lot.Fees})
+			continue
+		}
+
+		var cost float64
+		if method == models.CostAverage {
+			parcels, cost = disposeAverage(parcels, quantity)
+		} else {
+			parcels, cost = disposeFIFO(parcels, quantity)
+		}
+		realised += lot.Quantity*lot.Price - lot.Fees - cost
+	}
+
+	var held int64
+	var cost float64
+	for _, p := range parcels {
+		held += p.quantity
+		cost += p.cost
+	}
+	return models.Holding{
+		SecurityID:   s.ID,
+		Symbol:       s.Symbol,
+		Name:         s.Name,
+		Quantity:     float64(held) / 1e6,
+		CostBasis:    money.Round(cost),
+		RealisedGain: money.Round(realised),
+	}
+}
+
+// disposeFIFO sells quantity units from the parcels bought first, and
+// returns what is left and what the units sold cost.
+func disposeFIFO(parcels []parcel, quantity int64) ([]parcel, float64) {
+	var cost float64
+	for len(parcels) > 0 && quantity > 0 {
+		p := &parcels[0]
+		if p.quantity > quantity {
+			part := p.cost * float64(quantity) / float64(p.quantity)
+			p.quantity -= quantity
+			p.cost -= part
+			return parcels, cost + part
+		}
+		quantity -= p.quantity
+		cost += p.cost
+		parcels = parcels[1:]
+	}
+	return parcels, cost
+}
+
+// disposeAverage sells quantity units at the average cost of those held,
+// which it merges into one parcel, and returns it and what the units sold
+// cost.
+func disposeAverage(parcels []parcel, quantity int64) ([]parcel, float64) {
+	var held parcel
+	for _, p := range parcels {
+		held.quantity += p.quantity
+		held.cost += p.cost
+	}
+	if held.quantity <= quantity {
+		return nil, held.cost
+	}
+	cost := held.cost * float64(quantity) / float64(held.quantity)
+	held.quantity -= quantity
+	held.cost -= cost
+	return []parcel{held}, cost
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
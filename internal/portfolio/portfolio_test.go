@@ -0,0 +1,138 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/models"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var securities = []models.Security{
+	{ID: "acme", Symbol: "ACME", Name: "Acme Corp."},
+	{ID: "globex", Symbol: "GLBX", Name: "Globex"},
+	{ID: "initech", Symbol: "INIT", Name: "Initech"},
+}
+
+func lot(securityID, side string, quantity, price, fees float64, at time.Time) models.Lot {
+	return models.Lot{SecurityID: securityID, Side: side, Quantity: quantity, Price: price, Fees: fees, TradedAt: at}
+}
+
+func price(securityID string, p float64, at time.Time) models.Price {
+	return models.Price{SecurityID: securityID, Price: p, PricedAt: at}
+}
+
+var lots = []models.Lot{
+	lot("acme", models.LotBuy, 10, 100, 10, start),
+	lot("acme", models.LotBuy, 10, 120, 10, start.AddDate(0, 1, 0)),
+	lot("acme", models.LotSell, 15, 130, 15, start.AddDate(0, 2, 0)),
+	lot("globex", models.LotBuy, 3, 50, 0, start),
+	// Sold out on the same day it was bought.
+	lot("initech", models.LotSell, 2, 30, 1, start.AddDate(0, 1, 0)),
+	lot("initech", models.LotBuy, 2, 25, 1, start.AddDate(0, 1, 0)),
+}
+
+var prices = []models.Price{
+	price("acme", 125, start.AddDate(0, 1, 0)),
+	price("acme", 140, start.AddDate(0, 2, 0)),
+	price("acme", 200, start.AddDate(0, 4, 0)),
+}
+
+func TestReport_FIFO(t *testing.T) {
+	p := Report(securities, lots, prices, start.AddDate(0, 3, 0), models.CostFIFO)
+	assert.Equal(t, models.CostFIFO, p.Method)
+	require.Len(t, p.Holdings, 3)
+
+	acme := p.Holdings[0]
+	assert.Equal(t, "ACME", acme.Symbol)
+	assert.Equal(t, 5.0, acme.Quantity)
+	// The first 10 units and half of the next 10 were sold.
+	assert.Equal(t, 605.0, acme.CostBasis)
+	assert.Equal(t, 320.0, acme.RealisedGain, "1935 proceeds less 1010 and 605")
+	require.NotNil(t, acme.Price)
+	assert.Equal(t, 140.0, *acme.Price, "the latest price as of the report")
+	assert.Equal(t, start.AddDate(0, 2, 0), *acme.PricedAt)
+	assert.Equal(t, 700.0, *acme.MarketValue)
+	assert.Equal(t, 95.0, *acme.UnrealisedGain)
+
+	globex := p.Holdings[1]
+	assert.Equal(t, 3.0, globex.Quantity)
+	assert.Equal(t, 150.0, globex.CostBasis)
+	assert.Nil(t, globex.Price)
+	assert.Nil(t, globex.MarketValue, "held without a price")
+	assert.Nil(t, globex.UnrealisedGain)
+
+	initech := p.Holdings[2]
+	assert.Zero(t, initech.Quantity, "the buy comes before the sell traded at the same time")
+	assert.Zero(t, initech.CostBasis)
+	assert.Equal(t, 8.0, initech.RealisedGain)
+	require.NotNil(t, initech.MarketValue, "nothing held needs no price")
+	assert.Zero(t, *initech.MarketValue)
+
+	assert.Equal(t, 755.0, p.CostBasis)
+	assert.Equal(t, 700.0, p.MarketValue, "priced holdings only")
+	assert.Equal(t, 95.0, p.UnrealisedGain)
+	assert.Equal(t, 328.0, p.RealisedGain)
+	assert.Equal(t, 1, p.Unpriced)
+}
+
+func TestReport_Average(t *testing.T) {
+	p := Report(securities, lots, prices, start.AddDate(0, 3, 0), models.CostAverage)
+
+	acme := p.Holdings[0]
+	assert.Equal(t, 5.0, acme.Quantity)
+	// Each unit held cost 2220 / 20 = 111.
+	assert.Equal(t, 555.0, acme.CostBasis)
+	assert.Equal(t, 270.0, acme.RealisedGain, "1935 proceeds less 15 units at 111")
+	assert.Equal(t, 145.0, *acme.UnrealisedGain)
+
+	assert.Equal(t, 150.0, p.Holdings[1].CostBasis, "no sells, so the same as FIFO")
+	assert.Equal(t, 278.0, p.RealisedGain)
+}
+
+func TestReport_AsOf(t *testing.T) {
+	p := Report(securities, lots, prices, start.AddDate(0, 1, 15), models.CostFIFO)
+
+	acme := p.Holdings[0]
+	assert.Equal(t, 20.0, acme.Quantity, "the sell comes later")
+	assert.Equal(t, 2220.0, acme.CostBasis)
+	assert.Zero(t, acme.RealisedGain)
+	assert.Equal(t, 125.0, *acme.Price)
+	assert.Equal(t, 2500.0, *acme.MarketValue)
+	assert.Equal(t, 280.0, *acme.UnrealisedGain)
+
+	p = Report(securities, lots, prices, start.AddDate(0, 0, -1), models.CostFIFO)
+	for _, h := range p.Holdings {
+		assert.Zero(t, h.Quantity, h.Symbol)
+		assert.Nil(t, h.Price, h.Symbol)
+	}
+	assert.Zero(t, p.Unpriced, "nothing held yet")
+}
+
+func TestReport_FractionalUnits(t *testing.T) {
+	lots := []models.Lot{
+		lot("acme", models.LotBuy, 0.1, 100, 0, start),
+		lot("acme", models.LotBuy, 0.2, 100, 0, start),
+		lot("acme", models.LotSell, 0.3, 110, 0, start.AddDate(0, 1, 0)),
+	}
+
+	for _, method := range models.CostMethods {
+		p := Report(securities[:1], lots, nil, start.AddDate(0, 2, 0), method)
+		h := p.Holdings[0]
+		assert.Zero(t, h.Quantity, method)
+		assert.Zero(t, h.CostBasis, method)
+		assert.Equal(t, 3.0, h.RealisedGain, method)
+		assert.Zero(t, p.Unpriced, method)
+	}
+}
+
+func TestReport_Empty(t *testing.T) {
+	p := Report(nil, nil, nil, start, models.CostFIFO)
+	assert.NotNil(t, p.Holdings)
+	assert.Empty(t, p.Holdings)
+	assert.Zero(t, p.MarketValue)
+}
@@ -0,0 +1,117 @@
+package privacy
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// ErasureConfig controls how long an erasure request waits for confirmation.
+type ErasureConfig struct {
+	// ConfirmationTTL is how long a request can be confirmed.
+	ConfirmationTTL time.Duration
+}
+
+// Eraser erases users in two steps: a request, and a confirmation that
+// names the user's email address before the request expires. Nothing the
+// request returns confirms it, so a client that doesn't know the address
+// can't erase the user, and confirming with the wrong one cancels the
+// request rather than allowing another guess. A new request replaces the
+// user's earlier one.
+type Eraser struct {
+	logger   zerolog.Logger
+	db       db.Database
+	exporter *Exporter
+	config   ErasureConfig
+	now      func() time.Time
+
+	mu sync.Mutex
+	// requests holds when each user's pending request expires.
+	requests map[string]time.Time
+}
+
+// NewEraser returns an Eraser. The exports of erased users are discarded
+// from exporter, which may be nil.
+func NewEraser(logger zerolog.Logger, database db.Database, exporter *Exporter, config ErasureConfig) *Eraser {
+	return &Eraser{
+		logger:   logger,
+		db:       database,
+		exporter: exporter,
+		config:   config,
+		now:      time.Now,
+		requests: make(map[string]time.Time),
+	}
+}
+
+// Request asks to erase the user.
+func (e *Eraser) Request(ctx context.Context, userID string) (*models.ErasureRequest, error) {
+	if _, err := e.db.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	expiresAt := e.now().UTC().Add(e.config.ConfirmationTTL)
+
+	e.mu.Lock()
+	e.sweep()
+	e.requests[userID] = expiresAt
+	e.mu.Unlock()
+
+	return &models.ErasureRequest{UserID: userID, ExpiresAt: expiresAt}, nil
+}
+
+// Confirm erases the user if they have a pending request and email is their
+// address, in any case. Their data is deleted, their audit entries are
+// redacted and their exports are discarded.
+func (e *Eraser) Confirm(ctx context.Context, userID, email string) error {
+	e.mu.Lock()
+	e.sweep()
+	_, ok := e.requests[userID]
+	e.mu.Unlock()
+	if !ok {
+		return ErrInvalidErasure
+	}
+
+	user, err := e.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(email), user.Email) {
+		e.mu.Lock()
+		delete(e.requests, userID)
+		e.mu.Unlock()
+		return ErrInvalidErasure
+	}
+
+	if err := e.db.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	delete(e.requests, userID)
+	e.mu.Unlock()
+	if e.exporter != nil {
+		if err := e.exporter.Discard(ctx, userID); err != nil {
+			// The user is erased; their exports are deleted when they
+			// expire.
+			e.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to discard exports")
+		}
+	}
+
+	e.logger.Info().Str("user_id", userID).Msg("Erased user")
+	return nil
+}
+
+// sweep forgets expired requests. e.mu must be held.
+func (e *Eraser) sweep() {
+	now := e.now()
+	for userID, expiresAt := range e.requests {
+		if !now.Before(expiresAt) {
+			delete(e.requests, userID)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package privacy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/blob"
+	"fintrack-go/internal/db"
+)
+
+func TestEraser(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "erase@example.com")
+	require.NoError(t, err)
+	other, err := database.CreateUser(ctx, "other@example.com")
+	require.NoError(t, err)
+
+	exporter := NewExporter(zerolog.Nop(), database, blob.NewMemoryStore(), ExportConfig{TTL: time.Hour})
+	t.Cleanup(exporter.Close)
+	export, err := exporter.Start(ctx, user.ID)
+	require.NoError(t, err)
+
+	eraser := NewEraser(zerolog.Nop(), database, exporter, ErasureConfig{ConfirmationTTL: 15 * time.Minute})
+
+	first, err := eraser.Request(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, first.UserID)
+	assert.False(t, first.ExpiresAt.IsZero())
+
+	assert.ErrorIs(t, eraser.Confirm(ctx, other.ID, user.Email), ErrInvalidErasure, "not requested")
+	assert.ErrorIs(t, eraser.Confirm(ctx, user.ID, other.Email), ErrInvalidErasure, "another user's address")
+	assert.ErrorIs(t, eraser.Confirm(ctx, user.ID, user.Email), ErrInvalidErasure, "cancelled by the wrong address")
+	_, err = database.GetUserByID(ctx, user.ID)
+	require.NoError(t, err, "not erased until confirmed")
+
+	_, err = eraser.Request(ctx, user.ID)
+	require.NoError(t, err)
+	require.NoError(t, eraser.Confirm(ctx, user.ID, " ERASE@example.com "))
+	_, err = database.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+	exporter.wg.Wait()
+	_, err = exporter.Get(ctx, user.ID, export.ID)
+	assert.ErrorIs(t, err, ErrExportNotFound, "exports are discarded")
+	_, err = database.GetUserByID(ctx, other.ID)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, eraser.Confirm(ctx, user.ID, user.Email), ErrInvalidErasure, "requests are confirmed once")
+	_, err = eraser.Request(ctx, user.ID)
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+}
+
+func TestEraser_Expires(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "erase@example.com")
+	require.NoError(t, err)
+
+	eraser := NewEraser(zerolog.Nop(), database, nil, ErasureConfig{ConfirmationTTL: 15 * time.Minute})
+	_, err = eraser.Request(ctx, user.ID)
+	require.NoError(t, err)
+
+	eraser.now = func() time.Time { return time.Now().Add(15 * time.Minute) }
+	assert.ErrorIs(t, eraser.Confirm(ctx, user.ID, user.Email), ErrInvalidErasure)
+	_, err = database.GetUserByID(ctx, user.ID)
+	assert.NoError(t, err)
+}
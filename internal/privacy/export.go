@@ -0,0 +1,573 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/blob"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+// ExportConfig controls how long exports are kept.
+type ExportConfig struct {
+	// TTL is how long an export can be downloaded after it is started.
+	TTL time.Duration
+}
+
+// exportsPrefix is where exports are kept in the blob store: each under
+// "exports/<id>/", as a record of the export and, once it is built, its
+// archive.
+const exportsPrefix = "exports/"
+
+// Exporter builds ZIP archives of a user's profile, categories and
+// transactions, including those in the trash, in the background. An archive
+// is downloaded with the token returned when its export is started, until
+// the export expires.
+//
+// Exports are kept in a blob store, so every instance sharing the store sees
+// them. An expired export is deleted when it is next read, and all of them
+// are swept out whenever an export is started.
+type Exporter struct {
+	logger zerolog.Logger
+	db     db.Database
+	store  blob.Store
+	config ExportConfig
+	now    func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// exportRecord is an export as it is stored, with the digest of its download
+// token and the size of its archive.
+type exportRecord struct {
+	models.Export
+	TokenDigest string `json:"token_digest"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// Archive is the archive of a ready export, which the caller closes.
+type Archive struct {
+	io.ReadCloser
+	Size int64
+}
+
+func NewExporter(logger zerolog.Logger, database db.Database, store blob.Store, config ExportConfig) *Exporter {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Exporter{
+		logger: logger,
+		db:     database,
+		store:  store,
+		config: config,
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start starts building an export of the user's data. The returned export is
+// the only one with its DownloadToken set.
+func (e *Exporter) Start(ctx context.Context, userID string) (*models.Export, error) {
+	if _, err := e.db.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	e.sweep(ctx)
+
+	token, digest, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := e.now().UTC()
+	record := &exportRecord{
+		Export: models.Export{
+			ID:        uuid.NewString(),
+			UserID:    userID,
+			Status:    models.ExportPending,
+			CreatedAt: now,
+			ExpiresAt: now.Add(e.config.TTL),
+		},
+		TokenDigest: hex.EncodeToString(digest[:]),
+	}
+	if err := e.put(ctx, record); err != nil {
+		return nil, err
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.build(*record)
+	}()
+
+	started := record.Export
+	started.DownloadToken = token
+	return &started, nil
+}
+
+// Get returns one of the user's exports.
+func (e *Exporter) Get(ctx context.Context, userID, id string) (*models.Export, error) {
+	record, err := e.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.UserID != userID {
+		return nil, ErrExportNotFound
+	}
+	return &record.Export, nil
+}
+
+// Download returns the archive of an export and the export itself. An
+// unknown export and a wrong token are both ErrExportNotFound.
+func (e *Exporter) Download(ctx context.Context, id, token string) (*Archive, *models.Export, error) {
+	record, err := e.load(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var digest [sha256.Size]byte
+	if n, err := hex.Decode(digest[:], []byte(record.TokenDigest)); err != nil || n != len(digest) || !tokenMatches(token, digest) {
+		return nil, nil, ErrExportNotFound
+	}
+	switch record.Status {
+	case models.ExportPending:
+		return nil, nil, ErrExportNotReady
+	case models.ExportFailed:
+		return nil, nil, ErrExportFailed
+	}
+
+	r, err := e.store.Get(ctx, archiveKey(record.ID))
+	if errors.Is(err, blob.ErrNotFound) {
+		// Discarded since its record was read.
+		return nil, nil, ErrExportNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Archive{ReadCloser: r, Size: record.Size}, &record.Export, nil
+}
+
+// Discard deletes the exports of a user who has been deleted. Exports still
+// being built are deleted when they finish, as their user is gone.
+func (e *Exporter) Discard(ctx context.Context, userID string) error {
+	records, err := e.records(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, record := range records {
+		if record.UserID == userID {
+			errs = append(errs, e.remove(ctx, record.ID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops the exports being built and waits for them to finish.
+func (e *Exporter) Close() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+func (e *Exporter) build(record exportRecord) {
+	ctx := e.ctx
+	data, err := e.archive(ctx, record.UserID, record.CreatedAt)
+	if err == nil {
+		err = e.store.Put(ctx, archiveKey(record.ID), bytes.NewReader(data), int64(len(data)), "application/zip")
+	}
+
+	now := e.now().UTC()
+	record.CompletedAt = &now
+	if err != nil {
+		e.logger.Error().Err(err).Str("export_id", record.ID).Msg("Failed to build export")
+		message := "the export could not be built"
+		record.Status = models.ExportFailed
+		record.Error = &message
+	} else {
+		record.Status = models.ExportReady
+		record.Size = int64(len(data))
+	}
+	if err := e.put(ctx, &record); err != nil {
+		e.logger.Error().Err(err).Str("export_id", record.ID).Msg("Failed to store export")
+		return
+	}
+
+	// A user erased while their export was built has had their exports
+	// discarded already, so this one is deleted here rather than kept
+	// until it expires. Erasure deletes the user before discarding their
+	// exports, so one of the two sees the other.
+	if _, err := e.db.GetUserByID(ctx, record.UserID); errors.Is(err, db.ErrUserNotFound) {
+		if err := e.remove(ctx, record.ID); err != nil {
+			e.logger.Error().Err(err).Str("export_id", record.ID).Msg("Failed to discard export")
+		}
+	}
+}
+
+// load reads an export's record, deleting it instead if it has expired.
+func (e *Exporter) load(ctx context.Context, id string) (*exportRecord, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrExportNotFound
+	}
+	record, err := e.read(ctx, recordKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if !e.now().Before(record.ExpiresAt) {
+		if err := e.remove(ctx, id); err != nil {
+			e.logger.Error().Err(err).Str("export_id", id).Msg("Failed to delete expired export")
+		}
+		return nil, ErrExportNotFound
+	}
+	return record, nil
+}
+
+// read reads the record stored under key.
+func (e *Exporter) read(ctx context.Context, key string) (*exportRecord, error) {
+	r, err := e.store.Get(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, ErrExportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var record exportRecord
+	if err := json.NewDecoder(r).Decode(&record); err != nil {
+		return nil, fmt.Errorf("export record %s: %w", key, err)
+	}
+	return &record, nil
+}
+
+// records reads the records of every stored export.
+func (e *Exporter) records(ctx context.Context) ([]*exportRecord, error) {
+	keys, err := e.store.List(ctx, exportsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var records []*exportRecord
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "/export.json") {
+			continue
+		}
+		record, err := e.read(ctx, key)
+		if errors.Is(err, ErrExportNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (e *Exporter) put(ctx context.Context, record *exportRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return e.store.Put(ctx, recordKey(record.ID), bytes.NewReader(data), int64(len(data)), "application/json")
+}
+
+// remove deletes an export's archive and then its record, so an archive is
+// never left behind without the record that leads to it.
+func (e *Exporter) remove(ctx context.Context, id string) error {
+	if err := e.store.Delete(ctx, archiveKey(id)); err != nil {
+		return err
+	}
+	return e.store.Delete(ctx, recordKey(id))
+}
+
+// sweep deletes expired exports. Failures are logged, as they are retried
+// by the next sweep.
+func (e *Exporter) sweep(ctx context.Context) {
+	records, err := e.records(ctx)
+	if err != nil {
+		e.logger.Error().Err(err).Msg("Failed to list exports")
+		return
+	}
+	now := e.now()
+	for _, record := range records {
+		if now.Before(record.ExpiresAt) {
+			continue
+		}
+		if err := e.remove(ctx, record.ID); err != nil {
+			e.logger.Error().Err(err).Str("export_id", record.ID).Msg("Failed to delete expired export")
+		}
+	}
+}
+
+func recordKey(id string) string {
+	return exportsPrefix + id + "/export.json"
+}
+
+func archiveKey(id string) string {
+	return exportsPrefix + id + "/archive.zip"
+}
+
+// exportedGoal is a goal as it is exported, with its contributions.
+type exportedGoal struct {
+	models.Goal
+	Contributions []models.GoalContribution `json:"contributions"`
+}
+
+// exportedAccount is an account as it is exported, with its valuations.
+type exportedAccount struct {
+	models.Account
+	Valuations []models.Valuation `json:"valuations"`
+}
+
+// exportedDebt is a debt as it is exported, with its payments.
+type exportedDebt struct {
+	models.Debt
+	Payments []models.DebtPayment `json:"payments"`
+}
+
+// exportedSecurity is a security as it is exported, with its lots and
+// prices.
+type exportedSecurity struct {
+	models.Security
+	Lots   []models.Lot   `json:"lots"`
+	Prices []models.Price `json:"prices"`
+}
+
+// archive reads the user's data in a single transaction, so it is
+// consistent, and writes it to a ZIP archive as JSON and CSV files dated
+// modified.
+func (e *Exporter) archive(ctx context.Context, userID string, modified time.Time) ([]byte, error) {
+	var (
+		user         *models.User
+		categories   []models.Category
+		transactions []models.Transaction
+		goals        []exportedGoal
+		accounts     []exportedAccount
+		debts        []exportedDebt
+		securities   []exportedSecurity
+	)
+	err := e.db.WithTx(ctx, func(tx db.Database) error {
+		var err error
+		if user, err = tx.GetUserByID(ctx, userID); err != nil {
+			return err
+		}
+		if categories, err = tx.ListCategories(ctx, userID); err != nil {
+			return err
+		}
+		if transactions, err = tx.ListTransactions(ctx, userID, models.TransactionFilter{}); err != nil {
+			return err
+		}
+		trash, err := tx.ListTrash(ctx, userID)
+		if err != nil {
+			return err
+		}
+		categories = append(categories, trash.Categories...)
+		transactions = append(transactions, trash.Transactions...)
+
+		userGoals, err := tx.ListGoals(ctx, userID)
+		if err != nil {
+			return err
+		}
+		goals = make([]exportedGoal, 0, len(userGoals))
+		for _, goal := range userGoals {
+			contributions, err := tx.ListGoalContributions(ctx, userID, goal.ID)
+			if err != nil {
+				return err
+			}
+			if contributions == nil {
+				contributions = []models.GoalContribution{}
+			}
+			goals = append(goals, exportedGoal{Goal: goal, Contributions: contributions})
+		}
+
+		userAccounts, err := tx.ListAccounts(ctx, userID)
+		if err != nil {
+			return err
+		}
+		accounts = make([]exportedAccount, 0, len(userAccounts))
+		for _, account := range userAccounts {
+			valuations, err := tx.ListValuations(ctx, userID, models.ValuationFilter{AccountID: &account.ID})
+			if err != nil {
+				return err
+			}
+			if valuations == nil {
+				valuations = []models.Valuation{}
+			}
+			accounts = append(accounts, exportedAccount{Account: account, Valuations: valuations})
+		}
+
+		userDebts, err := tx.ListDebts(ctx, userID)
+		if err != nil {
+			return err
+		}
+		debts = make([]exportedDebt, 0, len(userDebts))
+		for _, debt := range userDebts {
+			payments, err := tx.ListDebtPayments(ctx, userID, debt.ID)
+			if err != nil {
+				return err
+			}
+			if payments == nil {
+				payments = []models.DebtPayment{}
+			}
+			debts = append(debts, exportedDebt{Debt: debt, Payments: payments})
+		}
+
+		userSecurities, err := tx.ListSecurities(ctx, userID)
+		if err != nil {
+			return err
+		}
+		securities = make([]exportedSecurity, 0, len(userSecurities))
+		for _, security := range userSecurities {
+			lots, err := tx.ListLots(ctx, userID, models.LotFilter{SecurityID: &security.ID})
+			if err != nil {
+				return err
+			}
+			if lots == nil {
+				lots = []models.Lot{}
+			}
+			prices, err := tx.ListPrices(ctx, userID, models.PriceFilter{SecurityID: &security.ID})
+			if err != nil {
+				return err
+			}
+			if prices == nil {
+				prices = []models.Price{}
+			}
+			securities = append(securities, exportedSecurity{Security: security, Lots: lots, Prices: prices})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		categories = []models.Category{}
+	}
+	if transactions == nil {
+		transactions = []models.Transaction{}
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	files := []struct {
+		name  string
+		write func(*bytes.Buffer) error
+	}{
+		{"profile.json", jsonFile(user)},
+		{"categories.json", jsonFile(categories)},
+		{"categories.csv", csvFile(categoryRecords(categories))},
+		{"transactions.json", jsonFile(transactions)},
+		{"transactions.csv", csvFile(transactionRecords(transactions, categories))},
+		{"goals.json", jsonFile(goals)},
+		{"accounts.json", jsonFile(accounts)},
+		{"debts.json", jsonFile(debts)},
+		{"securities.json", jsonFile(securities)},
+	}
+	for _, file := range files {
+		var content bytes.Buffer
+		if err := file.write(&content); err != nil {
+			return nil, err
+		}
+		f, err := w.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jsonFile(v any) func(*bytes.Buffer) error {
+	return func(buf *bytes.Buffer) error {
+		enc := json.NewEncoder(buf)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+func csvFile(records [][]string) func(*bytes.Buffer) error {
+	return func(buf *bytes.Buffer) error {
+		w := csv.NewWriter(buf)
+		if err := w.WriteAll(records); err != nil {
+			return err
+		}
+		return w.Error()
+	}
+}
+
+func categoryRecords(categories []models.Category) [][]string {
+	records := [][]string{{"id", "name", "created_at", "deleted_at"}}
+	for _, c := range categories {
+		records = append(records, []string{c.ID, csvText(c.Name), csvTime(&c.CreatedAt), csvTime(c.DeletedAt)})
+	}
+	return records
+}
+
+func transactionRecords(transactions []models.Transaction, categories []models.Category) [][]string {
+	// Trashed transactions, and those in a trashed category, are listed
+	// without their category's name.
+	names := make(map[string]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+
+	records := [][]string{{"id", "occurred_at", "amount", "category_id", "category_name", "description", "created_at", "deleted_at"}}
+	for _, t := range transactions {
+		var categoryID, categoryName, description string
+		if t.CategoryID != nil {
+			categoryID = *t.CategoryID
+			categoryName = names[categoryID]
+		}
+		if t.CategoryName != nil {
+			categoryName = *t.CategoryName
+		}
+		if t.Description != nil {
+			description = *t.Description
+		}
+		records = append(records, []string{
+			t.ID,
+			csvTime(&t.OccurredAt),
+			strconv.FormatFloat(t.Amount, 'f', 2, 64),
+			categoryID,
+			csvText(categoryName),
+			csvText(description),
+			csvTime(&t.CreatedAt),
+			csvTime(t.DeletedAt),
+		})
+	}
+	return records
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// csvText quotes text that a spreadsheet would take for a formula. The JSON
+// files have it as it was stored.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
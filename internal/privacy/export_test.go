@@ -0,0 +1,258 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/blob"
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func waitReady(t *testing.T, exporter *Exporter, userID, id string) *models.Export {
+	t.Helper()
+	var export *models.Export
+	require.Eventually(t, func() bool {
+		var err error
+		export, err = exporter.Get(context.Background(), userID, id)
+		require.NoError(t, err)
+		return export.Status != models.ExportPending
+	}, 5*time.Second, 10*time.Millisecond)
+	return export
+}
+
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestExporter(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "export@example.com")
+	require.NoError(t, err)
+	food, err := database.CreateCategory(ctx, user.ID, "=Food")
+	require.NoError(t, err)
+	description := "Lunch, with \"friends\""
+	_, err = database.CreateTransaction(ctx, user.ID, &food.ID, 12.5, &description, time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	trashed, err := database.CreateTransaction(ctx, user.ID, &food.ID, 20, nil, time.Date(2026, 3, 16, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NoError(t, database.DeleteTransaction(ctx, user.ID, trashed.ID))
+	goal, err := database.CreateGoal(ctx, user.ID, models.Goal{Name: "Laptop", TargetAmount: 1500})
+	require.NoError(t, err)
+	_, err = database.CreateGoalContribution(ctx, user.ID, goal.ID, models.GoalContribution{Amount: 100, ContributedAt: time.Now()})
+	require.NoError(t, err)
+	account, err := database.CreateAccount(ctx, user.ID, models.Account{Name: "Car", Type: models.AccountVehicle})
+	require.NoError(t, err)
+	_, err = database.CreateValuation(ctx, user.ID, account.ID, models.Valuation{Value: 8000, ValuedAt: time.Now()})
+	require.NoError(t, err)
+	debt, err := database.CreateDebt(ctx, user.ID, models.Debt{Name: "Car loan", Principal: 12000, TermMonths: 36, Payment: 333.33, FirstPaymentDate: time.Now()})
+	require.NoError(t, err)
+	_, err = database.CreateDebtPayment(ctx, user.ID, debt.ID, models.DebtPayment{Amount: 333.33, PaidAt: time.Now()})
+	require.NoError(t, err)
+	security, err := database.CreateSecurity(ctx, user.ID, models.Security{Symbol: "VWRL", Name: "Vanguard FTSE All-World"})
+	require.NoError(t, err)
+	_, err = database.CreateLot(ctx, user.ID, security.ID, models.Lot{Side: models.LotBuy, Quantity: 4, Price: 101.25, TradedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, database.SavePrices(ctx, user.ID, []models.Price{{SecurityID: security.ID, Price: 103.5, PricedAt: time.Now()}}))
+	other, err := database.CreateUser(ctx, "other@example.com")
+	require.NoError(t, err)
+
+	exporter := NewExporter(zerolog.Nop(), database, blob.NewMemoryStore(), ExportConfig{TTL: time.Hour})
+	t.Cleanup(exporter.Close)
+
+	started, err := exporter.Start(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, started.UserID)
+	assert.NotEmpty(t, started.DownloadToken)
+	assert.Equal(t, started.CreatedAt.Add(time.Hour), started.ExpiresAt)
+
+	export := waitReady(t, exporter, user.ID, started.ID)
+	assert.Equal(t, models.ExportReady, export.Status)
+	assert.NotNil(t, export.CompletedAt)
+	assert.Empty(t, export.DownloadToken, "the token is only returned once")
+
+	_, err = exporter.Get(ctx, other.ID, started.ID)
+	assert.ErrorIs(t, err, ErrExportNotFound)
+	_, _, err = exporter.Download(ctx, started.ID, "wrong")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+
+	archive, downloaded, err := exporter.Download(ctx, started.ID, started.DownloadToken)
+	require.NoError(t, err)
+	assert.Equal(t, started.ID, downloaded.ID)
+	data, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+	assert.Equal(t, int64(len(data)), archive.Size)
+	files := readZip(t, data)
+	assert.Len(t, files, 9)
+
+	var profile models.User
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, user.Email, profile.Email)
+
+	var transactions []models.Transaction
+	require.NoError(t, json.Unmarshal(files["transactions.json"], &transactions))
+	require.Len(t, transactions, 2, "including the trash")
+	assert.Equal(t, trashed.ID, transactions[1].ID)
+	assert.NotNil(t, transactions[1].DeletedAt)
+
+	records, err := csv.NewReader(bytes.NewReader(files["transactions.csv"])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"id", "occurred_at", "amount", "category_id", "category_name", "description", "created_at", "deleted_at"}, records[0])
+	assert.Equal(t, "2026-03-15T12:00:00Z", records[1][1])
+	assert.Equal(t, "12.50", records[1][2])
+	assert.Equal(t, "'=Food", records[1][4], "formulas are quoted")
+	assert.Equal(t, description, records[1][5])
+	assert.Empty(t, records[1][7])
+	assert.Equal(t, "'=Food", records[2][4], "trashed transactions keep their category's name")
+	assert.NotEmpty(t, records[2][7])
+
+	records, err = csv.NewReader(bytes.NewReader(files["categories.csv"])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, food.ID, records[1][0])
+
+	var goals []exportedGoal
+	require.NoError(t, json.Unmarshal(files["goals.json"], &goals))
+	require.Len(t, goals, 1)
+	assert.Equal(t, goal.ID, goals[0].ID)
+	require.Len(t, goals[0].Contributions, 1)
+	assert.Equal(t, 100.0, goals[0].Contributions[0].Amount)
+
+	var accounts []exportedAccount
+	require.NoError(t, json.Unmarshal(files["accounts.json"], &accounts))
+	require.Len(t, accounts, 1)
+	assert.Equal(t, account.ID, accounts[0].ID)
+	require.Len(t, accounts[0].Valuations, 1)
+	assert.Equal(t, 8000.0, accounts[0].Valuations[0].Value)
+
+	var debts []exportedDebt
+	require.NoError(t, json.Unmarshal(files["debts.json"], &debts))
+	require.Len(t, debts, 1)
+	assert.Equal(t, debt.ID, debts[0].ID)
+	require.Len(t, debts[0].Payments, 1)
+	assert.Equal(t, 333.33, debts[0].Payments[0].Amount)
+
+	var securities []exportedSecurity
+	require.NoError(t, json.Unmarshal(files["securities.json"], &securities))
+	require.Len(t, securities, 1)
+	assert.Equal(t, security.ID, securities[0].ID)
+	require.Len(t, securities[0].Lots, 1)
+	assert.Equal(t, 4.0, securities[0].Lots[0].Quantity)
+	require.Len(t, securities[0].Prices, 1)
+	assert.Equal(t, 103.5, securities[0].Prices[0].Price)
+}
+
+func TestExporter_UnknownUser(t *testing.T) {
+	exporter := NewExporter(zerolog.Nop(), db.NewMemoryDB(), blob.NewMemoryStore(), ExportConfig{TTL: time.Hour})
+	t.Cleanup(exporter.Close)
+
+	_, err := exporter.Start(context.Background(), "11111111-1111-1111-1111-111111111111")
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+}
+
+func TestExporter_Expires(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "export@example.com")
+	require.NoError(t, err)
+
+	store := blob.NewMemoryStore()
+	exporter := NewExporter(zerolog.Nop(), database, store, ExportConfig{TTL: time.Hour})
+	t.Cleanup(exporter.Close)
+
+	expired, err := exporter.Start(ctx, user.ID)
+	require.NoError(t, err)
+	waitReady(t, exporter, user.ID, expired.ID)
+	started, err := exporter.Start(ctx, user.ID)
+	require.NoError(t, err)
+	waitReady(t, exporter, user.ID, started.ID)
+	require.Equal(t, 4, store.Len())
+
+	exporter.wg.Wait()
+	exporter.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	_, err = exporter.Get(ctx, user.ID, started.ID)
+	assert.ErrorIs(t, err, ErrExportNotFound)
+	_, _, err = exporter.Download(ctx, started.ID, started.DownloadToken)
+	assert.ErrorIs(t, err, ErrExportNotFound)
+	assert.Equal(t, 2, store.Len(), "deleted from the store when read")
+
+	_, err = exporter.Start(ctx, user.ID)
+	require.NoError(t, err)
+	_, err = store.Get(ctx, recordKey(expired.ID))
+	assert.ErrorIs(t, err, blob.ErrNotFound, "swept when an export is started")
+	_, err = store.Get(ctx, archiveKey(expired.ID))
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+func TestExporter_SharedStore(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "export@example.com")
+	require.NoError(t, err)
+
+	store := blob.NewMemoryStore()
+	exporter := NewExporter(zerolog.Nop(), database, store, ExportConfig{TTL: time.Hour})
+	t.Cleanup(exporter.Close)
+	started, err := exporter.Start(ctx, user.ID)
+	require.NoError(t, err)
+	waitReady(t, exporter, user.ID, started.ID)
+
+	other := NewExporter(zerolog.Nop(), database, store, ExportConfig{TTL: time.Hour})
+	t.Cleanup(other.Close)
+	export, err := other.Get(ctx, user.ID, started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportReady, export.Status)
+	archive, _, err := other.Download(ctx, started.ID, started.DownloadToken)
+	require.NoError(t, err)
+	data, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+	assert.Contains(t, readZip(t, data), "profile.json")
+}
+
+func TestExporter_Discard(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "export@example.com")
+	require.NoError(t, err)
+
+	store := blob.NewMemoryStore()
+	exporter := NewExporter(zerolog.Nop(), database, store, ExportConfig{TTL: time.Hour})
+	t.Cleanup(exporter.Close)
+
+	started, err := exporter.Start(ctx, user.ID)
+	require.NoError(t, err)
+	require.NoError(t, database.DeleteUser(ctx, user.ID))
+	require.NoError(t, exporter.Discard(ctx, user.ID))
+
+	exporter.Close()
+	_, err = exporter.Get(ctx, user.ID, started.ID)
+	assert.ErrorIs(t, err, ErrExportNotFound, "not stored when it finishes")
+	assert.Zero(t, store.Len())
+}
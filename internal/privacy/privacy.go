@@ -0,0 +1,45 @@
+// Package privacy exports everything a user has stored and erases users on
+// request. Exports are kept in the blob store, which instances can share;
+// erasure requests are kept in memory, so they are only seen by the instance
+// that created them.
+package privacy
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+
+	"fintrack-go/internal/db"
+)
+
+// Defaults suited to most deployments.
+const (
+	DefaultExportTTL       = 24 * time.Hour
+	DefaultConfirmationTTL = 15 * time.Minute
+)
+
+var (
+	ErrExportNotFound = &db.Error{Kind: db.ErrNotFound, Code: "export_not_found", Message: "export not found"}
+	ErrExportNotReady = &db.Error{Kind: db.ErrConflict, Code: "export_not_ready", Message: "export is still being built"}
+	ErrExportFailed   = &db.Error{Kind: db.ErrConflict, Code: "export_failed", Message: "export could not be built"}
+	ErrInvalidErasure = &db.Error{Kind: db.ErrValidation, Code: "invalid_erasure_confirmation", Message: "erasure was not requested, has expired or was confirmed with another email address", Field: "email"}
+)
+
+// newToken returns a random token and the digest that is kept to check it,
+// so the tokens themselves are never stored.
+func newToken() (string, [sha256.Size]byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", [sha256.Size]byte{}, err
+	}
+	token := hex.EncodeToString(b)
+	return token, sha256.Sum256([]byte(token)), nil
+}
+
+// tokenMatches reports whether token has the given digest, in constant time.
+func tokenMatches(token string, digest [sha256.Size]byte) bool {
+	sum := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare(sum[:], digest[:]) == 1
+}
@@ -0,0 +1,29 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNewExporter_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := newExporter(context.Background(), ExporterStdout, &buf)
+	require.NoError(t, err)
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := provider.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	assert.Contains(t, buf.String(), `"Name":"test-span"`)
+}
+
+func TestNewExporter_Unknown(t *testing.T) {
+	_, err := newExporter(context.Background(), "jaeger", &bytes.Buffer{})
+	assert.ErrorContains(t, err, `unknown tracing exporter "jaeger"`)
+}
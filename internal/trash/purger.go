@@ -0,0 +1,71 @@
+// Package trash purges deleted categories and transactions once they have
+// been in the trash for the retention period.
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+)
+
+// Config controls when deleted rows are purged.
+type Config struct {
+	// Interval is how often Run purges.
+	Interval time.Duration
+	// Retention is how long rows stay in the trash. Zero keeps them until
+	// they are restored, and Run does nothing.
+	Retention time.Duration
+}
+
+// Purger permanently deletes the rows that have been in the trash for longer
+// than the retention period. Several purgers may share a database.
+type Purger struct {
+	logger zerolog.Logger
+	db     db.Database
+	config Config
+	now    func() time.Time
+}
+
+func NewPurger(logger zerolog.Logger, database db.Database, config Config) *Purger {
+	return &Purger{
+		logger: logger,
+		db:     database,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Run purges every Interval until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	if p.config.Retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.Purge(ctx)
+		if err != nil && ctx.Err() == nil {
+			p.logger.Error().Err(err).Msg("Failed to purge the trash")
+		}
+		if n > 0 {
+			p.logger.Info().Int("purged", n).Msg("Purged the trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge permanently deletes the rows deleted more than Retention ago and
+// returns how many there were.
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	return p.db.PurgeDeleted(ctx, p.now().Add(-p.config.Retention))
+}
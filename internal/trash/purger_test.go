@@ -0,0 +1,84 @@
+package trash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+)
+
+func TestPurger_Purge(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "trash@example.com")
+	require.NoError(t, err)
+	category, err := database.CreateCategory(ctx, user.ID, "Food")
+	require.NoError(t, err)
+	transaction, err := database.CreateTransaction(ctx, user.ID, nil, 10, nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, database.DeleteCategory(ctx, user.ID, category.ID))
+	require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+
+	purger := NewPurger(zerolog.Nop(), database, Config{Interval: time.Hour, Retention: 30 * 24 * time.Hour})
+
+	n, err := purger.Purge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "still within the retention period")
+
+	purger.now = func() time.Time { return time.Now().Add(31 * 24 * time.Hour) }
+	n, err = purger.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	trash, err := database.ListTrash(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, trash.Categories)
+	assert.Empty(t, trash.Transactions)
+	_, err = database.RestoreTransaction(ctx, user.ID, transaction.ID)
+	assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+}
+
+func TestPurger_Run(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemoryDB()
+	user, err := database.CreateUser(ctx, "run@example.com")
+	require.NoError(t, err)
+	transaction, err := database.CreateTransaction(ctx, user.ID, nil, 10, nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, database.DeleteTransaction(ctx, user.ID, transaction.ID))
+
+	trashed := func() []models.Transaction {
+		trash, err := database.ListTrash(ctx, user.ID)
+		require.NoError(t, err)
+		return trash.Transactions
+	}
+
+	t.Run("zero retention keeps the trash", func(t *testing.T) {
+		purger := NewPurger(zerolog.Nop(), database, Config{Interval: time.Millisecond})
+		purger.now = func() time.Time { return time.Now().Add(time.Hour) }
+		purger.Run(ctx)
+		assert.Len(t, trashed(), 1)
+	})
+
+	t.Run("purges until stopped", func(t *testing.T) {
+		purger := NewPurger(zerolog.Nop(), database, Config{Interval: 10 * time.Millisecond, Retention: time.Minute})
+		purger.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			purger.Run(runCtx)
+		}()
+
+		assert.Eventually(t, func() bool { return len(trashed()) == 0 }, 5*time.Second, 10*time.Millisecond)
+		stop()
+		<-done
+	})
+}
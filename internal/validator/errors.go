@@ -0,0 +1,99 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Rules name the check a field failed, for clients to match on.
+const (
+	RuleRequired     = "required"
+	RuleFormat       = "format"
+	RuleType         = "type"
+	RuleMin          = "min"
+	RuleMax          = "max"
+	RuleMaxLength    = "max_length"
+	RuleRange        = "range"
+	RuleUnknownField = "unknown_field"
+	RuleExclusive    = "exclusive"
+	RuleOneOf        = "one_of"
+	RuleMinLength    = "min_length"
+	RuleUnique       = "unique"
+)
+
+// RuleError is returned by the Validate functions: a message and the rule
+// that failed.
+type RuleError struct {
+	Rule    string
+	Message string
+}
+
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+func newRuleError(rule, format string, args ...any) error {
+	return &RuleError{Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
+// FieldError is a rule that one input field failed.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Value is the rejected value, left out where echoing it back isn't
+	// useful.
+	Value any `json:"value,omitempty"`
+}
+
+// Errors is every field error found in one request.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator collects the field errors of a request so they can be reported
+// together rather than one per attempt. The zero value is ready to use.
+type Validator struct {
+	errs Errors
+}
+
+// Check records err, the result of validating value, against field. A nil
+// err is ignored, so Validate calls can be passed straight in.
+func (v *Validator) Check(field string, value any, err error) {
+	if err == nil {
+		return
+	}
+
+	rule := RuleFormat
+	var ruleErr *RuleError
+	if errors.As(err, &ruleErr) {
+		rule = ruleErr.Rule
+	}
+	v.Add(field, rule, err.Error(), value)
+}
+
+// Add records a field error directly, for checks without a Validate
+// function.
+func (v *Validator) Add(field, rule, message string, value any) {
+	v.errs = append(v.errs, FieldError{Field: field, Rule: rule, Message: message, Value: value})
+}
+
+// Valid reports whether no field errors have been recorded.
+func (v *Validator) Valid() bool {
+	return len(v.errs) == 0
+}
+
+// Err returns the recorded field errors as Errors, or nil if there are none.
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator(t *testing.T) {
+	t.Run("zero value is valid", func(t *testing.T) {
+		var v Validator
+		assert.True(t, v.Valid())
+		assert.NoError(t, v.Err())
+	})
+
+	t.Run("ignores nil errors", func(t *testing.T) {
+		var v Validator
+		v.Check("email", "user@example.com", ValidateEmail("user@example.com"))
+		assert.True(t, v.Valid())
+	})
+
+	t.Run("collects every error in order", func(t *testing.T) {
+		var v Validator
+		v.Check("email", "bad", ValidateEmail("bad"))
+		v.Check("amount", -1.0, ValidateAmount(-1))
+		v.Check("name", "", ValidateCategoryName(""))
+		v.Add("extra", RuleUnknownField, "unknown field 'extra'", nil)
+
+		err := v.Err()
+		require.Error(t, err)
+
+		var errs Errors
+		require.True(t, errors.As(err, &errs))
+		assert.Equal(t, Errors{
+			{Field: "email", Rule: RuleFormat, Message: "invalid email format", Value: "bad"},
+			{Field: "amount", Rule: RuleMin, Message: "amount must be greater than 0, got -1.00", Value: -1.0},
+			{Field: "name", Rule: RuleRequired, Message: "category name is required", Value: ""},
+			{Field: "extra", Rule: RuleUnknownField, Message: "unknown field 'extra'"},
+		}, errs)
+		assert.Equal(t, "invalid email format; amount must be greater than 0, got -1.00; category name is required; unknown field 'extra'", err.Error())
+	})
+
+	t.Run("plain errors default to the format rule", func(t *testing.T) {
+		var v Validator
+		v.Check("field", nil, errors.New("broken"))
+
+		errs := v.Err().(Errors)
+		assert.Equal(t, RuleFormat, errs[0].Rule)
+	})
+}
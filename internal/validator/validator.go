@@ -1,11 +1,14 @@
 package validator
 
 import (
-	"errors"
-	"fmt"
+	"net"
+	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -13,52 +16,54 @@ import (
 var (
 	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	uuidRegex  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// symbolRegex matches ticker symbols such as BRK.B, VWRL.L or LON:VOD.
+	symbolRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.:_-]*$`)
 )
 
 func ValidateEmail(email string) error {
 	if email == "" {
-		return errors.New("email is required")
+		return newRuleError(RuleRequired, "email is required")
 	}
 	if !emailRegex.MatchString(email) {
-		return errors.New("invalid email format")
+		return newRuleError(RuleFormat, "invalid email format")
 	}
 	return nil
 }
 
 func ValidateUUID(id string) error {
 	if id == "" {
-		return errors.New("id is required")
+		return newRuleError(RuleRequired, "id is required")
 	}
 	if _, err := uuid.Parse(id); err != nil {
-		return errors.New("invalid UUID format")
+		return newRuleError(RuleFormat, "invalid UUID format")
 	}
 	// Ensure it's in the standard dashed format
 	if !uuidRegex.MatchString(id) {
-		return errors.New("invalid UUID format")
+		return newRuleError(RuleFormat, "invalid UUID format")
 	}
 	return nil
 }
 
 func ValidateAmount(amount float64) error {
 	if amount <= 0 {
-		return fmt.Errorf("amount must be greater than 0, got %.2f", amount)
+		return newRuleError(RuleMin, "amount must be greater than 0, got %.2f", amount)
 	}
 	if amount > 99999999.99 {
-		return fmt.Errorf("amount exceeds maximum value of 99999999.99, got %.2f", amount)
+		return newRuleError(RuleMax, "amount exceeds maximum value of 99999999.99, got %.2f", amount)
 	}
 	return nil
 }
 
 func ValidateCategoryName(name string) error {
 	if name == "" {
-		return errors.New("category name is required")
+		return newRuleError(RuleRequired, "category name is required")
 	}
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return errors.New("category name cannot be only whitespace")
+		return newRuleError(RuleRequired, "category name cannot be only whitespace")
 	}
 	if len(name) > 100 {
-		return fmt.Errorf("category name cannot exceed 100 characters, got %d", len(name))
+		return newRuleError(RuleMaxLength, "category name cannot exceed 100 characters, got %d", len(name))
 	}
 	return nil
 }
@@ -69,7 +74,7 @@ func ValidateDescription(desc *string) error {
 	}
 	trimmed := strings.TrimSpace(*desc)
 	if len(trimmed) > 1000 {
-		return fmt.Errorf("description cannot exceed 1000 characters, got %d", len(trimmed))
+		return newRuleError(RuleMaxLength, "description cannot exceed 1000 characters, got %d", len(trimmed))
 	}
 	return nil
 }
@@ -81,9 +86,279 @@ func ValidateDateRange(from, to *time.Time) error {
 	
 	if from != nil && to != nil {
 		if from.After(*to) {
-			return errors.New("'from' date must be before or equal to 'to' date")
+			return newRuleError(RuleRange, "'from' date must be before or equal to 'to' date")
 		}
 	}
 	
 	return nil
 }
+
+func ValidateAmountRange(min, max *float64) error {
+	if min != nil && *min < 0 {
+		return newRuleError(RuleMin, "min_amount cannot be negative, got %.2f", *min)
+	}
+	if max != nil && *max < 0 {
+		return newRuleError(RuleMin, "max_amount cannot be negative, got %.2f", *max)
+	}
+	if min != nil && max != nil && *min > *max {
+		return newRuleError(RuleRange, "'min_amount' must be less than or equal to 'max_amount'")
+	}
+	return nil
+}
+
+func ValidateDuplicateWindow(days int) error {
+	if days < 0 {
+		return newRuleError(RuleMin, "window_days cannot be negative, got %d", days)
+	}
+	if days > 30 {
+		return newRuleError(RuleMax, "window_days cannot exceed 30, got %d", days)
+	}
+	return nil
+}
+
+func ValidateWebhookURL(rawURL string) error {
+	if rawURL == "" {
+		return newRuleError(RuleRequired, "url is required")
+	}
+	if len(rawURL) > 2048 {
+		return newRuleError(RuleMaxLength, "url cannot exceed 2048 characters, got %d", len(rawURL))
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return newRuleError(RuleFormat, "url must be an absolute http or https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if i := strings.IndexByte(host, '%'); i >= 0 {
+		host = host[:i]
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return newRuleError(RuleFormat, "url cannot point to a local or private address")
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return newRuleError(RuleFormat, "url cannot point to a local or private address")
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which like the private
+// ranges is only reachable from within a provider's network.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether ip is an address webhooks may be sent to,
+// rather than a loopback, private, link-local, multicast or unspecified one
+// that would reach the server itself or the network it runs in.
+func IsPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+func ValidateWebhookSecret(secret string) error {
+	if secret == "" {
+		return newRuleError(RuleRequired, "secret is required")
+	}
+	if len(secret) < 16 {
+		return newRuleError(RuleMinLength, "secret must be at least 16 characters, got %d", len(secret))
+	}
+	if len(secret) > 256 {
+		return newRuleError(RuleMaxLength, "secret cannot exceed 256 characters, got %d", len(secret))
+	}
+	return nil
+}
+
+// ValidateOneOf checks that value, the named input, is one of allowed.
+func ValidateOneOf(name, value string, allowed []string) error {
+	if !slices.Contains(allowed, value) {
+		return newRuleError(RuleOneOf, "%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+	}
+	return nil
+}
+
+// ValidateLimit checks a page size against the most a listing returns.
+func ValidateLimit(limit, max int) error {
+	if limit < 1 {
+		return newRuleError(RuleMin, "limit must be at least 1, got %d", limit)
+	}
+	if limit > max {
+		return newRuleError(RuleMax, "limit cannot exceed %d, got %d", max, limit)
+	}
+	return nil
+}
+
+// ValidateEventTypes checks that eventTypes names at least one of known, and
+// nothing else, once.
+func ValidateEventTypes(eventTypes, known []string) error {
+	if len(eventTypes) == 0 {
+		return newRuleError(RuleRequired, "at least one event type is required")
+	}
+	for i, eventType := range eventTypes {
+		if !slices.Contains(known, eventType) {
+			return newRuleError(RuleOneOf, "unknown event type %q, expected one of %s", eventType, strings.Join(known, ", "))
+		}
+		if slices.Contains(eventTypes[:i], eventType) {
+			return newRuleError(RuleUnique, "event type %q is listed more than once", eventType)
+		}
+	}
+	return nil
+}
+
+// ValidateAttachmentFilename checks the name an uploaded file is stored and
+// downloaded under.
+func ValidateAttachmentFilename(name string) error {
+	if name == "" {
+		return newRuleError(RuleRequired, "filename is required")
+	}
+	if len(name) > 255 {
+		return newRuleError(RuleMaxLength, "filename cannot exceed 255 characters, got %d", len(name))
+	}
+	if !utf8.ValidString(name) || strings.ContainsFunc(name, unicode.IsControl) {
+		return newRuleError(RuleFormat, "filename must be valid UTF-8 without control characters")
+	}
+	return nil
+}
+
+func ValidateGoalName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return newRuleError(RuleRequired, "goal name is required")
+	}
+	if len(name) > 100 {
+		return newRuleError(RuleMaxLength, "goal name cannot exceed 100 characters, got %d", len(name))
+	}
+	return nil
+}
+
+func ValidateNote(note *string) error {
+	if note != nil && len(*note) > 1000 {
+		return newRuleError(RuleMaxLength, "note cannot exceed 1000 characters, got %d", len(*note))
+	}
+	return nil
+}
+
+// ValidateRateWindow checks the number of days a rate is averaged over.
+func ValidateRateWindow(days, max int) error {
+	if days < 1 {
+		return newRuleError(RuleMin, "window_days must be at least 1, got %d", days)
+	}
+	if days > max {
+		return newRuleError(RuleMax, "window_days cannot exceed %d, got %d", max, days)
+	}
+	return nil
+}
+
+func ValidateAccountName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return newRuleError(RuleRequired, "account name is required")
+	}
+	if len(name) > 100 {
+		return newRuleError(RuleMaxLength, "account name cannot exceed 100 characters, got %d", len(name))
+	}
+	return nil
+}
+
+// ValidateValue checks an account valuation, which unlike an amount may be
+// zero, such as a paid-off credit card.
+func ValidateValue(value float64) error {
+	if value < 0 {
+		return newRuleError(RuleMin, "value cannot be negative, got %.2f", value)
+	}
+	if value > 99999999.99 {
+		return newRuleError(RuleMax, "value exceeds maximum value of 99999999.99, got %.2f", value)
+	}
+	return nil
+}
+
+func ValidateDebtName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return newRuleError(RuleRequired, "debt name is required")
+	}
+	if len(name) > 100 {
+		return newRuleError(RuleMaxLength, "debt name cannot exceed 100 characters, got %d", len(name))
+	}
+	return nil
+}
+
+// ValidateRate checks an annual interest rate in percent. An interest-free
+// loan has a rate of zero.
+func ValidateRate(rate float64) error {
+	if rate < 0 {
+		return newRuleError(RuleMin, "rate cannot be negative, got %.3f", rate)
+	}
+	if rate >= 100 {
+		return newRuleError(RuleMax, "rate must be below 100, got %.3f", rate)
+	}
+	return nil
+}
+
+// ValidateTerm checks the number of monthly installments of a loan, at most
+// 50 years of them.
+func ValidateTerm(months int) error {
+	if months < 1 {
+		return newRuleError(RuleMin, "term must be at least 1 month, got %d", months)
+	}
+	if months > 600 {
+		return newRuleError(RuleMax, "term cannot exceed 600 months, got %d", months)
+	}
+	return nil
+}
+
+// ValidateSymbol checks a security's ticker symbol, which price files refer
+// to it by.
+func ValidateSymbol(symbol string) error {
+	if symbol == "" {
+		return newRuleError(RuleRequired, "symbol is required")
+	}
+	if len(symbol) > 20 {
+		return newRuleError(RuleMaxLength, "symbol cannot exceed 20 characters, got %d", len(symbol))
+	}
+	if !symbolRegex.MatchString(symbol) {
+		return newRuleError(RuleFormat, "symbol may only contain letters, digits and . : _ -")
+	}
+	return nil
+}
+
+func ValidateSecurityName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return newRuleError(RuleRequired, "security name is required")
+	}
+	if len(name) > 100 {
+		return newRuleError(RuleMaxLength, "security name cannot exceed 100 characters, got %d", len(name))
+	}
+	return nil
+}
+
+// ValidateQuantity checks the units of a security bought or sold, which
+// may be fractional.
+func ValidateQuantity(quantity float64) error {
+	if quantity <= 0 {
+		return newRuleError(RuleMin, "quantity must be greater than 0, got %g", quantity)
+	}
+	if quantity >= 1e12 {
+		return newRuleError(RuleMax, "quantity must be below 1000000000000, got %g", quantity)
+	}
+	return nil
+}
+
+// ValidatePrice checks a security's price per unit. Units given away, such
+// as bonus shares, are bought at a price of zero.
+func ValidatePrice(price float64) error {
+	if price < 0 {
+		return newRuleError(RuleMin, "price cannot be negative, got %.4f", price)
+	}
+	if price > 99999999.9999 {
+		return newRuleError(RuleMax, "price exceeds maximum value of 99999999.9999, got %.4f", price)
+	}
+	return nil
+}
+
+func ValidateFees(fees float64) error {
+	if fees < 0 {
+		return newRuleError(RuleMin, "fees cannot be negative, got %.2f", fees)
+	}
+	if fees > 99999999.99 {
+		return newRuleError(RuleMax, "fees exceed maximum value of 99999999.99, got %.2f", fees)
+	}
+	return nil
+}
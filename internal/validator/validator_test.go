@@ -1,10 +1,12 @@
 package validator
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidateEmail(t *testing.T) {
@@ -297,3 +299,428 @@ func TestValidateDateRange(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAmountRange(t *testing.T) {
+	low := 10.0
+	high := 100.0
+	negative := -5.0
+
+	tests := []struct {
+		name    string
+		min     *float64
+		max     *float64
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "both nil",
+			wantErr: false,
+		},
+		{
+			name:    "valid range",
+			min:     &low,
+			max:     &high,
+			wantErr: false,
+		},
+		{
+			name:    "equal bounds",
+			min:     &low,
+			max:     &low,
+			wantErr: false,
+		},
+		{
+			name:    "min only",
+			min:     &low,
+			wantErr: false,
+		},
+		{
+			name:    "negative min",
+			min:     &negative,
+			wantErr: true,
+			errMsg:  "min_amount cannot be negative",
+		},
+		{
+			name:    "negative max",
+			max:     &negative,
+			wantErr: true,
+			errMsg:  "max_amount cannot be negative",
+		},
+		{
+			name:    "min greater than max",
+			min:     &high,
+			max:     &low,
+			wantErr: true,
+			errMsg:  "'min_amount' must be less than or equal to 'max_amount'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAmountRange(tt.min, tt.max)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateDuplicateWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		days    int
+		wantErr bool
+		errMsg  string
+	}{
+		{name: "same day", days: 0, wantErr: false},
+		{name: "default window", days: 3, wantErr: false},
+		{name: "maximum window", days: 30, wantErr: false},
+		{name: "negative", days: -1, wantErr: true, errMsg: "window_days cannot be negative"},
+		{name: "too large", days: 31, wantErr: true, errMsg: "window_days cannot exceed 30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDuplicateWindow(tt.days)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+		errMsg  string
+	}{
+		{name: "https", url: "https://example.com/hooks", wantErr: false},
+		{name: "http with port", url: "http://hooks.example.com:9000/hooks?x=1", wantErr: false},
+		{name: "public address", url: "https://93.184.216.34/hooks", wantErr: false},
+		{name: "localhost", url: "http://localhost:9000/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "localhost subdomain", url: "http://api.localhost./hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "loopback", url: "http://127.0.0.2/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "IPv6 loopback", url: "http://[::1]:8080/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "private", url: "https://10.1.2.3/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "IPv4-mapped private", url: "https://[::ffff:192.168.0.1]/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", wantErr: true, errMsg: "local or private address"},
+		{name: "link-local with zone", url: "http://[fe80::1%25eth0]/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "unspecified", url: "http://0.0.0.0/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "shared address space", url: "http://100.64.0.1/hooks", wantErr: true, errMsg: "local or private address"},
+		{name: "empty", url: "", wantErr: true, errMsg: "url is required"},
+		{name: "relative", url: "/hooks", wantErr: true, errMsg: "absolute http or https URL"},
+		{name: "other scheme", url: "ftp://example.com/hooks", wantErr: true, errMsg: "absolute http or https URL"},
+		{name: "no host", url: "https:///hooks", wantErr: true, errMsg: "absolute http or https URL"},
+		{name: "too long", url: "https://example.com/" + strings.Repeat("a", 2048), wantErr: true, errMsg: "cannot exceed 2048 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhookURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+		errMsg  string
+	}{
+		{name: "minimum length", secret: strings.Repeat("s", 16), wantErr: false},
+		{name: "maximum length", secret: strings.Repeat("s", 256), wantErr: false},
+		{name: "empty", secret: "", wantErr: true, errMsg: "secret is required"},
+		{name: "too short", secret: "short", wantErr: true, errMsg: "at least 16 characters"},
+		{name: "too long", secret: strings.Repeat("s", 257), wantErr: true, errMsg: "cannot exceed 256 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhookSecret(tt.secret)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateEventTypes(t *testing.T) {
+	known := []string{"a.created", "b.created"}
+	tests := []struct {
+		name    string
+		types   []string
+		wantErr bool
+		rule    string
+	}{
+		{name: "one", types: []string{"a.created"}, wantErr: false},
+		{name: "all", types: []string{"b.created", "a.created"}, wantErr: false},
+		{name: "none", types: nil, wantErr: true, rule: RuleRequired},
+		{name: "unknown", types: []string{"a.created", "c.created"}, wantErr: true, rule: RuleOneOf},
+		{name: "duplicate", types: []string{"a.created", "a.created"}, wantErr: true, rule: RuleUnique},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEventTypes(tt.types, known)
+			if tt.wantErr {
+				var ruleErr *RuleError
+				require.ErrorAs(t, err, &ruleErr)
+				assert.Equal(t, tt.rule, ruleErr.Rule)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateOneOf(t *testing.T) {
+	allowed := []string{"create", "update", "delete"}
+
+	assert.NoError(t, ValidateOneOf("action", "update", allowed))
+
+	err := ValidateOneOf("action", "rename", allowed)
+	var ruleErr *RuleError
+	require.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, RuleOneOf, ruleErr.Rule)
+	assert.Contains(t, err.Error(), "create, update, delete")
+}
+
+func TestValidateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr bool
+		rule    string
+	}{
+		{name: "smallest", limit: 1, wantErr: false},
+		{name: "largest", limit: 500, wantErr: false},
+		{name: "zero", limit: 0, wantErr: true, rule: RuleMin},
+		{name: "too many", limit: 501, wantErr: true, rule: RuleMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLimit(tt.limit, 500)
+			if tt.wantErr {
+				var ruleErr *RuleError
+				require.ErrorAs(t, err, &ruleErr)
+				assert.Equal(t, tt.rule, ruleErr.Rule)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAttachmentFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		wantErr  bool
+		rule     string
+	}{
+		{name: "valid", filename: "receipt 2026-01.pdf", wantErr: false},
+		{name: "unicode", filename: "reçu.jpg", wantErr: false},
+		{name: "maximum length", filename: strings.Repeat("a", 255), wantErr: false},
+		{name: "empty", filename: "", wantErr: true, rule: RuleRequired},
+		{name: "too long", filename: strings.Repeat("a", 256), wantErr: true, rule: RuleMaxLength},
+		{name: "control character", filename: "receipt\n.pdf", wantErr: true, rule: RuleFormat},
+		{name: "invalid UTF-8", filename: "receipt\xff.pdf", wantErr: true, rule: RuleFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAttachmentFilename(tt.filename)
+			if tt.wantErr {
+				var ruleErr *RuleError
+				require.ErrorAs(t, err, &ruleErr)
+				assert.Equal(t, tt.rule, ruleErr.Rule)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateGoalName(t *testing.T) {
+	assert.NoError(t, ValidateGoalName("Emergency fund"))
+	assert.NoError(t, ValidateGoalName(strings.Repeat("a", 100)))
+
+	for name, rule := range map[string]string{
+		"":                       RuleRequired,
+		"   ":                    RuleRequired,
+		strings.Repeat("a", 101): RuleMaxLength,
+	} {
+		var ruleErr *RuleError
+		require.ErrorAs(t, ValidateGoalName(name), &ruleErr, name)
+		assert.Equal(t, rule, ruleErr.Rule)
+	}
+}
+
+func TestValidateNote(t *testing.T) {
+	note := strings.Repeat("a", 1000)
+	assert.NoError(t, ValidateNote(nil))
+	assert.NoError(t, ValidateNote(&note))
+
+	note += "a"
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidateNote(&note), &ruleErr)
+	assert.Equal(t, RuleMaxLength, ruleErr.Rule)
+}
+
+func TestValidateRateWindow(t *testing.T) {
+	assert.NoError(t, ValidateRateWindow(1, 365))
+	assert.NoError(t, ValidateRateWindow(365, 365))
+
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidateRateWindow(0, 365), &ruleErr)
+	assert.Equal(t, RuleMin, ruleErr.Rule)
+	require.ErrorAs(t, ValidateRateWindow(366, 365), &ruleErr)
+	assert.Equal(t, RuleMax, ruleErr.Rule)
+}
+
+func TestValidateAccountName(t *testing.T) {
+	assert.NoError(t, ValidateAccountName("Checking"))
+	assert.NoError(t, ValidateAccountName(strings.Repeat("a", 100)))
+
+	for name, rule := range map[string]string{
+		"":                       RuleRequired,
+		"   ":                    RuleRequired,
+		strings.Repeat("a", 101): RuleMaxLength,
+	} {
+		var ruleErr *RuleError
+		require.ErrorAs(t, ValidateAccountName(name), &ruleErr, name)
+		assert.Equal(t, rule, ruleErr.Rule)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	assert.NoError(t, ValidateValue(0))
+	assert.NoError(t, ValidateValue(99999999.99))
+
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidateValue(-0.01), &ruleErr)
+	assert.Equal(t, RuleMin, ruleErr.Rule)
+	require.ErrorAs(t, ValidateValue(100000000), &ruleErr)
+	assert.Equal(t, RuleMax, ruleErr.Rule)
+}
+
+func TestValidateDebtName(t *testing.T) {
+	assert.NoError(t, ValidateDebtName("Mortgage"))
+	assert.NoError(t, ValidateDebtName(strings.Repeat("a", 100)))
+
+	for name, rule := range map[string]string{
+		"":                       RuleRequired,
+		"   ":                    RuleRequired,
+		strings.Repeat("a", 101): RuleMaxLength,
+	} {
+		var ruleErr *RuleError
+		require.ErrorAs(t, ValidateDebtName(name), &ruleErr, name)
+		assert.Equal(t, rule, ruleErr.Rule)
+	}
+}
+
+func TestValidateRate(t *testing.T) {
+	assert.NoError(t, ValidateRate(0))
+	assert.NoError(t, ValidateRate(99.999))
+
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidateRate(-0.5), &ruleErr)
+	assert.Equal(t, RuleMin, ruleErr.Rule)
+	require.ErrorAs(t, ValidateRate(100), &ruleErr)
+	assert.Equal(t, RuleMax, ruleErr.Rule)
+}
+
+func TestValidateTerm(t *testing.T) {
+	assert.NoError(t, ValidateTerm(1))
+	assert.NoError(t, ValidateTerm(600))
+
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidateTerm(0), &ruleErr)
+	assert.Equal(t, RuleMin, ruleErr.Rule)
+	require.ErrorAs(t, ValidateTerm(601), &ruleErr)
+	assert.Equal(t, RuleMax, ruleErr.Rule)
+}
+
+func TestValidateSymbol(t *testing.T) {
+	for _, symbol := range []string{"ACME", "BRK.B", "VWRL.L", "LON:VOD", "BTC-USD", strings.Repeat("A", 20)} {
+		assert.NoError(t, ValidateSymbol(symbol), symbol)
+	}
+
+	for symbol, rule := range map[string]string{
+		"":                      RuleRequired,
+		strings.Repeat("A", 21): RuleMaxLength,
+		"AC ME":                 RuleFormat,
+		"ACME,INC":              RuleFormat,
+		".ACME":                 RuleFormat,
+	} {
+		var ruleErr *RuleError
+		require.ErrorAs(t, ValidateSymbol(symbol), &ruleErr, symbol)
+		assert.Equal(t, rule, ruleErr.Rule, symbol)
+	}
+}
+
+func TestValidateSecurityName(t *testing.T) {
+	assert.NoError(t, ValidateSecurityName("Acme Corp."))
+	assert.NoError(t, ValidateSecurityName(strings.Repeat("a", 100)))
+
+	for name, rule := range map[string]string{
+		"":                       RuleRequired,
+		"   ":                    RuleRequired,
+		strings.Repeat("a", 101): RuleMaxLength,
+	} {
+		var ruleErr *RuleError
+		require.ErrorAs(t, ValidateSecurityName(name), &ruleErr, name)
+		assert.Equal(t, rule, ruleErr.Rule)
+	}
+}
+
+func TestValidateQuantity(t *testing.T) {
+	assert.NoError(t, ValidateQuantity(0.000001))
+	assert.NoError(t, ValidateQuantity(999999999999.999))
+
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidateQuantity(0), &ruleErr)
+	assert.Equal(t, RuleMin, ruleErr.Rule)
+	require.ErrorAs(t, ValidateQuantity(1e12), &ruleErr)
+	assert.Equal(t, RuleMax, ruleErr.Rule)
+}
+
+func TestValidatePrice(t *testing.T) {
+	assert.NoError(t, ValidatePrice(0))
+	assert.NoError(t, ValidatePrice(99999999.9999))
+
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidatePrice(-0.0001), &ruleErr)
+	assert.Equal(t, RuleMin, ruleErr.Rule)
+	require.ErrorAs(t, ValidatePrice(100000000), &ruleErr)
+	assert.Equal(t, RuleMax, ruleErr.Rule)
+}
+
+func TestValidateFees(t *testing.T) {
+	assert.NoError(t, ValidateFees(0))
+	assert.NoError(t, ValidateFees(99999999.99))
+
+	var ruleErr *RuleError
+	require.ErrorAs(t, ValidateFees(-0.01), &ruleErr)
+	assert.Equal(t, RuleMin, ruleErr.Rule)
+	require.ErrorAs(t, ValidateFees(100000000), &ruleErr)
+	assert.Equal(t, RuleMax, ruleErr.Rule)
+}
@@ -0,0 +1,227 @@
+// Package webhook delivers the events queued in the database to the
+// subscriptions' URLs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+// DefaultBatchSize is a BatchSize suited to most deployments.
+const DefaultBatchSize = 20
+
+// leaseMargin is how much longer than a request's timeout a claimed
+// delivery is kept from other dispatchers, to cover recording the attempt.
+const leaseMargin = 30 * time.Second
+
+// Config controls how deliveries are sent and retried.
+type Config struct {
+	// PollInterval is how often Run looks for due deliveries.
+	PollInterval time.Duration
+	// BatchSize is how many deliveries are claimed, and sent concurrently,
+	// at once.
+	BatchSize int
+	// Timeout bounds each request, including reading the response.
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is tried before it fails.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed attempt. It doubles
+	// after each further one, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Dispatcher sends due deliveries and records the outcome of each attempt.
+// Several dispatchers may share a database; a delivery is claimed by one at
+// a time.
+type Dispatcher struct {
+	logger zerolog.Logger
+	db     db.Database
+	client *http.Client
+	config Config
+	now    func() time.Time
+	// permitted reports whether deliveries may connect to an address.
+	permitted func(net.IP) bool
+}
+
+func NewDispatcher(logger zerolog.Logger, database db.Database, config Config) *Dispatcher {
+	d := &Dispatcher{
+		logger:    logger,
+		db:        database,
+		config:    config,
+		now:       time.Now,
+		permitted: validator.IsPublicIP,
+	}
+
+	// A subscription's URL is checked when it is created, but its host may
+	// since resolve to another address, so the address of every connection
+	// is checked as it is dialled.
+	dialer := &net.Dialer{
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !d.permitted(ip) {
+				return fmt.Errorf("%s is not a public address", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Deliveries connect directly, so the address checked is the receiver's.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	d.client = &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		// A redirect is a response like any other non-2xx one; following
+		// it would post the event somewhere the user did not subscribe.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return d
+}
+
+// Run delivers due deliveries every PollInterval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going while full batches are claimed, so a backlog drains
+		// without waiting for the next tick.
+		for {
+			n, err := d.DeliverDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				d.logger.Error().Err(err).Msg("Failed to claim webhook deliveries")
+			}
+			if err != nil || n < d.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims a batch of due deliveries, sends them and records the
+// outcomes. It returns how many deliveries it claimed.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	webhooks, err := d.db.ClaimWebhookDeliveries(ctx, d.now(), d.config.Timeout+leaseMargin, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, webhook := range webhooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, webhook)
+		}()
+	}
+	wg.Wait()
+
+	return len(webhooks), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, webhook models.OutgoingWebhook) {
+	delivery := webhook.Delivery
+	logger := d.logger.With().
+		Str("delivery_id", delivery.ID).
+		Str("subscription_id", delivery.SubscriptionID).
+		Str("event_type", delivery.EventType).
+		Logger()
+
+	attempt := d.send(ctx, webhook)
+	if ctx.Err() != nil {
+		// Shutting down: the attempt was cut short rather than failed. The
+		// delivery is tried again once its lease runs out.
+		return
+	}
+
+	if !attempt.Delivered {
+		if n := delivery.Attempts + 1; n < d.config.MaxAttempts {
+			next := attempt.At.Add(d.backoff(n))
+			attempt.NextAttemptAt = &next
+		}
+		logger.Warn().
+			Int("attempt", delivery.Attempts+1).
+			Str("error", *attempt.Error).
+			Bool("retrying", attempt.NextAttemptAt != nil).
+			Msg("Webhook delivery failed")
+	}
+
+	if err := d.db.RecordWebhookAttempt(ctx, delivery.ID, attempt); err != nil {
+		logger.Error().Err(err).Msg("Failed to record webhook attempt")
+	}
+}
+
+// send posts the delivery's payload to the subscription's URL.
+func (d *Dispatcher) send(ctx context.Context, webhook models.OutgoingWebhook) models.WebhookAttempt {
+	delivery := webhook.Delivery
+	attempt := models.WebhookAttempt{At: d.now()}
+	fail := func(format string, args ...any) models.WebhookAttempt {
+		msg := fmt.Sprintf(format, args...)
+		attempt.Error = &msg
+		return attempt
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fail("invalid request: %v", err)
+	}
+	timestamp := attempt.At.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fintrack-webhooks")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fail("%v", err)
+	}
+	defer resp.Body.Close()
+	// Read some of the body so the connection can be reused; the receiver
+	// has nothing to tell us beyond the status.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.ResponseStatus = &resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fail("receiver responded with %d", resp.StatusCode)
+	}
+	attempt.Delivered = true
+	return attempt
+}
+
+// backoff returns how long to wait after the nth failed attempt.
+func (d *Dispatcher) backoff(n int) time.Duration {
+	wait := d.config.InitialBackoff
+	for i := 1; i < n && wait < d.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.config.MaxBackoff)
+}
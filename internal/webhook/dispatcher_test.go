@@ -0,0 +1,254 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fintrack-go/internal/db"
+	"fintrack-go/internal/models"
+	"fintrack-go/internal/validator"
+)
+
+const secret = "0123456789abcdef-secret"
+
+// receiver records the requests posted to it and answers with status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+type fixture struct {
+	db           db.Database
+	dispatcher   *Dispatcher
+	receiver     *receiver
+	user         *models.User
+	subscription *models.WebhookSubscription
+	clock        time.Time
+}
+
+func newFixture(t *testing.T, status int) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	f := &fixture{
+		db:       db.NewMemoryDB(),
+		receiver: &receiver{status: status},
+	}
+	server := httptest.NewServer(f.receiver)
+	t.Cleanup(server.Close)
+
+	var err error
+	f.user, err = f.db.CreateUser(ctx, "hooks@example.com")
+	require.NoError(t, err)
+	f.subscription, err = f.db.CreateWebhookSubscription(ctx, f.user.ID, server.URL+"/hooks", secret,
+		[]string{models.EventCategoryCreated})
+	require.NoError(t, err)
+
+	f.dispatcher = NewDispatcher(zerolog.Nop(), f.db, Config{
+		PollInterval:   10 * time.Millisecond,
+		BatchSize:      10,
+		Timeout:        5 * time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     90 * time.Second,
+	})
+	f.dispatcher.now = func() time.Time { return f.clock }
+	// The receiver listens on the loopback address.
+	f.dispatcher.permitted = func(net.IP) bool { return true }
+	return f
+}
+
+// createCategory queues a category.created event, and sets the clock to
+// when it is due. The clock keeps to microseconds, as the database does.
+func (f *fixture) createCategory(t *testing.T) *models.Category {
+	t.Helper()
+	category, err := f.db.CreateCategory(context.Background(), f.user.ID, "Groceries")
+	require.NoError(t, err)
+	f.clock = time.Now().Round(time.Microsecond)
+	return category
+}
+
+func (f *fixture) deliveries(t *testing.T) []models.WebhookDelivery {
+	t.Helper()
+	deliveries, err := f.db.ListWebhookDeliveries(context.Background(), f.user.ID, f.subscription.ID)
+	require.NoError(t, err)
+	return deliveries
+}
+
+func TestDispatcher_RefusesPrivateAddresses(t *testing.T) {
+	f := newFixture(t, http.StatusNoContent)
+	f.dispatcher.permitted = validator.IsPublicIP
+	f.createCategory(t)
+
+	n, err := f.dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Zero(t, f.receiver.count(), "nothing is sent to the loopback address")
+
+	deliveries := f.deliveries(t)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.DeliveryPending, deliveries[0].Status, "retried in case the host resolves elsewhere later")
+	require.NotNil(t, deliveries[0].LastError)
+	assert.Contains(t, *deliveries[0].LastError, "127.0.0.1 is not a public address")
+}
+
+func TestDispatcher_DeliversSignedEvent(t *testing.T) {
+	f := newFixture(t, http.StatusNoContent)
+	ctx := context.Background()
+
+	category := f.createCategory(t)
+
+	n, err := f.dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Equal(t, 1, f.receiver.count())
+
+	req, body := f.receiver.requests[0], f.receiver.bodies[0]
+	assert.Equal(t, "/hooks", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, models.EventCategoryCreated, req.Header.Get(HeaderEvent))
+	assert.True(t, Verify(secret, req.Header.Get(HeaderTimestamp), body, req.Header.Get(HeaderSignature)))
+	assert.False(t, Verify("another-secret-entirely", req.Header.Get(HeaderTimestamp), body, req.Header.Get(HeaderSignature)))
+
+	var event struct {
+		ID   string          `json:"id"`
+		Type string          `json:"type"`
+		Data models.Category `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, models.EventCategoryCreated, event.Type)
+	assert.Equal(t, category.ID, event.Data.ID)
+
+	deliveries := f.deliveries(t)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, req.Header.Get(HeaderDelivery), deliveries[0].ID)
+	assert.Equal(t, event.ID, deliveries[0].EventID)
+	assert.Equal(t, models.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	require.NotNil(t, deliveries[0].ResponseStatus)
+	assert.Equal(t, http.StatusNoContent, *deliveries[0].ResponseStatus)
+	assert.Nil(t, deliveries[0].NextAttemptAt)
+
+	n, err = f.dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "a delivered event is not sent again")
+}
+
+func TestDispatcher_RetriesWithBackoffThenFails(t *testing.T) {
+	f := newFixture(t, http.StatusInternalServerError)
+	ctx := context.Background()
+
+	f.createCategory(t)
+
+	// Backoff doubles from a minute, capped at 90s.
+	for attempt, wait := range []time.Duration{time.Minute, 90 * time.Second} {
+		n, err := f.dispatcher.DeliverDue(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		delivery := f.deliveries(t)[0]
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Equal(t, attempt+1, delivery.Attempts)
+		require.NotNil(t, delivery.LastError)
+		assert.Contains(t, *delivery.LastError, "500")
+		require.NotNil(t, delivery.NextAttemptAt)
+		assert.WithinDuration(t, f.clock.Add(wait), *delivery.NextAttemptAt, time.Millisecond)
+
+		n, err = f.dispatcher.DeliverDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "not due before the backoff")
+
+		f.clock = f.clock.Add(wait)
+	}
+
+	n, err := f.dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	delivery := f.deliveries(t)[0]
+	assert.Equal(t, models.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Equal(t, 3, f.receiver.count())
+
+	f.clock = f.clock.Add(24 * time.Hour)
+	n, err = f.dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "a failed delivery is not retried")
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	f := newFixture(t, http.StatusFound)
+	ctx := context.Background()
+
+	f.createCategory(t)
+
+	_, err := f.dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	delivery := f.deliveries(t)[0]
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	require.NotNil(t, delivery.ResponseStatus)
+	assert.Equal(t, http.StatusFound, *delivery.ResponseStatus)
+}
+
+func TestDispatcher_RunStopsWithContext(t *testing.T) {
+	f := newFixture(t, http.StatusOK)
+	f.dispatcher.now = time.Now
+
+	f.createCategory(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		f.dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return f.receiver.count() == 1 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after its context was cancelled")
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := Sign(secret, 1700000000, body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.True(t, Verify(secret, "1700000000", body, signature))
+	assert.False(t, Verify(secret, "1700000001", body, signature), "timestamp is signed")
+	assert.False(t, Verify(secret, "1700000000", []byte(`{"id":"2"}`), signature), "body is signed")
+	assert.False(t, Verify(secret, "not-a-number", body, signature))
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Fintrack-Event"
+	HeaderDelivery  = "X-Fintrack-Delivery"
+	HeaderTimestamp = "X-Fintrack-Timestamp"
+	HeaderSignature = "X-Fintrack-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the signature header of a delivery of body sent at timestamp,
+// in Unix seconds: "sha256=" and the hex HMAC-SHA256, keyed by the
+// subscription's secret, of the timestamp, a dot and the body. Signing the
+// timestamp lets receivers reject replays of old deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body sent at
+// timestamp, as found in the delivery's headers.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transactions_category;
+DROP INDEX IF EXISTS idx_transactions_user_date;
+DROP INDEX IF EXISTS idx_transactions_occurred_at;
+DROP INDEX IF EXISTS idx_transactions_user_id;
+DROP INDEX IF EXISTS idx_categories_user_id;
+DROP INDEX IF EXISTS idx_users_email;
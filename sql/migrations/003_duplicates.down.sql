@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_user_amount;
+DROP TABLE IF EXISTS dismissed_duplicates;
@@ -0,0 +1,11 @@
+-- Changes to each user's ledger, in the order they were committed, for
+-- clients following them live. See publishEvent for how the order is kept.
+CREATE TABLE events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_events_user_id ON events(user_id, id);
@@ -0,0 +1,29 @@
+-- Every change made to a user's data, who made it and what it looked like
+-- before and after. Rows are only ever added: there is no foreign key to
+-- users, so the log outlives what it describes, and the trigger below
+-- rejects updates and deletes.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_user_id ON audit_log(user_id, id);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,16 @@
+-- Erasing a user redacts their audit entries: the snapshots and request id
+-- are cleared and the actor replaced with 'redacted'. That is the only
+-- update the audit log allows, and rows still can't be deleted.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.before IS NULL AND NEW.after IS NULL AND NEW.request_id IS NULL
+        AND NEW.actor = 'redacted'
+        AND (NEW.id, NEW.user_id, NEW.entity, NEW.entity_id, NEW.action, NEW.created_at)
+            = (OLD.id, OLD.user_id, OLD.entity, OLD.entity_id, OLD.action, OLD.created_at)
+    THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,33 @@
+-- Files such as receipts and invoices kept with a transaction. The content
+-- is in the blob store under storage_key.
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    sha256 TEXT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_attachments_transaction ON attachments(transaction_id, created_at);
+
+-- The blobs of deleted attachments, however they were deleted, until they
+-- are removed from the blob store.
+CREATE TABLE orphaned_blobs (
+    storage_key TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE FUNCTION attachments_orphan_blob() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO orphaned_blobs (storage_key) VALUES (OLD.storage_key) ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachments_orphan_blob
+    AFTER DELETE ON attachments
+    FOR EACH ROW EXECUTE FUNCTION attachments_orphan_blob();
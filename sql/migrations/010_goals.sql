@@ -0,0 +1,27 @@
+-- Amounts a user is saving toward, optionally by a date.
+CREATE TABLE goals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    target_amount DECIMAL(10, 2) NOT NULL CHECK (target_amount > 0),
+    target_date TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_goals_user_id ON goals(user_id, created_at);
+
+-- Money put toward a goal, either entered by hand or taken from one of the
+-- user's transactions, whose amount and date it copies. A transaction
+-- contributes to one goal at most.
+CREATE TABLE goal_contributions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    note TEXT,
+    contributed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_goal_contributions_goal ON goal_contributions(goal_id, contributed_at);
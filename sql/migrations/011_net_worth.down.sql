@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS valuations;
+DROP TABLE IF EXISTS accounts;
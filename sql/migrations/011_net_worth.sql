@@ -0,0 +1,28 @@
+-- What a user owns or owes: bank accounts, property and vehicles, and the
+-- loans and credit cards whose balances count against their net worth.
+CREATE TABLE accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN (
+        'cash', 'investment', 'property', 'vehicle', 'other_asset',
+        'credit_card', 'loan', 'mortgage', 'other_liability'
+    )),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_accounts_user_id ON accounts(user_id, created_at);
+
+-- An account's value, or for a liability what is owed, as of a date. It
+-- holds until the account's next valuation.
+CREATE TABLE valuations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value DECIMAL(10, 2) NOT NULL CHECK (value >= 0),
+    valued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_valuations_account ON valuations(account_id, valued_at);
+CREATE INDEX idx_valuations_user ON valuations(user_id, valued_at);
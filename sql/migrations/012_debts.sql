@@ -0,0 +1,32 @@
+-- Loans a user repays in monthly installments, such as a mortgage or a car
+-- loan. annual_rate is the nominal yearly interest rate in percent, and
+-- payment the installment due each month from first_payment_date.
+CREATE TABLE debts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    principal DECIMAL(10, 2) NOT NULL CHECK (principal > 0),
+    annual_rate DECIMAL(6, 3) NOT NULL CHECK (annual_rate >= 0 AND annual_rate < 100),
+    term_months INTEGER NOT NULL CHECK (term_months BETWEEN 1 AND 600),
+    payment DECIMAL(10, 2) NOT NULL CHECK (payment > 0),
+    first_payment_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_debts_user_id ON debts(user_id, created_at);
+
+-- Money paid toward a debt, either entered by hand or taken from one of the
+-- user's transactions, whose amount and date it copies. A transaction pays
+-- one debt at most.
+CREATE TABLE debt_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    debt_id UUID NOT NULL REFERENCES debts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    note TEXT,
+    paid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_debt_payments_debt ON debt_payments(debt_id, paid_at);
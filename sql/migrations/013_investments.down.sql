@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS security_prices;
+DROP TABLE IF EXISTS lots;
+DROP TABLE IF EXISTS securities;
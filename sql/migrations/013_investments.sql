@@ -0,0 +1,37 @@
+-- Securities a user holds at a brokerage, such as shares or fund units,
+-- identified by the symbol their prices are imported under.
+CREATE TABLE securities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, symbol)
+);
+
+-- Buys and sells of a security. price is per unit and fees are paid on top
+-- of a buy or out of a sell's proceeds.
+CREATE TABLE lots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    security_id UUID NOT NULL REFERENCES securities(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    side VARCHAR(4) NOT NULL CHECK (side IN ('buy', 'sell')),
+    quantity DECIMAL(19, 6) NOT NULL CHECK (quantity > 0 AND quantity < 1000000000000),
+    price DECIMAL(12, 4) NOT NULL CHECK (price >= 0),
+    fees DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (fees >= 0),
+    traded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_lots_security ON lots(security_id, traded_at);
+CREATE INDEX idx_lots_user_id ON lots(user_id, traded_at);
+
+-- A security's closing price on a day, imported from price files rather
+-- than fetched from a market data service. Importing a day again replaces
+-- its price.
+CREATE TABLE security_prices (
+    security_id UUID NOT NULL REFERENCES securities(id) ON DELETE CASCADE,
+    price DECIMAL(12, 4) NOT NULL CHECK (price >= 0),
+    priced_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (security_id, priced_at)
+);
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL schema migrations.
+//
+// Each migration is a pair of files: NNN_name.sql applies it and
+// NNN_name.down.sql reverts it. Migrations are applied in order of NNN.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,26 @@
+-- Users table
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    email TEXT UNIQUE NOT NULL CHECK (length(email) <= 255),
+    created_at TEXT NOT NULL
+);
+
+-- Categories table
+CREATE TABLE categories (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (length(name) <= 100),
+    created_at TEXT NOT NULL,
+    UNIQUE(user_id, name)
+);
+
+-- Transactions table
+CREATE TABLE transactions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id TEXT REFERENCES categories(id) ON DELETE SET NULL,
+    amount REAL NOT NULL CHECK (amount > 0 AND amount < 100000000),
+    description TEXT,
+    occurred_at TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
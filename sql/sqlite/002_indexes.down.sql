@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_category;
+DROP INDEX IF EXISTS idx_transactions_user_date;
+DROP INDEX IF EXISTS idx_categories_user_id;
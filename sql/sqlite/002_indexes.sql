@@ -0,0 +1,8 @@
+-- Index for category lookups by user
+CREATE INDEX idx_categories_user_id ON categories(user_id);
+
+-- Index for transaction lookups by user and date range
+CREATE INDEX idx_transactions_user_date ON transactions(user_id, occurred_at DESC);
+
+-- Index for summary queries
+CREATE INDEX idx_transactions_category ON transactions(category_id);
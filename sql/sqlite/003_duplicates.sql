@@ -0,0 +1,15 @@
+-- Transaction pairs a user has reviewed and marked as not duplicates.
+-- Pairs are stored with the lower id first so each pair has one row.
+CREATE TABLE dismissed_duplicates (
+    transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    duplicate_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (transaction_id, duplicate_id),
+    CHECK (transaction_id < duplicate_id)
+);
+
+CREATE INDEX idx_dismissed_duplicates_user_id ON dismissed_duplicates(user_id);
+
+-- Index for duplicate candidate lookups
+CREATE INDEX idx_transactions_user_amount ON transactions(user_id, amount);
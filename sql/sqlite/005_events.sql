@@ -0,0 +1,11 @@
+-- Changes to each user's ledger, in the order they were committed, for
+-- clients following them live. AUTOINCREMENT keeps ids from being reused.
+CREATE TABLE events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    data TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_events_user_id ON events(user_id, id);
@@ -0,0 +1,29 @@
+-- Every change made to a user's data, who made it and what it looked like
+-- before and after. Rows are only ever added: there is no foreign key to
+-- users, so the log outlives what it describes, and the triggers below
+-- reject updates and deletes.
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before TEXT,
+    after TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_audit_log_user_id ON audit_log(user_id, id);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id);
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
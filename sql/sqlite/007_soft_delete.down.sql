@@ -0,0 +1,9 @@
+-- Rows in the trash would reappear as if never deleted.
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_transactions_deleted_at;
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+
+ALTER TABLE transactions DROP COLUMN deleted_at;
+ALTER TABLE categories DROP COLUMN deleted_at;
@@ -0,0 +1,9 @@
+-- Deleted categories and transactions stay in the trash, with the time they
+-- were deleted, until they are restored or purged. A category's name stays
+-- taken while it is in the trash, so restoring it never conflicts.
+ALTER TABLE categories ADD COLUMN deleted_at TEXT;
+ALTER TABLE transactions ADD COLUMN deleted_at TEXT;
+
+-- Indexes for the trash and the purge
+CREATE INDEX idx_categories_deleted_at ON categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_transactions_deleted_at ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP TRIGGER audit_log_no_update;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,16 @@
+-- Erasing a user redacts their audit entries: the snapshots and request id
+-- are cleared and the actor replaced with 'redacted'. That is the only
+-- update the audit log allows, and rows still can't be deleted.
+DROP TRIGGER audit_log_no_update;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+WHEN NOT (
+    NEW.before IS NULL AND NEW.after IS NULL AND NEW.request_id IS NULL
+    AND NEW.actor = 'redacted'
+    AND NEW.id = OLD.id AND NEW.user_id = OLD.user_id
+    AND NEW.entity = OLD.entity AND NEW.entity_id = OLD.entity_id
+    AND NEW.action = OLD.action AND NEW.created_at = OLD.created_at
+)
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
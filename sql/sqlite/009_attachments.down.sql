@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS attachments_orphan_blob;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS orphaned_blobs;
@@ -0,0 +1,28 @@
+-- Files such as receipts and invoices kept with a transaction. The content
+-- is in the blob store under storage_key.
+CREATE TABLE attachments (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL CHECK (size >= 0),
+    sha256 TEXT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_attachments_transaction ON attachments(transaction_id, created_at);
+
+-- The blobs of deleted attachments, however they were deleted, until they
+-- are removed from the blob store.
+CREATE TABLE orphaned_blobs (
+    storage_key TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL
+);
+
+CREATE TRIGGER attachments_orphan_blob AFTER DELETE ON attachments
+BEGIN
+    INSERT OR IGNORE INTO orphaned_blobs (storage_key, created_at)
+    VALUES (OLD.storage_key, strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'));
+END;
@@ -0,0 +1,27 @@
+-- Amounts a user is saving toward, optionally by a date.
+CREATE TABLE goals (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (length(name) <= 100),
+    target_amount REAL NOT NULL CHECK (target_amount > 0 AND target_amount < 100000000),
+    target_date TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_goals_user_id ON goals(user_id, created_at);
+
+-- Money put toward a goal, either entered by hand or taken from one of the
+-- user's transactions, whose amount and date it copies. A transaction
+-- contributes to one goal at most.
+CREATE TABLE goal_contributions (
+    id TEXT PRIMARY KEY,
+    goal_id TEXT NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id TEXT UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    amount REAL NOT NULL CHECK (amount > 0 AND amount < 100000000),
+    note TEXT,
+    contributed_at TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_goal_contributions_goal ON goal_contributions(goal_id, contributed_at);
@@ -0,0 +1,28 @@
+-- What a user owns or owes: bank accounts, property and vehicles, and the
+-- loans and credit cards whose balances count against their net worth.
+CREATE TABLE accounts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (length(name) <= 100),
+    type TEXT NOT NULL CHECK (type IN (
+        'cash', 'investment', 'property', 'vehicle', 'other_asset',
+        'credit_card', 'loan', 'mortgage', 'other_liability'
+    )),
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_accounts_user_id ON accounts(user_id, created_at);
+
+-- An account's value, or for a liability what is owed, as of a date. It
+-- holds until the account's next valuation.
+CREATE TABLE valuations (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value REAL NOT NULL CHECK (value >= 0 AND value < 100000000),
+    valued_at TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_valuations_account ON valuations(account_id, valued_at);
+CREATE INDEX idx_valuations_user ON valuations(user_id, valued_at);
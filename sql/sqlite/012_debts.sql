@@ -0,0 +1,32 @@
+-- Loans a user repays in monthly installments, such as a mortgage or a car
+-- loan. annual_rate is the nominal yearly interest rate in percent, and
+-- payment the installment due each month from first_payment_date.
+CREATE TABLE debts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (length(name) <= 100),
+    principal REAL NOT NULL CHECK (principal > 0 AND principal < 100000000),
+    annual_rate REAL NOT NULL CHECK (annual_rate >= 0 AND annual_rate < 100),
+    term_months INTEGER NOT NULL CHECK (term_months BETWEEN 1 AND 600),
+    payment REAL NOT NULL CHECK (payment > 0 AND payment < 100000000),
+    first_payment_date TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_debts_user_id ON debts(user_id, created_at);
+
+-- Money paid toward a debt, either entered by hand or taken from one of the
+-- user's transactions, whose amount and date it copies. A transaction pays
+-- one debt at most.
+CREATE TABLE debt_payments (
+    id TEXT PRIMARY KEY,
+    debt_id TEXT NOT NULL REFERENCES debts(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id TEXT UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    amount REAL NOT NULL CHECK (amount > 0 AND amount < 100000000),
+    note TEXT,
+    paid_at TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_debt_payments_debt ON debt_payments(debt_id, paid_at);
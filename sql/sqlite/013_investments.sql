@@ -0,0 +1,37 @@
+-- Securities a user holds at a brokerage, such as shares or fund units,
+-- identified by the symbol their prices are imported under.
+CREATE TABLE securities (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol TEXT NOT NULL CHECK (length(symbol) <= 20),
+    name TEXT NOT NULL CHECK (length(name) <= 100),
+    created_at TEXT NOT NULL,
+    UNIQUE(user_id, symbol)
+);
+
+-- Buys and sells of a security. price is per unit and fees are paid on top
+-- of a buy or out of a sell's proceeds.
+CREATE TABLE lots (
+    id TEXT PRIMARY KEY,
+    security_id TEXT NOT NULL REFERENCES securities(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    side TEXT NOT NULL CHECK (side IN ('buy', 'sell')),
+    quantity REAL NOT NULL CHECK (quantity > 0 AND quantity < 1000000000000),
+    price REAL NOT NULL CHECK (price >= 0 AND price < 100000000),
+    fees REAL NOT NULL DEFAULT 0 CHECK (fees >= 0 AND fees < 100000000),
+    traded_at TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_lots_security ON lots(security_id, traded_at);
+CREATE INDEX idx_lots_user_id ON lots(user_id, traded_at);
+
+-- A security's closing price on a day, imported from price files rather
+-- than fetched from a market data service. Importing a day again replaces
+-- its price.
+CREATE TABLE security_prices (
+    security_id TEXT NOT NULL REFERENCES securities(id) ON DELETE CASCADE,
+    price REAL NOT NULL CHECK (price >= 0 AND price < 100000000),
+    priced_at TEXT NOT NULL,
+    PRIMARY KEY (security_id, priced_at)
+);
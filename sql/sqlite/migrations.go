@@ -0,0 +1,11 @@
+// Package sqlite embeds the SQL schema migrations for the SQLite backend.
+//
+// They mirror the Postgres migrations version for version. Ids are UUID
+// text, timestamps are fixed-width UTC text so they sort correctly, and
+// amounts are REAL values rounded to the cent before they are stored.
+package sqlite
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS